@@ -4,7 +4,7 @@ import MessageList from './MessageList';
 import MessageInput from './MessageInput';
 import './styles/Chat.css';
 import { useTranslation } from '../i18n';
-import { useSession, Message } from '../contexts/SessionContext';
+import { useSession, Message, getWebSessionID } from '../contexts/SessionContext';
 
 interface Conversation {
   id: string;
@@ -75,7 +75,7 @@ export default function Chat() {
   const connectWebSocket = useCallback(() => {
     const wsProtocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
     const wsHost = import.meta.env.VITE_WS_HOST || window.location.host;
-    const wsUrl = `${wsProtocol}//${wsHost}/ws`;
+    const wsUrl = `${wsProtocol}//${wsHost}/ws?session_id=${encodeURIComponent(getWebSessionID())}`;
 
     console.log('Connecting to WebSocket:', wsUrl);
 
@@ -31,6 +31,10 @@ interface KVPair {
   value: string;
 }
 
+// 远程 server（sse / streamable http）通过 URL 连接
+const isRemoteType = (type?: string) =>
+  type === "sse" || type === "http" || type === "streamable-http";
+
 function KVEditor({ pairs, onChange, keyPlaceholder, valuePlaceholder }: {
   pairs: KVPair[];
   onChange: (pairs: KVPair[]) => void;
@@ -61,6 +65,10 @@ function KVEditor({ pairs, onChange, keyPlaceholder, valuePlaceholder }: {
 
 export default function MCPServers() {
   const { t } = useTranslation();
+  const typeLabel = (type?: string) => {
+    if (type === "http" || type === "streamable-http") return t("mcp.typeHTTP");
+    return type === "sse" ? t("mcp.typeSSE") : t("mcp.typeStdio");
+  };
   const [tab, setTab] = useState<"catalog" | "installed" | "custom">("catalog");
   const [servers, setServers] = useState<MCPServer[]>([]);
   const [catalog, setCatalog] = useState<CatalogEntry[]>([]);
@@ -71,7 +79,7 @@ export default function MCPServers() {
   const [expandedTools, setExpandedTools] = useState<Record<string, MCPTool[]>>({});
   const [formData, setFormData] = useState({
     name: "",
-    type: "sse" as "sse" | "stdio",
+    type: "sse" as "sse" | "http" | "stdio",
     url: "",
     headers: [] as KVPair[],
     command: "",
@@ -145,11 +153,11 @@ export default function MCPServers() {
 
     let body: Record<string, unknown>;
 
-    if (formData.type === "sse") {
+    if (formData.type !== "stdio") {
       if (!formData.url) return;
       body = {
         name: formData.name,
-        type: "sse",
+        type: formData.type,
         url: formData.url,
         headers: kvToRecord(formData.headers),
         env: kvToRecord(formData.env),
@@ -242,14 +250,14 @@ export default function MCPServers() {
               <div className="server-info">
                 <div className="info-row">
                   <span className="info-label">{t("mcp.type")}</span>
-                  <span className="info-value">{server.config.type === "sse" ? t("mcp.typeSSE") : t("mcp.typeStdio")}</span>
+                  <span className="info-value">{typeLabel(server.config.type)}</span>
                 </div>
                 <div className="info-row">
                   <span className="info-label">
-                    {server.config.type === "sse" ? t("mcp.url") : t("mcp.command")}
+                    {isRemoteType(server.config.type) ? t("mcp.url") : t("mcp.command")}
                   </span>
                   <span className="info-value">
-                    {server.config.type === "sse"
+                    {isRemoteType(server.config.type)
                       ? server.config.url
                       : `${server.config.command} ${server.config.args?.join(" ") || ""}`}
                   </span>
@@ -321,6 +329,10 @@ export default function MCPServers() {
                   className={`type-btn ${formData.type === "sse" ? "active" : ""}`}
                   onClick={() => setFormData({ ...formData, type: "sse" })}
                 >{t("mcp.typeSSE")}</button>
+                <button
+                  className={`type-btn ${formData.type === "http" ? "active" : ""}`}
+                  onClick={() => setFormData({ ...formData, type: "http" })}
+                >{t("mcp.typeHTTP")}</button>
                 <button
                   className={`type-btn ${formData.type === "stdio" ? "active" : ""}`}
                   onClick={() => setFormData({ ...formData, type: "stdio" })}
@@ -328,7 +340,7 @@ export default function MCPServers() {
               </div>
             </div>
 
-            {formData.type === "sse" ? (
+            {formData.type !== "stdio" ? (
               <>
                 <div className="form-group">
                   <label>{t("mcp.url")}</label>
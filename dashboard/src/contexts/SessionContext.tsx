@@ -27,13 +27,30 @@ interface SessionContextType {
 
 const SessionContext = createContext<SessionContextType | undefined>(undefined);
 
+const WEB_SESSION_STORAGE_KEY = 'mindx.webSessionID';
+
+// getWebSessionID returns a stable per-browser session ID so the realtime
+// channel and the conversations API resolve the same isolated session.
+export function getWebSessionID(): string {
+  let id = localStorage.getItem(WEB_SESSION_STORAGE_KEY);
+  if (!id) {
+    id = `web_${Date.now()}_${Math.random().toString(36).slice(2, 10)}`;
+    localStorage.setItem(WEB_SESSION_STORAGE_KEY, id);
+  }
+  return id;
+}
+
+export function sessionQuery(): string {
+  return `channel_id=realtime&session_id=${encodeURIComponent(getWebSessionID())}`;
+}
+
 export function SessionProvider({ children }: { children: ReactNode }) {
   const [currentSession, setCurrentSession] = useState<Session | null>(null);
   const [messages, setMessages] = useState<Message[]>([]);
 
   const loadCurrentSession = useCallback(async () => {
     try {
-      const response = await fetch('/api/conversations/current');
+      const response = await fetch(`/api/conversations/current?${sessionQuery()}`);
       if (!response.ok) {
         console.error('Failed to load current session');
         return;
@@ -61,7 +78,7 @@ export function SessionProvider({ children }: { children: ReactNode }) {
 
   const switchSession = useCallback(async (sessionId: string) => {
     try {
-      const response = await fetch(`/api/conversations/${sessionId}/switch`, {
+      const response = await fetch(`/api/conversations/${sessionId}/switch?${sessionQuery()}`, {
         method: 'POST',
       });
       if (!response.ok) {
@@ -86,7 +103,7 @@ export function SessionProvider({ children }: { children: ReactNode }) {
 
   const createNewSession = useCallback(async () => {
     try {
-      const response = await fetch('/api/conversations', {
+      const response = await fetch(`/api/conversations?${sessionQuery()}`, {
         method: 'POST',
       });
       if (!response.ok) {
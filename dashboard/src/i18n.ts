@@ -88,6 +88,7 @@ const translations = {
       name: '名称',
       type: '类型',
       typeSSE: '公共',
+      typeHTTP: '公共 (HTTP)',
       typeStdio: '本地',
       command: '命令',
       args: '参数',
@@ -381,6 +382,7 @@ const translations = {
       name: 'Name',
       type: 'Type',
       typeSSE: 'Public',
+      typeHTTP: 'Public (HTTP)',
       typeStdio: 'Local',
       command: 'Command',
       args: 'Arguments',
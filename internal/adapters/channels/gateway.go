@@ -3,6 +3,7 @@ package channels
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"mindx/internal/entity"
@@ -19,6 +20,7 @@ type Gateway struct {
 	channelContextMgr *ChannelContextManager
 	defaultChan       string
 	onMessage         func(ctx context.Context, msg *entity.IncomingMessage, eventChan chan<- entity.ThinkingEvent) (string, string, error)
+	commandHandler    func(ctx context.Context, msg *entity.IncomingMessage) (string, bool)
 	embeddingSvc      *embedding.EmbeddingService
 	channelVectors    map[string][]float64
 	logger            logging.Logger
@@ -28,6 +30,10 @@ type Gateway struct {
 	mu                sync.RWMutex
 	activeMessages    int
 	shutdownWG        sync.WaitGroup
+
+	inflightMu  sync.Mutex
+	inflightSeq uint64
+	inflight    map[entity.SessionKey]map[uint64]context.CancelFunc // 各会话正在处理的请求
 }
 
 // NewGateway 创建网关
@@ -48,6 +54,7 @@ func NewGateway(defaultChannel string, embeddingSvc *embedding.EmbeddingService)
 		convLogger:        logging.GetConversationLogger(),
 		ctx:               ctx,
 		cancel:            cancel,
+		inflight:          make(map[entity.SessionKey]map[uint64]context.CancelFunc),
 	}
 
 	if embeddingSvc != nil {
@@ -71,7 +78,83 @@ func (r *Gateway) SetOnMessage(callback func(ctx context.Context, msg *entity.In
 	r.onMessage = callback
 }
 
+type replyAttachmentsKey struct{}
+
+// AttachToReply 把附件（如 MCP 工具返回的图片）加入当前消息的回答，随回答一起发送到来源 Channel
+// 在 onMessage 回调中以 Gateway 传入的 ctx 调用，附件只属于这一条消息的回答
+func AttachToReply(ctx context.Context, attachments ...*entity.Attachment) {
+	if holder, ok := ctx.Value(replyAttachmentsKey{}).(*[]*entity.Attachment); ok {
+		*holder = append(*holder, attachments...)
+	}
+}
+
+// SetCommandHandler 设置会话指令处理器
+// 指令（如工具调用的确认回复）在进入大脑前拦截处理，handled 为 true 时把 reply 回复到来源 Channel
+func (r *Gateway) SetCommandHandler(handler func(ctx context.Context, msg *entity.IncomingMessage) (reply string, handled bool)) {
+	r.commandHandler = handler
+}
+
+// SendToSession 向会话所在的 Channel 主动发送一条文本消息
+func (r *Gateway) SendToSession(ctx context.Context, key entity.SessionKey, content string) error {
+	return r.sendToChannel(ctx, key.ChannelID, key.SenderID, content)
+}
+
+// CancelSession 取消会话正在处理的请求（思考和正在执行的技能），返回被取消的请求数
+func (r *Gateway) CancelSession(key entity.SessionKey) int {
+	r.inflightMu.Lock()
+	defer r.inflightMu.Unlock()
+
+	requests := r.inflight[key]
+	for _, cancel := range requests {
+		cancel()
+	}
+	delete(r.inflight, key)
+	return len(requests)
+}
+
+// trackRequest 登记会话正在处理的请求，返回可被 CancelSession 取消的 ctx 和注销函数
+func (r *Gateway) trackRequest(ctx context.Context, key entity.SessionKey) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	r.inflightMu.Lock()
+	r.inflightSeq++
+	id := r.inflightSeq
+	if r.inflight[key] == nil {
+		r.inflight[key] = make(map[uint64]context.CancelFunc)
+	}
+	r.inflight[key][id] = cancel
+	r.inflightMu.Unlock()
+
+	return ctx, func() {
+		cancel()
+		r.inflightMu.Lock()
+		defer r.inflightMu.Unlock()
+		if requests, ok := r.inflight[key]; ok {
+			delete(requests, id)
+			if len(requests) == 0 {
+				delete(r.inflight, key)
+			}
+		}
+	}
+}
+
+// isStopCommand 是否为停止当前请求的指令
+func isStopCommand(content string) bool {
+	switch strings.ToLower(strings.TrimSpace(content)) {
+	case "/stop", "/停止":
+		return true
+	}
+	return false
+}
+
+// HandleMessage 处理 Channel 收到的消息
 func (r *Gateway) HandleMessage(ctx context.Context, msg *entity.IncomingMessage) {
+	_ = r.Dispatch(ctx, msg)
+}
+
+// Dispatch 处理一条消息并将回复发送回消息所属的 Channel 和会话
+// 与 HandleMessage 相同，但返回处理或发送回复失败的错误，供定时任务等系统发起的消息记录结果
+func (r *Gateway) Dispatch(ctx context.Context, msg *entity.IncomingMessage) error {
 	// 检查是否正在关闭
 	r.mu.RLock()
 	isShuttingDown := r.ctx.Err() != nil
@@ -81,7 +164,7 @@ func (r *Gateway) HandleMessage(ctx context.Context, msg *entity.IncomingMessage
 		r.logger.Debug("Router is shutting down, rejecting message",
 			logging.String("session_id", msg.SessionID),
 		)
-		return
+		return fmt.Errorf("gateway is shutting down")
 	}
 
 	// 增加活跃消息计数
@@ -117,6 +200,34 @@ func (r *Gateway) HandleMessage(ctx context.Context, msg *entity.IncomingMessage
 		logging.String("content_type", msg.ContentType),
 	)
 
+	// /stop 取消该会话正在处理的请求
+	if isStopCommand(msg.Content) {
+		reply := i18n.T("adapter.stop_none")
+		if n := r.CancelSession(msg.SessionKey()); n > 0 {
+			reply = i18n.T("adapter.stop_done")
+		}
+		if err := r.sendToChannel(ctx, msg.ChannelID, msg.SessionID, reply); err != nil {
+			r.logger.Warn("发送指令回复失败",
+				logging.String(i18n.T("adapter.session_id"), msg.SessionID),
+				logging.Err(err))
+		}
+		return nil
+	}
+
+	// 会话指令不进入大脑
+	if r.commandHandler != nil {
+		if reply, handled := r.commandHandler(ctx, msg); handled {
+			if reply != "" {
+				if err := r.sendToChannel(ctx, msg.ChannelID, msg.SessionID, reply); err != nil {
+					r.logger.Warn("发送指令回复失败",
+						logging.String(i18n.T("adapter.session_id"), msg.SessionID),
+						logging.Err(err))
+				}
+			}
+			return nil
+		}
+	}
+
 	// 1. 确保 Channel 会话上下文存在
 	r.channelContextMgr.Ensure(msg.SessionID, msg.ChannelID)
 
@@ -137,18 +248,29 @@ func (r *Gateway) HandleMessage(ctx context.Context, msg *entity.IncomingMessage
 		}
 	}
 
-	answer, sendTo, err := r.onMessage(ctx, msg, eventChan)
+	var attachments []*entity.Attachment
+	reqCtx, untrack := r.trackRequest(ctx, msg.SessionKey())
+	reqCtx = context.WithValue(reqCtx, replyAttachmentsKey{}, &attachments)
+	answer, sendTo, err := r.onMessage(reqCtx, msg, eventChan)
+	canceled := err != nil && reqCtx.Err() != nil
+	untrack()
+	if canceled {
+		// 用户主动取消或连接已断开导致处理中止，不再发送错误
+		r.logger.Info("请求已取消", logging.String(i18n.T("adapter.session_id"), msg.SessionID))
+		return err
+	}
 	if err != nil {
 		r.logger.Error(i18n.T("adapter.msg_process_failed"),
 			logging.String(i18n.T("adapter.session_id"), msg.SessionID),
 			logging.Err(err),
 		)
 		r.sendErrorResponse(ctx, msg, err)
-		return
+		return err
 	}
 
 	// 1. 发送响应到当前 Channel
-	if answer != "" {
+	var sendErr error
+	if answer != "" || len(attachments) > 0 {
 		// 同步到 RealTimeChannel（保持信息流畅性）
 		// 如果当前 Channel 不是 RealTimeChannel，则同步消息
 		if msg.ChannelID != "realtime" {
@@ -156,12 +278,13 @@ func (r *Gateway) HandleMessage(ctx context.Context, msg *entity.IncomingMessage
 		}
 
 		// 发送到当前 Channel
-		if err := r.sendToChannel(ctx, msg.ChannelID, msg.SessionID, answer); err != nil {
+		if err := r.sendToChannel(ctx, msg.ChannelID, msg.SessionID, answer, attachments...); err != nil {
 			r.logger.Error(i18n.T("adapter.send_response_failed"),
 				logging.String(i18n.T("adapter.channel_id"), msg.ChannelID),
 				logging.String(i18n.T("adapter.session_id"), msg.SessionID),
 				logging.Err(err),
 			)
+			sendErr = fmt.Errorf("send response to %s: %w", msg.ChannelID, err)
 		} else {
 			// 记录回复的对话日志
 			r.convLogger.Info("发送回复",
@@ -170,6 +293,7 @@ func (r *Gateway) HandleMessage(ctx context.Context, msg *entity.IncomingMessage
 				logging.String("direction", "outgoing"),
 				logging.String("content", answer),
 				logging.String("content_type", "text"),
+				logging.Int("attachments", len(attachments)),
 			)
 		}
 	}
@@ -180,7 +304,7 @@ func (r *Gateway) HandleMessage(ctx context.Context, msg *entity.IncomingMessage
 		r.logger.Debug(i18n.T("adapter.sendto_empty_skip"),
 			logging.String(i18n.T("adapter.session_id"), msg.SessionID),
 		)
-		return
+		return sendErr
 	}
 
 	r.logger.Info(i18n.T("adapter.forward_intent"),
@@ -195,12 +319,12 @@ func (r *Gateway) HandleMessage(ctx context.Context, msg *entity.IncomingMessage
 		r.logger.Warn(i18n.T("adapter.target_channel_not_match"),
 			logging.String("send_to", sendTo),
 		)
-		return
+		return sendErr
 	}
 
 	if matchedChannel == msg.ChannelID {
 		r.logger.Info(i18n.T("adapter.same_channel_skip"))
-		return
+		return sendErr
 	}
 
 	r.logger.Info(i18n.T("adapter.forward_to_target"),
@@ -269,6 +393,7 @@ func (r *Gateway) HandleMessage(ctx context.Context, msg *entity.IncomingMessage
 			}
 		}
 	}
+	return sendErr
 }
 
 // handleChannelSwitch 处理 Channel 切换
@@ -329,8 +454,8 @@ func (r *Gateway) syncToRealTimeChannel(ctx context.Context, channelID, sessionI
 	}
 }
 
-// sendToChannel 发送消息到指定 Channel
-func (r *Gateway) sendToChannel(ctx context.Context, channelID, sessionID, content string) error {
+// sendToChannel 发送消息到指定 Channel，可附带附件
+func (r *Gateway) sendToChannel(ctx context.Context, channelID, sessionID, content string, attachments ...*entity.Attachment) error {
 	channel, err := r.manager.Get(channelID)
 	if err != nil {
 		return err
@@ -345,6 +470,7 @@ func (r *Gateway) sendToChannel(ctx context.Context, channelID, sessionID, conte
 		SessionID:   sessionID,
 		Content:     content,
 		ContentType: "text",
+		Attachments: attachments,
 	}
 
 	return channel.SendMessage(ctx, outMsg)
@@ -0,0 +1,114 @@
+package channels
+
+import (
+	"context"
+	"mindx/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGateway_StopCommand 测试 /stop 取消同一会话正在处理的请求
+func TestGateway_StopCommand(t *testing.T) {
+	gateway := NewGateway("realtime", mockEmbeddingService())
+
+	channel := NewMockChannel("test", entity.ChannelTypeRealTime, "Test")
+	gateway.Manager().AddChannel(channel)
+	channel.Start(context.Background())
+
+	started := make(chan struct{})
+	gateway.SetOnMessage(func(ctx context.Context, msg *entity.IncomingMessage, eventChan chan<- entity.ThinkingEvent) (string, string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", "", ctx.Err()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		gateway.HandleMessage(context.Background(), createTestMessage("test", "session1", "写一篇长文"))
+		close(done)
+	}()
+	<-started
+
+	// 其他会话的 /stop 不影响该请求
+	gateway.HandleMessage(context.Background(), createTestMessage("test", "session2", "/stop"))
+	select {
+	case <-done:
+		t.Fatal("request should not be canceled by another session")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	gateway.HandleMessage(context.Background(), createTestMessage("test", "session1", " /STOP "))
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("request was not canceled")
+	}
+
+	// 只有两条 /stop 的回复，被取消的请求不再发送错误
+	sent := channel.GetSentMessages()
+	require.Len(t, sent, 2)
+	assert.Equal(t, "session2", sent[0].SessionID)
+	assert.Equal(t, "session1", sent[1].SessionID)
+	assert.NotEqual(t, sent[0].Content, sent[1].Content)
+	assert.Equal(t, 0, gateway.CancelSession(entity.NewSessionKey("test", "session1")))
+}
+
+// TestGateway_CommandHandler 测试会话指令在进入大脑前被拦截
+func TestGateway_CommandHandler(t *testing.T) {
+	gateway := NewGateway("realtime", mockEmbeddingService())
+
+	channel := NewMockChannel("test", entity.ChannelTypeRealTime, "Test")
+	gateway.Manager().AddChannel(channel)
+	channel.Start(context.Background())
+
+	var asked []string
+	gateway.SetOnMessage(func(ctx context.Context, msg *entity.IncomingMessage, eventChan chan<- entity.ThinkingEvent) (string, string, error) {
+		asked = append(asked, msg.Content)
+		return "answer", "", nil
+	})
+	gateway.SetCommandHandler(func(ctx context.Context, msg *entity.IncomingMessage) (string, bool) {
+		if msg.Content == "同意" {
+			return "已批准", true
+		}
+		return "", false
+	})
+
+	gateway.HandleMessage(context.Background(), createTestMessage("test", "session1", "同意"))
+	gateway.HandleMessage(context.Background(), createTestMessage("test", "session1", "你好"))
+
+	assert.Equal(t, []string{"你好"}, asked)
+	sent := channel.GetSentMessages()
+	require.Len(t, sent, 2)
+	assert.Equal(t, "已批准", sent[0].Content)
+	assert.Equal(t, "answer", sent[1].Content)
+}
+
+// TestGateway_Dispatch 测试 Dispatch 返回处理和发送回复的错误
+func TestGateway_Dispatch(t *testing.T) {
+	gateway := NewGateway("realtime", mockEmbeddingService())
+
+	channel := NewMockChannel("telegram", entity.ChannelTypeTelegram, "Telegram")
+	gateway.Manager().AddChannel(channel)
+	channel.Start(context.Background())
+
+	gateway.SetOnMessage(func(ctx context.Context, msg *entity.IncomingMessage, eventChan chan<- entity.ThinkingEvent) (string, string, error) {
+		if msg.Content == "fail" {
+			return "", "", assert.AnError
+		}
+		return "早上好", "", nil
+	})
+
+	require.NoError(t, gateway.Dispatch(context.Background(), createTestMessage("telegram", "chat-42", "提醒我喝水")))
+	sent := channel.GetSentMessages()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "chat-42", sent[0].SessionID)
+	assert.Equal(t, "早上好", sent[0].Content)
+
+	assert.ErrorIs(t, gateway.Dispatch(context.Background(), createTestMessage("telegram", "chat-42", "fail")), assert.AnError)
+
+	channel.Stop()
+	assert.Error(t, gateway.Dispatch(context.Background(), createTestMessage("telegram", "chat-42", "提醒我喝水")))
+}
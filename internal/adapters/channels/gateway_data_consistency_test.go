@@ -4,6 +4,8 @@ import (
 	"mindx/internal/entity"
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -243,3 +245,39 @@ func TestGateway_DataConsistency_ContextPreservation(t *testing.T) {
 	sentMessages := channel.GetSentMessages()
 	assert.Equal(t, 10, len(sentMessages), "应该有10条消息")
 }
+
+// TestGateway_DataConsistency_ReplyAttachments 同一会话交叠处理的两条消息，附件只随各自的回答发送
+func TestGateway_DataConsistency_ReplyAttachments(t *testing.T) {
+	gateway := NewGateway("realtime", mockEmbeddingService())
+
+	channel := NewMockChannel("test", entity.ChannelTypeRealTime, "Test")
+	gateway.Manager().AddChannel(channel)
+	channel.Start(context.Background())
+
+	release := make(chan struct{})
+	gateway.SetOnMessage(func(ctx context.Context, msg *entity.IncomingMessage, eventChan chan<- entity.ThinkingEvent) (string, string, error) {
+		if msg.Content == "slow" {
+			<-release
+		}
+		AttachToReply(ctx, &entity.Attachment{Type: "image", URL: msg.Content + ".png"})
+		return "Reply to " + msg.Content, "", nil
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		gateway.HandleMessage(context.Background(), createTestMessage("test", "session1", "slow"))
+	}()
+	gateway.HandleMessage(context.Background(), createTestMessage("test", "session1", "fast"))
+	close(release)
+	wg.Wait()
+
+	sentMessages := channel.GetSentMessages()
+	assert.Len(t, sentMessages, 2)
+	for _, sent := range sentMessages {
+		if assert.Len(t, sent.Attachments, 1, sent.Content) {
+			assert.Equal(t, strings.TrimPrefix(sent.Content, "Reply to ")+".png", sent.Attachments[0].URL)
+		}
+	}
+}
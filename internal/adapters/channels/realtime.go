@@ -30,6 +30,8 @@ type RealTimeChannel struct {
 	startTime       *time.Time
 	logger          logging.Logger
 	onThinkingEvent func(sessionID string, event map[string]any) // 思考流事件回调
+	onApproval      func(key entity.SessionKey, id string, approved bool, operator string) error
+	onCancel        func(key entity.SessionKey) int
 	maxConnections  int
 	wsCfg           config.WebSocketConfig
 	lifecycleCtx    context.Context // 渠道生命周期 context
@@ -162,6 +164,18 @@ func (w *RealTimeChannel) SetOnMessage(callback func(ctx context.Context, msg *e
 	w.onMessage = callback
 }
 
+// SetOnApproval 设置工具调用确认回调
+// 客户端发送 {"type":"approval","id":"...","approved":true} 时调用
+func (w *RealTimeChannel) SetOnApproval(callback func(key entity.SessionKey, id string, approved bool, operator string) error) {
+	w.onApproval = callback
+}
+
+// SetOnCancel 设置取消回调
+// 客户端发送 {"type":"cancel"} 时调用，取消该会话正在处理的请求，返回被取消的请求数
+func (w *RealTimeChannel) SetOnCancel(callback func(key entity.SessionKey) int) {
+	w.onCancel = callback
+}
+
 // SetOnThinkingEvent 设置思考流事件回调
 func (w *RealTimeChannel) SetOnThinkingEvent(callback func(sessionID string, event map[string]any)) {
 	w.onThinkingEvent = callback
@@ -233,6 +247,9 @@ func (w *RealTimeChannel) SendMessage(ctx context.Context, msg *entity.OutgoingM
 				"content":   msg.Content,
 				"timestamp": time.Now().Unix(),
 			}
+			if len(msg.Attachments) > 0 {
+				response["attachments"] = msg.Attachments
+			}
 
 			if err := conn.WriteJSON(response); err != nil {
 				w.logger.Error(i18n.T("adapter.send_msg_failed"),
@@ -462,6 +479,24 @@ func (w *RealTimeChannel) handleMessages(client *entity.WebClient) {
 	// 初始读超时
 	_ = conn.SetReadDeadline(time.Now().Add(readDeadline))
 
+	// 消息交给独立的 worker 串行处理，读循环保持空闲以便在处理期间接收确认、取消等控制消息
+	// 连接断开时取消正在处理的请求
+	parentCtx := w.lifecycleCtx
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+	clientCtx, cancelClient := context.WithCancel(parentCtx)
+	defer cancelClient()
+	queue := make(chan *entity.IncomingMessage, 16)
+	defer close(queue)
+	go func() {
+		for msg := range queue {
+			if w.onMessage != nil {
+				w.onMessage(clientCtx, msg)
+			}
+		}
+	}()
+
 	// 启动心跳 goroutine
 	done := make(chan struct{})
 	defer close(done)
@@ -547,10 +582,13 @@ func (w *RealTimeChannel) handleMessages(client *entity.WebClient) {
 			now := time.Now()
 			w.lastMessage = &now
 
-			// 调用消息回调
-			if w.onMessage != nil {
-				w.onMessage(w.lifecycleCtx, msg)
-			}
+			queue <- msg
+
+		case "approval":
+			w.handleApproval(client, msgData)
+
+		case "cancel":
+			w.handleCancel(client)
 
 		default:
 			w.logger.Debug(i18n.T("adapter.unknown_msg_type"), logging.String(i18n.T("adapter.msg_type"), msgType))
@@ -558,6 +596,61 @@ func (w *RealTimeChannel) handleMessages(client *entity.WebClient) {
 	}
 }
 
+// handleCancel 取消客户端会话正在处理的请求
+func (w *RealTimeChannel) handleCancel(client *entity.WebClient) {
+	canceled := 0
+	if w.onCancel != nil {
+		canceled = w.onCancel(entity.NewSessionKey(client.ChannelID, client.SessionID))
+	}
+	w.logger.Info("客户端取消请求",
+		logging.String(i18n.T("adapter.session_id"), client.SessionID),
+		logging.Int("canceled", canceled))
+
+	if err := client.Conn.WriteJSON(map[string]any{
+		"type":      "cancelled",
+		"count":     canceled,
+		"timestamp": time.Now().Unix(),
+	}); err != nil {
+		w.logger.Warn(i18n.T("adapter.send_msg_failed"),
+			logging.String(i18n.T("adapter.session_id"), client.SessionID),
+			logging.Err(err))
+	}
+}
+
+// handleApproval 处理客户端对工具调用的确认
+func (w *RealTimeChannel) handleApproval(client *entity.WebClient, msgData map[string]any) {
+	id, _ := msgData["id"].(string)
+	approved, _ := msgData["approved"].(bool)
+
+	response := map[string]any{
+		"type":      "approval_result",
+		"id":        id,
+		"approved":  approved,
+		"timestamp": time.Now().Unix(),
+	}
+
+	var err error
+	if w.onApproval == nil {
+		err = fmt.Errorf("approval is not enabled")
+	} else {
+		key := entity.NewSessionKey(client.ChannelID, client.SessionID)
+		err = w.onApproval(key, id, approved, client.SenderID)
+	}
+	if err != nil {
+		w.logger.Warn("处理工具调用确认失败",
+			logging.String(i18n.T("adapter.session_id"), client.SessionID),
+			logging.String("id", id),
+			logging.Err(err))
+		response["error"] = err.Error()
+	}
+
+	if err := client.Conn.WriteJSON(response); err != nil {
+		w.logger.Warn(i18n.T("adapter.send_msg_failed"),
+			logging.String(i18n.T("adapter.session_id"), client.SessionID),
+			logging.Err(err))
+	}
+}
+
 // GetActiveConnections 获取活跃连接数
 func (w *RealTimeChannel) GetActiveConnections() int {
 	w.mutex.RLock()
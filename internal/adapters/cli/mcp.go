@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"mindx/internal/config"
+	"mindx/internal/usecase/approval"
+	"mindx/internal/usecase/skills"
+	"mindx/internal/usecase/skills/builtins"
+	"mindx/pkg/i18n"
+	"mindx/pkg/logging"
+
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: i18n.T("cli.mcp.short"),
+	Long:  i18n.T("cli.mcp.long"),
+}
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: i18n.T("cli.mcp.serve.short"),
+	Long:  i18n.T("cli.mcp.serve.long"),
+	Example: `  # ` + i18n.T("cli.mcp.serve.example") + `
+  mindx mcp serve`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// stdout 为 MCP 协议通道，所有提示信息只能写到 stderr
+		mgr, err := createSkillManager()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, i18n.TWithData("cli.skill.list.init_error", map[string]interface{}{"Error": err.Error()}))
+			os.Exit(1)
+		}
+
+		builtins.RegisterBuiltins(mgr, defaultBuiltinConfig(), nil)
+
+		approvalMgr, err := newApprovalManager()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, i18n.TWithData("cli.mcp.serve.error", map[string]interface{}{"Error": err.Error()}))
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := skills.NewMCPServer(mgr, approvalMgr, logging.GetSystemLogger())
+
+		// 连接已配置的 MCP server，完成后把转出的工具同步给客户端
+		if mcpCfg, err := config.LoadMCPServersConfig(); err == nil && len(mcpCfg.MCPServers) > 0 {
+			go func() {
+				mgr.InitMCPServers(ctx, mcpCfg)
+				server.Sync()
+			}()
+		}
+
+		if err := server.RunStdio(ctx); err != nil && ctx.Err() == nil {
+			fmt.Fprintln(os.Stderr, i18n.TWithData("cli.mcp.serve.error", map[string]interface{}{"Error": err.Error()}))
+			os.Exit(1)
+		}
+		_ = mgr.Close()
+	},
+}
+
+// newApprovalManager 按 server 配置的审批规则创建审批管理器，与服务端共用审计日志
+func newApprovalManager() (*approval.Manager, error) {
+	srvCfg, err := config.LoadServerConfig()
+	if err != nil {
+		return nil, err
+	}
+	dataPath, err := config.GetWorkspaceDataPath()
+	if err != nil {
+		return nil, err
+	}
+	return approval.NewManager(srvCfg.Approval, filepath.Join(dataPath, "approval_audit.jsonl"), logging.GetSystemLogger())
+}
+
+// defaultBuiltinConfig 使用默认模型构造内置技能（deep_search）的配置
+func defaultBuiltinConfig() *builtins.BuiltinConfig {
+	modelsMgr := config.GetModelsManager()
+	if modelsMgr == nil {
+		return nil
+	}
+	model, err := modelsMgr.GetModel(modelsMgr.GetDefaultModel())
+	if err != nil {
+		return nil
+	}
+
+	langName := "Chinese"
+	if i18n.GetLanguage() == "en-US" {
+		langName = "English"
+	}
+
+	return &builtins.BuiltinConfig{
+		BaseURL:  model.BaseURL,
+		Model:    model.Name,
+		APIKey:   model.APIKey,
+		LangName: langName,
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.AddCommand(mcpServeCmd)
+}
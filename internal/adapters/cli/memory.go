@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mindx/pkg/i18n"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// memoryTransferTimeout 导入时可能需要为每个记忆点重新生成向量，超时时间较长
+const memoryTransferTimeout = 30 * time.Minute
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: i18n.T("cli.memory.short"),
+	Long:  i18n.T("cli.memory.long"),
+}
+
+var memoryExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: i18n.T("cli.memory.export.short"),
+	Long:  i18n.T("cli.memory.export.long"),
+	Example: fmt.Sprintf(`  # %s
+  mindx memory export -o memory.jsonl
+
+  # %s
+  mindx memory export --vectors > memory.jsonl`,
+		i18n.T("cli.memory.export.example1"),
+		i18n.T("cli.memory.export.example2")),
+	Run: func(cmd *cobra.Command, args []string) {
+		port, _ := cmd.Flags().GetInt("port")
+		output, _ := cmd.Flags().GetString("output")
+		vectors, _ := cmd.Flags().GetBool("vectors")
+
+		if err := exportMemories(port, output, vectors); err != nil {
+			fmt.Fprintln(os.Stderr, i18n.TWithData("cli.memory.error", map[string]interface{}{"Error": err.Error()}))
+			os.Exit(1)
+		}
+		if output != "" {
+			fmt.Println(i18n.TWithData("cli.memory.export.success", map[string]interface{}{"File": output}))
+		}
+	},
+}
+
+var memoryImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: i18n.T("cli.memory.import.short"),
+	Long:  i18n.T("cli.memory.import.long"),
+	Example: fmt.Sprintf(`  # %s
+  mindx memory import memory.jsonl
+
+  # %s
+  mindx memory import memory.jsonl --reembed`,
+		i18n.T("cli.memory.import.example1"),
+		i18n.T("cli.memory.import.example2")),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		port, _ := cmd.Flags().GetInt("port")
+		reEmbed, _ := cmd.Flags().GetBool("reembed")
+
+		result, err := importMemories(port, args[0], reEmbed)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, i18n.TWithData("cli.memory.error", map[string]interface{}{"Error": err.Error()}))
+			os.Exit(1)
+		}
+		fmt.Println(i18n.TWithData("cli.memory.import.success", map[string]interface{}{
+			"Imported": result.Imported,
+			"Merged":   result.Merged,
+			"Skipped":  result.Skipped,
+		}))
+		for _, msg := range result.Errors {
+			fmt.Println("  " + msg)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(memoryCmd)
+
+	memoryCmd.PersistentFlags().IntP("port", "p", 1314, i18n.T("cli.memory.flag.port"))
+
+	memoryExportCmd.Flags().StringP("output", "o", "", i18n.T("cli.memory.export.flag_output"))
+	memoryExportCmd.Flags().Bool("vectors", false, i18n.T("cli.memory.export.flag_vectors"))
+	memoryCmd.AddCommand(memoryExportCmd)
+
+	memoryImportCmd.Flags().Bool("reembed", false, i18n.T("cli.memory.import.flag_reembed"))
+	memoryCmd.AddCommand(memoryImportCmd)
+}
+
+// exportMemories 从运行中的内核导出记忆，output 为空时写到标准输出
+func exportMemories(port int, output string, vectors bool) error {
+	client := &http.Client{Timeout: memoryTransferTimeout}
+	url := fmt.Sprintf("http://localhost:%d/api/memory/export?vectors=%s", port, strconv.FormatBool(vectors))
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to export memories: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// memoryImportResult 与 memory.ImportResult 对应
+type memoryImportResult struct {
+	Imported int      `json:"imported"`
+	Merged   int      `json:"merged"`
+	Skipped  int      `json:"skipped"`
+	Errors   []string `json:"errors"`
+	Error    string   `json:"error"`
+}
+
+// importMemories 将 JSONL 文件导入运行中的内核
+func importMemories(port int, file string, reEmbed bool) (*memoryImportResult, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	client := &http.Client{Timeout: memoryTransferTimeout}
+	url := fmt.Sprintf("http://localhost:%d/api/memory/import?reembed=%s", port, strconv.FormatBool(reEmbed))
+
+	resp, err := client.Post(url, "application/x-ndjson", f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import memories: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result memoryImportResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unexpected response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", result.Error)
+	}
+	return &result, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"mindx/internal/config"
+	"mindx/internal/core"
 	infraEmbedding "mindx/internal/infrastructure/embedding"
 	"mindx/internal/infrastructure/persistence"
 	"mindx/internal/usecase/embedding"
@@ -76,17 +77,23 @@ var trainCmd = &cobra.Command{
 			log.Fatal(err)
 		}
 
-		dataPath, err := config.GetWorkspaceDataPath()
+		// 与内核使用同一个向量存储，读取内核记录的记忆
+		vectorsPath, err := config.GetWorkspaceVectorsPath()
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		store, err := persistence.NewStore("badger", filepath.Join(dataPath, "memory"), nil)
+		store, err := persistence.NewStore(srvCfg.VectorStore.GetType(), vectorsPath, nil)
 		if err != nil {
 			log.Fatal(i18n.TWithData("cli.store.create_failed", map[string]interface{}{"Error": err.Error()}))
 		}
 		defer store.Close()
 
+		memoryStore, err := store.Collection(core.CollectionMemories)
+		if err != nil {
+			log.Fatal(i18n.TWithData("cli.store.create_failed", map[string]interface{}{"Error": err.Error()}))
+		}
+
 		ollamaEmbeddingURL := ollamaURL
 		if ollamaEmbeddingURL == "" {
 			ollamaEmbeddingURL = "http://localhost:11434"
@@ -115,7 +122,7 @@ var trainCmd = &cobra.Command{
 		openaiCfg.BaseURL = defaultModel.BaseURL
 		memLLMClient := openai.NewClientWithConfig(openaiCfg)
 
-		mem, err := memory.NewMemory(srvCfg, memLLMClient, logger, store, embeddingSvc)
+		mem, err := memory.NewMemory(srvCfg, memLLMClient, logger, memoryStore, embeddingSvc)
 		if err != nil {
 			log.Fatal(i18n.TWithData("cli.memory.init_failed", map[string]interface{}{"Error": err.Error()}))
 		}
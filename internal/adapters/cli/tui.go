@@ -44,6 +44,10 @@ type (
 		msgType string
 		content string
 	}
+	approvalMsg struct {
+		id    string
+		skill string
+	}
 	errorMsg error
 )
 
@@ -76,6 +80,7 @@ type model struct {
 	reconnectAttempts int
 	inputHistory      []string
 	historyIndex      int
+	pendingApproval   string // 等待确认的工具调用 ID
 }
 
 var (
@@ -133,7 +138,29 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 
 		case tea.KeyEnter:
-			if m.connected && m.input != "" {
+			if m.connected && m.input == "/stop" {
+				cmd = sendCancel(m.conn)
+				m.input = ""
+			} else if m.connected && (m.input == "/approve" || m.input == "/deny") {
+				m.messages = append(m.messages, chatMessage{
+					sender:    i18n.T("cli.tui.sender.you"),
+					content:   m.input,
+					timestamp: time.Now(),
+					isUser:    true,
+				})
+				if m.pendingApproval == "" {
+					m.messages = append(m.messages, chatMessage{
+						sender:    i18n.T("cli.tui.sender.system"),
+						content:   i18n.T("cli.tui.no_pending_approval"),
+						timestamp: time.Now(),
+					})
+				} else {
+					cmd = sendApproval(m.conn, m.pendingApproval, m.input == "/approve")
+					m.pendingApproval = ""
+				}
+				m.input = ""
+				m.scrollToBottom()
+			} else if m.connected && m.input != "" {
 				cmd = sendMessage(m.conn, m.input)
 				m.messages = append(m.messages, chatMessage{
 					sender:    i18n.T("cli.tui.sender.you"),
@@ -191,6 +218,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				isUser:    false,
 			})
 			m.scrollToBottom()
+		} else if msg.msgType == "cancelled" {
+			m.thinking = false
+			m.pendingApproval = ""
+			m.messages = append(m.messages, chatMessage{
+				sender:    i18n.T("cli.tui.sender.system"),
+				content:   i18n.T("cli.tui.cancelled"),
+				timestamp: time.Now(),
+				isUser:    false,
+			})
+			m.scrollToBottom()
+		} else if msg.msgType == "system" {
+			m.messages = append(m.messages, chatMessage{
+				sender:    i18n.T("cli.tui.sender.system"),
+				content:   msg.content,
+				timestamp: time.Now(),
+				isUser:    false,
+			})
+			m.scrollToBottom()
 		} else if msg.msgType == "thinking" {
 			m.messages = append(m.messages, chatMessage{
 				sender:      "MindX",
@@ -204,6 +249,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		cmd = listenToMsgChan(m.msgChan)
 
+	case approvalMsg:
+		m.pendingApproval = msg.id
+		m.messages = append(m.messages, chatMessage{
+			sender:    i18n.T("cli.tui.sender.system"),
+			content:   i18n.TWithData("cli.tui.approval_hint", map[string]interface{}{"Skill": msg.skill}),
+			timestamp: time.Now(),
+			isUser:    false,
+		})
+		m.scrollToBottom()
+		cmd = listenToMsgChan(m.msgChan)
+
 	case connectedMsg:
 		m.conn = msg.conn
 		m.connected = true
@@ -459,7 +515,12 @@ func connectWebSocket(port int, sessionID string, msgChan chan tea.Msg) tea.Cmd
 					if event, ok := msg["event"].(map[string]any); ok {
 						content, _ := event["content"].(string)
 						eventType, _ := event["type"].(string)
-						if eventType == "chunk" {
+						if eventType == "approval" {
+							metadata, _ := event["metadata"].(map[string]any)
+							id, _ := metadata["approval_id"].(string)
+							skill, _ := metadata["skill"].(string)
+							msgChan <- approvalMsg{id: id, skill: skill}
+						} else if eventType == "chunk" {
 							msgChan <- wsMsg{msgType: "thinking", content: content}
 						} else if content != "" {
 							displayContent := content
@@ -469,6 +530,14 @@ func connectWebSocket(port int, sessionID string, msgChan chan tea.Msg) tea.Cmd
 							msgChan <- wsMsg{msgType: "thinking", content: displayContent}
 						}
 					}
+				case "cancelled":
+					msgChan <- wsMsg{msgType: "cancelled"}
+				case "approval_result":
+					content := i18n.T("cli.tui.approval_sent")
+					if errText, ok := msg["error"].(string); ok && errText != "" {
+						content = errText
+					}
+					msgChan <- wsMsg{msgType: "system", content: content}
 				case "connected":
 					msgChan <- connectedMsg{conn: conn}
 				}
@@ -498,6 +567,40 @@ func sendMessage(conn *websocket.Conn, content string) tea.Cmd {
 	}
 }
 
+func sendCancel(conn *websocket.Conn) tea.Cmd {
+	return func() tea.Msg {
+		if conn == nil {
+			return errorMsg(fmt.Errorf("not connected"))
+		}
+
+		if err := conn.WriteJSON(map[string]any{"type": "cancel"}); err != nil {
+			return errorMsg(fmt.Errorf("send failed: %w", err))
+		}
+
+		return nil
+	}
+}
+
+func sendApproval(conn *websocket.Conn, id string, approved bool) tea.Cmd {
+	return func() tea.Msg {
+		if conn == nil {
+			return errorMsg(fmt.Errorf("not connected"))
+		}
+
+		msg := map[string]any{
+			"type":     "approval",
+			"id":       id,
+			"approved": approved,
+		}
+
+		if err := conn.WriteJSON(msg); err != nil {
+			return errorMsg(fmt.Errorf("send failed: %w", err))
+		}
+
+		return nil
+	}
+}
+
 type styles struct {
 	header           lipgloss.Style
 	logo             lipgloss.Style
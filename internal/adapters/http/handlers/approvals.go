@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"mindx/internal/usecase/approval"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ApprovalHandler struct {
+	manager *approval.Manager
+}
+
+func NewApprovalHandler(manager *approval.Manager) *ApprovalHandler {
+	return &ApprovalHandler{manager: manager}
+}
+
+func (h *ApprovalHandler) RegisterRoutes(api *gin.RouterGroup) {
+	approvalGroup := api.Group("/approvals")
+	{
+		approvalGroup.GET("", h.listPending)
+		approvalGroup.GET("/audit", h.listAudit)
+		approvalGroup.POST("/:id", h.resolve)
+	}
+}
+
+func (h *ApprovalHandler) listPending(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"approvals": h.manager.Pending()})
+}
+
+func (h *ApprovalHandler) listAudit(c *gin.Context) {
+	limit := 100
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = n
+	}
+
+	records, err := h.manager.Audit().List(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"records": records})
+}
+
+func (h *ApprovalHandler) resolve(c *gin.Context) {
+	var req struct {
+		Approved bool   `json:"approved"`
+		Operator string `json:"operator"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	operator := req.Operator
+	if operator == "" {
+		operator = "api"
+	}
+	if err := h.manager.Resolve(c.Param("id"), req.Approved, operator); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Approval resolved"})
+}
@@ -12,8 +12,10 @@ import (
 )
 
 type SendMessageRequest struct {
-	Type    string `json:"type"`
-	Content string `json:"content"`
+	Type      string `json:"type"`
+	Content   string `json:"content"`
+	ChannelID string `json:"channel_id,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
 }
 
 type ConversationsHandler struct {
@@ -28,6 +30,7 @@ type CurrentSessionResponse struct {
 
 type ConversationSummary struct {
 	ID           string `json:"id"`
+	ChannelID    string `json:"channel_id,omitempty"`
 	Title        string `json:"title"`
 	Timestamp    int64  `json:"timestamp"`
 	MessageCount int    `json:"messageCount"`
@@ -51,6 +54,17 @@ func NewConversationsHandler(sessionMgr *sessionMgr.SessionMgr, assistant Assist
 	}
 }
 
+// sessionKeyFromRequest 从请求参数解析发起请求的会话隔离键
+// 通过 query 参数 channel_id 和 session_id 指定，均未指定时使用默认会话
+func sessionKeyFromRequest(c *gin.Context) entity.SessionKey {
+	return entity.NewSessionKey(c.Query("channel_id"), c.Query("session_id"))
+}
+
+// hasSessionKey 请求是否显式指定了会话
+func hasSessionKey(c *gin.Context) bool {
+	return c.Query("channel_id") != "" || c.Query("session_id") != ""
+}
+
 func (h *ConversationsHandler) sendMessage(c *gin.Context) {
 	var req SendMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -58,23 +72,21 @@ func (h *ConversationsHandler) sendMessage(c *gin.Context) {
 		return
 	}
 
-	currentSession, exists := h.sessionMgr.GetCurrentSession()
-	if !exists || currentSession == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No current session"})
-		return
+	key := sessionKeyFromRequest(c)
+	if req.ChannelID != "" || req.SessionID != "" {
+		key = entity.NewSessionKey(req.ChannelID, req.SessionID)
 	}
 
-	eventChan := make(chan entity.ThinkingEvent, 100)
-	defer close(eventChan)
-
-	answer, _, err := h.assistant.Ask(req.Content, currentSession.ID, eventChan)
+	// 同步接口不推送思考过程，需要确认的工具调用会因无人可以确认而直接拒绝
+	resp, err := h.assistant.Ask(c.Request.Context(), req.Content, key, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"content": answer,
+		"content":     resp.Answer,
+		"attachments": resp.Attachments,
 	})
 }
 
@@ -92,6 +104,17 @@ func (h *ConversationsHandler) listConversations(c *gin.Context) {
 		return
 	}
 
+	if hasSessionKey(c) {
+		key := sessionKeyFromRequest(c)
+		owned := make([]entity.Session, 0, len(sessions))
+		for _, sess := range sessions {
+			if sess.Key() == key {
+				owned = append(owned, sess)
+			}
+		}
+		sessions = owned
+	}
+
 	conversations := h.convertToSummaries(sessions)
 
 	if len(conversations) > limit {
@@ -128,7 +151,7 @@ func (h *ConversationsHandler) getConversation(c *gin.Context) {
 }
 
 func (h *ConversationsHandler) getCurrentSession(c *gin.Context) {
-	currentSession, exists := h.sessionMgr.GetCurrentSession()
+	currentSession, exists := h.sessionMgr.GetCurrentSession(sessionKeyFromRequest(c))
 	if !exists || currentSession == nil {
 		c.JSON(http.StatusOK, gin.H{
 			"id":       "",
@@ -166,7 +189,7 @@ func (h *ConversationsHandler) deleteConversation(c *gin.Context) {
 func (h *ConversationsHandler) switchConversation(c *gin.Context) {
 	id := c.Param("id")
 
-	session, err := h.sessionMgr.SwitchSession(id)
+	session, err := h.sessionMgr.SwitchSession(sessionKeyFromRequest(c), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "切换对话失败"})
 		return
@@ -187,7 +210,7 @@ func (h *ConversationsHandler) switchConversation(c *gin.Context) {
 }
 
 func (h *ConversationsHandler) createNewConversation(c *gin.Context) {
-	session, err := h.sessionMgr.CreateNewSession()
+	session, err := h.sessionMgr.CreateNewSession(sessionKeyFromRequest(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建新对话失败"})
 		return
@@ -210,6 +233,7 @@ func (h *ConversationsHandler) convertToSummaries(sessions []entity.Session) []C
 		title := extractTitleFromMessage(sess.Messages[0].Content)
 		summaries = append(summaries, ConversationSummary{
 			ID:           sess.ID,
+			ChannelID:    sess.ChannelID,
 			Title:        title,
 			Timestamp:    sess.Messages[0].Time.Unix(),
 			MessageCount: len(sess.Messages),
@@ -3,6 +3,7 @@ package handlers
 import (
 	"mindx/internal/usecase/cron"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,6 +26,8 @@ func (h *CronHandler) RegisterRoutes(api *gin.RouterGroup) {
 		cronGroup.DELETE("/jobs/:id", h.deleteJob)
 		cronGroup.POST("/jobs/:id/pause", h.pauseJob)
 		cronGroup.POST("/jobs/:id/resume", h.resumeJob)
+		cronGroup.POST("/jobs/:id/run", h.runJob)
+		cronGroup.GET("/jobs/:id/history", h.jobHistory)
 	}
 }
 
@@ -105,3 +108,31 @@ func (h *CronHandler) resumeJob(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Job resumed"})
 }
+
+func (h *CronHandler) runJob(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.scheduler.Get(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	// 投递会经过大脑处理，耗时较长，异步执行，结果记录在执行历史中
+	go func() {
+		_ = h.scheduler.RunJob(id)
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"message": "Job triggered"})
+}
+
+func (h *CronHandler) jobHistory(c *gin.Context) {
+	provider, ok := h.scheduler.(cron.HistoryProvider)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "execution history is only available with the in-process scheduler"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	history, err := provider.History(c.Param("id"), limit)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
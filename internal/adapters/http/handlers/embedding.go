@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"mindx/internal/usecase/embedding"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmbeddingHandler 嵌入模型迁移接口：查看进度、检查不一致的集合、手动启动重新生成向量
+type EmbeddingHandler struct {
+	migrator *embedding.Migrator
+}
+
+func NewEmbeddingHandler(migrator *embedding.Migrator) *EmbeddingHandler {
+	return &EmbeddingHandler{migrator: migrator}
+}
+
+func (h *EmbeddingHandler) RegisterRoutes(api *gin.RouterGroup) {
+	migrationGroup := api.Group("/embedding/migration")
+	{
+		migrationGroup.GET("", h.getProgress)
+		migrationGroup.GET("/check", h.check)
+		migrationGroup.POST("", h.start)
+	}
+}
+
+func (h *EmbeddingHandler) getProgress(c *gin.Context) {
+	c.JSON(http.StatusOK, h.migrator.Progress())
+}
+
+func (h *EmbeddingHandler) check(c *gin.Context) {
+	mismatches, err := h.migrator.Check()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if mismatches == nil {
+		mismatches = []embedding.Mismatch{}
+	}
+	c.JSON(http.StatusOK, gin.H{"mismatches": mismatches})
+}
+
+// start 未指定集合时优先继续上次未完成的迁移，否则重新生成全部集合的向量
+func (h *EmbeddingHandler) start(c *gin.Context) {
+	var req struct {
+		Collections []string `json:"collections"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var err error
+	resumed := false
+	if len(req.Collections) == 0 {
+		resumed, err = h.migrator.Resume()
+	}
+	if !resumed && err == nil {
+		err = h.migrator.Start(req.Collections...)
+	}
+	if errors.Is(err, embedding.ErrMigrationRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, h.migrator.Progress())
+}
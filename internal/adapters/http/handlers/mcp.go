@@ -38,7 +38,7 @@ func (h *MCPHandler) addServer(c *gin.Context) {
 		Command string            `json:"command"`
 		Args    []string          `json:"args"`
 		Env     map[string]string `json:"env"`
-		// sse fields
+		// sse / http fields
 		URL     string            `json:"url"`
 		Headers map[string]string `json:"headers"`
 		// common
@@ -49,33 +49,32 @@ func (h *MCPHandler) addServer(c *gin.Context) {
 		return
 	}
 
-	serverType := req.Type
-	if serverType == "" {
-		serverType = "stdio"
+	entry := config.MCPServerEntry{
+		Type:    req.Type,
+		Command: req.Command,
+		Args:    req.Args,
+		Env:     req.Env,
+		URL:     req.URL,
+		Headers: req.Headers,
+		Enabled: req.Enabled,
 	}
+	entry.Type = entry.GetType()
 
 	// 校验必填字段
-	switch serverType {
-	case "sse":
+	switch entry.Type {
+	case config.MCPTransportSSE, config.MCPTransportHTTP:
 		if req.URL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "url is required for SSE type"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "url is required for " + entry.Type + " type"})
 			return
 		}
-	default:
+	case config.MCPTransportStdio:
 		if req.Command == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "command is required for stdio type"})
 			return
 		}
-	}
-
-	entry := config.MCPServerEntry{
-		Type:    serverType,
-		Command: req.Command,
-		Args:    req.Args,
-		Env:     req.Env,
-		URL:     req.URL,
-		Headers: req.Headers,
-		Enabled: req.Enabled,
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported type: " + req.Type})
+		return
 	}
 
 	if err := h.skillMgr.AddMCPServer(c.Request.Context(), req.Name, entry); err != nil {
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mindx/internal/core"
+	"mindx/internal/usecase/memory"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MemoryHandler 记忆管理接口：查看、检索、修改和删除助理记住的内容
+type MemoryHandler struct {
+	memory    *memory.Memory
+	summarize func() error
+}
+
+// NewMemoryHandler summarize 为记忆点重整（从未记忆的会话中提取记忆），可以为 nil
+func NewMemoryHandler(mem *memory.Memory, summarize func() error) *MemoryHandler {
+	return &MemoryHandler{memory: mem, summarize: summarize}
+}
+
+func (h *MemoryHandler) RegisterRoutes(api *gin.RouterGroup) {
+	memoryGroup := api.Group("/memory")
+	{
+		memoryGroup.GET("", h.listMemories)
+		memoryGroup.GET("/stats", h.getStats)
+		memoryGroup.GET("/search", h.searchMemories)
+		memoryGroup.POST("/optimize", h.optimize)
+		memoryGroup.POST("/decay", h.decayWeights)
+		memoryGroup.POST("/summarize", h.triggerSummarize)
+		memoryGroup.GET("/export", h.exportMemories)
+		memoryGroup.POST("/import", h.importMemories)
+		memoryGroup.GET("/:id", h.getMemory)
+		memoryGroup.PUT("/:id", h.updateMemory)
+		memoryGroup.DELETE("/:id", h.deleteMemory)
+		memoryGroup.POST("/:id/weight", h.adjustWeight)
+	}
+}
+
+// memoryView 返回给前端的记忆点，不包含向量
+func memoryView(point core.MemoryPoint) core.MemoryPoint {
+	point.Vector = nil
+	return point
+}
+
+func memoryFilterFromQuery(c *gin.Context) memory.MemoryFilter {
+	return memory.MemoryFilter{
+		ChannelID:  c.Query("channel_id"),
+		OwnerID:    c.Query("owner_id"),
+		Visibility: core.MemoryVisibility(c.Query("visibility")),
+		Query:      c.Query("q"),
+	}
+}
+
+func (h *MemoryHandler) memoryID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid memory id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *MemoryHandler) memoryError(c *gin.Context, err error) {
+	if errors.Is(err, memory.ErrMemoryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+func (h *MemoryHandler) listMemories(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 20
+	}
+
+	points, err := h.memory.ListMemories(memoryFilterFromQuery(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	total := len(points)
+	start := min((page-1)*pageSize, total)
+	end := min(start+pageSize, total)
+	items := make([]core.MemoryPoint, 0, end-start)
+	for _, point := range points[start:end] {
+		items = append(items, memoryView(point))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"memories":  items,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+func (h *MemoryHandler) getStats(c *gin.Context) {
+	points, err := h.memory.ListMemories(memory.MemoryFilter{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	byVisibility := make(map[string]int)
+	byChannel := make(map[string]int)
+	var totalWeight float64
+	for _, point := range points {
+		byVisibility[string(point.Visibility)]++
+		byChannel[point.ChannelID]++
+		totalWeight += point.TotalWeight
+	}
+	averageWeight := 0.0
+	if len(points) > 0 {
+		averageWeight = totalWeight / float64(len(points))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":          len(points),
+		"by_visibility":  byVisibility,
+		"by_channel":     byChannel,
+		"average_weight": averageWeight,
+	})
+}
+
+// searchMemories mode=semantic（默认）按向量相似度检索，mode=text 按文本包含检索（结果不带相似度）
+func (h *MemoryHandler) searchMemories(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	switch c.DefaultQuery("mode", "semantic") {
+	case "semantic":
+		results, err := h.memory.SemanticSearch(query, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for i := range results {
+			results[i] = memoryView(results[i])
+		}
+		c.JSON(http.StatusOK, gin.H{"results": results})
+	case "text":
+		points, err := h.memory.ListMemories(memoryFilterFromQuery(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		results := make([]core.MemoryPoint, 0, min(limit, len(points)))
+		for _, point := range points[:min(limit, len(points))] {
+			results = append(results, memoryView(point))
+		}
+		c.JSON(http.StatusOK, gin.H{"results": results})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be semantic or text"})
+	}
+}
+
+func (h *MemoryHandler) getMemory(c *gin.Context) {
+	id, ok := h.memoryID(c)
+	if !ok {
+		return
+	}
+	point, err := h.memory.GetMemoryPoint(id)
+	if err != nil {
+		h.memoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, memoryView(point))
+}
+
+type updateMemoryRequest struct {
+	Summary    *string                `json:"summary"`
+	Content    *string                `json:"content"`
+	Keywords   []string               `json:"keywords"`
+	Visibility *core.MemoryVisibility `json:"visibility"`
+}
+
+func (h *MemoryHandler) updateMemory(c *gin.Context) {
+	id, ok := h.memoryID(c)
+	if !ok {
+		return
+	}
+	var req updateMemoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Visibility != nil && !req.Visibility.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "visibility must be private, shared or global"})
+		return
+	}
+
+	point, err := h.memory.UpdateMemory(id, memory.MemoryUpdate{
+		Summary:    req.Summary,
+		Content:    req.Content,
+		Keywords:   req.Keywords,
+		Visibility: req.Visibility,
+	})
+	if err != nil {
+		h.memoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, memoryView(point))
+}
+
+func (h *MemoryHandler) deleteMemory(c *gin.Context) {
+	id, ok := h.memoryID(c)
+	if !ok {
+		return
+	}
+	if err := h.memory.DeleteMemory(id); err != nil {
+		h.memoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Memory deleted"})
+}
+
+func (h *MemoryHandler) adjustWeight(c *gin.Context) {
+	id, ok := h.memoryID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Multiple float64 `json:"multiple" binding:"required,gt=0"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.memory.AdjustMemoryWeight(id, req.Multiple); err != nil {
+		h.memoryError(c, err)
+		return
+	}
+	point, err := h.memory.GetMemoryPoint(id)
+	if err != nil {
+		h.memoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, memoryView(point))
+}
+
+func (h *MemoryHandler) optimize(c *gin.Context) {
+	if err := h.memory.Optimize(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Memory optimized"})
+}
+
+func (h *MemoryHandler) decayWeights(c *gin.Context) {
+	if err := h.memory.DecayWeights(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Memory weights decayed"})
+}
+
+func (h *MemoryHandler) triggerSummarize(c *gin.Context) {
+	if h.summarize == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "memory summarization is not available"})
+		return
+	}
+	// 重整需要逐个会话调用模型提取记忆，耗时较长，异步执行
+	go func() {
+		_ = h.summarize()
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"message": "Memory summarization triggered"})
+}
+
+// exportMemories 以 JSONL 文件下载所有记忆点，vectors=true 时包含向量
+func (h *MemoryHandler) exportMemories(c *gin.Context) {
+	includeVectors, _ := strconv.ParseBool(c.DefaultQuery("vectors", "false"))
+
+	filename := fmt.Sprintf("mindx-memory-%s.jsonl", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// 响应头已经发出，导出失败时只能中断响应
+	if _, err := h.memory.Export(c.Writer, memory.ExportOptions{IncludeVectors: includeVectors}); err != nil {
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// importMemories 导入 JSONL 记忆文件，文件可以是请求体，也可以是表单字段 file；reembed=true 时重新生成向量
+func (h *MemoryHandler) importMemories(c *gin.Context) {
+	reEmbed, _ := strconv.ParseBool(c.DefaultQuery("reembed", "false"))
+
+	var body io.Reader = c.Request.Body
+	if c.ContentType() == "multipart/form-data" {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	result, err := h.memory.Import(body, memory.ImportOptions{ReEmbed: reEmbed})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "result": result})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
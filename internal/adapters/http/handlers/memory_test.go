@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"mindx/internal/core"
+	"mindx/internal/usecase/memory"
+	"mindx/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMemoryTestRouter(t *testing.T) (*gin.Engine, *memory.Memory) {
+	gin.SetMode(gin.TestMode)
+	mem := memory.NewTestMemory(logging.GetSystemLogger().Named("memory_test"))
+	for _, summary := range []string{"user likes green tea", "user lives in hangzhou", "user owns a cat"} {
+		require.NoError(t, mem.Record(core.MemoryPoint{
+			Keywords:    strings.Fields(summary)[1:],
+			Summary:     summary,
+			Content:     summary,
+			TotalWeight: 1.0,
+		}))
+	}
+
+	router := gin.New()
+	NewMemoryHandler(mem, nil).RegisterRoutes(router.Group("/api"))
+	return router, mem
+}
+
+func doMemoryRequest(router *gin.Engine, method, path, body string) (*httptest.ResponseRecorder, map[string]any) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestMemoryHandler_ListAndSearch(t *testing.T) {
+	router, _ := newMemoryTestRouter(t)
+
+	w, resp := doMemoryRequest(router, http.MethodGet, "/api/memory?page=1&page_size=2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 3, resp["total"])
+	items := resp["memories"].([]any)
+	require.Len(t, items, 2)
+	assert.Nil(t, items[0].(map[string]any)["vector"], "列表不返回向量")
+
+	w, resp = doMemoryRequest(router, http.MethodGet, "/api/memory/search?mode=text&q=HANGZHOU", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	results := resp["results"].([]any)
+	require.Len(t, results, 1)
+	assert.Equal(t, "user lives in hangzhou", results[0].(map[string]any)["summary"])
+
+	w, resp = doMemoryRequest(router, http.MethodGet, "/api/memory/search?q=green+tea&limit=1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	results = resp["results"].([]any)
+	require.Len(t, results, 1)
+	assert.Equal(t, "user likes green tea", results[0].(map[string]any)["summary"])
+
+	w, _ = doMemoryRequest(router, http.MethodGet, "/api/memory/search", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, resp = doMemoryRequest(router, http.MethodGet, "/api/memory/stats", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 3, resp["total"])
+}
+
+func TestMemoryHandler_EditAndDelete(t *testing.T) {
+	router, mem := newMemoryTestRouter(t)
+	points, err := mem.ListMemories(memory.MemoryFilter{Query: "cat"})
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	target, err := mem.GetMemoryPoint(points[0].ID)
+	require.NoError(t, err)
+	path := "/api/memory/" + strconv.Itoa(target.ID)
+
+	w, resp := doMemoryRequest(router, http.MethodPut, path, `{"summary":"user owns two dogs","keywords":["dogs"]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "user owns two dogs", resp["summary"])
+
+	updated, err := mem.GetMemoryPoint(target.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dogs"}, updated.Keywords)
+	assert.NotEqual(t, target.Vector, updated.Vector, "修改摘要后重新生成向量")
+	all, err := mem.ListMemories(memory.MemoryFilter{})
+	require.NoError(t, err)
+	assert.Len(t, all, 3, "修改覆盖原记忆点")
+
+	w, _ = doMemoryRequest(router, http.MethodPut, path, `{"visibility":"public"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, resp = doMemoryRequest(router, http.MethodPost, path+"/weight", `{"multiple":2}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.EqualValues(t, 2.0, resp["total_weight"])
+
+	w, _ = doMemoryRequest(router, http.MethodDelete, path, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	w, _ = doMemoryRequest(router, http.MethodGet, path, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w, _ = doMemoryRequest(router, http.MethodDelete, path, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w, _ = doMemoryRequest(router, http.MethodGet, "/api/memory/abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestMemoryHandler_Maintenance(t *testing.T) {
+	router, _ := newMemoryTestRouter(t)
+
+	w, _ := doMemoryRequest(router, http.MethodPost, "/api/memory/optimize", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w, _ = doMemoryRequest(router, http.MethodPost, "/api/memory/decay", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w, _ = doMemoryRequest(router, http.MethodPost, "/api/memory/summarize", "")
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+func TestMemoryHandler_ExportImport(t *testing.T) {
+	router, _ := newMemoryTestRouter(t)
+
+	w, _ := doMemoryRequest(router, http.MethodGet, "/api/memory/export", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".jsonl")
+	exported := w.Body.String()
+	assert.Len(t, strings.Split(strings.TrimSpace(exported), "\n"), 4)
+
+	// 导入到另一个记忆库
+	target, _ := newMemoryTestRouter(t)
+	w, resp := doMemoryRequest(target, http.MethodPost, "/api/memory/import?reembed=true", exported)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.EqualValues(t, 3, resp["merged"], "相同的记忆合并而不是重复写入")
+
+	w, _ = doMemoryRequest(target, http.MethodPost, "/api/memory/import", `{"format":"other","version":9}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mindx/internal/config"
+	"mindx/internal/core"
+	"mindx/internal/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DefaultOpenAIModel 不指定能力或模型时使用的模型名，走仿生大脑的默认流程
+const DefaultOpenAIModel = "mindx"
+
+// openAIKeepAliveInterval 流式响应等待大脑回答期间发送 SSE 注释的间隔
+const openAIKeepAliveInterval = 10 * time.Second
+
+// CapabilityLookup 按名称查找能力（由 CapabilityManager 实现）
+type CapabilityLookup interface {
+	GetCapability(name string) (*entity.Capability, bool)
+	ListEnabledCapabilities() []entity.Capability
+}
+
+// OpenAIHandler OpenAI 兼容接口
+// 让使用 OpenAI SDK 的客户端（IDE 插件、聊天前端等）可以直接对接仿生大脑
+// stream: true 的响应是缓冲的，回答完成后才整体推送
+type OpenAIHandler struct {
+	post   func(ctx context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error)
+	capMgr CapabilityLookup
+}
+
+// ChatCompletionMessage OpenAI 聊天消息
+type ChatCompletionMessage struct {
+	Role    string         `json:"role"`
+	Content MessageContent `json:"content"`
+}
+
+// MessageContent 消息内容，请求中可以是字符串或内容片段数组（[{"type":"text","text":"..."}]），
+// 片段数组只取文本片段并按换行拼接；响应中总是字符串
+type MessageContent string
+
+func (c *MessageContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = MessageContent(text)
+		return nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		if string(data) == "null" {
+			*c = ""
+			return nil
+		}
+		return fmt.Errorf("content must be a string or an array of content parts")
+	}
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	*c = MessageContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+// ChatCompletionRequest OpenAI 聊天补全请求
+type ChatCompletionRequest struct {
+	Model    string                  `json:"model"`
+	Messages []ChatCompletionMessage `json:"messages" binding:"required"`
+	Stream   bool                    `json:"stream,omitempty"` // 回答完成后一次性推送，见 streamCompletion
+	User     string                  `json:"user,omitempty"`
+}
+
+// ChatCompletionChoice 非流式响应的候选项
+type ChatCompletionChoice struct {
+	Index        int                   `json:"index"`
+	Message      ChatCompletionMessage `json:"message"`
+	FinishReason string                `json:"finish_reason"`
+}
+
+// ChatCompletionResponse 非流式聊天补全响应
+type ChatCompletionResponse struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []ChatCompletionChoice `json:"choices"`
+}
+
+// ChatCompletionDelta 流式响应的增量内容
+type ChatCompletionDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// ChatCompletionStreamChoice 流式响应的候选项
+type ChatCompletionStreamChoice struct {
+	Index        int                 `json:"index"`
+	Delta        ChatCompletionDelta `json:"delta"`
+	FinishReason *string             `json:"finish_reason"`
+}
+
+// ChatCompletionChunk 流式聊天补全响应块
+type ChatCompletionChunk struct {
+	ID      string                       `json:"id"`
+	Object  string                       `json:"object"`
+	Created int64                        `json:"created"`
+	Model   string                       `json:"model"`
+	Choices []ChatCompletionStreamChoice `json:"choices"`
+}
+
+// ModelObject OpenAI 模型对象
+type ModelObject struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// NewOpenAIHandler 创建 OpenAI 兼容接口处理器
+// post 为大脑的思考入口（core.Brain.Post），capMgr 可为 nil
+func NewOpenAIHandler(post func(ctx context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error), capMgr CapabilityLookup) *OpenAIHandler {
+	return &OpenAIHandler{
+		post:   post,
+		capMgr: capMgr,
+	}
+}
+
+// RegisterRoutes 注册 /v1 路由
+func (h *OpenAIHandler) RegisterRoutes(router gin.IRouter) {
+	v1 := router.Group("/v1")
+	{
+		v1.POST("/chat/completions", h.chatCompletions)
+		v1.GET("/models", h.listModels)
+	}
+}
+
+func (h *OpenAIHandler) chatCompletions(c *gin.Context) {
+	var req ChatCompletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	thinkingReq, err := h.buildThinkingRequest(&req, bearerToken(c))
+	if err != nil {
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	model := req.Model
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	id := "chatcmpl-" + uuid.New().String()
+	created := time.Now().Unix()
+
+	if req.Stream {
+		h.streamCompletion(c, thinkingReq, id, model, created)
+		return
+	}
+
+	resp, err := h.post(c.Request.Context(), thinkingReq)
+	if err != nil {
+		openAIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, ChatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: created,
+		Model:   model,
+		Choices: []ChatCompletionChoice{{
+			Index:        0,
+			Message:      ChatCompletionMessage{Role: "assistant", Content: MessageContent(resp.Answer)},
+			FinishReason: "stop",
+		}},
+	})
+}
+
+// streamCompletion 以 SSE 返回回答
+// 流式响应是缓冲的：大脑的思考结果在完成后才能确定（左脑输出为结构化 JSON，之后还可能调用工具），
+// 因此等待期间只发送保活注释，完成后把完整回答放在一个 chat.completion.chunk 中推送，不会逐字输出；
+// 出错时先推送错误对象，再推送带 finish_reason 的结束块，保证客户端总能看到流的结束
+func (h *OpenAIHandler) streamCompletion(c *gin.Context, thinkingReq *core.ThinkingRequest, id, model string, created int64) {
+	type result struct {
+		resp *core.ThinkingResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := h.post(c.Request.Context(), thinkingReq)
+		done <- result{resp: resp, err: err}
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	writeChunk := func(delta ChatCompletionDelta, finishReason *string) {
+		data, _ := json.Marshal(ChatCompletionChunk{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   model,
+			Choices: []ChatCompletionStreamChoice{{Index: 0, Delta: delta, FinishReason: finishReason}},
+		})
+		fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		c.Writer.Flush()
+	}
+
+	writeChunk(ChatCompletionDelta{Role: "assistant"}, nil)
+
+	ticker := time.NewTicker(openAIKeepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		case r := <-done:
+			if r.err != nil {
+				data, _ := json.Marshal(gin.H{"error": gin.H{"message": r.err.Error(), "type": "server_error"}})
+				fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+			} else {
+				writeChunk(ChatCompletionDelta{Content: r.resp.Answer}, nil)
+			}
+			stop := "stop"
+			writeChunk(ChatCompletionDelta{}, &stop)
+			fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+			c.Writer.Flush()
+			return
+		}
+	}
+}
+
+// buildThinkingRequest 将 OpenAI 请求转换为思考请求
+// 最后一条 user 消息作为问题，之前的 user/assistant 消息作为历史对话，system/developer 消息作为调用方的系统提示
+// model 映射规则：能力名 → 以 "/能力名 " 前缀交给主意识；已配置的模型 → 主意识直接使用该模型；其它 → 默认流程
+// apiKey 为请求携带的 Bearer Token，请求未指定 user 时用于区分客户端
+func (h *OpenAIHandler) buildThinkingRequest(req *ChatCompletionRequest, apiKey string) (*core.ThinkingRequest, error) {
+	sessionID, err := openAISessionID(req.User, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	last := -1
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			last = i
+			break
+		}
+	}
+	if last == -1 || strings.TrimSpace(string(req.Messages[last].Content)) == "" {
+		return nil, fmt.Errorf("messages must contain a non-empty user message")
+	}
+
+	var systemPrompts []string
+	for _, msg := range req.Messages {
+		if (msg.Role == "system" || msg.Role == "developer") && strings.TrimSpace(string(msg.Content)) != "" {
+			systemPrompts = append(systemPrompts, string(msg.Content))
+		}
+	}
+
+	history := make([]*core.DialogueMessage, 0, last)
+	for _, msg := range req.Messages[:last] {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			continue
+		}
+		history = append(history, &core.DialogueMessage{Role: msg.Role, Content: string(msg.Content)})
+	}
+
+	thinkingReq := &core.ThinkingRequest{
+		Question:     string(req.Messages[last].Content),
+		Timeout:      30,
+		ChannelID:    "openai",
+		SessionID:    sessionID,
+		History:      history,
+		SystemPrompt: strings.Join(systemPrompts, "\n\n"),
+	}
+
+	switch {
+	case req.Model == "" || req.Model == DefaultOpenAIModel:
+	case h.isCapability(req.Model):
+		thinkingReq.Question = "/" + req.Model + " " + thinkingReq.Question
+	case isConfiguredModel(req.Model):
+		thinkingReq.Model = req.Model
+	default:
+		return nil, fmt.Errorf("model not found: %s", req.Model)
+	}
+
+	return thinkingReq, nil
+}
+
+// openAISessionID 返回请求所属的会话 ID，会话同时决定历史记录和记忆的可见范围
+// 优先使用请求的 user；未指定时按 API Key 的摘要派生，避免不同客户端落入同一个会话；两者都没有时拒绝请求
+func openAISessionID(user, apiKey string) (string, error) {
+	if user = strings.TrimSpace(user); user != "" {
+		return user, nil
+	}
+	if apiKey == "" {
+		return "", fmt.Errorf("user is required when the request has no API key")
+	}
+	hash := sha256.Sum256([]byte(apiKey))
+	return "key-" + hex.EncodeToString(hash[:8]), nil
+}
+
+// bearerToken 返回 Authorization 头中的 Bearer Token
+func bearerToken(c *gin.Context) string {
+	auth := c.GetHeader("Authorization")
+	if len(auth) < len("Bearer ") || !strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(auth[len("Bearer "):])
+}
+
+func (h *OpenAIHandler) isCapability(name string) bool {
+	if h.capMgr == nil {
+		return false
+	}
+	_, ok := h.capMgr.GetCapability(name)
+	return ok
+}
+
+func isConfiguredModel(name string) bool {
+	modelsMgr := config.GetModelsManager()
+	if modelsMgr == nil {
+		return false
+	}
+	_, err := modelsMgr.GetModel(name)
+	return err == nil
+}
+
+// listModels 列出可用的模型：默认大脑、已启用的能力和已配置的模型
+func (h *OpenAIHandler) listModels(c *gin.Context) {
+	created := time.Now().Unix()
+	models := []ModelObject{{ID: DefaultOpenAIModel, Object: "model", Created: created, OwnedBy: "mindx"}}
+
+	if h.capMgr != nil {
+		for _, capability := range h.capMgr.ListEnabledCapabilities() {
+			models = append(models, ModelObject{ID: capability.Name, Object: "model", Created: created, OwnedBy: "capability"})
+		}
+	}
+
+	if modelsMgr := config.GetModelsManager(); modelsMgr != nil {
+		for _, model := range modelsMgr.ListModels() {
+			models = append(models, ModelObject{ID: model.Name, Object: "model", Created: created, OwnedBy: "model"})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": models})
+}
+
+// openAIError 以 OpenAI 错误格式返回
+func openAIError(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, gin.H{"error": gin.H{"message": message, "type": errType}})
+}
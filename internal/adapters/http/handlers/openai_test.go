@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mindx/internal/core"
+	"mindx/internal/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCapabilities map[string]entity.Capability
+
+func (f fakeCapabilities) GetCapability(name string) (*entity.Capability, bool) {
+	capability, ok := f[name]
+	return &capability, ok
+}
+
+func (f fakeCapabilities) ListEnabledCapabilities() []entity.Capability {
+	result := make([]entity.Capability, 0, len(f))
+	for _, capability := range f {
+		result = append(result, capability)
+	}
+	return result
+}
+
+func newOpenAITestRouter(post func(ctx context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	caps := fakeCapabilities{"writer": {Name: "writer", Enabled: true}}
+	NewOpenAIHandler(post, caps).RegisterRoutes(router)
+	return router
+}
+
+func TestOpenAI_ChatCompletions(t *testing.T) {
+	var got *core.ThinkingRequest
+	router := newOpenAITestRouter(func(_ context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
+		got = req
+		return &core.ThinkingResponse{Answer: "你好"}, nil
+	})
+
+	body := `{"model":"mindx","user":"u1","messages":[
+		{"role":"system","content":"用中文回答"},
+		{"role":"user","content":"hi"},
+		{"role":"assistant","content":"hello"},
+		{"role":"user","content":"在吗"}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp ChatCompletionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "chat.completion", resp.Object)
+	require.Len(t, resp.Choices, 1)
+	assert.Equal(t, MessageContent("你好"), resp.Choices[0].Message.Content)
+
+	require.NotNil(t, got)
+	assert.Equal(t, "在吗", got.Question)
+	assert.Equal(t, "用中文回答", got.SystemPrompt)
+	assert.Equal(t, "u1", got.SessionID)
+	assert.Empty(t, got.Model)
+	require.Len(t, got.History, 2)
+	assert.Equal(t, "hi", got.History[0].Content)
+	assert.Equal(t, "assistant", got.History[1].Role)
+}
+
+// TestOpenAI_ContentParts OpenAI SDK 以内容片段数组发送 content 时只取文本片段
+func TestOpenAI_ContentParts(t *testing.T) {
+	var got *core.ThinkingRequest
+	router := newOpenAITestRouter(func(_ context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
+		got = req
+		return &core.ThinkingResponse{Answer: "ok"}, nil
+	})
+
+	body := `{"user":"u1","messages":[
+		{"role":"system","content":[{"type":"text","text":"你是助手"}]},
+		{"role":"user","content":[
+			{"type":"text","text":"这张图"},
+			{"type":"image_url","image_url":{"url":"https://example.com/a.png"}},
+			{"type":"text","text":"是什么"}]}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotNil(t, got)
+	assert.Equal(t, "这张图\n是什么", got.Question)
+	assert.Equal(t, "你是助手", got.SystemPrompt)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"user":"u1","messages":[{"role":"user","content":123}]}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestOpenAI_SessionPerClient 未指定 user 时按 API Key 区分会话，两者都没有时拒绝请求
+func TestOpenAI_SessionPerClient(t *testing.T) {
+	var got *core.ThinkingRequest
+	router := newOpenAITestRouter(func(_ context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
+		got = req
+		return &core.ThinkingResponse{Answer: "ok"}, nil
+	})
+
+	ask := func(apiKey string) int {
+		got = nil
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/chat/completions",
+			strings.NewReader(`{"messages":[{"role":"user","content":"hi"}]}`))
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, ask("sk-a"))
+	sessionA := got.SessionID
+	assert.NotEmpty(t, sessionA)
+	assert.NotContains(t, sessionA, "sk-a")
+
+	require.Equal(t, http.StatusOK, ask("sk-a"))
+	assert.Equal(t, sessionA, got.SessionID)
+
+	require.Equal(t, http.StatusOK, ask("sk-b"))
+	assert.NotEqual(t, sessionA, got.SessionID)
+
+	assert.Equal(t, http.StatusBadRequest, ask(""))
+	assert.Nil(t, got)
+}
+
+func TestOpenAI_CapabilityModel(t *testing.T) {
+	var got *core.ThinkingRequest
+	router := newOpenAITestRouter(func(_ context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
+		got = req
+		return &core.ThinkingResponse{Answer: "ok"}, nil
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/chat/completions",
+		strings.NewReader(`{"model":"writer","user":"u1","messages":[{"role":"user","content":"写首诗"}]}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/writer 写首诗", got.Question)
+}
+
+func TestOpenAI_UnknownModel(t *testing.T) {
+	router := newOpenAITestRouter(func(_ context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
+		t.Fatal("post should not be called")
+		return nil, nil
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/chat/completions",
+		strings.NewReader(`{"model":"nope","user":"u1","messages":[{"role":"user","content":"hi"}]}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOpenAI_Stream(t *testing.T) {
+	router := newOpenAITestRouter(func(_ context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
+		return &core.ThinkingResponse{Answer: "流式回答"}, nil
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/chat/completions",
+		strings.NewReader(`{"stream":true,"user":"u1","messages":[{"role":"user","content":"hi"}]}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	var content strings.Builder
+	lines := strings.Split(w.Body.String(), "\n")
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "data: ") || line == "data: [DONE]" {
+			continue
+		}
+		var chunk ChatCompletionChunk
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk))
+		assert.Equal(t, "chat.completion.chunk", chunk.Object)
+		content.WriteString(chunk.Choices[0].Delta.Content)
+	}
+	assert.Equal(t, "流式回答", content.String())
+	assert.Contains(t, w.Body.String(), "data: [DONE]\n\n")
+}
+
+// TestOpenAI_StreamError 出错时推送错误对象后仍以带 finish_reason 的结束块结束流
+func TestOpenAI_StreamError(t *testing.T) {
+	router := newOpenAITestRouter(func(_ context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
+		return nil, errors.New("model unavailable")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/chat/completions",
+		strings.NewReader(`{"stream":true,"user":"u1","messages":[{"role":"user","content":"hi"}]}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var events []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if strings.HasPrefix(line, "data: ") {
+			events = append(events, strings.TrimPrefix(line, "data: "))
+		}
+	}
+	require.Len(t, events, 4)
+	assert.Contains(t, events[1], "model unavailable")
+
+	var last ChatCompletionChunk
+	require.NoError(t, json.Unmarshal([]byte(events[2]), &last))
+	require.Len(t, last.Choices, 1)
+	require.NotNil(t, last.Choices[0].FinishReason)
+	assert.Equal(t, "stop", *last.Choices[0].FinishReason)
+	assert.Equal(t, "[DONE]", events[3])
+}
+
+func TestOpenAI_ListModels(t *testing.T) {
+	router := newOpenAITestRouter(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/models", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"mindx"`)
+	assert.Contains(t, w.Body.String(), `"id":"writer"`)
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"mindx/internal/core"
 	"mindx/internal/entity"
+	"mindx/internal/usecase/approval"
 	"mindx/internal/usecase/capability"
 	"mindx/internal/usecase/cron"
+	"mindx/internal/usecase/embedding"
+	"mindx/internal/usecase/memory"
 	"mindx/internal/usecase/session"
 	"mindx/internal/usecase/skills"
 
@@ -12,11 +16,20 @@ import (
 )
 
 type Assistant interface {
-	Ask(question string, sessionID string, eventChan chan<- entity.ThinkingEvent) (string, string, error)
+	Ask(ctx context.Context, question string, key entity.SessionKey, eventChan chan<- entity.ThinkingEvent) (*core.ThinkingResponse, error)
+	GetBrain() core.Brain
+	Summarize() error
 }
 
 // RegisterRoutes 注册所有路由
-func RegisterRoutes(router *gin.Engine, tokenUsageRepo core.TokenUsageRepository, skillMgr *skills.SkillMgr, capMgr *capability.CapabilityManager, sessionMgr *session.SessionMgr, cronScheduler cron.Scheduler, assistant Assistant) {
+func RegisterRoutes(router *gin.Engine, tokenUsageRepo core.TokenUsageRepository, skillMgr *skills.SkillMgr, capMgr *capability.CapabilityManager, sessionMgr *session.SessionMgr, cronScheduler cron.Scheduler, approvalMgr *approval.Manager, mem *memory.Memory, migrator *embedding.Migrator, assistant Assistant) {
+	// OpenAI 兼容接口
+	var capLookup CapabilityLookup
+	if capMgr != nil {
+		capLookup = capMgr
+	}
+	NewOpenAIHandler(assistant.GetBrain().Post, capLookup).RegisterRoutes(router)
+
 	api := router.Group("/api")
 	{
 		// 健康检查
@@ -96,6 +109,23 @@ func RegisterRoutes(router *gin.Engine, tokenUsageRepo core.TokenUsageRepository
 			cronHandler.RegisterRoutes(api)
 		}
 
+		// 工具调用审批
+		if approvalMgr != nil {
+			approvalHandler := NewApprovalHandler(approvalMgr)
+			approvalHandler.RegisterRoutes(api)
+		}
+
+		// 记忆管理
+		if mem != nil {
+			memoryHandler := NewMemoryHandler(mem, assistant.Summarize)
+			memoryHandler.RegisterRoutes(api)
+		}
+
+		// 嵌入模型迁移
+		if migrator != nil {
+			NewEmbeddingHandler(migrator).RegisterRoutes(api)
+		}
+
 		// 设置管理
 		settings := NewSettingsHandler()
 		api.GET("/settings", settings.getSettings)
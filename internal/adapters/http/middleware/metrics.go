@@ -37,6 +37,12 @@ var (
 		Help: "Total token usage",
 	}, []string{"model", "type"})
 
+	// ThinkingResultParseTotal 左脑 JSON 回答的解析结果：ok 首次解析成功，repaired 修正后成功，failed 修正后仍失败
+	ThinkingResultParseTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mindx_thinking_result_parse_total",
+		Help: "Total number of left-brain JSON result parses by outcome",
+	}, []string{"model", "status"})
+
 	ChannelMessagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "mindx_channel_messages_total",
 		Help: "Total channel messages",
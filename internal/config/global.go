@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type GlobalConfig struct {
 	Version           string                  `mapstructure:"version" json:"version" yaml:"version"`
 	Host              string                  `mapstructure:"host" json:"host" yaml:"host"`
@@ -16,6 +18,153 @@ type GlobalConfig struct {
 	WebSocket         WebSocketConfig         `mapstructure:"websocket,omitempty" json:"websocket,omitempty" yaml:"websocket,omitempty"`
 	GatewayProtection GatewayProtectionConfig `mapstructure:"gateway_protection,omitempty" json:"gateway_protection,omitempty" yaml:"gateway_protection,omitempty"`
 	FileAccess        FileAccessConfig        `mapstructure:"file_access,omitempty" json:"file_access,omitempty" yaml:"file_access,omitempty"`
+	MCPServe          MCPServeConfig          `mapstructure:"mcp_serve,omitempty" json:"mcp_serve,omitempty" yaml:"mcp_serve,omitempty"`
+	Approval          ApprovalConfig          `mapstructure:"approval,omitempty" json:"approval,omitempty" yaml:"approval,omitempty"`
+	ToolCall          ToolCallConfig          `mapstructure:"tool_call,omitempty" json:"tool_call,omitempty" yaml:"tool_call,omitempty"`
+	Cron              CronConfig              `mapstructure:"cron,omitempty" json:"cron,omitempty" yaml:"cron,omitempty"`
+	Rerank            RerankConfig            `mapstructure:"rerank,omitempty" json:"rerank,omitempty" yaml:"rerank,omitempty"`
+	ModelHealth       ModelHealthConfig       `mapstructure:"model_health,omitempty" json:"model_health,omitempty" yaml:"model_health,omitempty"`
+}
+
+// ModelHealthConfig 模型服务健康检查与熔断配置
+// 每个模型服务（按 base_url 区分）有独立的熔断器，熔断期间请求直接使用模型降级链中的下一个模型
+type ModelHealthConfig struct {
+	ProbeInterval int `mapstructure:"probe_interval,omitempty" json:"probe_interval,omitempty" yaml:"probe_interval,omitempty"` // 降级链中模型的健康探测间隔（秒），默认 30，小于 0 时不探测
+	MaxFailures   int `mapstructure:"max_failures,omitempty" json:"max_failures,omitempty" yaml:"max_failures,omitempty"`       // 连续失败多少次后熔断，默认 3
+	ResetTimeout  int `mapstructure:"reset_timeout,omitempty" json:"reset_timeout,omitempty" yaml:"reset_timeout,omitempty"`    // 熔断后多久重新尝试（秒），默认 30
+}
+
+// GetProbeInterval 返回健康探测间隔，为 0 表示不探测
+func (c ModelHealthConfig) GetProbeInterval() time.Duration {
+	if c.ProbeInterval < 0 {
+		return 0
+	}
+	if c.ProbeInterval == 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.ProbeInterval) * time.Second
+}
+
+// GetMaxFailures 返回熔断前允许的连续失败次数
+func (c ModelHealthConfig) GetMaxFailures() int {
+	if c.MaxFailures <= 0 {
+		return 3
+	}
+	return c.MaxFailures
+}
+
+// GetResetTimeout 返回熔断后重新尝试的等待时间
+func (c ModelHealthConfig) GetResetTimeout() time.Duration {
+	if c.ResetTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.ResetTimeout) * time.Second
+}
+
+// RerankConfig 检索结果重排配置
+// 召回的技能在交给右脑之前、记忆在注入提示词之前，按与查询的相关度重新排序
+type RerankConfig struct {
+	Type  string `mapstructure:"type,omitempty" json:"type,omitempty" yaml:"type,omitempty"`    // none（默认，不重排）、heuristic（本地启发式）或 llm
+	Model string `mapstructure:"model,omitempty" json:"model,omitempty" yaml:"model,omitempty"` // llm 重排使用的模型，为空时使用默认模型
+}
+
+// GetType 返回重排器类型，未配置时为 none
+func (c RerankConfig) GetType() string {
+	if c.Type == "" {
+		return "none"
+	}
+	return c.Type
+}
+
+// CronConfig 定时任务调度配置
+// 默认在内核进程内调度，到点后将任务消息投递到指定 Channel 和会话
+type CronConfig struct {
+	Scheduler    string `mapstructure:"scheduler,omitempty" json:"scheduler,omitempty" yaml:"scheduler,omitempty"`             // internal（默认，进程内调度）或 system（系统 crontab / 任务计划程序）
+	Timezone     string `mapstructure:"timezone,omitempty" json:"timezone,omitempty" yaml:"timezone,omitempty"`                // 默认时区，如 Asia/Shanghai，为空时使用系统时区
+	CatchUp      string `mapstructure:"catch_up,omitempty" json:"catch_up,omitempty" yaml:"catch_up,omitempty"`                // 错过执行的补跑策略：skip、once（默认）、all
+	Channel      string `mapstructure:"channel,omitempty" json:"channel,omitempty" yaml:"channel,omitempty"`                   // 投递的 Channel，默认 realtime
+	SessionID    string `mapstructure:"session_id,omitempty" json:"session_id,omitempty" yaml:"session_id,omitempty"`          // 投递的会话 ID
+	HistoryLimit int    `mapstructure:"history_limit,omitempty" json:"history_limit,omitempty" yaml:"history_limit,omitempty"` // 保留的执行记录条数，默认 1000
+}
+
+// UseSystemScheduler 是否使用系统 crontab / 任务计划程序
+func (c CronConfig) UseSystemScheduler() bool {
+	return c.Scheduler == "system"
+}
+
+// GetChannel 返回投递的 Channel，未配置时为 realtime
+func (c CronConfig) GetChannel() string {
+	if c.Channel == "" {
+		return "realtime"
+	}
+	return c.Channel
+}
+
+// GetHistoryLimit 返回保留的执行记录条数
+func (c CronConfig) GetHistoryLimit() int {
+	if c.HistoryLimit <= 0 {
+		return 1000
+	}
+	return c.HistoryLimit
+}
+
+// ToolCallConfig 工具调用执行配置
+// 模型一轮返回多个工具调用时并发执行，声明 non_concurrent 的技能按顺序执行
+type ToolCallConfig struct {
+	MaxParallel int `mapstructure:"max_parallel,omitempty" json:"max_parallel,omitempty" yaml:"max_parallel,omitempty"` // 同一轮最多并发执行的工具数，默认 4，设为 1 时顺序执行
+	Timeout     int `mapstructure:"timeout,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"`                // 单个工具调用的超时（秒），默认 120，不含等待用户确认的时间
+}
+
+// GetMaxParallel 返回同一轮最多并发执行的工具数
+func (c ToolCallConfig) GetMaxParallel() int {
+	if c.MaxParallel <= 0 {
+		return 4
+	}
+	return c.MaxParallel
+}
+
+// GetTimeout 返回单个工具调用的超时，未配置时为 2 分钟
+func (c ToolCallConfig) GetTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 2 * time.Minute
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// ApprovalConfig 工具调用人工审批配置
+// 技能在 SKILL.md 中声明 requires_approval，或命中这里的规则时，执行前需要用户确认
+type ApprovalConfig struct {
+	Timeout int            `mapstructure:"timeout,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"` // 等待确认的超时（秒），默认 120，超时视为拒绝
+	Rules   []ApprovalRule `mapstructure:"rules,omitempty" json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// ApprovalRule 审批规则：技能名匹配且参数匹配时需要确认
+type ApprovalRule struct {
+	Skill string `mapstructure:"skill" json:"skill" yaml:"skill"`                            // 技能名，支持通配符，如 mcp_github_*
+	Args  string `mapstructure:"args,omitempty" json:"args,omitempty" yaml:"args,omitempty"` // 匹配参数 JSON 的正则表达式，为空表示任意参数
+}
+
+// GetTimeout 返回等待确认的超时，未配置时为 2 分钟
+func (c ApprovalConfig) GetTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 2 * time.Minute
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// MCPServeConfig 将技能以 MCP server 形式对外暴露的配置
+// 技能包含 terminal 等高权限工具，默认不启用；启用后建议同时开启 Gateway 防护
+type MCPServeConfig struct {
+	Enabled bool   `mapstructure:"enabled" json:"enabled" yaml:"enabled"`
+	Path    string `mapstructure:"path,omitempty" json:"path,omitempty" yaml:"path,omitempty"` // HTTP 挂载路径，默认 /mcp
+}
+
+// GetPath 返回 HTTP 挂载路径，未配置时使用 /mcp
+func (c MCPServeConfig) GetPath() string {
+	if c.Path == "" {
+		return "/mcp"
+	}
+	return c.Path
 }
 
 // GatewayProtectionConfig Gateway 防护插件配置
@@ -47,9 +196,51 @@ type MemoryConfig struct {
 	SummaryModel string `mapstructure:"summary_model,omitempty" json:"summary_model,omitempty" yaml:"summary_model,omitempty"`
 	KeywordModel string `mapstructure:"keyword_model,omitempty" json:"keyword_model,omitempty" yaml:"keyword_model,omitempty"`
 	Schedule     string `mapstructure:"schedule" json:"schedule" yaml:"schedule"`
+	// DefaultOwner 默认记忆所有者，格式 "channel:user_id"，为空时为本地用户
+	// 迁移前没有所有者的记忆、本地 Channel 与未指定 Channel 的会话都归属该所有者
+	DefaultOwner string `mapstructure:"default_owner,omitempty" json:"default_owner,omitempty" yaml:"default_owner,omitempty"`
+	// LocalChannels 视为本地用户的 Channel，未配置时为 realtime（Web 控制台）
+	LocalChannels []string `mapstructure:"local_channels,omitempty" json:"local_channels,omitempty" yaml:"local_channels,omitempty"`
+	// SearchTopN 每次检索返回的记忆数，默认 3
+	SearchTopN int `mapstructure:"search_top_n,omitempty" json:"search_top_n,omitempty" yaml:"search_top_n,omitempty"`
+	// SearchThreshold 向量检索候选的最低余弦相似度，默认 0.5；全文检索的结果不受此限制
+	SearchThreshold float64 `mapstructure:"search_threshold,omitempty" json:"search_threshold,omitempty" yaml:"search_threshold,omitempty"`
+}
+
+// GetSearchTopN 返回每次检索返回的记忆数
+func (c MemoryConfig) GetSearchTopN() int {
+	if c.SearchTopN <= 0 {
+		return 3
+	}
+	return c.SearchTopN
+}
+
+// GetSearchThreshold 返回向量检索候选的最低余弦相似度
+func (c MemoryConfig) GetSearchThreshold() float64 {
+	if c.SearchThreshold <= 0 {
+		return 0.5
+	}
+	return c.SearchThreshold
+}
+
+// GetLocalChannels 返回视为本地用户的 Channel
+func (c MemoryConfig) GetLocalChannels() []string {
+	if c.LocalChannels == nil {
+		return []string{"realtime"}
+	}
+	return c.LocalChannels
 }
 
 type VectorStoreConfig struct {
+	// Type 存储类型：badger（默认）、sqlite 或 memory
 	Type     string `mapstructure:"type" json:"type" yaml:"type"`
 	DataPath string `mapstructure:"data_path" json:"data_path" yaml:"data_path"`
 }
+
+// GetType 返回向量存储类型，未配置时为 badger
+func (c VectorStoreConfig) GetType() string {
+	if c.Type == "" {
+		return "badger"
+	}
+	return c.Type
+}
@@ -14,14 +14,21 @@ type MCPServersConfig struct {
 	MCPServers map[string]MCPServerEntry `json:"mcpServers"`
 }
 
+// MCP 传输类型
+const (
+	MCPTransportStdio = "stdio" // 本地子进程
+	MCPTransportSSE   = "sse"   // 旧版 HTTP+SSE
+	MCPTransportHTTP  = "http"  // Streamable HTTP（配置中也可写作 "streamable-http"）
+)
+
 type MCPServerEntry struct {
-	// Type: "stdio" (local subprocess) or "sse" (remote HTTP SSE). Default: "stdio"
+	// Type: "stdio" (local subprocess), "sse" (legacy HTTP SSE) or "http"/"streamable-http" (Streamable HTTP). Default: "stdio"
 	Type    string            `json:"type,omitempty"`
 	// stdio fields
 	Command string            `json:"command,omitempty"`
 	Args    []string          `json:"args,omitempty"`
 	Env     map[string]string `json:"env,omitempty"`
-	// sse fields
+	// sse / http fields
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
 	// common
@@ -29,13 +36,23 @@ type MCPServerEntry struct {
 }
 
 // GetType returns the transport type, defaulting to "stdio" for backward compatibility.
+// "streamable-http" is normalized to "http".
 func (e MCPServerEntry) GetType() string {
-	if e.Type == "" {
-		return "stdio"
+	switch e.Type {
+	case "":
+		return MCPTransportStdio
+	case "streamable-http", "streamable_http":
+		return MCPTransportHTTP
 	}
 	return e.Type
 }
 
+// IsRemote 是否为通过 URL 连接的远程 server（sse / http）
+func (e MCPServerEntry) IsRemote() bool {
+	t := e.GetType()
+	return t == MCPTransportSSE || t == MCPTransportHTTP
+}
+
 // LoadMCPServersConfig 加载 MCP 服务器配置
 // 文件不存在时返回空配置（不报错）
 func LoadMCPServersConfig() (*MCPServersConfig, error) {
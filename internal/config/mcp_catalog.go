@@ -33,7 +33,7 @@ type CatalogEntry struct {
 }
 
 type CatalogConnection struct {
-	Type    string            `json:"type"`
+	Type    string            `json:"type"` // stdio | sse | http（streamable-http）
 	Command string            `json:"command,omitempty"`
 	Args    []string          `json:"args,omitempty"`
 	URL     string            `json:"url,omitempty"`
@@ -129,7 +129,7 @@ func ResolveCatalogEntry(entry *CatalogEntry, vars map[string]string) MCPServerE
 	}
 
 	result := MCPServerEntry{
-		Type:    entry.Connection.Type,
+		Type:    MCPServerEntry{Type: entry.Connection.Type}.GetType(),
 		Command: entry.Connection.Command,
 		URL:     replacer(entry.Connection.URL),
 		Enabled: true,
@@ -73,6 +73,31 @@ func TestResolveCatalogEntry_SSE(t *testing.T) {
 	assert.Equal(t, "Bearer my-token", result.Headers["Authorization"])
 }
 
+func TestResolveCatalogEntry_StreamableHTTP(t *testing.T) {
+	entry := &CatalogEntry{
+		ID: "test-http",
+		Connection: CatalogConnection{
+			Type:    "streamable-http",
+			URL:     "https://api.example.com/mcp",
+			Headers: map[string]string{"Authorization": "Bearer ${TOKEN}"},
+		},
+	}
+
+	result := ResolveCatalogEntry(entry, map[string]string{"TOKEN": "my-token"})
+	assert.Equal(t, MCPTransportHTTP, result.Type)
+	assert.True(t, result.IsRemote())
+	assert.Equal(t, "https://api.example.com/mcp", result.URL)
+	assert.Equal(t, "Bearer my-token", result.Headers["Authorization"])
+}
+
+func TestMCPServerEntry_GetType(t *testing.T) {
+	assert.Equal(t, MCPTransportStdio, MCPServerEntry{}.GetType())
+	assert.Equal(t, MCPTransportSSE, MCPServerEntry{Type: "sse"}.GetType())
+	assert.Equal(t, MCPTransportHTTP, MCPServerEntry{Type: "http"}.GetType())
+	assert.Equal(t, MCPTransportHTTP, MCPServerEntry{Type: "streamable-http"}.GetType())
+	assert.False(t, MCPServerEntry{Type: "stdio"}.IsRemote())
+}
+
 func TestMatchCatalogToolDescription(t *testing.T) {
 	descriptions := map[string]string{
 		"get_stock_quote": "获取股票实时行情",
@@ -19,8 +19,28 @@ type ModelConfig struct {
 	APIKey      string  `mapstructure:"api_key" json:"api_key" yaml:"api_key"`
 	Temperature float64 `mapstructure:"temperature" json:"temperature,omitempty" yaml:"temperature"`
 	MaxTokens   int     `mapstructure:"max_tokens" json:"max_tokens,omitempty" yaml:"max_tokens"`
+	// Provider 模型服务的接口类型，为空时按模型用途使用默认接口（对话为 OpenAI 兼容接口，嵌入为 Ollama）
+	// 对话模型还支持 ollama（原生 /api/chat）、anthropic 和 gemini
+	Provider string `mapstructure:"provider,omitempty" json:"provider,omitempty" yaml:"provider,omitempty"`
+	// Dimensions 嵌入模型输出向量的维度，大于 0 时截断到该维度，仅对嵌入模型有效
+	Dimensions int `mapstructure:"dimensions,omitempty" json:"dimensions,omitempty" yaml:"dimensions,omitempty"`
+	// KeepAlive 模型在 Ollama 中保持加载的时长，如 "30m"，"-1" 表示常驻，"0" 表示用完立即卸载；仅 provider 为 ollama 时有效
+	KeepAlive string `mapstructure:"keep_alive,omitempty" json:"keep_alive,omitempty" yaml:"keep_alive,omitempty"`
+	// StructuredOutput OpenAI 兼容接口是否支持 response_format 的 json_schema 类型，不支持时只要求输出 JSON 对象
+	// ollama 和 gemini 原生接口始终以 JSON Schema 约束输出，anthropic 不支持约束输出
+	StructuredOutput bool `mapstructure:"structured_output,omitempty" json:"structured_output,omitempty" yaml:"structured_output,omitempty"`
+	// Fallbacks 降级链：模型的服务不可用时依次改用的模型名称，如本地模型降级到云端模型
+	Fallbacks []string `mapstructure:"fallbacks,omitempty" json:"fallbacks,omitempty" yaml:"fallbacks,omitempty"`
 }
 
+// 模型服务的接口类型
+const (
+	ProviderOpenAI    = "openai"
+	ProviderOllama    = "ollama"
+	ProviderAnthropic = "anthropic" // Anthropic Messages API
+	ProviderGemini    = "gemini"    // Gemini generateContent API
+)
+
 type TokenBudgetConfig struct {
 	ReservedOutputTokens int `mapstructure:"reserved_output_tokens" json:"reserved_output_tokens" yaml:"reserved_output_tokens"`
 	MinHistoryRounds     int `mapstructure:"min_history_rounds" json:"min_history_rounds" yaml:"min_history_rounds"`
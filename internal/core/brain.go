@@ -15,6 +15,8 @@ const (
 	ThinkingEventToolResult = entity.ThinkingEventToolResult
 	ThinkingEventComplete   = entity.ThinkingEventComplete
 	ThinkingEventError      = entity.ThinkingEventError
+	ThinkingEventApproval   = entity.ThinkingEventApproval
+	ThinkingEventFallback   = entity.ThinkingEventFallback
 )
 
 type ThinkingEvent = entity.ThinkingEvent
@@ -25,18 +27,19 @@ type DialogueMessage struct {
 }
 
 // ThinkingResult 思考结果
+// 左脑输出的 JSON Schema 由字段生成，定时和转发相关字段为可选
 type ThinkingResult struct {
-	Answer          string   `json:"answer"`           // Answer 回答的内容（返回给用户)
-	Intent          string   `json:"intent"`           // Intent 意图 判断用户的意图
-	Keywords        []string `json:"keywords"`         // Keywords 关键词 判断用户的意图
-	SendTo          string   `json:"send_to"`          // 发送信息给其它的通信软件
-	HasSchedule     bool     `json:"has_schedule"`     // 是否有定时意图
-	ScheduleName    string   `json:"schedule_name"`    // 定时任务名称
-	ScheduleCron    string   `json:"schedule_cron"`    // Cron 表达式
-	ScheduleMessage string   `json:"schedule_message"` // 定时要发送的消息
-	CancelSchedule  string   `json:"cancel_schedule"`  // 要取消的定时任务名称（非空表示取消意图）
-	Useless         bool     `json:"useless"`          // Useless 标记用户的提问是否无意义
-	CanAnswer       bool     `json:"can_answer"`       // CanAnswer 是否能回答用户的问题
+	Answer          string   `json:"answer"`                            // Answer 回答的内容（返回给用户)
+	Intent          string   `json:"intent"`                            // Intent 意图 判断用户的意图
+	Keywords        []string `json:"keywords"`                          // Keywords 关键词 判断用户的意图
+	SendTo          string   `json:"send_to" required:"false"`          // 发送信息给其它的通信软件
+	HasSchedule     bool     `json:"has_schedule" required:"false"`     // 是否有定时意图
+	ScheduleName    string   `json:"schedule_name" required:"false"`    // 定时任务名称
+	ScheduleCron    string   `json:"schedule_cron" required:"false"`    // Cron 表达式
+	ScheduleMessage string   `json:"schedule_message" required:"false"` // 定时要发送的消息
+	CancelSchedule  string   `json:"cancel_schedule" required:"false"`  // 要取消的定时任务名称（非空表示取消意图）
+	Useless         bool     `json:"useless"`                           // Useless 标记用户的提问是否无意义
+	CanAnswer       bool     `json:"can_answer"`                        // CanAnswer 是否能回答用户的问题
 }
 
 type ToolCallResult struct {
@@ -69,6 +72,8 @@ type ToolCallFunction struct {
 
 // Thinking 负责思考并生成思考的结果
 // 大脑的本质是思考
+// 同一个 Thinking 实例会被并发的请求共享，实现不得保存请求级状态：
+// 思考过程推送到 ctx 携带的事件通道（见 ContextWithThinkingEvents），自定义系统提示随调用传入
 type Thinking interface {
 	// Think 根据提示思考并返回结果
 	Think(ctx context.Context, question string, history []*DialogueMessage, references string, jsonResult bool) (*ThinkingResult, error)
@@ -80,17 +85,50 @@ type Thinking interface {
 	ReturnFuncResults(ctx context.Context, results []ToolExecResult, history []*DialogueMessage, tools []*ToolSchema, question string) (*ToolCallResult, error)
 	// CalculateMaxHistoryCount 计算最大历史对话轮数
 	CalculateMaxHistoryCount() int
-	// SetEventChan 设置事件 channel（用于实时推送思考过程）
-	SetEventChan(ch chan<- ThinkingEvent)
 	// GetSystemPrompt 获取系统提示
 	GetSystemPrompt() string
 }
 
+type thinkingEventsKey struct{}
+
+// ContextWithThinkingEvents 在 ctx 中记录本次请求的思考事件通道，用于实时推送思考过程
+func ContextWithThinkingEvents(ctx context.Context, ch chan<- ThinkingEvent) context.Context {
+	return context.WithValue(ctx, thinkingEventsKey{}, ch)
+}
+
+// ThinkingEventsFromContext 获取 ctx 中的思考事件通道；未记录时返回 nil
+func ThinkingEventsFromContext(ctx context.Context) chan<- ThinkingEvent {
+	ch, _ := ctx.Value(thinkingEventsKey{}).(chan<- ThinkingEvent)
+	return ch
+}
+
 type ThinkingRequest struct {
 	Question  string               `json:"question"`
 	Timeout   int64                `json:"timeout"`
+	ChannelID string               `json:"channel_id,omitempty"`
 	SessionID string               `json:"session_id,omitempty"`
+	SenderID  string               `json:"sender_id,omitempty"` // 发送者在 Channel 中的 ID，用于限定可见的记忆；为空时视为会话本身
 	EventChan chan<- ThinkingEvent `json:"-"`
+	// Model 指定由主意识直接使用的已配置模型（为空则走默认的左右脑流程）
+	Model string `json:"model,omitempty"`
+	// History 调用方自带的历史对话（如 OpenAI 兼容接口），非 nil 时不再通过 OnHistoryRequest 获取
+	History []*DialogueMessage `json:"history,omitempty"`
+	// SystemPrompt 调用方提供的系统提示（如 OpenAI 兼容接口的 system 消息），并入大脑的参考上下文
+	SystemPrompt string `json:"system_prompt,omitempty"`
+}
+
+// SessionKey 返回请求所属会话的隔离键
+func (r *ThinkingRequest) SessionKey() entity.SessionKey {
+	return entity.NewSessionKey(r.ChannelID, r.SessionID)
+}
+
+// MemoryScope 返回请求方检索记忆的范围
+func (r *ThinkingRequest) MemoryScope() MemoryScope {
+	userID := r.SenderID
+	if userID == "" {
+		userID = r.SessionID
+	}
+	return MemoryScope{ChannelID: r.ChannelID, UserID: userID, SessionID: r.SessionID}
 }
 
 // ThinkingResponse 思考响应(大脑专用)
@@ -102,6 +140,8 @@ type ThinkingResponse struct {
 	ScheduleName    string        `json:"schedule_name"`    // 定时任务名称
 	ScheduleCron    string        `json:"schedule_cron"`    // Cron 表达式
 	ScheduleMessage string        `json:"schedule_message"` // 定时要发送的消息
+	// Attachments 工具产出的附件（如 MCP 工具返回的图片），随回答一并发送
+	Attachments []*entity.Attachment `json:"attachments,omitempty"`
 }
 
 // ToolSchema 发起FunctionCall使用的工具Schema
@@ -123,9 +163,6 @@ type Brain struct {
 	LeftBrain Thinking
 	// RightBrain 右脑，负责行为性思考，执行Function call获取Skill的最终调用Schema。采用专用的函索生成模型
 	RightBrain Thinking
-	// Consciousness 意识，负责高级思考和决策。通过能力定义指向的智能体+远程大语言模型
-	// 注意：主意识是动态创建的，初始化时可能为 nil
-	Consciousness Thinking
 	// GetMemory 获取长时记忆系统
 	GetMemory func() (Memory, error)
 	// Post 处理思考请求
@@ -134,29 +171,46 @@ type Brain struct {
 	// 2. 获取会话历史（通过 OnHistoryRequest 回调）
 	// 3. 使用左脑进行思考，如果Tools有匹配的工具，则触发OnToolsRequest获取工具的Schema，启动右脑获取Skill的最终调用Schema
 	// 4. 如果左脑思考的结果表明左脑无法回答用户，则会触发OnCapabilityRequest获取复杂的能力，如果能匹配则启用远程思考模式；
-	Post func(req *ThinkingRequest) (*ThinkingResponse, error)
+	// ctx 取消（如用户发送 /stop、关闭页面）时中止思考和正在执行的技能
+	Post func(ctx context.Context, req *ThinkingRequest) (*ThinkingResponse, error)
 	// OnThinkingEvent 思考流事件回调，用于实时推送思考过程
 	OnThinkingEvent func(sessionID string, event map[string]any)
 }
 
-// OnToolsRequest 处理工具请求的回调函数,从已装载工具中匹配与关键字最相似的工具
-type OnToolsRequest func(keywords ...string) ([]*ToolSchema, error)
+// OnToolsRequest 处理工具请求的回调函数,从已装载工具中匹配与关键字最相似的工具，ctx 为当前请求的上下文
+type OnToolsRequest func(ctx context.Context, keywords ...string) ([]*ToolSchema, error)
 
 // OnCapabilityRequest 处理能力请求的回调函数,从已装载能力中匹配最相近的能力
 type OnCapabilityRequest func(keywords ...string) (*entity.Capability, error)
 
 // OnHistoryRequest 处理历史对话请求的回调函数,获取会话的历史对话
+// key: 发起请求的会话隔离键
 // maxCount: 最多获取多少轮历史对话，用于限制模型的承载能力
-type OnHistoryRequest func(maxCount int) ([]*DialogueMessage, error)
+type OnHistoryRequest func(key entity.SessionKey, maxCount int) ([]*DialogueMessage, error)
+
+// ResourceContent 外部资源内容（如 MCP resources），作为思考的参考上下文
+type ResourceContent struct {
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+// OnResourceRequest 处理资源请求的回调函数,从已连接的外部资源中检索与问题相关的内容
+type OnResourceRequest func(question string) ([]*ResourceContent, error)
+
+// OnPromptRequest 处理快捷指令请求的回调函数,将 "/名称 参数" 展开为预置的对话消息（如 MCP prompts）
+// found 为 false 表示不存在该快捷指令，调用方应按能力前缀继续处理
+type OnPromptRequest func(name, args string) (messages []*DialogueMessage, found bool, err error)
 
 // SessionMgr 会话管理器接口
+// 会话按 (Channel, 发送者/会话标识) 隔离，每个键拥有独立的历史、Token 预算和记忆提取
 type SessionMgr interface {
 	// RecordMessage 记录消息并判断是否需要结束会话
-	RecordMessage(msg entity.Message) error
-	// GetHistory 获取当前会话的所有对话内容
-	GetHistory() []entity.Message
+	RecordMessage(key entity.SessionKey, msg entity.Message) error
+	// GetHistory 获取指定会话的所有对话内容
+	GetHistory(key entity.SessionKey) []entity.Message
 	// UpdateTokensFromModel 从模型Usage更新Token消耗
-	UpdateTokensFromModel(usage TokenUsage)
+	UpdateTokensFromModel(key entity.SessionKey, usage TokenUsage)
 }
 
 // MemoryExtractor 记忆提取器接口
@@ -1,10 +1,86 @@
 package core
 
 import (
+	"context"
 	"mindx/internal/entity"
+	"strings"
 	"time"
 )
 
+// MemoryVisibility 记忆的可见范围
+type MemoryVisibility string
+
+const (
+	// MemoryPrivate 仅所有者可见（默认）
+	MemoryPrivate MemoryVisibility = "private"
+	// MemoryShared 同一 Channel 内的所有用户可见，如家庭共用的机器人
+	MemoryShared MemoryVisibility = "shared"
+	// MemoryGlobal 所有用户可见
+	MemoryGlobal MemoryVisibility = "global"
+)
+
+// IsValid 是否为已知的可见范围
+func (v MemoryVisibility) IsValid() bool {
+	switch v {
+	case MemoryPrivate, MemoryShared, MemoryGlobal:
+		return true
+	}
+	return false
+}
+
+// MemoryOwner 记忆所有者：Channel 加上发送者在该 Channel 中的 ID
+// 零值为默认所有者，即本地用户（CLI、Web 控制台等）
+type MemoryOwner struct {
+	ChannelID string `json:"channel_id,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+}
+
+// ParseMemoryOwner 解析 "channel:user_id" 形式的所有者，空字符串为默认所有者
+func ParseMemoryOwner(s string) MemoryOwner {
+	channelID, userID, ok := strings.Cut(s, ":")
+	if !ok {
+		return MemoryOwner{UserID: s}
+	}
+	return MemoryOwner{ChannelID: channelID, UserID: userID}
+}
+
+// IsDefault 是否为默认所有者
+func (o MemoryOwner) IsDefault() bool {
+	return o == MemoryOwner{}
+}
+
+// String 返回 "channel:user_id" 形式，默认所有者为 "default"
+func (o MemoryOwner) String() string {
+	if o.IsDefault() {
+		return "default"
+	}
+	return o.ChannelID + ":" + o.UserID
+}
+
+// MemoryScope 检索记忆的请求方
+// 可见的记忆为：所有者是 User 或 Session 的私有记忆、同一 Channel 的共享记忆以及全局记忆。
+// 群聊中 Session 为群聊 ID，群聊中提取的记忆归属该 ID；零值表示默认所有者
+type MemoryScope struct {
+	ChannelID string `json:"channel_id,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// CanSee 记忆点对请求方是否可见
+func (s MemoryScope) CanSee(point MemoryPoint) bool {
+	switch point.Visibility {
+	case MemoryGlobal:
+		return true
+	case MemoryShared:
+		return point.ChannelID == s.ChannelID
+	default:
+		if point.ChannelID != s.ChannelID {
+			return false
+		}
+		return point.OwnerID == s.UserID || (s.SessionID != "" && point.OwnerID == s.SessionID)
+	}
+}
+
 // MemoryPoint 记忆点结构体
 type MemoryPoint struct {
 	ID             int       `json:"id"`
@@ -19,6 +95,23 @@ type MemoryPoint struct {
 	TotalWeight    float64   `json:"total_weight"`    // 总权重
 	CreatedAt      time.Time `json:"created_at"`      // 创建时间
 	UpdatedAt      time.Time `json:"updated_at"`      // 更新时间
+
+	ChannelID  string           `json:"channel_id,omitempty"` // 所有者所在 Channel
+	OwnerID    string           `json:"owner_id,omitempty"`   // 所有者 ID，空表示默认所有者
+	Visibility MemoryVisibility `json:"visibility,omitempty"` // 可见范围，空表示迁移前的旧数据
+
+	Score float64 `json:"score,omitempty"` // 检索相关度（0~1），仅检索结果中有值，不会存储
+}
+
+// Owner 返回记忆点的所有者
+func (p MemoryPoint) Owner() MemoryOwner {
+	return MemoryOwner{ChannelID: p.ChannelID, UserID: p.OwnerID}
+}
+
+// SetOwner 设置记忆点的所有者
+func (p *MemoryPoint) SetOwner(owner MemoryOwner) {
+	p.ChannelID = owner.ChannelID
+	p.OwnerID = owner.UserID
 }
 
 // Memory 长时记忆系统接口
@@ -27,9 +120,10 @@ type Memory interface {
 	// Record 记录一个完整的记忆点
 	// 接收一个完整的 MemoryPoint，包含所有必要的字段，然后存入记忆库
 	Record(point MemoryPoint) error
-	// Search 根据输入内容搜索相似的记忆
-	// 搜索循序是先筛选关键字的相似度(>60%)，再筛选摘要的相似度(并集),获得相似度最高的1~3条记忆片段, 并按权重值排序
-	Search(terms string) ([]MemoryPoint, error)
+	// Search 根据输入内容搜索请求方可见的相似记忆
+	// 分别进行向量检索和 BM25 全文检索，按倒数排名融合后返回相关度最高的记忆，Score 为融合后的相关度
+	// ctx 为当前请求的上下文，取消时中止重排
+	Search(ctx context.Context, scope MemoryScope, terms string) ([]MemoryPoint, error)
 	// Optimize 优化记忆系统
 	// 清理过期和无效记忆，提升系统性能
 	Optimize() error
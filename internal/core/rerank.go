@@ -0,0 +1,17 @@
+package core
+
+import "context"
+
+// RerankDocument 待重排的候选文档
+type RerankDocument struct {
+	ID    string  // 调用方用于对应原始结果的标识
+	Text  string  // 参与相关度判断的文本
+	Score float64 // 召回阶段的得分；重排后为重排器给出的 0~1 相关度
+}
+
+// Reranker 对召回的候选结果按与查询的相关度重新排序
+// 返回的文档与输入一一对应，Score 替换为重排得分，按得分降序排列
+type Reranker interface {
+	Name() string
+	Rerank(ctx context.Context, query string, docs []RerankDocument) ([]RerankDocument, error)
+}
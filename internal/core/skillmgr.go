@@ -1,5 +1,7 @@
 package core
 
+import "context"
+
 type Skill struct {
 	GetName     func() string
 	Execute     func(name string, params map[string]interface{}) error
@@ -9,9 +11,9 @@ type Skill struct {
 // SkillMgr 技能管理器
 // 技能管理器采用FIFO队列，每次执行一个技能
 type SkillManager interface {
-	Execute(skill *Skill, params map[string]interface{}) error // 执行技能
-	ExecuteFunc(function ToolCallFunction) (string, error)     // 执行工具
-	GetSkills() ([]*Skill, error)                              // 获取全部技能
-	SearchSkills(keywords ...string) ([]*Skill, error)         // 搜索名称、用法与关键字相似度最高的技能
-	RegisterInternalSkill(name string, fn func(params map[string]any) (string, error)) // 注册内部技能
+	Execute(skill *Skill, params map[string]interface{}) error                                              // 执行技能
+	ExecuteFunc(ctx context.Context, function ToolCallFunction) (string, error)                             // 执行工具，ctx 取消时中止执行
+	GetSkills() ([]*Skill, error)                                                                           // 获取全部技能
+	SearchSkills(ctx context.Context, keywords ...string) ([]*Skill, error)                                 // 搜索名称、用法与关键字相似度最高的技能，ctx 用于取消重排
+	RegisterInternalSkill(name string, fn func(ctx context.Context, params map[string]any) (string, error)) // 注册内部技能
 }
@@ -1,8 +1,35 @@
 package core
 
-import "mindx/internal/entity"
+import (
+	"errors"
+	"mindx/internal/entity"
+	"regexp"
+)
+
+// 内置集合名称
+const (
+	CollectionDefault      = "default"
+	CollectionMemories     = "memories"
+	CollectionSkills       = "skills"
+	CollectionCapabilities = "capabilities"
+)
+
+// ErrDimensionMismatch 写入集合的向量维度与集合已有向量不一致
+var ErrDimensionMismatch = errors.New("vector dimension mismatch")
+
+var collectionNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+// ValidateCollectionName 校验集合名称：小写字母、数字、下划线和连字符，最长 64 个字符
+func ValidateCollectionName(name string) error {
+	if !collectionNamePattern.MatchString(name) {
+		return errors.New("invalid collection name: " + name)
+	}
+	return nil
+}
 
 // Store 向量存储接口
+// 直接调用时作用于 default 集合；不同集合的 key 互不冲突，
+// 各自校验向量维度，检索只在集合内进行
 type Store interface {
 	Put(key string, vector []float64, metadata interface{}) error
 	Get(key string) (*entity.VectorEntry, error)
@@ -11,5 +38,10 @@ type Store interface {
 	SearchWithThreshold(queryVec []float64, topN int, minScore float64) ([]entity.VectorEntry, error)
 	BatchPut(entries []entity.VectorEntry) error
 	Scan(prefix string) ([]entity.VectorEntry, error)
+	// Collection 返回命名集合，集合与所属存储共享底层数据库
+	Collection(name string) (Store, error)
+	// Dimension 返回当前集合的向量维度，尚无向量时为 0
+	Dimension() int
+	// Close 关闭存储；对集合调用时不做任何事
 	Close() error
 }
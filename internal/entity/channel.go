@@ -69,6 +69,16 @@ type IncomingMessage struct {
 	ConversationID string `json:"conversation_id,omitempty"`
 }
 
+// SessionKey 返回消息所属会话的隔离键
+// 优先使用 Channel 提供的 SessionID（如群聊 ID），否则退化为发送者 ID
+func (m *IncomingMessage) SessionKey() SessionKey {
+	id := m.SessionID
+	if id == "" && m.Sender != nil {
+		id = m.Sender.ID
+	}
+	return NewSessionKey(m.ChannelID, id)
+}
+
 // OutgoingMessage 发出的消息 (从系统发送到外部)
 type OutgoingMessage struct {
 	// ChannelID 目标 Channel ID
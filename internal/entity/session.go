@@ -1,22 +1,89 @@
 package entity
 
 import (
+	"context"
 	"time"
 )
 
 // Message 表示一条对话消息
 type Message struct {
-	Role    string    `json:"role"`    // "user" 或 "assistant"
-	Content string    `json:"content"` // 消息内容
-	Time    time.Time `json:"time"`    // 消息时间
+	Role     string    `json:"role"`                // "user" 或 "assistant"
+	Content  string    `json:"content"`             // 消息内容
+	Time     time.Time `json:"time"`                // 消息时间
+	SenderID string    `json:"sender_id,omitempty"` // 用户消息的发送者 ID（群聊中区分不同成员）
 }
 
 // Session 表示一个会话
 type Session struct {
-	ID         string    `json:"id"`          // 会话ID（仅内部使用，不对外暴露）
-	Messages   []Message `json:"messages"`    // 所有消息记录（用于Web显示和Brain使用）
-	TokensUsed int       `json:"tokens_used"` // Token消耗量（累积统计）
-	IsEnded    bool      `json:"is_ended"`    // 会话是否已结束
-	CreatedAt  time.Time `json:"created_at"`   // 创建时间
-	EndedAt    time.Time `json:"ended_at"`    // 结束时间
+	ID         string    `json:"id"`                   // 会话ID（仅内部使用，不对外暴露）
+	ChannelID  string    `json:"channel_id,omitempty"` // 会话所属 Channel
+	SenderID   string    `json:"sender_id,omitempty"`  // 会话所属发送者/会话标识
+	Messages   []Message `json:"messages"`             // 所有消息记录（用于Web显示和Brain使用）
+	TokensUsed int       `json:"tokens_used"`          // Token消耗量（累积统计）
+	IsEnded    bool      `json:"is_ended"`             // 会话是否已结束
+	CreatedAt  time.Time `json:"created_at"`           // 创建时间
+	EndedAt    time.Time `json:"ended_at"`             // 结束时间
+}
+
+// Key 返回会话所属的隔离键
+func (s *Session) Key() SessionKey {
+	return SessionKey{ChannelID: s.ChannelID, SenderID: s.SenderID}
+}
+
+// SessionKey 会话隔离键
+// 由 Channel 与发送者/会话标识共同决定，不同键的会话拥有独立的历史和 Token 预算
+type SessionKey struct {
+	ChannelID string `json:"channel_id"`
+	SenderID  string `json:"sender_id"`
+}
+
+// DefaultSessionKey 默认会话键
+// 未指定 Channel 和会话标识的请求（如 CLI、旧版本持久化的会话）使用该键
+var DefaultSessionKey = SessionKey{}
+
+// NewSessionKey 创建会话隔离键
+func NewSessionKey(channelID, senderID string) SessionKey {
+	return SessionKey{ChannelID: channelID, SenderID: senderID}
+}
+
+// IsDefault 是否为默认会话键
+func (k SessionKey) IsDefault() bool {
+	return k == DefaultSessionKey
+}
+
+// String 返回会话键的字符串形式，用于日志和索引
+func (k SessionKey) String() string {
+	if k.IsDefault() {
+		return "default"
+	}
+	return k.ChannelID + ":" + k.SenderID
+}
+
+type sessionKeyContextKey struct{}
+
+// ContextWithSessionKey 在 ctx 中记录发起请求的会话，供技能等下游获取请求来源
+func ContextWithSessionKey(ctx context.Context, key SessionKey) context.Context {
+	return context.WithValue(ctx, sessionKeyContextKey{}, key)
+}
+
+// SessionKeyFromContext 获取 ctx 中记录的会话；未记录时返回 false
+func SessionKeyFromContext(ctx context.Context) (SessionKey, bool) {
+	key, ok := ctx.Value(sessionKeyContextKey{}).(SessionKey)
+	return key, ok
+}
+
+type senderContextKey struct{}
+
+// ContextWithSender 在 ctx 中记录消息发送者，供记忆等下游区分同一会话中的不同用户
+func ContextWithSender(ctx context.Context, sender *MessageSender) context.Context {
+	if sender == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, senderContextKey{}, sender)
+}
+
+// SenderFromContext 获取 ctx 中记录的消息发送者；未记录时返回 nil
+func SenderFromContext(ctx context.Context) *MessageSender {
+	sender, _ := ctx.Value(senderContextKey{}).(*MessageSender)
+	return sender
 }
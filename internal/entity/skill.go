@@ -4,24 +4,28 @@ import "time"
 
 // SkillDef 技能定义（从 SKILL.md 读取）
 type SkillDef struct {
-	Name         string                 `yaml:"name" json:"name"`
-	Description  string                 `yaml:"description" json:"description"`
-	Version      string                 `yaml:"version" json:"version"`
-	Category     string                 `yaml:"category" json:"category"`
-	Tags         []string               `yaml:"tags" json:"tags"`
-	Emoji        string                 `yaml:"emoji" json:"emoji"`
-	OS           []string               `yaml:"os" json:"os"`
-	Enabled      bool                   `yaml:"enabled" json:"enabled"`
-	Timeout      int                    `yaml:"timeout" json:"timeout"`
-	Command      string                 `yaml:"command" json:"command"`
+	Name         string                  `yaml:"name" json:"name"`
+	Description  string                  `yaml:"description" json:"description"`
+	Version      string                  `yaml:"version" json:"version"`
+	Category     string                  `yaml:"category" json:"category"`
+	Tags         []string                `yaml:"tags" json:"tags"`
+	Emoji        string                  `yaml:"emoji" json:"emoji"`
+	OS           []string                `yaml:"os" json:"os"`
+	Enabled      bool                    `yaml:"enabled" json:"enabled"`
+	Timeout      int                     `yaml:"timeout" json:"timeout"`
+	Command      string                  `yaml:"command" json:"command"`
 	Parameters   map[string]ParameterDef `yaml:"parameters" json:"parameters"`
-	Requires     *Requires              `yaml:"requires,omitempty" json:"requires,omitempty"`
-	Install      []InstallMethod        `yaml:"install,omitempty" json:"install,omitempty"`
-	Homepage     string                 `yaml:"homepage,omitempty" json:"homepage,omitempty"`
-	Metadata     map[string]interface{} `yaml:"metadata,omitempty" json:"metadata,omitempty"`
-	OutputFormat string                 `yaml:"output_format,omitempty" json:"output_format,omitempty"`
-	Guidance     string                 `yaml:"guidance,omitempty" json:"guidance,omitempty"`
-	IsInternal   bool                   `yaml:"is_internal,omitempty" json:"is_internal,omitempty"`
+	Requires     *Requires               `yaml:"requires,omitempty" json:"requires,omitempty"`
+	Install      []InstallMethod         `yaml:"install,omitempty" json:"install,omitempty"`
+	Homepage     string                  `yaml:"homepage,omitempty" json:"homepage,omitempty"`
+	Metadata     map[string]interface{}  `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	OutputFormat string                  `yaml:"output_format,omitempty" json:"output_format,omitempty"`
+	Guidance     string                  `yaml:"guidance,omitempty" json:"guidance,omitempty"`
+	IsInternal   bool                    `yaml:"is_internal,omitempty" json:"is_internal,omitempty"`
+	// RequiresApproval 执行前需要用户确认（如删除文件、发送邮件等不可逆操作）
+	RequiresApproval bool `yaml:"requires_approval,omitempty" json:"requires_approval,omitempty"`
+	// NonConcurrent 不能与同类调用并发执行（如写文件、执行命令），同一轮中的此类调用按顺序执行
+	NonConcurrent bool `yaml:"non_concurrent,omitempty" json:"non_concurrent,omitempty"`
 }
 
 // Requires 依赖定义
@@ -73,10 +77,10 @@ type SkillInfo struct {
 	Vector []float64 `json:"vector,omitempty"`
 
 	// 统计信息
-	SuccessCount    int        `json:"successCount"`
-	ErrorCount      int        `json:"errorCount"`
-	LastRunTime     *time.Time `json:"lastRunTime,omitempty"`
-	LastError       string     `json:"lastError,omitempty"`
-	AvgExecutionMs  int64      `json:"avgExecutionMs"`
-	ExecutionTimes  []int64    `json:"executionTimes"`
+	SuccessCount   int        `json:"successCount"`
+	ErrorCount     int        `json:"errorCount"`
+	LastRunTime    *time.Time `json:"lastRunTime,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	AvgExecutionMs int64      `json:"avgExecutionMs"`
+	ExecutionTimes []int64    `json:"executionTimes"`
 }
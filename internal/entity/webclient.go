@@ -16,6 +16,8 @@ const (
 	ThinkingEventToolResult ThinkingEventType = "tool_result"
 	ThinkingEventComplete   ThinkingEventType = "complete"
 	ThinkingEventError      ThinkingEventType = "error"
+	ThinkingEventApproval   ThinkingEventType = "approval" // 工具调用等待用户确认
+	ThinkingEventFallback   ThinkingEventType = "fallback" // 模型服务不可用，改用降级模型
 )
 
 type ThinkingEvent struct {
@@ -2,6 +2,7 @@ package bootstrap
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"mindx/internal/adapters/channels"
 	"mindx/internal/adapters/http/handlers"
@@ -12,10 +13,13 @@ import (
 	infraEmbedding "mindx/internal/infrastructure/embedding"
 	infraLlama "mindx/internal/infrastructure/llama"
 	"mindx/internal/infrastructure/persistence"
+	"mindx/internal/usecase/approval"
 	"mindx/internal/usecase/capability"
 	"mindx/internal/usecase/cron"
 	"mindx/internal/usecase/embedding"
 	"mindx/internal/usecase/memory"
+	"mindx/internal/usecase/modelrouter"
+	"mindx/internal/usecase/rerank"
 	"mindx/internal/usecase/session"
 	"mindx/internal/usecase/skills"
 	"mindx/internal/usecase/skills/builtins"
@@ -24,7 +28,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/sashabaranov/go-openai"
 )
@@ -59,6 +65,8 @@ type App struct {
 	Capabilities   *capability.CapabilityManager
 	CronScheduler  cron.Scheduler
 	TokenUsageRepo core.TokenUsageRepository
+	VectorStore    core.Store
+	ModelRouter    *modelrouter.Router
 }
 
 var a *App
@@ -116,34 +124,53 @@ func Startup() (*App, error) {
 
 	modelsMgr := config.GetModelsManager()
 
+	// 模型路由器需在创建大脑和能力管理器之前设置，二者通过它选择可用的模型
+	modelRouter := modelrouter.New(srvCfg.ModelHealth, logging.GetSystemLogger().Named("model_router"))
+	modelrouter.SetDefault(modelRouter)
+	modelRouter.Start(ctx)
+	systemLogger.Info("模型路由器初始化完成", logging.Duration("probe_interval", srvCfg.ModelHealth.GetProbeInterval()))
+
 	systemLogger.Info("初始化向量化服务")
 	embeddingModel := modelsMgr.GetEmbeddingModel()
 	if embeddingModel == "" {
 		embeddingModel = "qllama/bge-small-zh-v1.5:latest"
 	}
 
-	// 尝试从模型配置中获取 Ollama 服务器地址
-	ollamaURL := "http://localhost:11434"
-	if modelConfig, err := modelsMgr.GetModel(embeddingModel); err == nil && modelConfig.BaseURL != "" {
-		ollamaURL = modelConfig.BaseURL
-	}
-
-	ollamaProvider, err := infraEmbedding.NewOllamaEmbedding(ollamaURL, embeddingModel)
+	// 按 models.yml 中同名模型的配置选择提供器，未配置时使用本地 Ollama
+	embeddingModelCfg, _ := modelsMgr.GetModel(embeddingModel)
+	embeddingProvider, err := infraEmbedding.NewProvider(embeddingModel, embeddingModelCfg)
 	if err != nil {
 		return nil, fmt.Errorf("构建向量化提供器失败: %w", err)
 	}
-	embeddingSvc := embedding.NewEmbeddingService(ollamaProvider)
-	systemLogger.Info("向量化服务初始化完成", logging.String("provider", "ollama"), logging.String("model", embeddingModel), logging.String("base_url", ollamaURL))
+	embeddingSvc := embedding.NewEmbeddingService(embeddingProvider)
+	providerName, baseURL := config.ProviderOllama, ""
+	if embeddingModelCfg != nil {
+		baseURL = embeddingModelCfg.BaseURL
+		if embeddingModelCfg.Provider != "" {
+			providerName = embeddingModelCfg.Provider
+		}
+	}
+	systemLogger.Info("向量化服务初始化完成", logging.String("provider", providerName), logging.String("model", embeddingModel), logging.String("base_url", baseURL))
 
 	vectorsPath, err := config.GetWorkspaceVectorsPath()
 	if err != nil {
 		return nil, err
 	}
-	store, err := persistence.NewStore("badger", vectorsPath, nil)
+	storeType := srvCfg.VectorStore.GetType()
+	store, err := persistence.NewStore(storeType, vectorsPath, nil)
 	if err != nil {
 		return nil, fmt.Errorf("创建向量存储失败: %w", err)
 	}
-	systemLogger.Info("向量存储初始化完成", logging.String("type", "badger"))
+	// 记忆、技能和能力各自使用独立集合，互不出现在对方的检索结果中
+	collections := make(map[string]core.Store)
+	for _, name := range []string{core.CollectionMemories, core.CollectionSkills, core.CollectionCapabilities} {
+		collection, err := store.Collection(name)
+		if err != nil {
+			return nil, fmt.Errorf("打开向量集合 %s 失败: %w", name, err)
+		}
+		collections[name] = collection
+	}
+	systemLogger.Info("向量存储初始化完成", logging.String("type", storeType))
 
 	systemLogger.Info("初始化会话管理器")
 	sessionsPath, err := config.GetWorkspaceSessionsPath()
@@ -183,7 +210,7 @@ func Startup() (*App, error) {
 	openaiCfg.BaseURL = memModel.BaseURL
 	memLLMClient := openai.NewClientWithConfig(openaiCfg)
 
-	mem, err := memory.NewMemory(srvCfg, memLLMClient, systemLogger, store, embeddingSvc)
+	mem, err := memory.NewMemory(srvCfg, memLLMClient, systemLogger, collections[core.CollectionMemories], embeddingSvc)
 	if err != nil {
 		return nil, fmt.Errorf("初始化记忆系统失败: %w", err)
 	}
@@ -201,7 +228,7 @@ func Startup() (*App, error) {
 	systemLogger.Info("Token 使用记录仓库初始化完成")
 
 	systemLogger.Info("初始化能力管理器")
-	capMgr, err := capability.NewManager(capabilitiesCfg, store, embeddingSvc, workspace)
+	capMgr, err := capability.NewManager(capabilitiesCfg, collections[core.CollectionCapabilities], embeddingSvc, workspace)
 	if err != nil {
 		return nil, fmt.Errorf("初始化能力管理器失败: %w", err)
 	}
@@ -240,19 +267,39 @@ func Startup() (*App, error) {
 		return nil, err
 	}
 
-	skillMgr, err := skills.NewSkillMgrWithStore(installSkillsPath, workspace, embeddingSvc, llamaSvc, store, systemLogger)
+	skillMgr, err := skills.NewSkillMgrWithStore(installSkillsPath, workspace, embeddingSvc, llamaSvc, collections[core.CollectionSkills], systemLogger)
 	if err != nil {
 		return nil, fmt.Errorf("初始化技能管理器失败: %w", err)
 	}
 
+	reranker, err := rerank.New(srvCfg.Rerank, modelsMgr)
+	if err != nil {
+		systemLogger.Warn("初始化重排器失败，不进行重排", logging.Err(err))
+		reranker = nil
+	}
+	if reranker != nil {
+		mem.SetReranker(reranker)
+		skillMgr.SetReranker(reranker)
+		systemLogger.Info("检索重排已启用", logging.String("reranker", reranker.Name()))
+	}
+
+	// 默认使用进程内调度器，配置 cron.scheduler: system 时沿用系统 crontab / 任务计划程序
 	var cronScheduler cron.Scheduler
-	switch runtime.GOOS {
-	case "linux", "darwin":
-		cronScheduler, err = infra_cron.NewCrontabScheduler()
-	case "windows":
-		cronScheduler, err = infra_cron.NewWindowsTaskScheduler()
-	default:
-		systemLogger.Warn("Unsupported platform for cron scheduler")
+	var inProcessScheduler *infra_cron.InProcessScheduler
+	if !srvCfg.Cron.UseSystemScheduler() {
+		inProcessScheduler, err = infra_cron.NewInProcessScheduler(srvCfg.Cron, systemLogger.Named("cron"))
+		if err == nil {
+			cronScheduler = inProcessScheduler
+		}
+	} else {
+		switch runtime.GOOS {
+		case "linux", "darwin":
+			cronScheduler, err = infra_cron.NewCrontabScheduler()
+		case "windows":
+			cronScheduler, err = infra_cron.NewWindowsTaskScheduler()
+		default:
+			systemLogger.Warn("Unsupported platform for cron scheduler")
+		}
 	}
 	if err != nil {
 		systemLogger.Warn("Failed to init cron scheduler", logging.Err(err))
@@ -310,8 +357,30 @@ func Startup() (*App, error) {
 
 	systemLogger.Info("技能管理器初始化完成", logging.Int("skills_count", len(skillMgr.GetSkillInfos())))
 
+	// 嵌入模型与已存储的向量不一致时（更换了 embedding_model），在后台重新生成向量
+	dataPath, err := config.GetWorkspaceDataPath()
+	if err != nil {
+		return nil, err
+	}
+	migrator := embedding.NewMigrator(embeddingSvc, embeddingModel, filepath.Join(dataPath, "embedding_migration.json"), systemLogger,
+		mem.MigrationSource(), skillMgr.MigrationSource(), capMgr.MigrationSource())
+	go func() {
+		started, err := migrator.StartIfNeeded()
+		if err != nil {
+			systemLogger.Warn("检查嵌入模型一致性失败", logging.Err(err))
+		} else if started {
+			systemLogger.Info("嵌入模型已变更，开始重新生成向量（后台运行）", logging.String("model", embeddingModel))
+		}
+	}()
+
 	var memoryExtractor *memory.LLMExtractor
 
+	systemLogger.Info("初始化工具调用审批")
+	approvalMgr, err := approval.NewManager(srvCfg.Approval, filepath.Join(dataPath, "approval_audit.jsonl"), systemLogger)
+	if err != nil {
+		return nil, fmt.Errorf("初始化工具调用审批失败: %w", err)
+	}
+
 	systemLogger.Info("初始化 Assistant")
 	assistant := NewAssistant(
 		srvCfg,
@@ -322,6 +391,7 @@ func Startup() (*App, error) {
 		systemLogger,
 		tokenUsageRepo,
 		cronScheduler,
+		approvalMgr,
 	)
 	systemLogger.Info("Assistant 初始化完成",
 		logging.String("name", assistant.GetName()),
@@ -359,7 +429,7 @@ func Startup() (*App, error) {
 	})
 
 	channelRouter.SetOnMessage(func(ctx context.Context, msg *entity.IncomingMessage, eventChan chan<- entity.ThinkingEvent) (string, string, error) {
-		answer, sendTo, err := assistant.Ask(msg.Content, msg.SessionID, eventChan)
+		resp, err := assistant.Ask(entity.ContextWithSender(ctx, msg.Sender), msg.Content, msg.SessionKey(), eventChan)
 		if err != nil {
 			systemLogger.Error("处理消息失败",
 				logging.String("session_id", msg.SessionID),
@@ -368,7 +438,54 @@ func Startup() (*App, error) {
 			return "", "", err
 		}
 
-		return answer, sendTo, nil
+		channels.AttachToReply(ctx, resp.Attachments...)
+		return resp.Answer, resp.SendTo, nil
+	})
+
+	// 工具调用确认：实时通道通过思考流事件提示，其他 Channel 发送文本提示并接收回复
+	// 默认会话没有可以回复的用户，提示未送达时由审批管理器直接拒绝
+	approvalMgr.SetNotifier(func(req *approval.Request) bool {
+		if req.Session.IsDefault() || req.Session.ChannelID == realtimeChannel.Name() {
+			return false
+		}
+		prompt := i18n.TWithData("approval.im_prompt", map[string]interface{}{
+			"Skill":     req.Skill,
+			"Arguments": formatApprovalArgs(req.Arguments),
+		})
+		if err := channelRouter.SendToSession(ctx, req.Session, prompt); err != nil {
+			systemLogger.Warn("发送工具调用确认提示失败",
+				logging.String("session", req.Session.String()),
+				logging.Err(err))
+			return false
+		}
+		return true
+	})
+	channelRouter.SetCommandHandler(func(ctx context.Context, msg *entity.IncomingMessage) (string, bool) {
+		key := msg.SessionKey()
+		if !approvalMgr.HasPending(key) {
+			return "", false
+		}
+		approved, ok := approval.ParseReply(msg.Content)
+		if !ok {
+			return "", false
+		}
+		operator := key.SenderID
+		if msg.Sender != nil && msg.Sender.ID != "" {
+			operator = msg.Sender.ID
+		}
+		req, err := approvalMgr.ResolveForSession(key, "", approved, operator)
+		if err != nil {
+			return err.Error(), true
+		}
+		if approved {
+			return i18n.TWithData("approval.approved", map[string]interface{}{"Skill": req.Skill}), true
+		}
+		return i18n.TWithData("approval.denied", map[string]interface{}{"Skill": req.Skill}), true
+	})
+	realtimeChannel.SetOnCancel(channelRouter.CancelSession)
+	realtimeChannel.SetOnApproval(func(key entity.SessionKey, id string, approved bool, operator string) error {
+		_, err := approvalMgr.ResolveForSession(key, id, approved, operator)
+		return err
 	})
 
 	manager := channelRouter.Manager()
@@ -379,6 +496,20 @@ func Startup() (*App, error) {
 		systemLogger.Error("创建 Channels 失败", logging.Err(err))
 	}
 
+	if inProcessScheduler != nil {
+		// 任务消息经大脑处理后，回复发送到任务记录的 Channel 和会话
+		inProcessScheduler.SetDeliver(func(ctx context.Context, job *cron.Job) error {
+			msg := cronMessage(job, srvCfg.Cron)
+			if !manager.Exists(msg.ChannelID) {
+				return fmt.Errorf("target channel not found: %s", msg.ChannelID)
+			}
+			return channelRouter.Dispatch(ctx, msg)
+		})
+		if err := inProcessScheduler.Start(ctx); err != nil {
+			systemLogger.Error("启动定时任务调度器失败", logging.Err(err))
+		}
+	}
+
 	systemLogger.Info("创建 HTTP API 服务器")
 	staticDir := filepath.Join(installPath, "static")
 
@@ -404,7 +535,14 @@ func Startup() (*App, error) {
 	}
 	systemLogger.Info("HTTP API 服务器创建完成", logging.Int("port", srvCfg.Port))
 
-	handlers.RegisterRoutes(srv.GetEngine(), tokenUsageRepo, skillMgr, capMgr, sessionMgr, cronScheduler, assistant)
+	handlers.RegisterRoutes(srv.GetEngine(), tokenUsageRepo, skillMgr, capMgr, sessionMgr, cronScheduler, approvalMgr, mem, migrator, assistant)
+
+	// 以 MCP server 形式暴露技能（Streamable HTTP）
+	if srvCfg.MCPServe.Enabled {
+		mcpServer := skills.NewMCPServer(skillMgr, approvalMgr, systemLogger)
+		srv.GetEngine().Any(srvCfg.MCPServe.GetPath(), gin.WrapH(mcpServer.HTTPHandler()))
+		systemLogger.Info("技能 MCP server 已启用", logging.String("path", srvCfg.MCPServe.GetPath()))
+	}
 
 	a = &App{
 		Server:         srv,
@@ -416,6 +554,8 @@ func Startup() (*App, error) {
 		Capabilities:   capMgr,
 		CronScheduler:  cronScheduler,
 		TokenUsageRepo: tokenUsageRepo,
+		VectorStore:    store,
+		ModelRouter:    modelRouter,
 	}
 
 	if err := srv.Start(); err != nil {
@@ -445,6 +585,10 @@ func Shutdown() error {
 	logger := logging.GetSystemLogger().Named("app")
 	logger.Info(i18n.T("infra.shutting_down"))
 
+	if stopper, ok := a.CronScheduler.(interface{ Stop() }); ok {
+		stopper.Stop()
+	}
+
 	if a.ChannelRouter != nil {
 		logger.Info(i18n.T("infra.stop_channels"))
 		_ = a.ChannelRouter.Manager().StopAll()
@@ -457,11 +601,71 @@ func Shutdown() error {
 
 	logger.Info(i18n.T("infra.close_session_mgr"))
 
+	if a.ModelRouter != nil {
+		a.ModelRouter.Stop()
+	}
+
 	if a.TokenUsageRepo != nil {
 		logger.Info(i18n.T("infra.close_token_repo"))
 		_ = a.TokenUsageRepo.Close()
 	}
 
+	if a.VectorStore != nil {
+		logger.Info(i18n.T("infra.close_vector_store"))
+		_ = a.VectorStore.Close()
+	}
+
 	logger.Info(i18n.T("infra.shutdown_complete"))
 	return nil
 }
+
+// cronMessage 将定时任务转换为投递给网关的消息
+// 任务未记录目标时使用配置的默认 Channel 和会话；指定能力时以 /能力名 前缀交给大脑
+// 消息以创建任务的用户身份发出，使大脑检索该用户的记忆；未记录发送者时视为会话本身
+func cronMessage(job *cron.Job, cfg config.CronConfig) *entity.IncomingMessage {
+	channelID := job.Channel
+	sessionID := job.SessionID
+	senderID := job.SenderID
+	if channelID == "" {
+		channelID = cfg.GetChannel()
+		sessionID = cfg.SessionID
+		senderID = ""
+	}
+	if senderID == "" {
+		senderID = sessionID
+	}
+	if senderID == "" {
+		senderID = "cron"
+	}
+
+	content := job.Message
+	if job.Capability != "" {
+		content = "/" + job.Capability + " " + content
+	}
+
+	now := time.Now()
+	return &entity.IncomingMessage{
+		ChannelID:   channelID,
+		ChannelName: channelID,
+		SessionID:   sessionID,
+		MessageID:   fmt.Sprintf("cron-%s-%d", job.ID, now.UnixNano()),
+		Sender:      &entity.MessageSender{ID: senderID, Name: senderID},
+		Content:     content,
+		ContentType: "text",
+		Metadata:    map[string]interface{}{"cron_job_id": job.ID},
+		Timestamp:   now,
+		Source:      "cron",
+	}
+}
+
+// formatApprovalArgs 将工具参数格式化为确认提示中展示的文本
+func formatApprovalArgs(args map[string]any) string {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprintf("%v", args)
+	}
+	if runes := []rune(string(data)); len(runes) > 500 {
+		return string(runes[:500]) + "..."
+	}
+	return string(data)
+}
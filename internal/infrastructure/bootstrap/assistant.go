@@ -1,10 +1,12 @@
 package bootstrap
 
 import (
+	"context"
 	"fmt"
 	"mindx/internal/config"
 	"mindx/internal/core"
 	"mindx/internal/entity"
+	"mindx/internal/usecase/approval"
 	"mindx/internal/usecase/brain"
 	"mindx/internal/usecase/capability"
 	"mindx/internal/usecase/cron"
@@ -46,6 +48,7 @@ func NewAssistant(
 	logger logging.Logger,
 	tokenUsageRepo core.TokenUsageRepository,
 	cronScheduler cron.Scheduler,
+	approvalMgr *approval.Manager,
 ) *Assistant {
 
 	// 构建人设
@@ -57,13 +60,13 @@ func NewAssistant(
 	}
 
 	// 创建历史对话回调（使用新的 SessionMgr）
-	historyRequest := func(maxCount int) ([]*core.DialogueMessage, error) {
+	historyRequest := func(key entity.SessionKey, maxCount int) ([]*core.DialogueMessage, error) {
 		if sessionMgr == nil {
 			return []*core.DialogueMessage{}, nil
 		}
 
-		// 获取当前会话的所有消息（已由 SessionMgr 去重）
-		messages := sessionMgr.GetHistory()
+		// 获取发起请求的会话的所有消息（已由 SessionMgr 去重）
+		messages := sessionMgr.GetHistory(key)
 
 		// 转换消息格式
 		dialogueMessages := make([]*core.DialogueMessage, len(messages))
@@ -83,12 +86,12 @@ func NewAssistant(
 	}
 
 	// 创建工具请求回调
-	toolsRequest := func(keywords ...string) ([]*core.ToolSchema, error) {
+	toolsRequest := func(ctx context.Context, keywords ...string) ([]*core.ToolSchema, error) {
 		if skillMgr == nil {
 			return []*core.ToolSchema{}, nil
 		}
 
-		skillsList, err := skillMgr.SearchSkills(keywords...)
+		skillsList, err := skillMgr.SearchSkills(ctx, keywords...)
 		if err != nil {
 			logger.Warn(i18n.T("infra.search_skill_failed"), logging.Err(err))
 			return []*core.ToolSchema{}, nil
@@ -161,18 +164,32 @@ func NewAssistant(
 		return caps[0], nil
 	}
 
+	// 创建资源请求回调：从已连接 MCP server 的资源中检索参考内容
+	var resourceRequest core.OnResourceRequest
+	var promptRequest core.OnPromptRequest
+	if skillMgr != nil {
+		resourceRequest = func(question string) ([]*core.ResourceContent, error) {
+			return skillMgr.SearchMCPResources(question, 2)
+		}
+		// 创建快捷指令回调：MCP prompts 以 "/mcp_<server>_<prompt>" 形式使用
+		promptRequest = skillMgr.ExpandMCPPrompt
+	}
+
 	// 创建大脑（传入人设）
 	brain, err := brain.NewBrain(brain.BrainDeps{
-		Cfg:            cfg,
-		Persona:        persona,
-		Memory:         mem,
-		SkillMgr:       skillMgr,
-		ToolsRequest:   toolsRequest,
-		CapRequest:     capRequest,
-		HistoryRequest: historyRequest,
-		Logger:         logger,
-		TokenUsageRepo: tokenUsageRepo,
-		CronScheduler:  cronScheduler,
+		Cfg:             cfg,
+		Persona:         persona,
+		Memory:          mem,
+		SkillMgr:        skillMgr,
+		ToolsRequest:    toolsRequest,
+		CapRequest:      capRequest,
+		HistoryRequest:  historyRequest,
+		ResourceRequest: resourceRequest,
+		PromptRequest:   promptRequest,
+		Logger:          logger,
+		TokenUsageRepo:  tokenUsageRepo,
+		CronScheduler:   cronScheduler,
+		Approval:        approvalMgr,
 	})
 	if err != nil {
 		logger.Error(i18n.T("infra.create_brain_failed"), logging.Err(err))
@@ -290,16 +307,11 @@ func (a *Assistant) GetSessions() []interface{} {
 		return []interface{}{}
 	}
 
-	sess, ok := a.sessionMgr.(*session.SessionMgr).GetCurrentSession()
-	if !ok || sess == nil {
-		return []interface{}{}
-	}
-
 	// 从存储中加载所有会话
 	allSessions, err := a.sessionMgr.(*session.SessionMgr).GetAllSessions()
 	if err != nil {
 		a.logger.Warn(i18n.T("infra.load_sessions_failed"), logging.Err(err))
-		return []interface{}{*sess}
+		return []interface{}{}
 	}
 
 	sessions := make([]interface{}, len(allSessions))
@@ -309,39 +321,50 @@ func (a *Assistant) GetSessions() []interface{} {
 	return sessions
 }
 
-// Ask 问答
+// Ask 问答，ctx 取消时中止思考
 // Assistant 作为核心宿主，将问题转发给 Brain 处理
 // Brain 处理后的信息回调也是通过 Assistant 转发
-// 返回值: (answer, sendTo, error)
-// - answer: 回答内容
-// - sendTo: 目标 Channel（用于消息转发），为空表示不需要转发
-func (a *Assistant) Ask(question string, sessionID string, eventChan chan<- entity.ThinkingEvent) (string, string, error) {
-	a.logger.Info(i18n.T("infra.receive_question"), logging.String(i18n.T("infra.question"), question))
+// key 标识发起提问的会话，问答只会记录到该会话并使用该会话的历史
+// 返回大脑的回答：Answer 为回答内容，SendTo 为目标 Channel（用于消息转发，为空表示不需要转发），
+// Attachments 为本次回答中工具产出的附件（如 MCP 工具返回的图片）
+func (a *Assistant) Ask(ctx context.Context, question string, key entity.SessionKey, eventChan chan<- entity.ThinkingEvent) (*core.ThinkingResponse, error) {
+	a.logger.Info(i18n.T("infra.receive_question"),
+		logging.String(i18n.T("infra.question"), question),
+		logging.String("session_key", key.String()))
+
+	// 发送者由 Channel 网关记录在 ctx 中，用于区分群聊成员并限定可见的记忆
+	var senderID string
+	if sender := entity.SenderFromContext(ctx); sender != nil {
+		senderID = sender.ID
+	}
 
 	if a.sessionMgr != nil {
-		_ = a.sessionMgr.RecordMessage(entity.Message{
-			Role:    "user",
-			Content: question,
-			Time:    time.Now(),
+		_ = a.sessionMgr.RecordMessage(key, entity.Message{
+			Role:     "user",
+			Content:  question,
+			Time:     time.Now(),
+			SenderID: senderID,
 		})
 	}
 
 	req := &core.ThinkingRequest{
 		Question:  question,
 		Timeout:   30,
-		SessionID: sessionID,
+		ChannelID: key.ChannelID,
+		SessionID: key.SenderID,
+		SenderID:  senderID,
 		EventChan: eventChan,
 	}
 
-	resp, err := a.brain.Post(req)
+	resp, err := a.brain.Post(ctx, req)
 	if err != nil {
 		a.logger.Error(i18n.T("infra.brain_process_failed"), logging.Err(err))
-		return "", "", fmt.Errorf("大脑处理失败: %w", err)
+		return nil, fmt.Errorf("大脑处理失败: %w", err)
 	}
 
 	// 记录助手回复消息到新的 SessionMgr
 	if a.sessionMgr != nil {
-		_ = a.sessionMgr.RecordMessage(entity.Message{
+		_ = a.sessionMgr.RecordMessage(key, entity.Message{
 			Role:    "assistant",
 			Content: resp.Answer,
 			Time:    time.Now(),
@@ -359,7 +382,7 @@ func (a *Assistant) Ask(question string, sessionID string, eventChan chan<- enti
 		)
 	}
 
-	return resp.Answer, resp.SendTo, nil
+	return resp, nil
 }
 
 // Summarize 记忆点重整
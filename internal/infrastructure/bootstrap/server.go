@@ -161,8 +161,8 @@ func (s *Server) setupStaticFiles() error {
 	// SPA fallback: 非 API/WS 路径返回 index.html
 	s.engine.NoRoute(func(c *gin.Context) {
 		path := c.Request.URL.Path
-		// 跳过 API 和 WebSocket 路径
-		if len(path) >= 4 && path[:4] == "/api" || len(path) >= 3 && path[:3] == "/ws" {
+		// 跳过 API、OpenAI 兼容接口和 WebSocket 路径
+		if len(path) >= 4 && path[:4] == "/api" || len(path) >= 3 && path[:3] == "/ws" || len(path) >= 3 && path[:3] == "/v1" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 			return
 		}
@@ -36,8 +36,11 @@ func NewFileJobStore() (*FileJobStore, error) {
 		}
 	}
 
-	filePath := filepath.Join(dataDir, "cron_jobs.json")
+	return NewFileJobStoreAt(filepath.Join(dataDir, "cron_jobs.json"))
+}
 
+// NewFileJobStoreAt 使用指定文件创建任务存储
+func NewFileJobStoreAt(filePath string) (*FileJobStore, error) {
 	store := &FileJobStore{
 		filePath: filePath,
 		data: &JobStoreData{
@@ -63,7 +66,9 @@ func (s *FileJobStore) Add(job *cron.Job) (string, error) {
 	job.Enabled = true
 	job.LastStatus = cron.JobStatusPending
 
-	s.data.Jobs[job.ID] = job
+	cp := *job
+	cp.NextRun = nil
+	s.data.Jobs[job.ID] = &cp
 	return job.ID, s.save()
 }
 
@@ -75,7 +80,8 @@ func (s *FileJobStore) Get(id string) (*cron.Job, error) {
 	if !exists {
 		return nil, fmt.Errorf("job not found: %s", id)
 	}
-	return job, nil
+	cp := *job
+	return &cp, nil
 }
 
 func (s *FileJobStore) List() ([]*cron.Job, error) {
@@ -84,7 +90,8 @@ func (s *FileJobStore) List() ([]*cron.Job, error) {
 
 	jobs := make([]*cron.Job, 0, len(s.data.Jobs))
 	for _, job := range s.data.Jobs {
-		jobs = append(jobs, job)
+		cp := *job
+		jobs = append(jobs, &cp)
 	}
 	return jobs, nil
 }
@@ -107,6 +114,27 @@ func (s *FileJobStore) Update(id string, job *cron.Job) error {
 	if job.Message != "" {
 		existing.Message = job.Message
 	}
+	if job.Timezone != "" {
+		existing.Timezone = job.Timezone
+	}
+	if job.CatchUp != "" {
+		existing.CatchUp = job.CatchUp
+	}
+	// 投递目标和执行身份一起更新：改投到其他会话后不再以原创建者的身份和记忆执行
+	if job.Channel != "" || job.SessionID != "" {
+		if job.Channel != "" {
+			existing.Channel = job.Channel
+		}
+		if job.SessionID != "" {
+			existing.SessionID = job.SessionID
+		}
+		existing.SenderID = job.SenderID
+	} else if job.SenderID != "" {
+		existing.SenderID = job.SenderID
+	}
+	if job.Capability != "" {
+		existing.Capability = job.Capability
+	}
 
 	return s.save()
 }
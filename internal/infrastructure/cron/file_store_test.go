@@ -0,0 +1,39 @@
+package cron
+
+import (
+	"mindx/internal/usecase/cron"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileJobStore_UpdateSender(t *testing.T) {
+	store, err := NewFileJobStoreAt(filepath.Join(t.TempDir(), "cron_jobs.json"))
+	require.NoError(t, err)
+
+	id, err := store.Add(&cron.Job{Name: "早报", Cron: "0 8 * * *", Message: "发送早报", Channel: "telegram", SessionID: "alice", SenderID: "alice"})
+	require.NoError(t, err)
+
+	// 只修改时间时保留投递目标和执行身份
+	require.NoError(t, store.Update(id, &cron.Job{Cron: "0 9 * * *"}))
+	job, err := store.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", job.SenderID)
+
+	// 改投到其他会话时执行身份随之更新
+	require.NoError(t, store.Update(id, &cron.Job{Channel: "feishu", SessionID: "group1", SenderID: "bob"}))
+	job, err = store.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, "feishu", job.Channel)
+	assert.Equal(t, "group1", job.SessionID)
+	assert.Equal(t, "bob", job.SenderID)
+
+	// 未指定发送者时不再沿用原创建者
+	require.NoError(t, store.Update(id, &cron.Job{SessionID: "group2"}))
+	job, err = store.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, "group2", job.SessionID)
+	assert.Empty(t, job.SenderID)
+}
@@ -0,0 +1,138 @@
+package cron
+
+import (
+	"bufio"
+	"encoding/json"
+	"mindx/internal/usecase/cron"
+	"os"
+	"sync"
+)
+
+// HistoryStore 任务执行历史，按 JSONL 追加写入文件
+// 超过 limit 条时丢弃最早的记录
+type HistoryStore struct {
+	filePath string
+	limit    int
+	mu       sync.Mutex
+	entries  []*cron.Execution
+}
+
+// NewHistoryStore 创建执行历史存储
+func NewHistoryStore(filePath string, limit int) (*HistoryStore, error) {
+	h := &HistoryStore{filePath: filePath, limit: limit}
+	if err := h.load(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Append 记录一次执行
+func (h *HistoryStore) Append(exec *cron.Execution) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, exec)
+	if h.limit > 0 && len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+		return h.rewrite()
+	}
+
+	f, err := os.OpenFile(h.filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(exec)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// List 返回任务最近的执行记录，新的在前；jobID 为空时返回所有任务
+func (h *HistoryStore) List(jobID string, limit int) []*cron.Execution {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result := make([]*cron.Execution, 0)
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if jobID != "" && h.entries[i].JobID != jobID {
+			continue
+		}
+		cp := *h.entries[i]
+		result = append(result, &cp)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+// DeleteJob 删除任务的全部执行记录
+func (h *HistoryStore) DeleteJob(jobID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	kept := h.entries[:0]
+	for _, e := range h.entries {
+		if e.JobID != jobID {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(h.entries) {
+		return nil
+	}
+	h.entries = kept
+	return h.rewrite()
+}
+
+func (h *HistoryStore) load() error {
+	f, err := os.Open(h.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e cron.Execution
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		h.entries = append(h.entries, &e)
+	}
+	if h.limit > 0 && len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+	}
+	return scanner.Err()
+}
+
+func (h *HistoryStore) rewrite() error {
+	tmp := h.filePath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range h.entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.filePath)
+}
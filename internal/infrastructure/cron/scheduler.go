@@ -0,0 +1,523 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"mindx/internal/config"
+	"mindx/internal/usecase/cron"
+	"mindx/pkg/logging"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxCatchUpRuns 单个任务启动时最多补跑的次数，避免长时间停机后集中触发
+const maxCatchUpRuns = 100
+
+// DeliverFunc 将到点任务的消息投递到目标 Channel
+type DeliverFunc func(ctx context.Context, job *cron.Job) error
+
+// InProcessScheduler 在内核进程内运行的调度器
+// 不依赖系统 crontab，任务到点后直接调用 DeliverFunc 投递消息，并记录执行历史
+type InProcessScheduler struct {
+	store   cron.JobStore
+	history *HistoryStore
+	cfg     config.CronConfig
+	loc     *time.Location
+	logger  logging.Logger
+
+	mu      sync.Mutex
+	deliver DeliverFunc
+	entries map[string]*scheduleEntry
+	running map[string]bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	// 便于测试替换
+	now  func() time.Time
+	tick time.Duration
+}
+
+type scheduleEntry struct {
+	schedule *Schedule
+	loc      *time.Location
+	next     time.Time
+}
+
+// NewInProcessScheduler 使用工作区 data 目录下的任务文件和执行历史创建调度器
+func NewInProcessScheduler(cfg config.CronConfig, logger logging.Logger) (*InProcessScheduler, error) {
+	store, err := NewFileJobStore()
+	if err != nil {
+		return nil, err
+	}
+	historyPath := filepath.Join(filepath.Dir(store.filePath), "cron_history.jsonl")
+	history, err := NewHistoryStore(historyPath, cfg.GetHistoryLimit())
+	if err != nil {
+		return nil, fmt.Errorf("加载定时任务执行历史失败: %w", err)
+	}
+	return NewInProcessSchedulerWithStore(store, history, cfg, logger)
+}
+
+// NewInProcessSchedulerWithStore 使用指定的任务存储和执行历史创建调度器，history 可为 nil
+func NewInProcessSchedulerWithStore(store cron.JobStore, history *HistoryStore, cfg config.CronConfig, logger logging.Logger) (*InProcessScheduler, error) {
+	loc := time.Local
+	if cfg.Timezone != "" {
+		l, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区 %q: %w", cfg.Timezone, err)
+		}
+		loc = l
+	}
+	if err := validateCatchUp(cron.CatchUpPolicy(cfg.CatchUp)); err != nil {
+		return nil, err
+	}
+
+	return &InProcessScheduler{
+		store:   store,
+		history: history,
+		cfg:     cfg,
+		loc:     loc,
+		logger:  logger,
+		entries: make(map[string]*scheduleEntry),
+		running: make(map[string]bool),
+		now:     time.Now,
+		tick:    time.Second,
+	}, nil
+}
+
+// SetDeliver 设置任务消息的投递方式，需在 Start 之前调用
+func (s *InProcessScheduler) SetDeliver(fn DeliverFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliver = fn
+}
+
+// Start 加载已有任务，按补跑策略处理停机期间错过的执行，并开始调度
+func (s *InProcessScheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("scheduler already started")
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.mu.Unlock()
+
+	jobs, err := s.store.List()
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	for _, job := range jobs {
+		if !job.Enabled {
+			continue
+		}
+		entry, err := s.refresh(job, now)
+		if err != nil {
+			s.logger.Warn("定时任务表达式无效，已跳过",
+				logging.String("job_id", job.ID),
+				logging.String("cron", job.Cron),
+				logging.Err(err))
+			continue
+		}
+		s.catchUp(job, entry, now)
+	}
+
+	s.wg.Add(1)
+	go s.loop()
+
+	s.logger.Info("进程内定时任务调度器已启动",
+		logging.Int("jobs", len(jobs)),
+		logging.String("timezone", s.loc.String()))
+	return nil
+}
+
+// Stop 停止调度并等待正在执行的任务结束
+func (s *InProcessScheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+}
+
+func (s *InProcessScheduler) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.runDue(s.now())
+		}
+	}
+}
+
+// runDue 触发所有已到点的任务，并计算下次执行时间
+func (s *InProcessScheduler) runDue(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, entry := range s.entries {
+		if entry.next.IsZero() || entry.next.After(now) {
+			continue
+		}
+		scheduledAt := entry.next
+		entry.next = entry.schedule.Next(now, entry.loc)
+
+		if s.running[id] {
+			s.logger.Warn("定时任务上次执行尚未结束，跳过本次",
+				logging.String("job_id", id),
+				logging.String("scheduled_at", scheduledAt.Format(time.RFC3339)))
+			continue
+		}
+		job, err := s.store.Get(id)
+		if err != nil || !job.Enabled {
+			delete(s.entries, id)
+			continue
+		}
+
+		s.running[id] = true
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.release(id)
+			_ = s.execute(job, scheduledAt, false, false)
+		}()
+	}
+}
+
+// catchUp 按补跑策略执行停机期间错过的时间点
+func (s *InProcessScheduler) catchUp(job *cron.Job, entry *scheduleEntry, now time.Time) {
+	policy := job.CatchUp
+	if policy == "" {
+		policy = cron.CatchUpPolicy(s.cfg.CatchUp)
+	}
+	if policy == "" {
+		policy = cron.CatchUpOnce
+	}
+	if policy == cron.CatchUpSkip {
+		return
+	}
+
+	ref := job.CreatedAt
+	if job.LastRun != nil {
+		ref = *job.LastRun
+	}
+
+	var missed []time.Time
+	for t := entry.schedule.Next(ref, entry.loc); !t.IsZero() && !t.After(now); t = entry.schedule.Next(t, entry.loc) {
+		missed = append(missed, t)
+		if len(missed) >= maxCatchUpRuns {
+			break
+		}
+	}
+	if len(missed) == 0 {
+		return
+	}
+	if policy == cron.CatchUpOnce {
+		missed = missed[len(missed)-1:]
+	}
+
+	s.logger.Info("补跑错过的定时任务",
+		logging.String("job_id", job.ID),
+		logging.String("policy", string(policy)),
+		logging.Int("runs", len(missed)))
+
+	s.mu.Lock()
+	s.running[job.ID] = true
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.release(job.ID)
+		for _, at := range missed {
+			if s.ctx.Err() != nil {
+				return
+			}
+			_ = s.execute(job, at, true, false)
+		}
+	}()
+}
+
+// release 清除任务的运行标记
+func (s *InProcessScheduler) release(id string) {
+	s.mu.Lock()
+	delete(s.running, id)
+	s.mu.Unlock()
+}
+
+// execute 投递任务消息并记录结果，调用方负责设置和清除运行标记
+func (s *InProcessScheduler) execute(job *cron.Job, scheduledAt time.Time, catchUp, manual bool) error {
+	s.mu.Lock()
+	deliver := s.deliver
+	ctx := s.ctx
+	s.mu.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_ = s.store.UpdateLastRun(job.ID, cron.JobStatusRunning, nil)
+
+	startedAt := s.now()
+	var err error
+	if deliver == nil {
+		err = fmt.Errorf("no delivery target configured")
+	} else {
+		err = deliver(ctx, job)
+	}
+	finishedAt := s.now()
+
+	status := cron.JobStatusSuccess
+	var errMsg *string
+	if err != nil {
+		status = cron.JobStatusError
+		msg := err.Error()
+		errMsg = &msg
+		s.logger.Error("定时任务执行失败",
+			logging.String("job_id", job.ID),
+			logging.String("name", job.Name),
+			logging.Err(err))
+	} else {
+		s.logger.Info("定时任务执行完成",
+			logging.String("job_id", job.ID),
+			logging.String("name", job.Name),
+			logging.Bool("catch_up", catchUp))
+	}
+
+	if updateErr := s.store.UpdateLastRun(job.ID, status, errMsg); updateErr != nil {
+		s.logger.Warn("更新定时任务状态失败", logging.String("job_id", job.ID), logging.Err(updateErr))
+	}
+
+	if s.history != nil {
+		exec := &cron.Execution{
+			JobID:       job.ID,
+			JobName:     job.Name,
+			ScheduledAt: scheduledAt,
+			StartedAt:   startedAt,
+			FinishedAt:  finishedAt,
+			Status:      status,
+			CatchUp:     catchUp,
+			Manual:      manual,
+		}
+		if errMsg != nil {
+			exec.Error = *errMsg
+		}
+		if histErr := s.history.Append(exec); histErr != nil {
+			s.logger.Warn("记录定时任务执行历史失败", logging.String("job_id", job.ID), logging.Err(histErr))
+		}
+	}
+
+	return err
+}
+
+// refresh 解析任务表达式并计算下次执行时间
+func (s *InProcessScheduler) refresh(job *cron.Job, now time.Time) (*scheduleEntry, error) {
+	schedule, loc, err := s.parse(job)
+	if err != nil {
+		return nil, err
+	}
+	entry := &scheduleEntry{
+		schedule: schedule,
+		loc:      loc,
+		next:     schedule.Next(now, loc),
+	}
+
+	s.mu.Lock()
+	s.entries[job.ID] = entry
+	s.mu.Unlock()
+	return entry, nil
+}
+
+func (s *InProcessScheduler) unschedule(id string) {
+	s.mu.Lock()
+	delete(s.entries, id)
+	s.mu.Unlock()
+}
+
+func (s *InProcessScheduler) parse(job *cron.Job) (*Schedule, *time.Location, error) {
+	schedule, err := ParseExpr(job.Cron)
+	if err != nil {
+		return nil, nil, err
+	}
+	loc := s.loc
+	if job.Timezone != "" {
+		loc, err = time.LoadLocation(job.Timezone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid timezone %q: %w", job.Timezone, err)
+		}
+	}
+	return schedule, loc, nil
+}
+
+func validateCatchUp(policy cron.CatchUpPolicy) error {
+	switch policy {
+	case "", cron.CatchUpSkip, cron.CatchUpOnce, cron.CatchUpAll:
+		return nil
+	default:
+		return fmt.Errorf("invalid catch_up policy: %s", policy)
+	}
+}
+
+func (s *InProcessScheduler) validate(job *cron.Job) error {
+	if _, _, err := s.parse(job); err != nil {
+		return err
+	}
+	return validateCatchUp(job.CatchUp)
+}
+
+func (s *InProcessScheduler) Add(job *cron.Job) (string, error) {
+	if job.Message == "" {
+		return "", fmt.Errorf("message is required")
+	}
+	if err := s.validate(job); err != nil {
+		return "", err
+	}
+
+	id, err := s.store.Add(job)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.refresh(job, s.now()); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *InProcessScheduler) Delete(id string) error {
+	if err := s.store.Delete(id); err != nil {
+		return err
+	}
+	s.unschedule(id)
+	if s.history != nil {
+		return s.history.DeleteJob(id)
+	}
+	return nil
+}
+
+func (s *InProcessScheduler) List() ([]*cron.Job, error) {
+	jobs, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		s.fillNextRun(job)
+	}
+	return jobs, nil
+}
+
+func (s *InProcessScheduler) Get(id string) (*cron.Job, error) {
+	job, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	s.fillNextRun(job)
+	return job, nil
+}
+
+func (s *InProcessScheduler) fillNextRun(job *cron.Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[job.ID]; ok && !entry.next.IsZero() {
+		next := entry.next
+		job.NextRun = &next
+	}
+}
+
+func (s *InProcessScheduler) Pause(id string) error {
+	if err := s.store.Pause(id); err != nil {
+		return err
+	}
+	s.unschedule(id)
+	return nil
+}
+
+func (s *InProcessScheduler) Resume(id string) error {
+	if err := s.store.Resume(id); err != nil {
+		return err
+	}
+	job, err := s.store.Get(id)
+	if err != nil {
+		return err
+	}
+	_, err = s.refresh(job, s.now())
+	return err
+}
+
+func (s *InProcessScheduler) Update(id string, job *cron.Job) error {
+	existing, err := s.store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	merged := *existing
+	if job.Cron != "" {
+		merged.Cron = job.Cron
+	}
+	if job.Timezone != "" {
+		merged.Timezone = job.Timezone
+	}
+	if job.CatchUp != "" {
+		merged.CatchUp = job.CatchUp
+	}
+	if err := s.validate(&merged); err != nil {
+		return err
+	}
+
+	if err := s.store.Update(id, job); err != nil {
+		return err
+	}
+	updated, err := s.store.Get(id)
+	if err != nil {
+		return err
+	}
+	if updated.Enabled {
+		_, err = s.refresh(updated, s.now())
+	}
+	return err
+}
+
+// RunJob 立即执行一次任务，同步等待投递完成
+func (s *InProcessScheduler) RunJob(id string) error {
+	job, err := s.store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.running[id] {
+		s.mu.Unlock()
+		return fmt.Errorf("job is already running: %s", id)
+	}
+	s.running[id] = true
+	s.mu.Unlock()
+	defer s.release(id)
+
+	return s.execute(job, s.now(), false, true)
+}
+
+func (s *InProcessScheduler) UpdateLastRun(id string, status cron.JobStatus, errMsg *string) error {
+	return s.store.UpdateLastRun(id, status, errMsg)
+}
+
+// History 返回任务最近的执行记录，新的在前
+func (s *InProcessScheduler) History(id string, limit int) ([]*cron.Execution, error) {
+	if s.history == nil {
+		return []*cron.Execution{}, nil
+	}
+	if id != "" {
+		if _, err := s.store.Get(id); err != nil {
+			return nil, err
+		}
+	}
+	return s.history.List(id, limit), nil
+}
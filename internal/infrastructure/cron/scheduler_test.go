@@ -0,0 +1,205 @@
+package cron
+
+import (
+	"context"
+	"mindx/internal/config"
+	"mindx/internal/usecase/cron"
+	"mindx/pkg/logging"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpr_Next(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	base := time.Date(2025, 3, 14, 10, 30, 15, 0, shanghai) // 周五
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/5 * * * *", time.Date(2025, 3, 14, 10, 35, 0, 0, shanghai)},
+		{"0 9 * * *", time.Date(2025, 3, 15, 9, 0, 0, 0, shanghai)},
+		{"*/10 * * * * *", time.Date(2025, 3, 14, 10, 30, 20, 0, shanghai)},
+		{"30 0 9 * * mon-fri", time.Date(2025, 3, 17, 9, 0, 30, 0, shanghai)},
+		{"0 0 1 jan *", time.Date(2026, 1, 1, 0, 0, 0, 0, shanghai)},
+		{"0 12 13 * 5", time.Date(2025, 3, 14, 12, 0, 0, 0, shanghai)}, // 日与周任一满足
+		{"0 0 * * 7", time.Date(2025, 3, 16, 0, 0, 0, 0, shanghai)},
+		{"@hourly", time.Date(2025, 3, 14, 11, 0, 0, 0, shanghai)},
+		{"15/20 * * * *", time.Date(2025, 3, 14, 10, 35, 0, 0, shanghai)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseExpr(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(base, shanghai))
+		})
+	}
+}
+
+func TestParseExpr_Timezone(t *testing.T) {
+	s, err := ParseExpr("CRON_TZ=Asia/Tokyo 0 9 * * *")
+	require.NoError(t, err)
+
+	base := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	next := s.Next(base, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC).Add(24*time.Hour), next.UTC(), "东京 9 点即 UTC 0 点")
+}
+
+func TestParseExpr_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * *", "60 * * * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "@every", "TZ=Nowhere/City * * * * *"} {
+		_, err := ParseExpr(spec)
+		assert.Error(t, err, spec)
+	}
+
+	s, err := ParseExpr("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now(), time.UTC).IsZero(), "2 月 30 日永远不会到来")
+}
+
+type deliveryRecorder struct {
+	mu   sync.Mutex
+	jobs []string
+}
+
+func (r *deliveryRecorder) deliver(_ context.Context, job *cron.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs = append(r.jobs, job.Message)
+	return nil
+}
+
+func (r *deliveryRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.jobs)
+}
+
+func newTestScheduler(t *testing.T, cfg config.CronConfig) (*InProcessScheduler, *FileJobStore) {
+	dir := t.TempDir()
+	store, err := NewFileJobStoreAt(filepath.Join(dir, "cron_jobs.json"))
+	require.NoError(t, err)
+	history, err := NewHistoryStore(filepath.Join(dir, "cron_history.jsonl"), 100)
+	require.NoError(t, err)
+
+	s, err := NewInProcessSchedulerWithStore(store, history, cfg, logging.GetSystemLogger().Named("cron_test"))
+	require.NoError(t, err)
+	s.tick = 20 * time.Millisecond
+	return s, store
+}
+
+func TestInProcessScheduler_DeliversDueJobs(t *testing.T) {
+	s, _ := newTestScheduler(t, config.CronConfig{})
+	rec := &deliveryRecorder{}
+	s.SetDeliver(rec.deliver)
+
+	id, err := s.Add(&cron.Job{Name: "tick", Cron: "* * * * * *", Message: "ping"})
+	require.NoError(t, err)
+	require.NoError(t, s.Start(context.Background()))
+	defer s.Stop()
+
+	assert.Eventually(t, func() bool { return rec.count() >= 1 }, 3*time.Second, 20*time.Millisecond)
+
+	job, err := s.Get(id)
+	require.NoError(t, err)
+	assert.NotNil(t, job.NextRun)
+
+	history, err := s.History(id, 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, history)
+	assert.Equal(t, cron.JobStatusSuccess, history[0].Status)
+
+	require.NoError(t, s.Pause(id))
+	paused := rec.count()
+	time.Sleep(1500 * time.Millisecond)
+	assert.LessOrEqual(t, rec.count(), paused+1, "暂停后不再触发（最多一次已在执行中）")
+}
+
+func TestInProcessScheduler_CatchUp(t *testing.T) {
+	tests := []struct {
+		policy cron.CatchUpPolicy
+		want   int
+	}{
+		{cron.CatchUpSkip, 0},
+		{cron.CatchUpOnce, 1},
+		{cron.CatchUpAll, 3},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			s, store := newTestScheduler(t, config.CronConfig{})
+			rec := &deliveryRecorder{}
+			s.SetDeliver(rec.deliver)
+
+			// 上次执行在 3 个整点之前，模拟停机期间错过了 3 次
+			now := time.Now()
+			last := now.Truncate(time.Hour).Add(-3*time.Hour + time.Minute)
+			id, err := store.Add(&cron.Job{Cron: "0 * * * *", Message: "hourly", CatchUp: tt.policy})
+			require.NoError(t, err)
+			require.NoError(t, store.UpdateLastRun(id, cron.JobStatusSuccess, nil))
+			store.data.Jobs[id].LastRun = &last
+
+			require.NoError(t, s.Start(context.Background()))
+			assert.Eventually(t, func() bool { return rec.count() == tt.want }, 2*time.Second, 10*time.Millisecond)
+			s.Stop()
+
+			assert.Equal(t, tt.want, rec.count())
+			history, err := s.History(id, 0)
+			require.NoError(t, err)
+			assert.Len(t, history, tt.want)
+			for _, e := range history {
+				assert.True(t, e.CatchUp)
+			}
+		})
+	}
+}
+
+func TestInProcessScheduler_Validation(t *testing.T) {
+	s, _ := newTestScheduler(t, config.CronConfig{})
+
+	_, err := s.Add(&cron.Job{Cron: "* * *", Message: "x"})
+	assert.Error(t, err)
+	_, err = s.Add(&cron.Job{Cron: "0 9 * * *", Message: "x", Timezone: "Mars/Base"})
+	assert.Error(t, err)
+	_, err = s.Add(&cron.Job{Cron: "0 9 * * *", Message: "x", CatchUp: "sometimes"})
+	assert.Error(t, err)
+	_, err = s.Add(&cron.Job{Cron: "0 9 * * *"})
+	assert.Error(t, err)
+
+	id, err := s.Add(&cron.Job{Cron: "0 9 * * *", Message: "x", Timezone: "Asia/Shanghai"})
+	require.NoError(t, err)
+	assert.Error(t, s.Update(id, &cron.Job{Cron: "bad"}))
+
+	_, err = NewInProcessSchedulerWithStore(nil, nil, config.CronConfig{Timezone: "Mars/Base"}, logging.GetSystemLogger())
+	assert.Error(t, err)
+}
+
+func TestInProcessScheduler_RunJob(t *testing.T) {
+	s, store := newTestScheduler(t, config.CronConfig{})
+	rec := &deliveryRecorder{}
+	s.SetDeliver(rec.deliver)
+
+	id, err := s.Add(&cron.Job{Name: "manual", Cron: "0 0 1 1 *", Message: "now"})
+	require.NoError(t, err)
+	require.NoError(t, s.RunJob(id))
+	assert.Equal(t, 1, rec.count())
+
+	job, err := store.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, cron.JobStatusSuccess, job.LastStatus)
+	assert.NotNil(t, job.LastRun)
+
+	history, err := s.History(id, 1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.True(t, history[0].Manual)
+
+	require.NoError(t, s.Delete(id))
+	history, err = s.History("", 0)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
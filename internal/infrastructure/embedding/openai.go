@@ -0,0 +1,163 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"mindx/pkg/retry"
+	"net/http"
+	"sort"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// defaultBatchSize 单次请求的最大文本数，多数 OpenAI 兼容服务的上限不低于该值
+const defaultBatchSize = 32
+
+// OpenAIEmbedding OpenAI 兼容 /v1/embeddings 接口的 embedding 提供者
+// 适用于 OpenAI、vLLM、text-embeddings-inference、DashScope 等服务
+type OpenAIEmbedding struct {
+	client     *openai.Client
+	model      string
+	dimensions int
+	batchSize  int
+	timeout    time.Duration
+	retryCfg   retry.Config
+}
+
+// NewOpenAIEmbedding 创建 OpenAI 兼容的 embedding 提供者
+// dimensions 大于 0 时将向量截断到该维度并重新归一化（适用于 Matryoshka 训练的模型），
+// 服务端无需支持 dimensions 参数
+func NewOpenAIEmbedding(baseURL, apiKey, model string, dimensions int) (*OpenAIEmbedding, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("base_url is required")
+	}
+	if model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+
+	cfg := openai.DefaultConfig(apiKey)
+	cfg.BaseURL = baseURL
+
+	retryCfg := retry.DefaultConfig()
+	retryCfg.Retryable = isRetryable
+
+	return &OpenAIEmbedding{
+		client:     openai.NewClientWithConfig(cfg),
+		model:      model,
+		dimensions: dimensions,
+		batchSize:  defaultBatchSize,
+		timeout:    60 * time.Second,
+		retryCfg:   retryCfg,
+	}, nil
+}
+
+// GenerateEmbedding 生成单个文本的embedding
+func (o *OpenAIEmbedding) GenerateEmbedding(text string) ([]float64, error) {
+	if text == "" {
+		return nil, fmt.Errorf("文本不能为空")
+	}
+	embeddings, err := o.embed([]string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// GenerateBatchEmbeddings 批量生成embedding，每 batchSize 个文本发送一次请求
+// 返回的向量与输入一一对应，任一批次失败时返回错误
+func (o *OpenAIEmbedding) GenerateBatchEmbeddings(texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("texts cannot be empty")
+	}
+
+	embeddings := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += o.batchSize {
+		end := start + o.batchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+		batch, err := o.embed(texts[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed texts %d-%d: %w", start, end-1, err)
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
+
+func (o *OpenAIEmbedding) embed(texts []string) ([][]float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+
+	resp, err := retry.DoWithResult(ctx, o.retryCfg, func() (openai.EmbeddingResponse, error) {
+		return o.client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+			Input: texts,
+			Model: openai.EmbeddingModel(o.model),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Data))
+	}
+
+	// 部分服务不保证返回顺序，按 index 还原
+	sort.Slice(resp.Data, func(i, j int) bool {
+		return resp.Data[i].Index < resp.Data[j].Index
+	})
+
+	embeddings := make([][]float64, len(resp.Data))
+	for i, data := range resp.Data {
+		if len(data.Embedding) == 0 {
+			return nil, fmt.Errorf("empty embedding returned")
+		}
+		embeddings[i] = o.truncate(data.Embedding)
+	}
+	return embeddings, nil
+}
+
+// truncate 转换为 float64，需要时截断到配置的维度并重新归一化
+func (o *OpenAIEmbedding) truncate(vec []float32) []float64 {
+	n := len(vec)
+	if o.dimensions > 0 && o.dimensions < n {
+		n = o.dimensions
+	}
+
+	result := make([]float64, n)
+	for i := range result {
+		result[i] = float64(vec[i])
+	}
+	if n == len(vec) {
+		return result
+	}
+
+	var norm float64
+	for _, v := range result {
+		norm += v * v
+	}
+	if norm == 0 {
+		return result
+	}
+	norm = math.Sqrt(norm)
+	for i := range result {
+		result[i] /= norm
+	}
+	return result
+}
+
+// isRetryable 限流（429）和服务端错误重试，其余按 retry.DefaultRetryable 判断
+func isRetryable(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests || apiErr.HTTPStatusCode >= 500
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests || reqErr.HTTPStatusCode >= 500
+	}
+	return retry.DefaultRetryable(err)
+}
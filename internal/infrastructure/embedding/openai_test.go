@@ -0,0 +1,112 @@
+package embedding
+
+import (
+	"encoding/json"
+	"math"
+	"mindx/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEmbeddingServer 模拟 /v1/embeddings：第 i 个输入的向量为 [i+1, len(text), 1, 1]，倒序返回
+func newEmbeddingServer(t *testing.T, requests *int32, rateLimited int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		n := atomic.AddInt32(requests, 1)
+		if n <= rateLimited {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"rate limited","type":"rate_limit"}}`))
+			return
+		}
+
+		var req struct {
+			Input []string `json:"input"`
+			Model string   `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "bge-m3", req.Model)
+
+		resp := openai.EmbeddingResponse{Object: "list"}
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			resp.Data = append(resp.Data, openai.Embedding{
+				Object:    "embedding",
+				Index:     i,
+				Embedding: []float32{float32(i + 1), float32(len(req.Input[i])), 1, 1},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestOpenAIEmbedding_Batch(t *testing.T) {
+	var requests int32
+	ts := newEmbeddingServer(t, &requests, 0)
+	defer ts.Close()
+
+	provider, err := NewOpenAIEmbedding(ts.URL+"/v1", "", "bge-m3", 0)
+	require.NoError(t, err)
+	provider.batchSize = 2
+
+	vecs, err := provider.GenerateBatchEmbeddings([]string{"a", "bb", "ccc"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests, "3 个文本按每批 2 个发送")
+	assert.Equal(t, [][]float64{{1, 1, 1, 1}, {2, 2, 1, 1}, {1, 3, 1, 1}}, vecs)
+
+	vec, err := provider.GenerateEmbedding("hello")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 5, 1, 1}, vec)
+
+	_, err = provider.GenerateEmbedding("")
+	assert.Error(t, err)
+}
+
+func TestOpenAIEmbedding_Truncate(t *testing.T) {
+	var requests int32
+	ts := newEmbeddingServer(t, &requests, 0)
+	defer ts.Close()
+
+	provider, err := NewOpenAIEmbedding(ts.URL+"/v1", "", "bge-m3", 2)
+	require.NoError(t, err)
+
+	vec, err := provider.GenerateEmbedding("abc")
+	require.NoError(t, err)
+	require.Len(t, vec, 2)
+	assert.InDelta(t, 1/math.Sqrt(10), vec[0], 1e-6)
+	assert.InDelta(t, 3/math.Sqrt(10), vec[1], 1e-6)
+}
+
+func TestOpenAIEmbedding_RateLimitRetry(t *testing.T) {
+	var requests int32
+	ts := newEmbeddingServer(t, &requests, 1)
+	defer ts.Close()
+
+	provider, err := NewOpenAIEmbedding(ts.URL+"/v1", "", "bge-m3", 0)
+	require.NoError(t, err)
+	provider.retryCfg.InitialWait = 0
+
+	vec, err := provider.GenerateEmbedding("a")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 1, 1, 1}, vec)
+	assert.Equal(t, int32(2), requests, "限流后重试一次")
+}
+
+func TestNewProvider(t *testing.T) {
+	p, err := NewProvider("nomic-embed-text", nil)
+	require.NoError(t, err)
+	assert.IsType(t, &OllamaEmbedding{}, p)
+
+	p, err = NewProvider("bge-m3", &config.ModelConfig{Provider: config.ProviderOpenAI, BaseURL: "http://localhost:8000/v1"})
+	require.NoError(t, err)
+	assert.IsType(t, &OpenAIEmbedding{}, p)
+
+	_, err = NewProvider("bge-m3", &config.ModelConfig{Provider: "unknown"})
+	assert.Error(t, err)
+}
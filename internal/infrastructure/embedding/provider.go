@@ -0,0 +1,32 @@
+package embedding
+
+import (
+	"fmt"
+	"mindx/internal/config"
+	"mindx/internal/core"
+)
+
+// defaultOllamaURL 嵌入模型未在 models.yml 中配置地址时使用的 Ollama 服务
+const defaultOllamaURL = "http://localhost:11434"
+
+// NewProvider 按 models.yml 中同名模型的配置创建 embedding 提供者
+// provider 为 openai 时使用 OpenAI 兼容的 /v1/embeddings 接口，否则使用 Ollama；
+// cfg 为 nil（模型未在 models.yml 中配置）时使用本地 Ollama
+func NewProvider(model string, cfg *config.ModelConfig) (core.EmbeddingProvider, error) {
+	if cfg == nil {
+		return NewOllamaEmbedding(defaultOllamaURL, model)
+	}
+
+	switch cfg.Provider {
+	case config.ProviderOpenAI:
+		return NewOpenAIEmbedding(cfg.BaseURL, cfg.APIKey, model, cfg.Dimensions)
+	case "", config.ProviderOllama:
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = defaultOllamaURL
+		}
+		return NewOllamaEmbedding(baseURL, model)
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider: %s", cfg.Provider)
+	}
+}
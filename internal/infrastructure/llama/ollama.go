@@ -2,10 +2,12 @@ package llama
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"mindx/pkg/llama"
 )
@@ -102,3 +104,85 @@ func (o *OllamaService) ChatWithAgent(agent string, question string) (string, er
 		{Role: "user", Content: question},
 	})
 }
+
+// HasModel 模型是否已下载到本地
+func (o *OllamaService) HasModel(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/tags", o.baseURL), nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("API 错误: 状态码 %d, 响应: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Models []struct {
+			Name  string `json:"name"`
+			Model string `json:"model"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	// 未指定标签的模型名等同于 :latest
+	name := o.model
+	if !strings.Contains(name, ":") {
+		name += ":latest"
+	}
+	for _, m := range result.Models {
+		if m.Name == o.model || m.Name == name || m.Model == o.model || m.Model == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Pull 下载模型，下载完成后返回；模型较大时耗时较长，由 ctx 控制超时
+func (o *OllamaService) Pull(ctx context.Context) error {
+	jsonData, err := json.Marshal(map[string]any{
+		"model":  o.model,
+		"stream": false,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/pull", o.baseURL), bytes.NewReader(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API 错误: 状态码 %d, 响应: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Error != "" {
+		return fmt.Errorf("下载模型 %s 失败: %s", o.model, result.Error)
+	}
+	if result.Status != "success" {
+		return fmt.Errorf("下载模型 %s 未完成: %s", o.model, result.Status)
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+const (
+	anthropicVersion = "2023-06-01"
+	// anthropicMaxTokens 请求未指定 max_tokens 时的输出上限，Messages API 要求必填
+	anthropicMaxTokens = 4096
+)
+
+// AnthropicProvider Anthropic Messages API（/v1/messages）
+// system 消息合并为顶层 system 参数；工具调用和结果分别转换为 tool_use 和 tool_result 内容块
+type AnthropicProvider struct {
+	baseURL    string
+	header     http.Header
+	httpClient *http.Client
+}
+
+// NewAnthropicProvider 创建 Anthropic Messages API 的对话模型服务
+func NewAnthropicProvider(baseURL, apiKey string) *AnthropicProvider {
+	header := http.Header{}
+	header.Set("anthropic-version", anthropicVersion)
+	if apiKey != "" {
+		header.Set("x-api-key", apiKey)
+	}
+	return &AnthropicProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		header:     header,
+		httpClient: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+type anthropicRequest struct {
+	Model       string               `json:"model"`
+	System      string               `json:"system,omitempty"`
+	Messages    []anthropicMessage   `json:"messages"`
+	MaxTokens   int                  `json:"max_tokens"`
+	Temperature *float32             `json:"temperature,omitempty"`
+	Tools       []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice  *anthropicToolChoice `json:"tool_choice,omitempty"`
+	Stream      bool                 `json:"stream,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string             `json:"role"`
+	Content []anthropicContent `json:"content"`
+}
+
+// anthropicContent 内容块：text、tool_use 或 tool_result
+type anthropicContent struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicResponse struct {
+	ID         string             `json:"id"`
+	Model      string             `json:"model"`
+	Content    []anthropicContent `json:"content"`
+	StopReason string             `json:"stop_reason"`
+	Usage      anthropicUsage     `json:"usage"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+func (p *AnthropicProvider) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	resp, err := postJSON(ctx, p.httpClient, p.baseURL+"/messages", p.header, toAnthropicRequest(req, false))
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var result anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("decode anthropic response: %w", err)
+	}
+
+	message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant}
+	var text strings.Builder
+	for _, block := range result.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "tool_use":
+			message.ToolCalls = append(message.ToolCalls, openai.ToolCall{
+				ID:   block.ID,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      block.Name,
+					Arguments: string(toolArguments(string(block.Input))),
+				},
+			})
+		}
+	}
+	message.Content = text.String()
+
+	return openai.ChatCompletionResponse{
+		ID:      result.ID,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   result.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      message,
+			FinishReason: anthropicFinishReason(result.StopReason),
+		}},
+		Usage: anthropicToUsage(result.Usage),
+	}, nil
+}
+
+func (p *AnthropicProvider) CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (ChatStream, error) {
+	resp, err := postJSON(ctx, p.httpClient, p.baseURL+"/messages", p.header, toAnthropicRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+	return &anthropicStream{
+		reader:    newSSEReader(resp.Body),
+		model:     req.Model,
+		toolIndex: make(map[int]int),
+	}, nil
+}
+
+// toAnthropicRequest 转换 OpenAI 格式的请求
+// 连续的同角色消息（包括多个工具结果）合并为一条，满足 Messages API 角色交替的要求
+func toAnthropicRequest(req openai.ChatCompletionRequest, stream bool) anthropicRequest {
+	out := anthropicRequest{
+		Model:     req.Model,
+		MaxTokens: req.MaxTokens,
+		Stream:    stream,
+	}
+	if out.MaxTokens <= 0 {
+		out.MaxTokens = req.MaxCompletionTokens
+	}
+	if out.MaxTokens <= 0 {
+		out.MaxTokens = anthropicMaxTokens
+	}
+	if req.Temperature > 0 {
+		// Anthropic 的温度范围为 0-1
+		temperature := min(req.Temperature, 1)
+		out.Temperature = &temperature
+	}
+
+	var system []string
+	for _, msg := range req.Messages {
+		var role string
+		var blocks []anthropicContent
+		switch msg.Role {
+		case openai.ChatMessageRoleSystem, openai.ChatMessageRoleDeveloper:
+			if msg.Content != "" {
+				system = append(system, msg.Content)
+			}
+			continue
+		case openai.ChatMessageRoleTool:
+			role = openai.ChatMessageRoleUser
+			blocks = append(blocks, anthropicContent{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
+			})
+		case openai.ChatMessageRoleAssistant:
+			role = openai.ChatMessageRoleAssistant
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicContent{Type: "text", Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				blocks = append(blocks, anthropicContent{
+					Type:  "tool_use",
+					ID:    call.ID,
+					Name:  call.Function.Name,
+					Input: toolArguments(call.Function.Arguments),
+				})
+			}
+		default:
+			role = openai.ChatMessageRoleUser
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicContent{Type: "text", Text: msg.Content})
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+
+		if n := len(out.Messages); n > 0 && out.Messages[n-1].Role == role {
+			out.Messages[n-1].Content = append(out.Messages[n-1].Content, blocks...)
+			continue
+		}
+		out.Messages = append(out.Messages, anthropicMessage{Role: role, Content: blocks})
+	}
+	out.System = strings.Join(system, "\n\n")
+
+	for _, tool := range req.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		out.Tools = append(out.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: toolParameters(tool.Function.Parameters),
+		})
+	}
+	if len(out.Tools) > 0 {
+		out.ToolChoice = toAnthropicToolChoice(req.ToolChoice)
+	}
+	return out
+}
+
+// toAnthropicToolChoice 转换 OpenAI 格式的 tool_choice
+// required 对应 any（必须调用某个工具），指定函数对应 tool；无法识别时不设置，由模型自行决定
+func toAnthropicToolChoice(choice any) *anthropicToolChoice {
+	switch c := choice.(type) {
+	case string:
+		switch c {
+		case "auto", "none", "any":
+			return &anthropicToolChoice{Type: c}
+		case "required":
+			return &anthropicToolChoice{Type: "any"}
+		}
+	case openai.ToolChoice:
+		if c.Function.Name != "" {
+			return &anthropicToolChoice{Type: "tool", Name: c.Function.Name}
+		}
+	case *openai.ToolChoice:
+		if c != nil && c.Function.Name != "" {
+			return &anthropicToolChoice{Type: "tool", Name: c.Function.Name}
+		}
+	}
+	return nil
+}
+
+func anthropicFinishReason(stopReason string) openai.FinishReason {
+	switch stopReason {
+	case "tool_use":
+		return openai.FinishReasonToolCalls
+	case "max_tokens":
+		return openai.FinishReasonLength
+	case "refusal":
+		return openai.FinishReasonContentFilter
+	case "":
+		return openai.FinishReasonNull
+	default:
+		return openai.FinishReasonStop
+	}
+}
+
+func anthropicToUsage(usage anthropicUsage) openai.Usage {
+	return openai.Usage{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.InputTokens + usage.OutputTokens,
+	}
+}
+
+// anthropicStream 将 Messages API 的流式事件转换为 OpenAI 格式的流式片段
+type anthropicStream struct {
+	reader    *sseReader
+	model     string
+	id        string
+	usage     anthropicUsage
+	toolIndex map[int]int // 内容块序号 -> 工具调用序号
+}
+
+type anthropicStreamEvent struct {
+	Type    string             `json:"type"`
+	Index   int                `json:"index"`
+	Message *anthropicResponse `json:"message"`
+	Block   *anthropicContent  `json:"content_block"`
+	Delta   *struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *anthropicStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	for {
+		_, data, err := s.reader.next()
+		if err != nil {
+			return openai.ChatCompletionStreamResponse{}, err
+		}
+
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return openai.ChatCompletionStreamResponse{}, fmt.Errorf("decode anthropic stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				s.id = event.Message.ID
+				s.usage.InputTokens = event.Message.Usage.InputTokens
+			}
+		case "content_block_start":
+			if event.Block == nil || event.Block.Type != "tool_use" {
+				continue
+			}
+			index := len(s.toolIndex)
+			s.toolIndex[event.Index] = index
+			return s.chunk(openai.ChatCompletionStreamChoiceDelta{
+				ToolCalls: []openai.ToolCall{{
+					Index:    &index,
+					ID:       event.Block.ID,
+					Type:     openai.ToolTypeFunction,
+					Function: openai.FunctionCall{Name: event.Block.Name},
+				}},
+			}, ""), nil
+		case "content_block_delta":
+			if event.Delta == nil {
+				continue
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				return s.chunk(openai.ChatCompletionStreamChoiceDelta{Content: event.Delta.Text}, ""), nil
+			case "input_json_delta":
+				index, ok := s.toolIndex[event.Index]
+				if !ok {
+					continue
+				}
+				return s.chunk(openai.ChatCompletionStreamChoiceDelta{
+					ToolCalls: []openai.ToolCall{{
+						Index:    &index,
+						Function: openai.FunctionCall{Arguments: event.Delta.PartialJSON},
+					}},
+				}, ""), nil
+			}
+		case "message_delta":
+			if event.Usage != nil {
+				s.usage.OutputTokens = event.Usage.OutputTokens
+			}
+			stopReason := ""
+			if event.Delta != nil {
+				stopReason = event.Delta.StopReason
+			}
+			resp := s.chunk(openai.ChatCompletionStreamChoiceDelta{}, anthropicFinishReason(stopReason))
+			usage := anthropicToUsage(s.usage)
+			resp.Usage = &usage
+			return resp, nil
+		case "message_stop":
+			return openai.ChatCompletionStreamResponse{}, io.EOF
+		case "error":
+			apiErr := &openai.APIError{HTTPStatusCode: http.StatusInternalServerError, Message: data}
+			if event.Error != nil {
+				apiErr.Type = event.Error.Type
+				apiErr.Message = event.Error.Message
+				if event.Error.Type == "overloaded_error" {
+					apiErr.HTTPStatusCode = 529
+				}
+			}
+			return openai.ChatCompletionStreamResponse{}, apiErr
+		}
+	}
+}
+
+func (s *anthropicStream) chunk(delta openai.ChatCompletionStreamChoiceDelta, finishReason openai.FinishReason) openai.ChatCompletionStreamResponse {
+	return openai.ChatCompletionStreamResponse{
+		ID:      s.id,
+		Object:  "chat.completion.chunk",
+		Created: time.Now().Unix(),
+		Model:   s.model,
+		Choices: []openai.ChatCompletionStreamChoice{{Delta: delta, FinishReason: finishReason}},
+	}
+}
+
+func (s *anthropicStream) Close() error {
+	return s.reader.Close()
+}
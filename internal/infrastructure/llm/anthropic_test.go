@@ -0,0 +1,231 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mindx/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolRoundRequest 一轮工具调用后回传结果的对话：系统提示、用户问题、两个并行的工具调用及其结果
+func toolRoundRequest(model string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "你是助手"},
+			{Role: openai.ChatMessageRoleUser, Content: "北京和上海的天气"},
+			{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{
+				{ID: "call_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city":"北京"}`}},
+				{ID: "call_2", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city":"上海"}`}},
+			}},
+			{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "晴"},
+			{Role: openai.ChatMessageRoleTool, ToolCallID: "call_2", Content: `{"weather":"雨"}`},
+		},
+		Tools: []openai.Tool{{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        "weather",
+				Description: "查询天气",
+				Parameters: map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"city": map[string]any{"type": "string"}},
+				},
+			},
+		}},
+		ToolChoice: "auto",
+		MaxTokens:  1024,
+	}
+}
+
+func TestAnthropic_ToolUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "sk-test", r.Header.Get("x-api-key"))
+		assert.Equal(t, anthropicVersion, r.Header.Get("anthropic-version"))
+
+		var req anthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "claude-test", req.Model)
+		assert.Equal(t, "你是助手", req.System)
+		assert.Equal(t, 1024, req.MaxTokens)
+		assert.False(t, req.Stream)
+		require.Len(t, req.Tools, 1)
+		assert.JSONEq(t, `{"type":"object","properties":{"city":{"type":"string"}}}`, string(req.Tools[0].InputSchema))
+		require.NotNil(t, req.ToolChoice)
+		assert.Equal(t, "auto", req.ToolChoice.Type)
+
+		// 两个工具结果合并为一条 user 消息
+		require.Len(t, req.Messages, 3)
+		assert.Equal(t, "user", req.Messages[0].Role)
+		assistant := req.Messages[1]
+		assert.Equal(t, "assistant", assistant.Role)
+		require.Len(t, assistant.Content, 2)
+		assert.Equal(t, "tool_use", assistant.Content[0].Type)
+		assert.Equal(t, "call_1", assistant.Content[0].ID)
+		assert.JSONEq(t, `{"city":"北京"}`, string(assistant.Content[0].Input))
+		results := req.Messages[2]
+		assert.Equal(t, "user", results.Role)
+		require.Len(t, results.Content, 2)
+		assert.Equal(t, "tool_result", results.Content[1].Type)
+		assert.Equal(t, "call_2", results.Content[1].ToolUseID)
+		assert.Equal(t, `{"weather":"雨"}`, results.Content[1].Content)
+
+		_, _ = w.Write([]byte(`{
+			"id": "msg_1", "model": "claude-test", "stop_reason": "tool_use",
+			"content": [
+				{"type": "text", "text": "再查一下广州"},
+				{"type": "tool_use", "id": "toolu_1", "name": "weather", "input": {"city": "广州"}}
+			],
+			"usage": {"input_tokens": 120, "output_tokens": 30}
+		}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&config.ModelConfig{Provider: config.ProviderAnthropic, BaseURL: server.URL + "/v1", APIKey: "sk-test"})
+	require.NoError(t, err)
+
+	resp, err := provider.CreateChatCompletion(context.Background(), toolRoundRequest("claude-test"))
+	require.NoError(t, err)
+	require.Len(t, resp.Choices, 1)
+	choice := resp.Choices[0]
+	assert.Equal(t, openai.FinishReasonToolCalls, choice.FinishReason)
+	assert.Equal(t, "再查一下广州", choice.Message.Content)
+	require.Len(t, choice.Message.ToolCalls, 1)
+	assert.Equal(t, "toolu_1", choice.Message.ToolCalls[0].ID)
+	assert.Equal(t, "weather", choice.Message.ToolCalls[0].Function.Name)
+	assert.JSONEq(t, `{"city":"广州"}`, choice.Message.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, openai.Usage{PromptTokens: 120, CompletionTokens: 30, TotalTokens: 150}, resp.Usage)
+}
+
+func TestAnthropic_Stream(t *testing.T) {
+	events := []string{
+		`{"type":"message_start","message":{"id":"msg_2","model":"claude-test","content":[],"usage":{"input_tokens":50,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"ping"}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"你好"}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"，世界"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_2","name":"weather","input":{}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"北京\"}"}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":20}}`,
+		`{"type":"message_stop"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			var typed struct {
+				Type string `json:"type"`
+			}
+			_ = json.Unmarshal([]byte(event), &typed)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typed.Type, event)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(server.URL+"/v1", "")
+	stream, err := provider.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    "claude-test",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "你好"}},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var content, arguments strings.Builder
+	var toolID, toolName string
+	var finishReason openai.FinishReason
+	var usage *openai.Usage
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		require.Len(t, chunk.Choices, 1)
+		choice := chunk.Choices[0]
+		content.WriteString(choice.Delta.Content)
+		for _, call := range choice.Delta.ToolCalls {
+			require.NotNil(t, call.Index)
+			assert.Equal(t, 0, *call.Index)
+			if call.ID != "" {
+				toolID, toolName = call.ID, call.Function.Name
+			}
+			arguments.WriteString(call.Function.Arguments)
+		}
+		if choice.FinishReason != "" {
+			finishReason = choice.FinishReason
+		}
+	}
+
+	assert.Equal(t, "你好，世界", content.String())
+	assert.Equal(t, "toolu_2", toolID)
+	assert.Equal(t, "weather", toolName)
+	assert.JSONEq(t, `{"city":"北京"}`, arguments.String())
+	assert.Equal(t, openai.FinishReasonToolCalls, finishReason)
+	require.NotNil(t, usage)
+	assert.Equal(t, openai.Usage{PromptTokens: 50, CompletionTokens: 20, TotalTokens: 70}, *usage)
+}
+
+func TestAnthropic_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(529)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(server.URL, "")
+	_, err := provider.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    "claude-test",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "你好"}},
+	})
+
+	var apiErr *openai.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 529, apiErr.HTTPStatusCode)
+	assert.Equal(t, "overloaded_error", apiErr.Type)
+	assert.Equal(t, "Overloaded", apiErr.Message)
+}
+
+func TestAnthropic_ToolChoice(t *testing.T) {
+	named := openai.ToolChoice{Type: openai.ToolTypeFunction, Function: openai.ToolFunction{Name: "weather"}}
+	tests := []struct {
+		name   string
+		choice any
+		want   *anthropicToolChoice
+	}{
+		{"auto", "auto", &anthropicToolChoice{Type: "auto"}},
+		{"none", "none", &anthropicToolChoice{Type: "none"}},
+		{"required", "required", &anthropicToolChoice{Type: "any"}},
+		{"named", named, &anthropicToolChoice{Type: "tool", Name: "weather"}},
+		{"named pointer", &named, &anthropicToolChoice{Type: "tool", Name: "weather"}},
+		{"unset", nil, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := openai.ChatCompletionRequest{
+				Messages:   []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "你好"}},
+				Tools:      []openai.Tool{{Type: openai.ToolTypeFunction, Function: &openai.FunctionDefinition{Name: "weather"}}},
+				ToolChoice: tc.choice,
+			}
+			assert.Equal(t, tc.want, toAnthropicRequest(req, false).ToolChoice)
+		})
+	}
+}
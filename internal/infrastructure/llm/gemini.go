@@ -0,0 +1,380 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// GeminiProvider Gemini generateContent API
+// 工具调用转换为 functionCall / functionResponse；Gemini 按函数名关联调用和结果，
+// 未返回调用 ID 时按序号生成，回传结果时通过 ID 找回函数名；
+// functionCall 附带的 thoughtSignature 编码在调用 ID 中，回传调用时原样带回
+type GeminiProvider struct {
+	baseURL    string
+	header     http.Header
+	httpClient *http.Client
+}
+
+// NewGeminiProvider 创建 Gemini generateContent API 的对话模型服务
+func NewGeminiProvider(baseURL, apiKey string) *GeminiProvider {
+	header := http.Header{}
+	if apiKey != "" {
+		header.Set("x-goog-api-key", apiKey)
+	}
+	return &GeminiProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		header:     header,
+		httpClient: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+type geminiRequest struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+	// ThoughtSignature 思考模型调用函数时返回的签名，后续请求必须在同一 functionCall 上带回
+	ThoughtSignature string `json:"thoughtSignature,omitempty"`
+}
+
+// geminiSignatureSep 分隔调用 ID 和编码在其中的 thoughtSignature
+const geminiSignatureSep = "|sig:"
+
+type geminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature      *float32 `json:"temperature,omitempty"`
+	MaxOutputTokens  int      `json:"maxOutputTokens,omitempty"`
+	ResponseMimeType string   `json:"responseMimeType,omitempty"`
+	// ResponseJSONSchema 以 JSON Schema 约束输出，需同时设置 ResponseMimeType 为 application/json
+	ResponseJSONSchema json.RawMessage `json:"responseJsonSchema,omitempty"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+}
+
+func (p *GeminiProvider) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	url := fmt.Sprintf("%s/models/%s:generateContent", p.baseURL, req.Model)
+	resp, err := postJSON(ctx, p.httpClient, url, p.header, toGeminiRequest(req))
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var result geminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("decode gemini response: %w", err)
+	}
+
+	message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant}
+	finishReason := openai.FinishReasonNull
+	if len(result.Candidates) > 0 {
+		candidate := result.Candidates[0]
+		var text strings.Builder
+		for _, part := range candidate.Content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				message.ToolCalls = append(message.ToolCalls, geminiToolCall(part, len(message.ToolCalls)))
+			case !part.Thought:
+				text.WriteString(part.Text)
+			}
+		}
+		message.Content = text.String()
+		finishReason = geminiFinishReason(candidate.FinishReason, len(message.ToolCalls) > 0)
+	}
+
+	return openai.ChatCompletionResponse{
+		ID:      responseID("gemini"),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Choices: []openai.ChatCompletionChoice{{Message: message, FinishReason: finishReason}},
+		Usage:   geminiToUsage(&result),
+	}, nil
+}
+
+func (p *GeminiProvider) CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (ChatStream, error) {
+	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", p.baseURL, req.Model)
+	resp, err := postJSON(ctx, p.httpClient, url, p.header, toGeminiRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return &geminiStream{
+		reader: newSSEReader(resp.Body),
+		id:     responseID("gemini"),
+		model:  req.Model,
+	}, nil
+}
+
+// toGeminiRequest 转换 OpenAI 格式的请求
+// 助手消息的角色为 model，工具结果作为 user 消息中的 functionResponse
+func toGeminiRequest(req openai.ChatCompletionRequest) geminiRequest {
+	var out geminiRequest
+
+	config := &geminiGenerationConfig{MaxOutputTokens: req.MaxTokens}
+	if config.MaxOutputTokens <= 0 {
+		config.MaxOutputTokens = req.MaxCompletionTokens
+	}
+	if req.Temperature > 0 {
+		temperature := req.Temperature
+		config.Temperature = &temperature
+	}
+	if format := req.ResponseFormat; format != nil {
+		switch format.Type {
+		case openai.ChatCompletionResponseFormatTypeJSONSchema:
+			config.ResponseMimeType = "application/json"
+			if format.JSONSchema != nil && format.JSONSchema.Schema != nil {
+				if schema, err := json.Marshal(format.JSONSchema.Schema); err == nil {
+					config.ResponseJSONSchema = schema
+				}
+			}
+		case openai.ChatCompletionResponseFormatTypeJSONObject:
+			config.ResponseMimeType = "application/json"
+		}
+	}
+	if config.Temperature != nil || config.MaxOutputTokens > 0 || config.ResponseMimeType != "" {
+		out.GenerationConfig = config
+	}
+
+	// 工具调用 ID -> 函数名，用于回传工具结果
+	callNames := make(map[string]string)
+	var system []geminiPart
+	for _, msg := range req.Messages {
+		var role string
+		var parts []geminiPart
+		switch msg.Role {
+		case openai.ChatMessageRoleSystem, openai.ChatMessageRoleDeveloper:
+			if msg.Content != "" {
+				system = append(system, geminiPart{Text: msg.Content})
+			}
+			continue
+		case openai.ChatMessageRoleTool:
+			role = openai.ChatMessageRoleUser
+			name := callNames[msg.ToolCallID]
+			if name == "" {
+				name = msg.Name
+			}
+			parts = append(parts, geminiPart{FunctionResponse: &geminiFunctionResponse{
+				Name:     name,
+				Response: geminiToolResponse(msg.Content),
+			}})
+		case openai.ChatMessageRoleAssistant:
+			role = "model"
+			if msg.Content != "" {
+				parts = append(parts, geminiPart{Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				callNames[call.ID] = call.Function.Name
+				_, signature, _ := strings.Cut(call.ID, geminiSignatureSep)
+				parts = append(parts, geminiPart{
+					FunctionCall: &geminiFunctionCall{
+						Name: call.Function.Name,
+						Args: toolArguments(call.Function.Arguments),
+					},
+					ThoughtSignature: signature,
+				})
+			}
+		default:
+			role = openai.ChatMessageRoleUser
+			if msg.Content != "" {
+				parts = append(parts, geminiPart{Text: msg.Content})
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+
+		if n := len(out.Contents); n > 0 && out.Contents[n-1].Role == role {
+			out.Contents[n-1].Parts = append(out.Contents[n-1].Parts, parts...)
+			continue
+		}
+		out.Contents = append(out.Contents, geminiContent{Role: role, Parts: parts})
+	}
+	if len(system) > 0 {
+		out.SystemInstruction = &geminiContent{Parts: system}
+	}
+
+	var declarations []geminiFunctionDeclaration
+	for _, tool := range req.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		declarations = append(declarations, geminiFunctionDeclaration{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  toolParameters(tool.Function.Parameters),
+		})
+	}
+	if len(declarations) > 0 {
+		out.Tools = []geminiTool{{FunctionDeclarations: declarations}}
+	}
+	return out
+}
+
+// geminiToolResponse functionResponse.response 必须是对象，非对象的结果包装为 {"result": ...}
+func geminiToolResponse(content string) json.RawMessage {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	data, _ := json.Marshal(map[string]string{"result": content})
+	return data
+}
+
+func geminiToolCall(part geminiPart, index int) openai.ToolCall {
+	call := part.FunctionCall
+	id := call.ID
+	if id == "" {
+		id = fmt.Sprintf("call_%d_%s", index, call.Name)
+	}
+	if part.ThoughtSignature != "" {
+		id += geminiSignatureSep + part.ThoughtSignature
+	}
+	return openai.ToolCall{
+		Index: &index,
+		ID:    id,
+		Type:  openai.ToolTypeFunction,
+		Function: openai.FunctionCall{
+			Name:      call.Name,
+			Arguments: string(toolArguments(string(call.Args))),
+		},
+	}
+}
+
+func geminiFinishReason(reason string, hasToolCalls bool) openai.FinishReason {
+	switch reason {
+	case "":
+		return openai.FinishReasonNull
+	case "STOP":
+		if hasToolCalls {
+			return openai.FinishReasonToolCalls
+		}
+		return openai.FinishReasonStop
+	case "MAX_TOKENS":
+		return openai.FinishReasonLength
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+		return openai.FinishReasonContentFilter
+	default:
+		return openai.FinishReasonStop
+	}
+}
+
+func geminiToUsage(resp *geminiResponse) openai.Usage {
+	if resp.UsageMetadata == nil {
+		return openai.Usage{}
+	}
+	return openai.Usage{
+		PromptTokens:     resp.UsageMetadata.PromptTokenCount,
+		CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:      resp.UsageMetadata.TotalTokenCount,
+	}
+}
+
+// geminiStream 将 streamGenerateContent 的每个响应片段转换为 OpenAI 格式的流式片段
+type geminiStream struct {
+	reader    *sseReader
+	id        string
+	model     string
+	toolCalls int
+}
+
+func (s *geminiStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	for {
+		_, data, err := s.reader.next()
+		if err != nil {
+			return openai.ChatCompletionStreamResponse{}, err
+		}
+
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return openai.ChatCompletionStreamResponse{}, fmt.Errorf("decode gemini stream chunk: %w", err)
+		}
+
+		resp := openai.ChatCompletionStreamResponse{
+			ID:      s.id,
+			Object:  "chat.completion.chunk",
+			Created: time.Now().Unix(),
+			Model:   s.model,
+		}
+		if chunk.UsageMetadata != nil {
+			usage := geminiToUsage(&chunk)
+			resp.Usage = &usage
+		}
+		if len(chunk.Candidates) == 0 {
+			if resp.Usage == nil {
+				continue
+			}
+			return resp, nil
+		}
+
+		candidate := chunk.Candidates[0]
+		var delta openai.ChatCompletionStreamChoiceDelta
+		var text strings.Builder
+		for _, part := range candidate.Content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				delta.ToolCalls = append(delta.ToolCalls, geminiToolCall(part, s.toolCalls))
+				s.toolCalls++
+			case !part.Thought:
+				text.WriteString(part.Text)
+			}
+		}
+		delta.Content = text.String()
+		resp.Choices = []openai.ChatCompletionStreamChoice{{
+			Delta:        delta,
+			FinishReason: geminiFinishReason(candidate.FinishReason, s.toolCalls > 0),
+		}}
+		return resp, nil
+	}
+}
+
+func (s *geminiStream) Close() error {
+	return s.reader.Close()
+}
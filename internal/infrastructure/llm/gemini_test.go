@@ -0,0 +1,222 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mindx/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGemini_FunctionCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/models/gemini-test:generateContent", r.URL.Path)
+		assert.Equal(t, "gm-test", r.Header.Get("x-goog-api-key"))
+
+		var req geminiRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.NotNil(t, req.SystemInstruction)
+		assert.Equal(t, "你是助手", req.SystemInstruction.Parts[0].Text)
+		require.NotNil(t, req.GenerationConfig)
+		assert.Equal(t, 1024, req.GenerationConfig.MaxOutputTokens)
+		require.Len(t, req.Tools, 1)
+		require.Len(t, req.Tools[0].FunctionDeclarations, 1)
+		assert.Equal(t, "weather", req.Tools[0].FunctionDeclarations[0].Name)
+
+		require.Len(t, req.Contents, 3)
+		assert.Equal(t, "user", req.Contents[0].Role)
+		model := req.Contents[1]
+		assert.Equal(t, "model", model.Role)
+		require.Len(t, model.Parts, 2)
+		require.NotNil(t, model.Parts[1].FunctionCall)
+		assert.JSONEq(t, `{"city":"上海"}`, string(model.Parts[1].FunctionCall.Args))
+
+		// 工具结果按调用 ID 找回函数名，非对象结果包装为 {"result": ...}
+		results := req.Contents[2]
+		assert.Equal(t, "user", results.Role)
+		require.Len(t, results.Parts, 2)
+		require.NotNil(t, results.Parts[0].FunctionResponse)
+		assert.Equal(t, "weather", results.Parts[0].FunctionResponse.Name)
+		assert.JSONEq(t, `{"result":"晴"}`, string(results.Parts[0].FunctionResponse.Response))
+		assert.JSONEq(t, `{"weather":"雨"}`, string(results.Parts[1].FunctionResponse.Response))
+
+		_, _ = w.Write([]byte(`{
+			"candidates": [{
+				"content": {"role": "model", "parts": [
+					{"text": "思考中", "thought": true},
+					{"functionCall": {"name": "weather", "args": {"city": "广州"}}}
+				]},
+				"finishReason": "STOP"
+			}],
+			"usageMetadata": {"promptTokenCount": 80, "candidatesTokenCount": 12, "totalTokenCount": 92}
+		}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&config.ModelConfig{Provider: config.ProviderGemini, BaseURL: server.URL + "/v1beta/", APIKey: "gm-test"})
+	require.NoError(t, err)
+
+	resp, err := provider.CreateChatCompletion(context.Background(), toolRoundRequest("gemini-test"))
+	require.NoError(t, err)
+	require.Len(t, resp.Choices, 1)
+	choice := resp.Choices[0]
+	assert.Equal(t, openai.FinishReasonToolCalls, choice.FinishReason)
+	assert.Empty(t, choice.Message.Content, "思考内容不应作为回答")
+	require.Len(t, choice.Message.ToolCalls, 1)
+	call := choice.Message.ToolCalls[0]
+	assert.NotEmpty(t, call.ID, "Gemini 未返回调用 ID 时应生成")
+	assert.Equal(t, "weather", call.Function.Name)
+	assert.JSONEq(t, `{"city":"广州"}`, call.Function.Arguments)
+	assert.Equal(t, openai.Usage{PromptTokens: 80, CompletionTokens: 12, TotalTokens: 92}, resp.Usage)
+}
+
+func TestGemini_ThoughtSignature(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"candidates": [{
+				"content": {"role": "model", "parts": [
+					{"functionCall": {"name": "weather", "args": {"city": "广州"}}, "thoughtSignature": "c2lnLWFiYw=="}
+				]},
+				"finishReason": "STOP"
+			}]
+		}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&config.ModelConfig{Provider: config.ProviderGemini, BaseURL: server.URL + "/v1beta/"})
+	require.NoError(t, err)
+
+	req := openai.ChatCompletionRequest{
+		Model:    "gemini-test",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "广州天气"}},
+	}
+	resp, err := provider.CreateChatCompletion(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.Choices[0].Message.ToolCalls, 1)
+	call := resp.Choices[0].Message.ToolCalls[0]
+
+	// 回传调用时应带回签名，工具结果仍能按调用 ID 找回函数名
+	req.Messages = append(req.Messages,
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{call}},
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, ToolCallID: call.ID, Content: "晴"},
+	)
+	out := toGeminiRequest(req)
+	require.Len(t, out.Contents, 3)
+	require.Len(t, out.Contents[1].Parts, 1)
+	assert.Equal(t, "c2lnLWFiYw==", out.Contents[1].Parts[0].ThoughtSignature)
+	require.NotNil(t, out.Contents[1].Parts[0].FunctionCall)
+	assert.Equal(t, "weather", out.Contents[1].Parts[0].FunctionCall.Name)
+	require.NotNil(t, out.Contents[2].Parts[0].FunctionResponse)
+	assert.Equal(t, "weather", out.Contents[2].Parts[0].FunctionResponse.Name)
+}
+
+func TestGemini_StreamJSON(t *testing.T) {
+	chunks := []string{
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"{\"answer\":"}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"\"你好\"}"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"totalTokenCount":15}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/gemini-test:streamGenerateContent", r.URL.Path)
+		assert.Equal(t, "sse", r.URL.Query().Get("alt"))
+
+		var req geminiRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.NotNil(t, req.GenerationConfig)
+		assert.Equal(t, "application/json", req.GenerationConfig.ResponseMimeType)
+		require.NotNil(t, req.GenerationConfig.Temperature)
+		assert.InDelta(t, 0.3, *req.GenerationConfig.Temperature, 1e-6)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\r\n\r\n", chunk)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewGeminiProvider(server.URL, "")
+	stream, err := provider.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:          "gemini-test",
+		Messages:       []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "你好"}},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject},
+		Temperature:    0.3,
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var content strings.Builder
+	var usage *openai.Usage
+	var finishReason openai.FinishReason
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+
+	assert.JSONEq(t, `{"answer":"你好"}`, content.String())
+	assert.Equal(t, openai.FinishReasonStop, finishReason)
+	require.NotNil(t, usage)
+	assert.Equal(t, 15, usage.TotalTokens)
+}
+
+func TestGemini_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":{"code":503,"message":"The model is overloaded.","status":"UNAVAILABLE"}}`))
+	}))
+	defer server.Close()
+
+	provider := NewGeminiProvider(server.URL, "")
+	_, err := provider.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    "gemini-test",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "你好"}},
+	})
+
+	var apiErr *openai.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.HTTPStatusCode)
+	assert.Equal(t, "UNAVAILABLE", apiErr.Type)
+	assert.Equal(t, "The model is overloaded.", apiErr.Message)
+}
+
+func TestGemini_ResponseSchema(t *testing.T) {
+	req := toGeminiRequest(openai.ChatCompletionRequest{
+		Model:    "gemini-test",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "你好"}},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name: "answer",
+				Schema: &jsonschema.Definition{
+					Type:       jsonschema.Object,
+					Properties: map[string]jsonschema.Definition{"answer": {Type: jsonschema.String}},
+					Required:   []string{"answer"},
+				},
+			},
+		},
+	})
+
+	require.NotNil(t, req.GenerationConfig)
+	assert.Equal(t, "application/json", req.GenerationConfig.ResponseMimeType)
+	assert.JSONEq(t, `{"type":"object","properties":{"answer":{"type":"string"}},"required":["answer"]}`, string(req.GenerationConfig.ResponseJSONSchema))
+}
@@ -0,0 +1,349 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mindx/internal/infrastructure/llama"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// OllamaProvider Ollama 原生 /api/chat 接口
+// 相比 OpenAI 兼容的 /v1 接口，支持以 JSON Schema 约束输出（format）、原生工具调用、
+// 控制模型驻留时长（keep_alive）和上下文窗口（num_ctx），模型未下载时自动下载
+type OllamaProvider struct {
+	baseURL    string
+	numCtx     int
+	keepAlive  any
+	httpClient *http.Client
+
+	pullMu sync.Mutex
+}
+
+// NewOllamaProvider 创建 Ollama 原生接口的对话模型服务
+// numCtx 为模型的上下文窗口（models.yml 中的 max_tokens），keepAlive 为空时使用 Ollama 的默认值
+func NewOllamaProvider(baseURL string, numCtx int, keepAlive string) *OllamaProvider {
+	return &OllamaProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		numCtx:     numCtx,
+		keepAlive:  ollamaKeepAlive(keepAlive),
+		httpClient: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+type ollamaChatRequest struct {
+	Model     string          `json:"model"`
+	Messages  []ollamaMessage `json:"messages"`
+	Tools     []ollamaTool    `json:"tools,omitempty"`
+	Format    json.RawMessage `json:"format,omitempty"`
+	Stream    bool            `json:"stream"`
+	KeepAlive any             `json:"keep_alive,omitempty"`
+	Options   map[string]any  `json:"options,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description,omitempty"`
+		Parameters  json.RawMessage `json:"parameters"`
+	} `json:"function"`
+}
+
+type ollamaChatResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+func (p *OllamaProvider) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	resp, err := p.post(ctx, p.toOllamaRequest(req, false))
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var result ollamaChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("decode ollama response: %w", err)
+	}
+
+	message := openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleAssistant,
+		Content: result.Message.Content,
+	}
+	for i, call := range result.Message.ToolCalls {
+		message.ToolCalls = append(message.ToolCalls, ollamaToOpenAIToolCall(call, i))
+	}
+
+	return openai.ChatCompletionResponse{
+		ID:      responseID("ollama"),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   result.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      message,
+			FinishReason: ollamaFinishReason(result.DoneReason, len(message.ToolCalls) > 0),
+		}},
+		Usage: ollamaToUsage(&result),
+	}, nil
+}
+
+func (p *OllamaProvider) CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (ChatStream, error) {
+	resp, err := p.post(ctx, p.toOllamaRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	return &ollamaStream{
+		body:    resp.Body,
+		scanner: scanner,
+		id:      responseID("ollama"),
+		model:   req.Model,
+	}, nil
+}
+
+// post 发送对话请求，模型未下载时先下载再重试一次
+func (p *OllamaProvider) post(ctx context.Context, req ollamaChatRequest) (*http.Response, error) {
+	resp, err := postJSON(ctx, p.httpClient, p.baseURL+"/api/chat", http.Header{}, req)
+	if !isModelNotFound(err) {
+		return resp, err
+	}
+	if pullErr := p.pull(ctx, req.Model); pullErr != nil {
+		return nil, fmt.Errorf("%w (pull model: %v)", err, pullErr)
+	}
+	return postJSON(ctx, p.httpClient, p.baseURL+"/api/chat", http.Header{}, req)
+}
+
+// pull 下载模型，同一时间只下载一个模型；等待期间其它请求已下载完成时不再重复下载
+func (p *OllamaProvider) pull(ctx context.Context, model string) error {
+	p.pullMu.Lock()
+	defer p.pullMu.Unlock()
+
+	svc := llama.NewOllamaService(model).WithBaseUrl(p.baseURL)
+	if ok, err := svc.HasModel(ctx); err == nil && ok {
+		return nil
+	}
+	return svc.Pull(ctx)
+}
+
+// isModelNotFound Ollama 对未下载的模型返回 404 和 "model ... not found"
+func isModelNotFound(err error) bool {
+	var apiErr *openai.APIError
+	return errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusNotFound &&
+		strings.Contains(apiErr.Message, "not found")
+}
+
+// toOllamaRequest 转换 OpenAI 格式的请求
+// response_format 转换为 format：json_schema 传入 Schema，json_object 传入 "json"；
+// max_tokens 在本项目中表示上下文窗口，已作为 num_ctx，只有 max_completion_tokens 限制输出长度
+func (p *OllamaProvider) toOllamaRequest(req openai.ChatCompletionRequest, stream bool) ollamaChatRequest {
+	out := ollamaChatRequest{
+		Model:     req.Model,
+		Stream:    stream,
+		KeepAlive: p.keepAlive,
+		Options:   map[string]any{},
+	}
+	if p.numCtx > 0 {
+		out.Options["num_ctx"] = p.numCtx
+	}
+	if req.MaxCompletionTokens > 0 {
+		out.Options["num_predict"] = req.MaxCompletionTokens
+	}
+	if req.Temperature > 0 {
+		out.Options["temperature"] = req.Temperature
+	}
+	if len(out.Options) == 0 {
+		out.Options = nil
+	}
+
+	if format := req.ResponseFormat; format != nil {
+		switch format.Type {
+		case openai.ChatCompletionResponseFormatTypeJSONSchema:
+			if format.JSONSchema != nil && format.JSONSchema.Schema != nil {
+				if schema, err := json.Marshal(format.JSONSchema.Schema); err == nil {
+					out.Format = schema
+				}
+			}
+		case openai.ChatCompletionResponseFormatTypeJSONObject:
+			out.Format = json.RawMessage(`"json"`)
+		}
+	}
+
+	// 工具调用 ID -> 函数名，Ollama 按函数名关联工具结果
+	callNames := make(map[string]string)
+	for _, msg := range req.Messages {
+		message := ollamaMessage{Role: msg.Role, Content: msg.Content}
+		switch msg.Role {
+		case openai.ChatMessageRoleDeveloper:
+			message.Role = openai.ChatMessageRoleSystem
+		case openai.ChatMessageRoleAssistant:
+			for _, call := range msg.ToolCalls {
+				callNames[call.ID] = call.Function.Name
+				var toolCall ollamaToolCall
+				toolCall.Function.Name = call.Function.Name
+				toolCall.Function.Arguments = toolArguments(call.Function.Arguments)
+				message.ToolCalls = append(message.ToolCalls, toolCall)
+			}
+		case openai.ChatMessageRoleTool:
+			message.ToolName = callNames[msg.ToolCallID]
+			if message.ToolName == "" {
+				message.ToolName = msg.Name
+			}
+		}
+		out.Messages = append(out.Messages, message)
+	}
+
+	for _, tool := range req.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		var t ollamaTool
+		t.Type = string(openai.ToolTypeFunction)
+		t.Function.Name = tool.Function.Name
+		t.Function.Description = tool.Function.Description
+		t.Function.Parameters = toolParameters(tool.Function.Parameters)
+		out.Tools = append(out.Tools, t)
+	}
+	return out
+}
+
+// ollamaKeepAlive 整数按秒传递（-1 表示常驻），其余按时长字符串传递，如 "30m"
+func ollamaKeepAlive(keepAlive string) any {
+	if keepAlive == "" {
+		return nil
+	}
+	if seconds, err := strconv.Atoi(keepAlive); err == nil {
+		return seconds
+	}
+	return keepAlive
+}
+
+func ollamaToOpenAIToolCall(call ollamaToolCall, index int) openai.ToolCall {
+	return openai.ToolCall{
+		Index: &index,
+		ID:    fmt.Sprintf("call_%d_%s", index, call.Function.Name),
+		Type:  openai.ToolTypeFunction,
+		Function: openai.FunctionCall{
+			Name:      call.Function.Name,
+			Arguments: string(toolArguments(string(call.Function.Arguments))),
+		},
+	}
+}
+
+func ollamaFinishReason(doneReason string, hasToolCalls bool) openai.FinishReason {
+	switch {
+	case hasToolCalls:
+		return openai.FinishReasonToolCalls
+	case doneReason == "length":
+		return openai.FinishReasonLength
+	case doneReason == "":
+		return openai.FinishReasonNull
+	default:
+		return openai.FinishReasonStop
+	}
+}
+
+func ollamaToUsage(resp *ollamaChatResponse) openai.Usage {
+	return openai.Usage{
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+		TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+	}
+}
+
+// ollamaStream 将 /api/chat 的 NDJSON 流转换为 OpenAI 格式的流式片段
+type ollamaStream struct {
+	body      io.ReadCloser
+	scanner   *bufio.Scanner
+	id        string
+	model     string
+	toolCalls int
+	done      bool
+}
+
+func (s *ollamaStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	for !s.done && s.scanner.Scan() {
+		line := strings.TrimSpace(s.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var chunk ollamaChatResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return openai.ChatCompletionStreamResponse{}, fmt.Errorf("decode ollama stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return openai.ChatCompletionStreamResponse{}, &openai.APIError{
+				HTTPStatusCode: http.StatusInternalServerError,
+				Message:        chunk.Error,
+			}
+		}
+
+		var delta openai.ChatCompletionStreamChoiceDelta
+		delta.Content = chunk.Message.Content
+		for _, call := range chunk.Message.ToolCalls {
+			delta.ToolCalls = append(delta.ToolCalls, ollamaToOpenAIToolCall(call, s.toolCalls))
+			s.toolCalls++
+		}
+
+		resp := openai.ChatCompletionStreamResponse{
+			ID:      s.id,
+			Object:  "chat.completion.chunk",
+			Created: time.Now().Unix(),
+			Model:   s.model,
+		}
+		if chunk.Done {
+			s.done = true
+			usage := ollamaToUsage(&chunk)
+			resp.Usage = &usage
+			resp.Choices = []openai.ChatCompletionStreamChoice{{
+				Delta:        delta,
+				FinishReason: ollamaFinishReason(chunk.DoneReason, s.toolCalls > 0),
+			}}
+			return resp, nil
+		}
+		if delta.Content == "" && len(delta.ToolCalls) == 0 {
+			continue
+		}
+		resp.Choices = []openai.ChatCompletionStreamChoice{{Delta: delta}}
+		return resp, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return openai.ChatCompletionStreamResponse{}, err
+	}
+	return openai.ChatCompletionStreamResponse{}, io.EOF
+}
+
+func (s *ollamaStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,217 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mindx/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOllama_ToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "qwen3:1.7b", req["model"])
+		assert.Equal(t, false, req["stream"])
+		assert.Equal(t, float64(-1), req["keep_alive"])
+		options := req["options"].(map[string]any)
+		assert.Equal(t, float64(40960), options["num_ctx"])
+		assert.NotContains(t, options, "num_predict", "max_tokens 是上下文窗口，不限制输出长度")
+
+		tools := req["tools"].([]any)
+		require.Len(t, tools, 1)
+		function := tools[0].(map[string]any)["function"].(map[string]any)
+		assert.Equal(t, "weather", function["name"])
+
+		// 工具调用参数为对象，工具结果按调用 ID 找回函数名
+		messages := req["messages"].([]any)
+		require.Len(t, messages, 5)
+		assistant := messages[2].(map[string]any)
+		calls := assistant["tool_calls"].([]any)
+		require.Len(t, calls, 2)
+		assert.Equal(t, map[string]any{"city": "北京"}, calls[0].(map[string]any)["function"].(map[string]any)["arguments"])
+		result := messages[4].(map[string]any)
+		assert.Equal(t, "tool", result["role"])
+		assert.Equal(t, "weather", result["tool_name"])
+
+		_, _ = w.Write([]byte(`{
+			"model": "qwen3:1.7b",
+			"message": {"role": "assistant", "content": "", "tool_calls": [
+				{"function": {"name": "weather", "arguments": {"city": "广州"}}}
+			]},
+			"done": true, "done_reason": "stop",
+			"prompt_eval_count": 64, "eval_count": 16
+		}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&config.ModelConfig{
+		Provider:  config.ProviderOllama,
+		BaseURL:   server.URL + "/v1",
+		MaxTokens: 40960,
+		KeepAlive: "-1",
+	})
+	require.NoError(t, err)
+
+	resp, err := provider.CreateChatCompletion(context.Background(), toolRoundRequest("qwen3:1.7b"))
+	require.NoError(t, err)
+	require.Len(t, resp.Choices, 1)
+	choice := resp.Choices[0]
+	assert.Equal(t, openai.FinishReasonToolCalls, choice.FinishReason)
+	require.Len(t, choice.Message.ToolCalls, 1)
+	call := choice.Message.ToolCalls[0]
+	assert.NotEmpty(t, call.ID)
+	assert.Equal(t, "weather", call.Function.Name)
+	assert.JSONEq(t, `{"city":"广州"}`, call.Function.Arguments)
+	assert.Equal(t, openai.Usage{PromptTokens: 64, CompletionTokens: 16, TotalTokens: 80}, resp.Usage)
+}
+
+func TestOllama_StreamWithSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Format    json.RawMessage `json:"format"`
+			Stream    bool            `json:"stream"`
+			KeepAlive string          `json:"keep_alive"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+		assert.Equal(t, "30m", req.KeepAlive)
+		assert.JSONEq(t, `{"type":"object","properties":{"answer":{"type":"string"}},"required":["answer"]}`, string(req.Format))
+
+		for _, line := range []string{
+			`{"message":{"role":"assistant","content":"{\"answer\":"},"done":false}`,
+			`{"message":{"role":"assistant","content":"\"你好\"}"},"done":false}`,
+			`{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":10,"eval_count":6}`,
+		} {
+			fmt.Fprintln(w, line)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(server.URL, 0, "30m")
+	stream, err := provider.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    "qwen3:0.6b",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "你好"}},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name: "answer",
+				Schema: &jsonschema.Definition{
+					Type:       jsonschema.Object,
+					Properties: map[string]jsonschema.Definition{"answer": {Type: jsonschema.String}},
+					Required:   []string{"answer"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var content strings.Builder
+	var usage *openai.Usage
+	var finishReason openai.FinishReason
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+
+	assert.JSONEq(t, `{"answer":"你好"}`, content.String())
+	assert.Equal(t, openai.FinishReasonStop, finishReason)
+	require.NotNil(t, usage)
+	assert.Equal(t, 16, usage.TotalTokens)
+}
+
+func TestOllama_PullOnDemand(t *testing.T) {
+	var pulled atomic.Bool
+	var chats, pulls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat":
+			chats.Add(1)
+			if !pulled.Load() {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"model \"qwen3:0.6b\" not found, try pulling it first"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"你好"},"done":true,"done_reason":"stop"}`))
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[{"name":"qwen3:1.7b","model":"qwen3:1.7b"}]}`))
+		case "/api/pull":
+			var req struct {
+				Model  string `json:"model"`
+				Stream bool   `json:"stream"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "qwen3:0.6b", req.Model)
+			assert.False(t, req.Stream)
+			pulls.Add(1)
+			pulled.Store(true)
+			_, _ = w.Write([]byte(`{"status":"success"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(server.URL, 0, "")
+	resp, err := provider.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    "qwen3:0.6b",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "你好"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "你好", resp.Choices[0].Message.Content)
+	assert.Equal(t, int32(1), pulls.Load())
+	assert.Equal(t, int32(2), chats.Load())
+}
+
+func TestOllama_PullFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model \"missing\" not found, try pulling it first"}`))
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[]}`))
+		case "/api/pull":
+			_, _ = w.Write([]byte(`{"error":"pull model manifest: file does not exist"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(server.URL, 0, "")
+	_, err := provider.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    "missing",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "你好"}},
+	})
+
+	var apiErr *openai.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.HTTPStatusCode)
+	assert.Contains(t, err.Error(), "file does not exist")
+}
@@ -0,0 +1,270 @@
+// Package llm 对话模型服务的接口适配
+//
+// 大脑以 OpenAI Chat Completions 的请求和响应结构描述对话（消息、工具调用、流式片段），
+// 各 Provider 负责与具体服务的接口互相转换，使 Thinking 和 ToolCaller 无需关心模型来自哪个服务。
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mindx/internal/config"
+	"net/http"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Provider 对话模型服务
+// 错误中服务端返回的 HTTP 状态以 *openai.APIError 表示，供重试和降级判断服务是否可用
+type Provider interface {
+	CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (ChatStream, error)
+}
+
+// ChatStream 流式回答，Recv 在回答结束时返回 io.EOF
+type ChatStream interface {
+	Recv() (openai.ChatCompletionStreamResponse, error)
+	Close() error
+}
+
+// 各服务未配置 base_url 时使用的地址
+const (
+	defaultOpenAIURL    = "https://api.openai.com/v1"
+	defaultOllamaURL    = "http://localhost:11434"
+	defaultAnthropicURL = "https://api.anthropic.com/v1"
+	defaultGeminiURL    = "https://generativelanguage.googleapis.com/v1beta"
+)
+
+// NewProvider 按模型配置的 provider 创建对话模型服务
+// provider 为空时使用 OpenAI 兼容接口
+func NewProvider(cfg *config.ModelConfig) (Provider, error) {
+	baseURL := BaseURL(cfg)
+	switch cfg.Provider {
+	case "", config.ProviderOpenAI:
+		return NewOpenAIProvider(baseURL, cfg.APIKey), nil
+	case config.ProviderOllama:
+		return NewOllamaProvider(baseURL, cfg.MaxTokens, cfg.KeepAlive), nil
+	case config.ProviderAnthropic:
+		return NewAnthropicProvider(baseURL, cfg.APIKey), nil
+	case config.ProviderGemini:
+		return NewGeminiProvider(baseURL, cfg.APIKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported chat provider: %s", cfg.Provider)
+	}
+}
+
+// BaseURL 返回模型服务的地址，未配置时使用 provider 的官方地址
+// Ollama 原生接口不在 /v1 下，沿用 OpenAI 兼容接口的地址时去掉 /v1
+func BaseURL(cfg *config.ModelConfig) string {
+	if cfg.BaseURL != "" {
+		baseURL := strings.TrimRight(cfg.BaseURL, "/")
+		if cfg.Provider == config.ProviderOllama {
+			baseURL = strings.TrimSuffix(baseURL, "/v1")
+		}
+		return baseURL
+	}
+	switch cfg.Provider {
+	case config.ProviderOllama:
+		return defaultOllamaURL
+	case config.ProviderAnthropic:
+		return defaultAnthropicURL
+	case config.ProviderGemini:
+		return defaultGeminiURL
+	default:
+		return defaultOpenAIURL
+	}
+}
+
+// ProbeURL 返回健康探测请求的地址
+func ProbeURL(cfg *config.ModelConfig) string {
+	if cfg.Provider == config.ProviderOllama {
+		return BaseURL(cfg) + "/api/tags"
+	}
+	return BaseURL(cfg) + "/models"
+}
+
+// JSONResponseFormat 返回以 schema 约束输出的 response_format
+// ollama 和 gemini 原生接口始终支持 JSON Schema，anthropic 不支持；OpenAI 兼容接口由 structured_output 声明是否支持。
+// 不支持时退回 json_object，只要求输出 JSON，调用方需在提示词中说明字段
+func JSONResponseFormat(cfg *config.ModelConfig, name string, schema json.Marshaler) *openai.ChatCompletionResponseFormat {
+	structured := cfg.StructuredOutput
+	switch cfg.Provider {
+	case config.ProviderOllama, config.ProviderGemini:
+		structured = true
+	case config.ProviderAnthropic:
+		structured = false
+	}
+	if !structured {
+		return &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	}
+	return &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:   name,
+			Schema: schema,
+		},
+	}
+}
+
+// SetAuthHeader 按 provider 设置请求的鉴权头
+func SetAuthHeader(req *http.Request, cfg *config.ModelConfig) {
+	if cfg.APIKey == "" {
+		return
+	}
+	switch cfg.Provider {
+	case config.ProviderAnthropic:
+		req.Header.Set("x-api-key", cfg.APIKey)
+		req.Header.Set("anthropic-version", anthropicVersion)
+	case config.ProviderGemini:
+		req.Header.Set("x-goog-api-key", cfg.APIKey)
+	default:
+		req.Header.Set("Authorization", "Bearer "+cfg.APIKey)
+	}
+}
+
+// OpenAIProvider OpenAI 兼容的 /chat/completions 接口
+type OpenAIProvider struct {
+	client *openai.Client
+}
+
+// NewOpenAIProvider 创建 OpenAI 兼容接口的对话模型服务
+func NewOpenAIProvider(baseURL, apiKey string) *OpenAIProvider {
+	cfg := openai.DefaultConfig(apiKey)
+	cfg.BaseURL = baseURL
+	return &OpenAIProvider{client: openai.NewClientWithConfig(cfg)}
+}
+
+func (p *OpenAIProvider) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	return p.client.CreateChatCompletion(ctx, req)
+}
+
+func (p *OpenAIProvider) CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (ChatStream, error) {
+	stream, err := p.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// postJSON 发送 JSON 请求，非 2xx 响应转换为 *openai.APIError
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+	return resp, nil
+}
+
+// newAPIError 解析错误响应，兼容 Anthropic、Gemini（error 为对象）和 Ollama（error 为字符串）的错误格式
+func newAPIError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var payload struct {
+		Error json.RawMessage `json:"error"`
+	}
+	var detail struct {
+		Type    string `json:"type"`
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	var message string
+	apiErr := &openai.APIError{HTTPStatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	if json.Unmarshal(body, &payload) == nil && len(payload.Error) > 0 {
+		if json.Unmarshal(payload.Error, &message) == nil && message != "" {
+			apiErr.Message = message
+		} else if json.Unmarshal(payload.Error, &detail) == nil && detail.Message != "" {
+			apiErr.Message = detail.Message
+			apiErr.Type = detail.Type
+			if apiErr.Type == "" {
+				apiErr.Type = detail.Status
+			}
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+// sseReader 读取 text/event-stream 响应
+type sseReader struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+func newSSEReader(body io.ReadCloser) *sseReader {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	return &sseReader{body: body, scanner: scanner}
+}
+
+// next 返回下一个事件的类型和数据，响应结束时返回 io.EOF
+func (r *sseReader) next() (event string, data string, err error) {
+	var lines []string
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		switch {
+		case line == "":
+			if len(lines) > 0 {
+				return event, strings.Join(lines, "\n"), nil
+			}
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			lines = append(lines, strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return "", "", err
+	}
+	if len(lines) > 0 {
+		return event, strings.Join(lines, "\n"), nil
+	}
+	return "", "", io.EOF
+}
+
+func (r *sseReader) Close() error {
+	return r.body.Close()
+}
+
+// toolParameters 将工具参数定义转换为 JSON Schema，未定义参数时返回空对象 Schema
+func toolParameters(params any) json.RawMessage {
+	if params != nil {
+		if data, err := json.Marshal(params); err == nil && string(data) != "null" && string(data) != "{}" {
+			return data
+		}
+	}
+	return json.RawMessage(`{"type":"object","properties":{}}`)
+}
+
+// toolArguments 工具调用参数，模型未给出参数时返回空对象
+func toolArguments(arguments string) json.RawMessage {
+	if strings.TrimSpace(arguments) == "" || !json.Valid([]byte(arguments)) {
+		return json.RawMessage(`{}`)
+	}
+	return json.RawMessage(arguments)
+}
+
+// responseID 为不返回 ID 的服务生成响应 ID
+func responseID(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
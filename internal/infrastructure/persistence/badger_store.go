@@ -1,8 +1,17 @@
 package persistence
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/badger/v4"
@@ -10,18 +19,50 @@ import (
 	"mindx/internal/core"
 	"mindx/internal/entity"
 	apperrors "mindx/internal/errors"
-	"mindx/internal/utils"
+	"mindx/pkg/hnsw"
+)
+
+// annIndexFile 近似最近邻索引文件名，与 Badger 数据位于同一目录
+const annIndexFile = "vectors.hnsw"
+
+// 数据库 key 布局：集合数据为 "col/<集合名>/<key>"，存储自身的元信息以 "meta/" 开头
+const (
+	collectionKeyPrefix = "col/"
+	metaKeyPrefix       = "meta/"
+	layoutKey           = metaKeyPrefix + "layout"
+	layoutVersion       = "1"
 )
 
 // BadgerStore Badger向量存储实现
+// 数据按集合划分，每个集合的向量维护在各自的 HNSW 索引中，Search 不再逐条扫描数据库
+// 直接调用 BadgerStore 的读写方法时作用于 default 集合
 type BadgerStore struct {
+	*badgerCollection
+
 	db       *badger.DB
 	svc      *VectorService
 	provider core.EmbeddingProvider
 	stopCh   chan struct{}
+
+	collectionsMu sync.RWMutex
+	collections   map[string]*badgerCollection
+
+	// indexMu 串行化写操作与索引保存，保证索引与数据库的写入顺序和版本一致
+	indexMu    sync.Mutex
+	indexPath  string
+	indexDirty atomic.Bool
+}
+
+// badgerCollection BadgerStore 中的一个命名集合
+type badgerCollection struct {
+	collectionIndex
+
+	store  *BadgerStore
+	prefix []byte
 }
 
 // NewBadgerStore 创建Badger向量存储
+// 首次打开旧版本的数据目录时，会按 key 前缀把数据迁移到对应集合
 func NewBadgerStore(dbPath string, provider core.EmbeddingProvider) (*BadgerStore, error) {
 	opts := badger.DefaultOptions(dbPath)
 	opts.Logger = nil
@@ -33,17 +74,154 @@ func NewBadgerStore(dbPath string, provider core.EmbeddingProvider) (*BadgerStor
 		return nil, apperrors.Wrap(err, apperrors.ErrTypeStorage, "打开 Badger 数据库失败")
 	}
 
+	if err := migrateLegacyKeys(db); err != nil {
+		db.Close()
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeStorage, "迁移向量数据到集合失败")
+	}
+
 	store := &BadgerStore{
-		db:       db,
-		svc:      NewVectorService(),
-		provider: provider,
-		stopCh:   make(chan struct{}),
+		db:          db,
+		svc:         NewVectorService(),
+		provider:    provider,
+		stopCh:      make(chan struct{}),
+		collections: make(map[string]*badgerCollection),
+		indexPath:   filepath.Join(dbPath, annIndexFile),
+	}
+	if err := store.openIndex(); err != nil {
+		db.Close()
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeStorage, "加载向量索引失败")
 	}
+	store.badgerCollection = store.collection(core.CollectionDefault)
 	go store.runGC()
 
 	return store, nil
 }
 
+// legacyCollections 旧版本 key 前缀与集合的对应关系，未列出的 key 迁入 default 集合
+var legacyCollections = []struct {
+	prefix     string
+	collection string
+}{
+	{"memory_", core.CollectionMemories},
+	{"skill_vector:", core.CollectionSkills},
+	{"skill_stats:", core.CollectionSkills},
+	{"capability:", core.CollectionCapabilities},
+}
+
+func legacyCollectionOf(key string) string {
+	for _, l := range legacyCollections {
+		if strings.HasPrefix(key, l.prefix) {
+			return l.collection
+		}
+	}
+	return core.CollectionDefault
+}
+
+// migrateLegacyKeys 将未划分集合的旧数据迁入集合，完成后写入布局版本，只执行一次
+func migrateLegacyKeys(db *badger.DB) error {
+	type kv struct {
+		key   []byte
+		value []byte
+	}
+	var legacy []kv
+	migrated := false
+
+	err := db.View(func(txn *badger.Txn) error {
+		if _, err := txn.Get([]byte(layoutKey)); err == nil {
+			migrated = true
+			return nil
+		} else if err != badger.ErrKeyNotFound {
+			return err
+		}
+
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			key := item.KeyCopy(nil)
+			if bytes.HasPrefix(key, []byte(collectionKeyPrefix)) || bytes.HasPrefix(key, []byte(metaKeyPrefix)) {
+				continue
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			legacy = append(legacy, kv{key: key, value: value})
+		}
+		return nil
+	})
+	if err != nil || migrated {
+		return err
+	}
+
+	wb := db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, e := range legacy {
+		newKey := collectionKey(legacyCollectionOf(string(e.key)), string(e.key))
+		if err := wb.Set(newKey, e.value); err != nil {
+			return err
+		}
+		if err := wb.Delete(e.key); err != nil {
+			return err
+		}
+	}
+	if err := wb.Set([]byte(layoutKey), []byte(layoutVersion)); err != nil {
+		return err
+	}
+	return wb.Flush()
+}
+
+func collectionPrefix(name string) []byte {
+	return []byte(collectionKeyPrefix + name + "/")
+}
+
+func collectionKey(name, key string) []byte {
+	return append(collectionPrefix(name), key...)
+}
+
+// Collection 返回命名集合，不存在时创建
+func (s *BadgerStore) Collection(name string) (core.Store, error) {
+	if err := core.ValidateCollectionName(name); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeStorage, "集合名称无效")
+	}
+	return s.collection(name), nil
+}
+
+// Collections 返回已有集合名称
+func (s *BadgerStore) Collections() []string {
+	s.collectionsMu.RLock()
+	defer s.collectionsMu.RUnlock()
+
+	names := make([]string, 0, len(s.collections))
+	for name := range s.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *BadgerStore) collection(name string) *badgerCollection {
+	s.collectionsMu.RLock()
+	c, ok := s.collections[name]
+	s.collectionsMu.RUnlock()
+	if ok {
+		return c
+	}
+
+	s.collectionsMu.Lock()
+	defer s.collectionsMu.Unlock()
+	if c, ok := s.collections[name]; ok {
+		return c
+	}
+	c = &badgerCollection{
+		collectionIndex: newCollectionIndex(name),
+		store:           s,
+		prefix:          collectionPrefix(name),
+	}
+	s.collections[name] = c
+	return c
+}
+
 // runGC 后台定期执行 Value Log GC
 func (s *BadgerStore) runGC() {
 	ticker := time.NewTicker(10 * time.Minute)
@@ -58,12 +236,20 @@ func (s *BadgerStore) runGC() {
 					break
 				}
 			}
+			if s.indexDirty.Load() {
+				_ = s.saveIndex()
+			}
 		}
 	}
 }
 
+// Collection 返回所属存储中的另一个集合
+func (c *badgerCollection) Collection(name string) (core.Store, error) {
+	return c.store.Collection(name)
+}
+
 // Put 存储向量
-func (s *BadgerStore) Put(key string, vector []float64, metadata interface{}) error {
+func (c *badgerCollection) Put(key string, vector []float64, metadata interface{}) error {
 	if vector == nil {
 		return apperrors.New(apperrors.ErrTypeStorage, "vector cannot be nil")
 	}
@@ -73,37 +259,40 @@ func (s *BadgerStore) Put(key string, vector []float64, metadata interface{}) er
 		Vector: vector,
 	}
 
-	if metadata != nil {
-		switch m := metadata.(type) {
-		case []byte:
-			entry.Metadata = m
-		case json.RawMessage:
-			entry.Metadata = m
-		default:
-			metadataBytes, err := json.Marshal(metadata)
-			if err != nil {
-				return apperrors.Wrap(err, apperrors.ErrTypeStorage, "failed to marshal metadata")
-			}
-			entry.Metadata = metadataBytes
-		}
+	metadataBytes, err := marshalMetadata(metadata)
+	if err != nil {
+		return err
 	}
+	entry.Metadata = metadataBytes
 
 	entryBytes, err := json.Marshal(entry)
 	if err != nil {
 		return apperrors.Wrap(err, apperrors.ErrTypeStorage, "failed to marshal entry")
 	}
 
-	return s.db.Update(func(txn *badger.Txn) error {
-		return txn.Set([]byte(key), entryBytes)
-	})
+	c.store.indexMu.Lock()
+	defer c.store.indexMu.Unlock()
+
+	if err := c.checkDimension(key, vector, c.index.Dim()); err != nil {
+		return err
+	}
+	if err := c.store.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(collectionKey(c.name, key), entryBytes)
+	}); err != nil {
+		return err
+	}
+	if c.indexEntry(key, vector) {
+		c.store.indexDirty.Store(true)
+	}
+	return nil
 }
 
 // Get 获取向量
-func (s *BadgerStore) Get(key string) (*entity.VectorEntry, error) {
+func (c *badgerCollection) Get(key string) (*entity.VectorEntry, error) {
 	var entry entity.VectorEntry
 
-	err := s.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte(key))
+	err := c.store.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(collectionKey(c.name, key))
 		if err != nil {
 			return err
 		}
@@ -121,24 +310,68 @@ func (s *BadgerStore) Get(key string) (*entity.VectorEntry, error) {
 }
 
 // Delete 删除向量
-func (s *BadgerStore) Delete(key string) error {
-	return s.db.Update(func(txn *badger.Txn) error {
-		return txn.Delete([]byte(key))
-	})
+func (c *badgerCollection) Delete(key string) error {
+	c.store.indexMu.Lock()
+	defer c.store.indexMu.Unlock()
+
+	if err := c.store.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(collectionKey(c.name, key))
+	}); err != nil {
+		return err
+	}
+	if c.index.Remove(key) {
+		c.store.indexDirty.Store(true)
+	}
+	return nil
 }
 
 // Search 搜索最相似的向量
-func (s *BadgerStore) Search(queryVec []float64, topN int) ([]entity.VectorEntry, error) {
-	return s.SearchWithThreshold(queryVec, topN, 0)
+func (c *badgerCollection) Search(queryVec []float64, topN int) ([]entity.VectorEntry, error) {
+	return c.SearchWithThreshold(queryVec, topN, 0)
 }
 
 // SearchWithThreshold 搜索最相似的向量，过滤低于 minScore 的结果
-func (s *BadgerStore) SearchWithThreshold(queryVec []float64, topN int, minScore float64) ([]entity.VectorEntry, error) {
+// 查询向量与集合维度一致时使用 HNSW 索引，否则（如空查询向量）退化为集合内全量扫描
+func (c *badgerCollection) SearchWithThreshold(queryVec []float64, topN int, minScore float64) ([]entity.VectorEntry, error) {
+	return c.search(queryVec, topN, minScore, c.getEntries, c.vectorEntries)
+}
+
+// getEntries 按 key 读取条目，跳过已不存在或无法解析的 key
+func (c *badgerCollection) getEntries(keys []string) ([]entity.VectorEntry, error) {
+	results := make([]entity.VectorEntry, 0, len(keys))
+	err := c.store.db.View(func(txn *badger.Txn) error {
+		for _, key := range keys {
+			item, err := txn.Get(collectionKey(c.name, key))
+			if err != nil {
+				if err == badger.ErrKeyNotFound {
+					continue
+				}
+				return err
+			}
+			var entry entity.VectorEntry
+			if err := item.Value(func(val []byte) error {
+				return json.Unmarshal(val, &entry)
+			}); err != nil {
+				continue
+			}
+			results = append(results, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// vectorEntries 逐条扫描集合，返回带向量的条目
+func (c *badgerCollection) vectorEntries() ([]entity.VectorEntry, error) {
 	var candidates []entity.VectorEntry
 
-	err := s.db.View(func(txn *badger.Txn) error {
+	err := c.store.db.View(func(txn *badger.Txn) error {
 		opts := badger.DefaultIteratorOptions
 		opts.PrefetchSize = 100
+		opts.Prefix = c.prefix
 		it := txn.NewIterator(opts)
 		defer it.Close()
 
@@ -153,7 +386,7 @@ func (s *BadgerStore) SearchWithThreshold(queryVec []float64, topN int, minScore
 				continue
 			}
 
-			if entry.Vector != nil && len(entry.Vector) > 0 {
+			if len(entry.Vector) > 0 {
 				candidates = append(candidates, entry)
 			}
 		}
@@ -164,42 +397,18 @@ func (s *BadgerStore) SearchWithThreshold(queryVec []float64, topN int, minScore
 	if err != nil {
 		return nil, err
 	}
+	return candidates, nil
+}
 
-	if len(candidates) == 0 {
-		return []entity.VectorEntry{}, nil
-	}
-
-	similarityResults := make([]entity.SimilarityResult, 0, len(candidates))
-	for _, candidate := range candidates {
-		score := utils.CalculateCosineSimilarity(queryVec, candidate.Vector)
-		if score < minScore {
-			continue
-		}
-		similarityResults = append(similarityResults, entity.SimilarityResult{
-			Target: candidate.Key,
-			Score:  score,
-			Metadata: map[string]interface{}{
-				"vector": candidate.Vector,
-				"entry":  candidate,
-			},
-		})
-	}
-
-	topResults := utils.FindMostSimilar(queryVec, similarityResults, topN)
-
-	results := make([]entity.VectorEntry, 0, len(topResults))
-	for _, result := range topResults {
-		if entry, ok := result.Metadata["entry"].(entity.VectorEntry); ok {
-			results = append(results, entry)
-		}
-	}
-
-	return results, nil
+// Close 集合与所属存储共享数据库，关闭集合不做任何事
+func (c *badgerCollection) Close() error {
+	return nil
 }
 
-// Close 关闭数据库
+// Close 保存向量索引并关闭数据库
 func (s *BadgerStore) Close() error {
 	close(s.stopCh)
+	_ = s.saveIndex()
 	return s.db.Close()
 }
 
@@ -209,35 +418,59 @@ func (s *BadgerStore) Backup(w io.Writer) (uint64, error) {
 }
 
 // BatchPut 批量存储向量
-func (s *BadgerStore) BatchPut(entries []entity.VectorEntry) error {
+func (c *badgerCollection) BatchPut(entries []entity.VectorEntry) error {
 	if len(entries) == 0 {
 		return nil
 	}
 
-	return s.db.Update(func(txn *badger.Txn) error {
+	c.store.indexMu.Lock()
+	defer c.store.indexMu.Unlock()
+
+	dim := c.index.Dim()
+	for _, entry := range entries {
+		if err := c.checkDimension(entry.Key, entry.Vector, dim); err != nil {
+			return err
+		}
+		if dim == 0 {
+			dim = len(entry.Vector)
+		}
+	}
+
+	written := make([]entity.VectorEntry, 0, len(entries))
+	if err := c.store.db.Update(func(txn *badger.Txn) error {
 		for _, entry := range entries {
 			entryBytes, err := json.Marshal(entry)
 			if err != nil {
 				continue
 			}
-			if err := txn.Set([]byte(entry.Key), entryBytes); err != nil {
+			if err := txn.Set(collectionKey(c.name, entry.Key), entryBytes); err != nil {
 				return err
 			}
+			written = append(written, entry)
 		}
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+	for _, entry := range written {
+		if c.indexEntry(entry.Key, entry.Vector) {
+			c.store.indexDirty.Store(true)
+		}
+	}
+	return nil
 }
 
-func (s *BadgerStore) Scan(prefix string) ([]entity.VectorEntry, error) {
+// Scan 按 key 前缀列出集合中的条目
+func (c *badgerCollection) Scan(prefix string) ([]entity.VectorEntry, error) {
 	var entries []entity.VectorEntry
 
-	err := s.db.View(func(txn *badger.Txn) error {
+	err := c.store.db.View(func(txn *badger.Txn) error {
 		opts := badger.DefaultIteratorOptions
 		opts.PrefetchSize = 100
 		it := txn.NewIterator(opts)
 		defer it.Close()
 
-		prefixBytes := []byte(prefix)
+		prefixBytes := collectionKey(c.name, prefix)
 		for it.Seek(prefixBytes); it.ValidForPrefix(prefixBytes); it.Next() {
 			item := it.Item()
 
@@ -261,3 +494,139 @@ func (s *BadgerStore) Scan(prefix string) ([]entity.VectorEntry, error) {
 
 	return entries, nil
 }
+
+// openIndex 加载持久化的索引；文件缺失、损坏或与数据库版本不一致时重建
+func (s *BadgerStore) openIndex() error {
+	if err := s.loadIndex(); err == nil {
+		return nil
+	}
+	return s.rebuildIndex()
+}
+
+// loadIndex 读取索引文件：数据库版本、集合数量，再依次为集合名与该集合的索引
+func (s *BadgerStore) loadIndex() error {
+	f, err := os.Open(s.indexPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var version uint64
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return err
+	}
+	if version != s.db.MaxVersion() {
+		return apperrors.New(apperrors.ErrTypeStorage, "vector index is stale")
+	}
+
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return err
+	}
+	collections := make(map[string]*badgerCollection, count)
+	for i := uint32(0); i < count; i++ {
+		var nameLen uint16
+		if err := binary.Read(r, binary.LittleEndian, &nameLen); err != nil {
+			return err
+		}
+		name := make([]byte, nameLen)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return err
+		}
+		index, err := hnsw.Load(r)
+		if err != nil {
+			return err
+		}
+		collections[string(name)] = &badgerCollection{
+			collectionIndex: collectionIndex{name: string(name), index: index},
+			store:           s,
+			prefix:          collectionPrefix(string(name)),
+		}
+	}
+
+	s.collections = collections
+	return nil
+}
+
+// rebuildIndex 扫描数据库重建全部集合的索引
+func (s *BadgerStore) rebuildIndex() error {
+	s.collections = make(map[string]*badgerCollection)
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(collectionKeyPrefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			rest := strings.TrimPrefix(string(it.Item().Key()), collectionKeyPrefix)
+			name, _, ok := strings.Cut(rest, "/")
+			if !ok {
+				continue
+			}
+			c := s.collection(name)
+
+			var entry entity.VectorEntry
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &entry)
+			}); err != nil || len(entry.Vector) == 0 {
+				continue
+			}
+			_ = c.index.Add(entry.Key, entry.Vector)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.indexDirty.Store(true)
+	return nil
+}
+
+// saveIndex 将各集合的索引连同当前数据库版本写入文件，启动时据此判断索引是否过期
+func (s *BadgerStore) saveIndex() error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	names := s.Collections()
+
+	tmp := s.indexPath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	write := func() error {
+		if err := binary.Write(w, binary.LittleEndian, s.db.MaxVersion()); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint32(len(names))); err != nil {
+			return err
+		}
+		for _, name := range names {
+			if err := binary.Write(w, binary.LittleEndian, uint16(len(name))); err != nil {
+				return err
+			}
+			if _, err := w.WriteString(name); err != nil {
+				return err
+			}
+			if err := s.collection(name).index.Save(w); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}
+	if err := write(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.indexPath); err != nil {
+		return err
+	}
+	s.indexDirty.Store(false)
+	return nil
+}
@@ -2,8 +2,14 @@ package persistence
 
 import (
 	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/dgraph-io/badger/v4"
+
+	"mindx/internal/core"
 	"mindx/internal/entity"
 )
 
@@ -181,3 +187,224 @@ func TestBadgerStore_PutWithMetadata(t *testing.T) {
 		t.Fatalf("expected name=test, got %v", decoded["name"])
 	}
 }
+
+func TestBadgerStore_IndexPersistence(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewBadgerStore(dir, nil)
+	if err != nil {
+		t.Fatalf("NewBadgerStore failed: %v", err)
+	}
+	store.Put("x", []float64{1, 0, 0}, nil)
+	store.Put("y", []float64{0, 1, 0}, nil)
+	store.Put("gone", []float64{0.9, 0.1, 0}, nil)
+	store.Delete("gone")
+	store.Put("skill_vector:demo", []float64{}, map[string]string{"type": "skill"})
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// 重新打开时直接加载索引文件，无需重建
+	store, err = NewBadgerStore(dir, nil)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if store.indexDirty.Load() {
+		t.Fatal("expected index to be loaded from file instead of rebuilt")
+	}
+	if store.index.Len() != 2 {
+		t.Fatalf("expected 2 indexed vectors, got %d", store.index.Len())
+	}
+	results, err := store.Search([]float64{1, 0.05, 0}, 1)
+	if err != nil || len(results) != 1 || results[0].Key != "x" {
+		t.Fatalf("unexpected search results: %v, %v", results, err)
+	}
+	store.Put("z", []float64{0, 0, 1}, nil)
+	store.Close()
+
+	// 索引文件缺失时从数据库重建
+	if err := os.Remove(filepath.Join(dir, annIndexFile)); err != nil {
+		t.Fatalf("remove index file failed: %v", err)
+	}
+	store, err = NewBadgerStore(dir, nil)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+	if store.index.Len() != 3 {
+		t.Fatalf("expected 3 indexed vectors after rebuild, got %d", store.index.Len())
+	}
+	results, err = store.Search([]float64{0, 0, 1}, 1)
+	if err != nil || len(results) != 1 || results[0].Key != "z" {
+		t.Fatalf("unexpected search results after rebuild: %v, %v", results, err)
+	}
+}
+
+func TestBadgerStore_StaleIndexRebuilt(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewBadgerStore(dir, nil)
+	if err != nil {
+		t.Fatalf("NewBadgerStore failed: %v", err)
+	}
+	store.Put("x", []float64{1, 0, 0}, nil)
+	if err := store.saveIndex(); err != nil {
+		t.Fatalf("saveIndex failed: %v", err)
+	}
+	// 模拟进程异常退出：索引保存后仍有写入
+	store.Put("y", []float64{0, 1, 0}, nil)
+	close(store.stopCh)
+	store.db.Close()
+
+	store, err = NewBadgerStore(dir, nil)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+	if !store.index.Has("y") {
+		t.Fatal("expected stale index to be rebuilt with the latest writes")
+	}
+}
+
+func TestBadgerStore_CollectionsIsolated(t *testing.T) {
+	store := newTestBadgerStore(t)
+	memories, err := store.Collection(core.CollectionMemories)
+	if err != nil {
+		t.Fatalf("Collection failed: %v", err)
+	}
+	skills, err := store.Collection(core.CollectionSkills)
+	if err != nil {
+		t.Fatalf("Collection failed: %v", err)
+	}
+
+	memories.Put("same", []float64{1, 0, 0}, nil)
+	skills.Put("same", []float64{1, 0, 0, 0}, nil)
+	skills.Put("other", []float64{0.9, 0.1, 0, 0}, nil)
+
+	results, err := memories.Search([]float64{1, 0, 0}, 10)
+	if err != nil || len(results) != 1 || results[0].Key != "same" {
+		t.Fatalf("expected only the memory entry, got %v, %v", results, err)
+	}
+	if all, _ := memories.Search(nil, 10); len(all) != 1 {
+		t.Fatalf("expected scan to stay inside the collection, got %d entries", len(all))
+	}
+	if entries, _ := skills.Scan(""); len(entries) != 2 {
+		t.Fatalf("expected 2 skill entries, got %d", len(entries))
+	}
+	if _, err := store.Get("same"); err == nil {
+		t.Fatal("default collection should not see other collections' keys")
+	}
+	if memories.Dimension() != 3 || skills.Dimension() != 4 || store.Dimension() != 0 {
+		t.Fatalf("unexpected dimensions: %d, %d, %d", memories.Dimension(), skills.Dimension(), store.Dimension())
+	}
+
+	if _, err := store.Collection("Bad/Name"); err == nil {
+		t.Fatal("expected invalid collection name to be rejected")
+	}
+}
+
+func TestBadgerStore_DimensionCheck(t *testing.T) {
+	store := newTestBadgerStore(t)
+	store.Put("a", []float64{1, 0, 0}, nil)
+
+	if err := store.Put("b", []float64{1, 0}, nil); !errors.Is(err, core.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", err)
+	}
+	err := store.BatchPut([]entity.VectorEntry{
+		{Key: "c", Vector: []float64{0, 1, 0}},
+		{Key: "d", Vector: []float64{0, 1}},
+	})
+	if !errors.Is(err, core.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch for batch, got %v", err)
+	}
+	if _, err := store.Get("c"); err == nil {
+		t.Fatal("rejected batch should not be partially written")
+	}
+	// 空向量不参与维度校验
+	if err := store.Put("meta", []float64{}, nil); err != nil {
+		t.Fatalf("empty vector should be accepted: %v", err)
+	}
+	// 集合中唯一的向量可以替换为新维度
+	if err := store.Put("a", []float64{1, 0}, nil); err != nil {
+		t.Fatalf("replacing the only vector should be allowed: %v", err)
+	}
+	if store.Dimension() != 2 {
+		t.Fatalf("expected dimension 2, got %d", store.Dimension())
+	}
+}
+
+func TestBadgerStore_MigrateLegacyKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	// 按旧版本布局直接写入数据库
+	opts := badger.DefaultOptions(dir)
+	opts.Logger = nil
+	db, err := badger.Open(opts)
+	if err != nil {
+		t.Fatalf("open badger failed: %v", err)
+	}
+	legacy := []entity.VectorEntry{
+		{Key: "memory_1", Vector: []float64{1, 0, 0}, Metadata: []byte(`{"memory_point":{"id":1}}`)},
+		{Key: "skill_vector:weather", Vector: []float64{}},
+		{Key: "skill_stats:weather", Vector: []float64{}},
+		{Key: "capability:coder", Vector: []float64{0, 1, 0, 0}},
+		{Key: "misc", Vector: []float64{0, 0, 1}},
+	}
+	err = db.Update(func(txn *badger.Txn) error {
+		for _, e := range legacy {
+			data, _ := json.Marshal(e)
+			if err := txn.Set([]byte(e.Key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("write legacy data failed: %v", err)
+	}
+	db.Close()
+
+	store, err := NewBadgerStore(dir, nil)
+	if err != nil {
+		t.Fatalf("NewBadgerStore failed: %v", err)
+	}
+
+	want := map[string][]string{
+		core.CollectionMemories:     {"memory_1"},
+		core.CollectionSkills:       {"skill_stats:weather", "skill_vector:weather"},
+		core.CollectionCapabilities: {"capability:coder"},
+		core.CollectionDefault:      {"misc"},
+	}
+	check := func(store *BadgerStore) {
+		t.Helper()
+		for name, keys := range want {
+			c, err := store.Collection(name)
+			if err != nil {
+				t.Fatalf("Collection %s failed: %v", name, err)
+			}
+			entries, err := c.Scan("")
+			if err != nil || len(entries) != len(keys) {
+				t.Fatalf("collection %s: expected %v, got %v, %v", name, keys, entries, err)
+			}
+			for i, key := range keys {
+				if entries[i].Key != key {
+					t.Fatalf("collection %s: expected key %s, got %s", name, key, entries[i].Key)
+				}
+			}
+		}
+	}
+	check(store)
+
+	memories, _ := store.Collection(core.CollectionMemories)
+	results, err := memories.Search([]float64{1, 0, 0}, 5)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("expected migrated memory to be indexed, got %v, %v", results, err)
+	}
+	store.Close()
+
+	// 再次打开不会重复迁移
+	store, err = NewBadgerStore(dir, nil)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+	check(store)
+}
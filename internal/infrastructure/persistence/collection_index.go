@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"fmt"
+
+	"mindx/internal/core"
+	"mindx/internal/entity"
+	apperrors "mindx/internal/errors"
+	"mindx/internal/utils"
+	"mindx/pkg/hnsw"
+)
+
+// collectionIndex 集合的 HNSW 索引
+// Badger 和 SQLite 存储的集合共用维度校验、索引同步和检索流程，后端只负责按 key 读取条目
+type collectionIndex struct {
+	name  string
+	index *hnsw.Index
+}
+
+func newCollectionIndex(name string) collectionIndex {
+	return collectionIndex{name: name, index: hnsw.New(hnsw.DefaultConfig())}
+}
+
+// Dimension 返回集合的向量维度，尚无向量时为 0
+func (c *collectionIndex) Dimension() int {
+	return c.index.Dim()
+}
+
+// checkDimension 校验向量维度与集合已有向量一致；集合中仅有同 key 的向量时允许替换为新维度
+func (c *collectionIndex) checkDimension(key string, vector []float64, dim int) error {
+	return checkDimension(c.name, key, vector, dim, c.index.Len() == 1 && c.index.Has(key))
+}
+
+// indexEntry 同步索引：有向量时写入，向量为空时移除，返回索引是否有变化
+// 维度已经过 checkDimension 校验，Add 失败只可能是替换集合中唯一向量的维度，移除后重新写入
+func (c *collectionIndex) indexEntry(key string, vector []float64) bool {
+	if len(vector) == 0 {
+		return c.index.Remove(key)
+	}
+	if err := c.index.Add(key, vector); err != nil {
+		c.index.Remove(key)
+		_ = c.index.Add(key, vector)
+	}
+	return true
+}
+
+// search 搜索最相似的向量，过滤低于 minScore 的结果
+// 查询向量与集合维度一致时通过索引查找候选，再由 load 按 key 读取完整条目；
+// 否则（如空查询向量）由 scan 读取集合内全部带向量的条目逐条计算相似度
+func (c *collectionIndex) search(queryVec []float64, topN int, minScore float64,
+	load func(keys []string) ([]entity.VectorEntry, error),
+	scan func() ([]entity.VectorEntry, error)) ([]entity.VectorEntry, error) {
+	if len(queryVec) == 0 || len(queryVec) != c.index.Dim() {
+		candidates, err := scan()
+		if err != nil {
+			return nil, err
+		}
+		return rankBySimilarity(queryVec, candidates, topN, minScore), nil
+	}
+
+	hits := c.index.Search(queryVec, topN, nil)
+	keys := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		if hit.Score < minScore {
+			break
+		}
+		keys = append(keys, hit.Key)
+	}
+	if len(keys) == 0 {
+		return []entity.VectorEntry{}, nil
+	}
+
+	entries, err := load(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按索引返回的相似度顺序排列，跳过索引中已不存在于数据库的 key
+	byKey := make(map[string]entity.VectorEntry, len(entries))
+	for _, entry := range entries {
+		byKey[entry.Key] = entry
+	}
+	results := make([]entity.VectorEntry, 0, len(entries))
+	for _, key := range keys {
+		if entry, ok := byKey[key]; ok {
+			results = append(results, entry)
+		}
+	}
+	return results, nil
+}
+
+// checkDimension 校验写入集合的向量维度，onlyKey 表示集合中仅有该 key 的向量
+func checkDimension(collection, key string, vector []float64, dim int, onlyKey bool) error {
+	if len(vector) == 0 || dim == 0 || len(vector) == dim || onlyKey {
+		return nil
+	}
+	return apperrors.Wrap(core.ErrDimensionMismatch, apperrors.ErrTypeStorage,
+		fmt.Sprintf("集合 %s 的向量维度为 %d，写入的向量维度为 %d", collection, dim, len(vector)))
+}
+
+// rankBySimilarity 逐条计算相似度，返回不低于 minScore 的前 topN 个条目
+func rankBySimilarity(queryVec []float64, candidates []entity.VectorEntry, topN int, minScore float64) []entity.VectorEntry {
+	if len(candidates) == 0 {
+		return []entity.VectorEntry{}
+	}
+
+	similarityResults := make([]entity.SimilarityResult, 0, len(candidates))
+	for _, candidate := range candidates {
+		if len(candidate.Vector) == 0 {
+			continue
+		}
+		score := utils.CalculateCosineSimilarity(queryVec, candidate.Vector)
+		if score < minScore {
+			continue
+		}
+		similarityResults = append(similarityResults, entity.SimilarityResult{
+			Target: candidate.Key,
+			Score:  score,
+			Metadata: map[string]interface{}{
+				"vector": candidate.Vector,
+				"entry":  candidate,
+			},
+		})
+	}
+
+	topResults := utils.FindMostSimilar(queryVec, similarityResults, topN)
+
+	results := make([]entity.VectorEntry, 0, len(topResults))
+	for _, result := range topResults {
+		if entry, ok := result.Metadata["entry"].(entity.VectorEntry); ok {
+			results = append(results, entry)
+		}
+	}
+	return results
+}
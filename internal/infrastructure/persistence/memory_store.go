@@ -7,25 +7,93 @@ import (
 
 	"mindx/internal/core"
 	"mindx/internal/entity"
-	"mindx/internal/utils"
+	apperrors "mindx/internal/errors"
 )
 
 // MemoryStore 内存向量存储实现
+// NewMemoryStore 返回的实例即 default 集合，其他集合由 Collection 创建并挂在同一根存储下
 type MemoryStore struct {
 	vectors  map[string][]float64
 	metadata map[string][]byte
 	svc      *VectorService
 	provider core.EmbeddingProvider
 	mu       sync.RWMutex
+
+	name        string
+	dim         int
+	vectorCount int
+
+	root          *MemoryStore
+	collectionsMu sync.Mutex
+	collections   map[string]*MemoryStore
 }
 
 // NewMemoryStore 创建内存向量存储
 func NewMemoryStore(provider core.EmbeddingProvider) *MemoryStore {
+	s := newMemoryCollection(core.CollectionDefault, provider)
+	s.collections = make(map[string]*MemoryStore)
+	return s
+}
+
+func newMemoryCollection(name string, provider core.EmbeddingProvider) *MemoryStore {
 	return &MemoryStore{
 		vectors:  make(map[string][]float64),
 		metadata: make(map[string][]byte),
 		svc:      NewVectorService(),
 		provider: provider,
+		name:     name,
+	}
+}
+
+// Collection 返回命名集合，不存在时创建
+func (s *MemoryStore) Collection(name string) (core.Store, error) {
+	if err := core.ValidateCollectionName(name); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeStorage, "集合名称无效")
+	}
+
+	root := s
+	if s.root != nil {
+		root = s.root
+	}
+	if name == core.CollectionDefault {
+		return root, nil
+	}
+
+	root.collectionsMu.Lock()
+	defer root.collectionsMu.Unlock()
+	c, ok := root.collections[name]
+	if !ok {
+		c = newMemoryCollection(name, root.provider)
+		c.root = root
+		root.collections[name] = c
+	}
+	return c, nil
+}
+
+// Dimension 返回集合的向量维度，尚无向量时为 0
+func (s *MemoryStore) Dimension() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dim
+}
+
+// checkDimension 校验向量维度与集合已有向量一致；集合中仅有同 key 的向量时允许替换为新维度
+func (s *MemoryStore) checkDimension(key string, vector []float64, dim int) error {
+	return checkDimension(s.name, key, vector, dim, s.vectorCount == 1 && len(s.vectors[key]) > 0)
+}
+
+// setVector 写入向量并维护集合维度，调用方需持有写锁
+func (s *MemoryStore) setVector(key string, vector []float64) {
+	if len(s.vectors[key]) > 0 {
+		s.vectorCount--
+	}
+	s.vectors[key] = vector
+	if len(vector) > 0 {
+		s.vectorCount++
+		s.dim = len(vector)
+	}
+	if s.vectorCount == 0 {
+		s.dim = 0
 	}
 }
 
@@ -34,7 +102,10 @@ func (s *MemoryStore) Put(key string, vector []float64, metadata interface{}) er
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.vectors[key] = vector
+	if err := s.checkDimension(key, vector, s.dim); err != nil {
+		return err
+	}
+	s.setVector(key, vector)
 
 	if metadata != nil {
 		metadataBytes, err := json.Marshal(metadata)
@@ -69,6 +140,12 @@ func (s *MemoryStore) Delete(key string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if len(s.vectors[key]) > 0 {
+		s.vectorCount--
+		if s.vectorCount == 0 {
+			s.dim = 0
+		}
+	}
 	delete(s.vectors, key)
 	delete(s.metadata, key)
 
@@ -85,42 +162,17 @@ func (s *MemoryStore) SearchWithThreshold(queryVec []float64, topN int, minScore
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if len(s.vectors) == 0 {
-		return []entity.VectorEntry{}, nil
-	}
-
-	similarityResults := make([]entity.SimilarityResult, 0, len(s.vectors))
+	candidates := make([]entity.VectorEntry, 0, s.vectorCount)
 	for key, vector := range s.vectors {
-		if vector != nil && len(vector) > 0 {
-			score := utils.CalculateCosineSimilarity(queryVec, vector)
-			if score < minScore {
-				continue
-			}
-			similarityResults = append(similarityResults, entity.SimilarityResult{
-				Target: key,
-				Score:  score,
-				Metadata: map[string]interface{}{
-					"vector": vector,
-					"entry": entity.VectorEntry{
-						Key:      key,
-						Vector:   vector,
-						Metadata: s.metadata[key],
-					},
-				},
+		if len(vector) > 0 {
+			candidates = append(candidates, entity.VectorEntry{
+				Key:      key,
+				Vector:   vector,
+				Metadata: s.metadata[key],
 			})
 		}
 	}
-
-	topResults := utils.FindMostSimilar(queryVec, similarityResults, topN)
-
-	results := make([]entity.VectorEntry, 0, len(topResults))
-	for _, result := range topResults {
-		if entry, ok := result.Metadata["entry"].(entity.VectorEntry); ok {
-			results = append(results, entry)
-		}
-	}
-
-	return results, nil
+	return rankBySimilarity(queryVec, candidates, topN, minScore), nil
 }
 
 // Close 关闭存储
@@ -137,8 +189,18 @@ func (s *MemoryStore) BatchPut(entries []entity.VectorEntry) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	dim := s.dim
+	for _, entry := range entries {
+		if err := s.checkDimension(entry.Key, entry.Vector, dim); err != nil {
+			return err
+		}
+		if dim == 0 {
+			dim = len(entry.Vector)
+		}
+	}
+
 	for _, entry := range entries {
-		s.vectors[entry.Key] = entry.Vector
+		s.setVector(entry.Key, entry.Vector)
 		if entry.Metadata != nil {
 			s.metadata[entry.Key] = entry.Metadata
 		}
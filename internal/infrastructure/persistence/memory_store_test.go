@@ -2,8 +2,10 @@ package persistence
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
+	"mindx/internal/core"
 	"mindx/internal/entity"
 )
 
@@ -174,3 +176,49 @@ func TestMemoryStore_Close(t *testing.T) {
 		t.Fatalf("Close failed: %v", err)
 	}
 }
+
+func TestMemoryStore_Collections(t *testing.T) {
+	store := NewMemoryStore(nil)
+	memories, err := store.Collection(core.CollectionMemories)
+	if err != nil {
+		t.Fatalf("Collection failed: %v", err)
+	}
+	again, _ := store.Collection(core.CollectionMemories)
+	if again != memories {
+		t.Fatal("expected the same collection instance")
+	}
+	if def, _ := memories.Collection(core.CollectionDefault); def != core.Store(store) {
+		t.Fatal("expected default collection to be the root store")
+	}
+
+	store.Put("k", []float64{1, 0}, nil)
+	memories.Put("k", []float64{1, 0, 0}, nil)
+
+	results, _ := memories.Search([]float64{1, 0, 0}, 10)
+	if len(results) != 1 || len(results[0].Vector) != 3 {
+		t.Fatalf("expected only the memory entry, got %v", results)
+	}
+	if store.Size() != 1 {
+		t.Fatalf("expected root store to hold 1 entry, got %d", store.Size())
+	}
+}
+
+func TestMemoryStore_DimensionCheck(t *testing.T) {
+	store := NewMemoryStore(nil)
+	store.Put("a", []float64{1, 0, 0}, nil)
+
+	if err := store.Put("b", []float64{1, 0}, nil); !errors.Is(err, core.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", err)
+	}
+	if err := store.BatchPut([]entity.VectorEntry{{Key: "c", Vector: []float64{1}}}); !errors.Is(err, core.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch for batch, got %v", err)
+	}
+
+	store.Delete("a")
+	if store.Dimension() != 0 {
+		t.Fatalf("expected dimension reset after last vector removed, got %d", store.Dimension())
+	}
+	if err := store.Put("b", []float64{1, 0}, nil); err != nil {
+		t.Fatalf("empty collection should accept any dimension: %v", err)
+	}
+}
@@ -0,0 +1,384 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"mindx/internal/core"
+	"mindx/internal/entity"
+	apperrors "mindx/internal/errors"
+)
+
+// SQLiteStore SQLite 向量存储实现
+// 所有集合保存在同一个数据库文件的 vectors 表中，向量以小端 float64 BLOB 存储，元数据为 JSON 文本，
+// 便于备份和直接用 SQL 查询；检索使用启动时从表中构建的进程内 HNSW 索引
+// 直接调用 SQLiteStore 的读写方法时作用于 default 集合
+type SQLiteStore struct {
+	*sqliteCollection
+
+	db       *sql.DB
+	provider core.EmbeddingProvider
+
+	// mu 串行化写操作，保证索引与数据库一致
+	mu            sync.Mutex
+	collectionsMu sync.RWMutex
+	collections   map[string]*sqliteCollection
+}
+
+// sqliteCollection SQLiteStore 中的一个命名集合
+type sqliteCollection struct {
+	collectionIndex
+
+	store *SQLiteStore
+}
+
+// NewSQLiteStore 创建 SQLite 向量存储
+func NewSQLiteStore(dbPath string, provider core.EmbeddingProvider) (*SQLiteStore, error) {
+	if err := createDirectoryIfNotExists(dbPath); err != nil {
+		return nil, fmt.Errorf("创建数据库目录失败: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+
+	if err := createVectorsTable(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("创建表失败: %w", err)
+	}
+
+	if _, err := db.Exec("PRAGMA journal_mode=WAL;"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("启用 WAL 模式失败: %w", err)
+	}
+
+	store := &SQLiteStore{
+		db:          db,
+		provider:    provider,
+		collections: make(map[string]*sqliteCollection),
+	}
+	if err := store.buildIndexes(); err != nil {
+		db.Close()
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeStorage, "构建向量索引失败")
+	}
+	store.sqliteCollection = store.collection(core.CollectionDefault)
+
+	return store, nil
+}
+
+// createVectorsTable 创建向量表
+func createVectorsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS vectors (
+		collection TEXT NOT NULL,
+		key TEXT NOT NULL,
+		vector BLOB,
+		dimension INTEGER NOT NULL DEFAULT 0,
+		metadata TEXT,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (collection, key)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_vectors_dimension ON vectors(collection, dimension);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// buildIndexes 读取全部向量构建各集合的索引
+func (s *SQLiteStore) buildIndexes() error {
+	rows, err := s.db.Query(`SELECT DISTINCT collection FROM vectors`)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		c := s.collection(name)
+		entries, err := c.query(`WHERE collection = ? AND dimension > 0`, name)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			_ = c.index.Add(entry.Key, entry.Vector)
+		}
+	}
+	return nil
+}
+
+// Collection 返回命名集合，不存在时创建
+func (s *SQLiteStore) Collection(name string) (core.Store, error) {
+	if err := core.ValidateCollectionName(name); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeStorage, "集合名称无效")
+	}
+	return s.collection(name), nil
+}
+
+// Collections 返回已有集合名称
+func (s *SQLiteStore) Collections() []string {
+	s.collectionsMu.RLock()
+	defer s.collectionsMu.RUnlock()
+
+	names := make([]string, 0, len(s.collections))
+	for name := range s.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *SQLiteStore) collection(name string) *sqliteCollection {
+	s.collectionsMu.RLock()
+	c, ok := s.collections[name]
+	s.collectionsMu.RUnlock()
+	if ok {
+		return c
+	}
+
+	s.collectionsMu.Lock()
+	defer s.collectionsMu.Unlock()
+	if c, ok := s.collections[name]; ok {
+		return c
+	}
+	c = &sqliteCollection{
+		collectionIndex: newCollectionIndex(name),
+		store:           s,
+	}
+	s.collections[name] = c
+	return c
+}
+
+// Close 关闭数据库
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Collection 返回所属存储中的另一个集合
+func (c *sqliteCollection) Collection(name string) (core.Store, error) {
+	return c.store.Collection(name)
+}
+
+// Close 集合与所属存储共享数据库，关闭集合不做任何事
+func (c *sqliteCollection) Close() error {
+	return nil
+}
+
+const upsertVectorSQL = `
+	INSERT INTO vectors (collection, key, vector, dimension, metadata, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(collection, key) DO UPDATE SET
+		vector = excluded.vector,
+		dimension = excluded.dimension,
+		metadata = excluded.metadata,
+		updated_at = excluded.updated_at
+	`
+
+// Put 存储向量
+func (c *sqliteCollection) Put(key string, vector []float64, metadata interface{}) error {
+	if vector == nil {
+		return apperrors.New(apperrors.ErrTypeStorage, "vector cannot be nil")
+	}
+	metadataBytes, err := marshalMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	if err := c.checkDimension(key, vector, c.index.Dim()); err != nil {
+		return err
+	}
+	if _, err := c.store.db.Exec(upsertVectorSQL,
+		c.name, key, encodeVector(vector), len(vector), nullableJSON(metadataBytes), time.Now(),
+	); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrTypeStorage, "写入向量失败")
+	}
+	c.indexEntry(key, vector)
+	return nil
+}
+
+// BatchPut 在一个事务中批量存储向量，任一条失败则全部回滚
+func (c *sqliteCollection) BatchPut(entries []entity.VectorEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	dim := c.index.Dim()
+	for _, entry := range entries {
+		if err := c.checkDimension(entry.Key, entry.Vector, dim); err != nil {
+			return err
+		}
+		if dim == 0 {
+			dim = len(entry.Vector)
+		}
+	}
+
+	tx, err := c.store.db.Begin()
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrTypeStorage, "开启事务失败")
+	}
+	stmt, err := tx.Prepare(upsertVectorSQL)
+	if err != nil {
+		tx.Rollback()
+		return apperrors.Wrap(err, apperrors.ErrTypeStorage, "准备语句失败")
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, entry := range entries {
+		if _, err := stmt.Exec(
+			c.name, entry.Key, encodeVector(entry.Vector), len(entry.Vector), nullableJSON(entry.Metadata), now,
+		); err != nil {
+			tx.Rollback()
+			return apperrors.Wrap(err, apperrors.ErrTypeStorage, "批量写入向量失败")
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrTypeStorage, "提交事务失败")
+	}
+
+	for _, entry := range entries {
+		c.indexEntry(entry.Key, entry.Vector)
+	}
+	return nil
+}
+
+// Get 获取向量
+func (c *sqliteCollection) Get(key string) (*entity.VectorEntry, error) {
+	entries, err := c.query(`WHERE collection = ? AND key = ?`, c.name, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("key not found: %s", key)
+	}
+	return &entries[0], nil
+}
+
+// Delete 删除向量
+func (c *sqliteCollection) Delete(key string) error {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	if _, err := c.store.db.Exec(`DELETE FROM vectors WHERE collection = ? AND key = ?`, c.name, key); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrTypeStorage, "删除向量失败")
+	}
+	c.index.Remove(key)
+	return nil
+}
+
+// Scan 按 key 前缀列出集合中的条目，按 key 排序
+func (c *sqliteCollection) Scan(prefix string) ([]entity.VectorEntry, error) {
+	if prefix == "" {
+		return c.query(`WHERE collection = ? ORDER BY key`, c.name)
+	}
+	return c.query(`WHERE collection = ? AND substr(key, 1, length(?)) = ? ORDER BY key`, c.name, prefix, prefix)
+}
+
+// Search 搜索最相似的向量
+func (c *sqliteCollection) Search(queryVec []float64, topN int) ([]entity.VectorEntry, error) {
+	return c.SearchWithThreshold(queryVec, topN, 0)
+}
+
+// SearchWithThreshold 搜索最相似的向量，过滤低于 minScore 的结果
+// 查询向量与集合维度一致时使用 HNSW 索引，否则（如空查询向量）退化为集合内全量扫描
+func (c *sqliteCollection) SearchWithThreshold(queryVec []float64, topN int, minScore float64) ([]entity.VectorEntry, error) {
+	return c.search(queryVec, topN, minScore, c.getEntries, c.vectorEntries)
+}
+
+// getEntries 按 key 读取条目
+func (c *sqliteCollection) getEntries(keys []string) ([]entity.VectorEntry, error) {
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, c.name)
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(keys)), ",")
+	return c.query(`WHERE collection = ? AND key IN (`+placeholders+`)`, args...)
+}
+
+// vectorEntries 读取集合中全部带向量的条目
+func (c *sqliteCollection) vectorEntries() ([]entity.VectorEntry, error) {
+	return c.query(`WHERE collection = ? AND dimension > 0`, c.name)
+}
+
+// query 按条件查询条目，where 以 "WHERE" 开头
+func (c *sqliteCollection) query(where string, args ...interface{}) ([]entity.VectorEntry, error) {
+	rows, err := c.store.db.Query(`SELECT key, vector, metadata FROM vectors `+where, args...)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeStorage, "查询向量失败")
+	}
+	defer rows.Close()
+
+	var entries []entity.VectorEntry
+	for rows.Next() {
+		var (
+			key      string
+			blob     []byte
+			metadata sql.NullString
+		)
+		if err := rows.Scan(&key, &blob, &metadata); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrTypeStorage, "读取向量失败")
+		}
+		entry := entity.VectorEntry{
+			Key:    key,
+			Vector: decodeVector(blob),
+		}
+		if metadata.Valid {
+			entry.Metadata = []byte(metadata.String)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// encodeVector 将向量编码为小端 float64 序列
+func encodeVector(vector []float64) []byte {
+	buf := make([]byte, 8*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint64(buf[i*8:], math.Float64bits(v))
+	}
+	return buf
+}
+
+func decodeVector(blob []byte) []float64 {
+	vector := make([]float64, len(blob)/8)
+	for i := range vector {
+		vector[i] = math.Float64frombits(binary.LittleEndian.Uint64(blob[i*8:]))
+	}
+	return vector
+}
+
+// nullableJSON 空元数据存为 NULL，其余按文本保存以便使用 SQLite 的 JSON 函数查询
+func nullableJSON(metadata []byte) interface{} {
+	if len(metadata) == 0 {
+		return nil
+	}
+	return string(metadata)
+}
@@ -0,0 +1,163 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"mindx/internal/core"
+	"mindx/internal/entity"
+)
+
+func newTestSQLiteStore(t *testing.T) (*SQLiteStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vectors.db")
+	store, err := NewSQLiteStore(path, nil)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, path
+}
+
+func TestSQLiteStore_PutGetDelete(t *testing.T) {
+	store, _ := newTestSQLiteStore(t)
+
+	if err := store.Put("k1", []float64{0.1, -0.2, 0.3}, map[string]string{"name": "test"}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	entry, err := store.Get("k1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(entry.Vector) != 3 || entry.Vector[1] != -0.2 {
+		t.Fatalf("unexpected vector: %v", entry.Vector)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal(entry.Metadata, &decoded); err != nil || decoded["name"] != "test" {
+		t.Fatalf("unexpected metadata: %s, %v", entry.Metadata, err)
+	}
+
+	if err := store.Put("k1", nil, nil); err == nil {
+		t.Fatal("expected error for nil vector")
+	}
+	if err := store.Delete("k1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get("k1"); err == nil {
+		t.Fatal("expected error after delete")
+	}
+}
+
+func TestSQLiteStore_ScanPrefix(t *testing.T) {
+	store, _ := newTestSQLiteStore(t)
+	store.Put("skill_vector:a", []float64{}, nil)
+	store.Put("skill_vector:b", []float64{}, nil)
+	store.Put("skill_stats:a", []float64{}, nil)
+	store.Put("skill_vector%", []float64{}, nil)
+
+	entries, err := store.Scan("skill_vector:")
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Key != "skill_vector:a" || entries[1].Key != "skill_vector:b" {
+		t.Fatalf("unexpected scan result: %v", entries)
+	}
+	if all, _ := store.Scan(""); len(all) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(all))
+	}
+}
+
+func TestSQLiteStore_Search(t *testing.T) {
+	store, _ := newTestSQLiteStore(t)
+	store.BatchPut([]entity.VectorEntry{
+		{Key: "x", Vector: []float64{1, 0, 0}},
+		{Key: "y", Vector: []float64{0, 1, 0}},
+		{Key: "z", Vector: []float64{0.9, 0.1, 0}},
+		{Key: "meta", Vector: []float64{}},
+	})
+
+	results, err := store.SearchWithThreshold([]float64{1, 0, 0}, 5, 0.5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Key != "x" || results[1].Key != "z" {
+		t.Fatalf("unexpected search results: %v", results)
+	}
+
+	// 维度不一致的查询退化为全量扫描
+	results, err = store.Search(nil, 10)
+	if err != nil || len(results) != 3 {
+		t.Fatalf("expected 3 vectors from scan, got %v, %v", results, err)
+	}
+}
+
+func TestSQLiteStore_BatchPutAtomic(t *testing.T) {
+	store, _ := newTestSQLiteStore(t)
+	store.Put("a", []float64{1, 0, 0}, nil)
+
+	err := store.BatchPut([]entity.VectorEntry{
+		{Key: "b", Vector: []float64{0, 1, 0}},
+		{Key: "c", Vector: []float64{0, 1}},
+	})
+	if !errors.Is(err, core.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", err)
+	}
+	if _, err := store.Get("b"); err == nil {
+		t.Fatal("rejected batch should not be partially written")
+	}
+}
+
+func TestSQLiteStore_CollectionsAndReopen(t *testing.T) {
+	store, path := newTestSQLiteStore(t)
+	memories, err := store.Collection(core.CollectionMemories)
+	if err != nil {
+		t.Fatalf("Collection failed: %v", err)
+	}
+	memories.Put("memory_1", []float64{1, 0}, nil)
+	store.Put("memory_1", []float64{0, 1, 0}, nil)
+	store.Close()
+
+	store, err = NewSQLiteStore(path, nil)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+
+	memories, _ = store.Collection(core.CollectionMemories)
+	if memories.Dimension() != 2 || store.Dimension() != 3 {
+		t.Fatalf("unexpected dimensions after reopen: %d, %d", memories.Dimension(), store.Dimension())
+	}
+	results, err := memories.Search([]float64{1, 0}, 5)
+	if err != nil || len(results) != 1 || len(results[0].Vector) != 2 {
+		t.Fatalf("unexpected search results after reopen: %v, %v", results, err)
+	}
+
+	// 数据可直接通过 SQL 查询
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM vectors WHERE collection = ?`, core.CollectionMemories).Scan(&count); err != nil || count != 1 {
+		t.Fatalf("expected 1 row in memories collection, got %d, %v", count, err)
+	}
+}
+
+func TestNewStore_SQLite(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore("sqlite", dir, nil)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer store.Close()
+	if _, ok := store.(*SQLiteStore); !ok {
+		t.Fatalf("expected *SQLiteStore, got %T", store)
+	}
+	if err := store.Put("k", []float64{1}, nil); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+}
@@ -1,15 +1,20 @@
 package persistence
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"mindx/internal/core"
 	"mindx/internal/entity"
+	apperrors "mindx/internal/errors"
 	"mindx/internal/utils"
 )
 
+// sqliteVectorsFile dbPath 为目录时 SQLite 向量库的文件名
+const sqliteVectorsFile = "vectors.db"
+
 // createDirectoryIfNotExists 创建目录（如果不存在）
 func createDirectoryIfNotExists(dbPath string) error {
 	if dbPath == "" {
@@ -35,6 +40,15 @@ func NewStore(storeType string, dbPath string, provider core.EmbeddingProvider)
 		}
 
 		return NewBadgerStore(dbPath, provider)
+	case "sqlite":
+		if dbPath == "" {
+			dbPath = filepath.Join("data", "vectors")
+		}
+		if filepath.Ext(dbPath) == "" {
+			dbPath = filepath.Join(dbPath, sqliteVectorsFile)
+		}
+
+		return NewSQLiteStore(dbPath, provider)
 	case "memory":
 		fallthrough
 	default:
@@ -42,6 +56,24 @@ func NewStore(storeType string, dbPath string, provider core.EmbeddingProvider)
 	}
 }
 
+// marshalMetadata 将元数据序列化为 JSON，[]byte 与 json.RawMessage 原样保留
+func marshalMetadata(metadata interface{}) ([]byte, error) {
+	switch m := metadata.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return m, nil
+	case json.RawMessage:
+		return m, nil
+	default:
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrTypeStorage, "failed to marshal metadata")
+		}
+		return data, nil
+	}
+}
+
 // VectorService 向量相似度计算服务
 type VectorService struct{}
 
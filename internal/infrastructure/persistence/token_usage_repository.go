@@ -48,6 +48,7 @@ func createTokenUsageTable(db *sql.DB) error {
 	CREATE TABLE IF NOT EXISTS token_usage (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		model TEXT NOT NULL,
+		requested_model TEXT NOT NULL DEFAULT '',
 		duration INTEGER NOT NULL,
 		completion_tokens INTEGER NOT NULL,
 		total_tokens INTEGER NOT NULL,
@@ -59,19 +60,54 @@ func createTokenUsageTable(db *sql.DB) error {
 	CREATE INDEX IF NOT EXISTS idx_created_at ON token_usage(created_at);
 	`
 
-	_, err := db.Exec(query)
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	return addRequestedModelColumn(db)
+}
+
+// addRequestedModelColumn 为旧版本创建的表补充 requested_model 列
+func addRequestedModelColumn(db *sql.DB) error {
+	rows, err := db.Query("PRAGMA table_info(token_usage)")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == "requested_model" {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec("ALTER TABLE token_usage ADD COLUMN requested_model TEXT NOT NULL DEFAULT ''")
 	return err
 }
 
 // Save 保存 Token 使用记录
 func (r *SQLiteTokenUsageRepository) Save(usage *entity.TokenUsage) error {
 	query := `
-	INSERT INTO token_usage (model, duration, completion_tokens, total_tokens, prompt_tokens, created_at)
-	VALUES (?, ?, ?, ?, ?, ?)
+	INSERT INTO token_usage (model, requested_model, duration, completion_tokens, total_tokens, prompt_tokens, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.Exec(query,
 		usage.Model,
+		usage.RequestedModel,
 		usage.Duration,
 		usage.CompletionTokens,
 		usage.TotalTokens,
@@ -94,7 +130,7 @@ func (r *SQLiteTokenUsageRepository) Save(usage *entity.TokenUsage) error {
 // GetByID 根据 ID 获取记录
 func (r *SQLiteTokenUsageRepository) GetByID(id int) (*entity.TokenUsage, error) {
 	query := `
-	SELECT id, model, duration, completion_tokens, total_tokens, prompt_tokens, created_at
+	SELECT id, model, requested_model, duration, completion_tokens, total_tokens, prompt_tokens, created_at
 	FROM token_usage
 	WHERE id = ?
 	`
@@ -103,6 +139,7 @@ func (r *SQLiteTokenUsageRepository) GetByID(id int) (*entity.TokenUsage, error)
 	err := r.db.QueryRow(query, id).Scan(
 		&usage.ID,
 		&usage.Model,
+		&usage.RequestedModel,
 		&usage.Duration,
 		&usage.CompletionTokens,
 		&usage.TotalTokens,
@@ -119,7 +156,7 @@ func (r *SQLiteTokenUsageRepository) GetByID(id int) (*entity.TokenUsage, error)
 // GetByModel 根据模型名称获取记录
 func (r *SQLiteTokenUsageRepository) GetByModel(model string, limit int) ([]*entity.TokenUsage, error) {
 	query := `
-	SELECT id, model, duration, completion_tokens, total_tokens, prompt_tokens, created_at
+	SELECT id, model, requested_model, duration, completion_tokens, total_tokens, prompt_tokens, created_at
 	FROM token_usage
 	WHERE model = ?
 	ORDER BY created_at DESC
@@ -142,6 +179,7 @@ func (r *SQLiteTokenUsageRepository) GetByModel(model string, limit int) ([]*ent
 		if err := rows.Scan(
 			&usage.ID,
 			&usage.Model,
+			&usage.RequestedModel,
 			&usage.Duration,
 			&usage.CompletionTokens,
 			&usage.TotalTokens,
@@ -159,7 +197,7 @@ func (r *SQLiteTokenUsageRepository) GetByModel(model string, limit int) ([]*ent
 // GetByTimeRange 根据时间范围获取记录
 func (r *SQLiteTokenUsageRepository) GetByTimeRange(start, end time.Time) ([]*entity.TokenUsage, error) {
 	query := `
-	SELECT id, model, duration, completion_tokens, total_tokens, prompt_tokens, created_at
+	SELECT id, model, requested_model, duration, completion_tokens, total_tokens, prompt_tokens, created_at
 	FROM token_usage
 	WHERE created_at >= ? AND created_at <= ?
 	ORDER BY created_at DESC
@@ -177,6 +215,7 @@ func (r *SQLiteTokenUsageRepository) GetByTimeRange(start, end time.Time) ([]*en
 		if err := rows.Scan(
 			&usage.ID,
 			&usage.Model,
+			&usage.RequestedModel,
 			&usage.Duration,
 			&usage.CompletionTokens,
 			&usage.TotalTokens,
@@ -1,6 +1,7 @@
 package kernel
 
 import (
+	"context"
 	"fmt"
 	"mindx/internal/config"
 	"mindx/internal/core"
@@ -174,7 +175,7 @@ func TestAllSkills(t *testing.T) {
 				Timeout:  60,
 			}
 
-			resp, err := brain.Post(req)
+			resp, err := brain.Post(context.Background(), req)
 			if err != nil {
 				t.Logf("Brain.Post 失败: %v", err)
 				results[tc.Name] = false
@@ -256,7 +257,7 @@ func TestSkillDetail(t *testing.T) {
 				Timeout:  60,
 			}
 
-			resp, err := brain.Post(req)
+			resp, err := brain.Post(context.Background(), req)
 			if err != nil {
 				t.Logf("错误: %v", err)
 				return
@@ -1,6 +1,7 @@
 package kernel
 
 import (
+	"context"
 	"mindx/internal/config"
 	"mindx/internal/core"
 	"mindx/internal/infrastructure/bootstrap"
@@ -98,7 +99,7 @@ indexed:
 			Timeout:  60,
 		}
 
-		resp, err := brain.Post(req)
+		resp, err := brain.Post(context.Background(), req)
 		if err != nil {
 			t.Logf("错误: %v", err)
 			continue
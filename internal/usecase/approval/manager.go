@@ -0,0 +1,333 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mindx/internal/config"
+	"mindx/internal/entity"
+	"mindx/pkg/logging"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Decision 审批结果
+type Decision string
+
+const (
+	DecisionApproved Decision = "approved"
+	DecisionDenied   Decision = "denied"
+	DecisionTimeout  Decision = "timeout"
+	DecisionCanceled Decision = "canceled"
+	// DecisionUnreachable 确认提示无法送达任何用户（如 API 调用、无人接收的会话），立即拒绝而不是等待超时
+	DecisionUnreachable Decision = "unreachable"
+)
+
+// Request 等待用户确认的工具调用
+type Request struct {
+	ID        string            `json:"id"`
+	Skill     string            `json:"skill"`
+	Arguments map[string]any    `json:"arguments"`
+	Session   entity.SessionKey `json:"session"`
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type rule struct {
+	skill string
+	args  *regexp.Regexp
+}
+
+type pendingRequest struct {
+	req  *Request
+	done chan resolution
+}
+
+type resolution struct {
+	decision Decision
+	operator string
+}
+
+// Manager 工具调用审批管理器
+// 负责判断工具调用是否需要确认、挂起调用等待用户决定，并记录审计日志
+type Manager struct {
+	rules   []rule
+	timeout time.Duration
+	audit   *AuditLog
+	logger  logging.Logger
+
+	mu       sync.Mutex
+	pending  map[string]*pendingRequest
+	notifier func(req *Request) bool
+}
+
+// NewManager 创建审批管理器
+// auditPath 为审计日志（JSONL）路径，为空时只写系统日志
+func NewManager(cfg config.ApprovalConfig, auditPath string, logger logging.Logger) (*Manager, error) {
+	rules := make([]rule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		if r.Skill == "" {
+			return nil, fmt.Errorf("approval rule requires skill")
+		}
+		if _, err := path.Match(r.Skill, ""); err != nil {
+			return nil, fmt.Errorf("invalid approval rule skill pattern %q: %w", r.Skill, err)
+		}
+		compiled := rule{skill: r.Skill}
+		if r.Args != "" {
+			re, err := regexp.Compile(r.Args)
+			if err != nil {
+				return nil, fmt.Errorf("invalid approval rule args pattern %q: %w", r.Args, err)
+			}
+			compiled.args = re
+		}
+		rules = append(rules, compiled)
+	}
+
+	return &Manager{
+		rules:   rules,
+		timeout: cfg.GetTimeout(),
+		audit:   NewAuditLog(auditPath),
+		logger:  logger.Named("Approval"),
+		pending: make(map[string]*pendingRequest),
+	}, nil
+}
+
+// SetNotifier 设置新审批请求的通知回调（如向 IM 会话发送确认提示），返回提示是否已送达用户
+func (m *Manager) SetNotifier(fn func(req *Request) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifier = fn
+}
+
+// RequiresApproval 判断工具调用是否需要用户确认
+// 技能声明了 requires_approval、命中配置规则，或 terminal 以 dangerous=true 执行时需要确认
+// （dangerous 参数由模型自行填写，不能单独作为执行破坏性命令的依据）
+func (m *Manager) RequiresApproval(name string, def *entity.SkillDef, args map[string]any) bool {
+	if def != nil && def.RequiresApproval {
+		return true
+	}
+	if name == "terminal" && isTruthy(args["dangerous"]) {
+		return true
+	}
+
+	if len(m.rules) == 0 {
+		return false
+	}
+	argsJSON, _ := json.Marshal(args)
+	for _, r := range m.rules {
+		if matched, _ := path.Match(r.skill, name); !matched {
+			continue
+		}
+		if r.args == nil || r.args.Match(argsJSON) {
+			return true
+		}
+	}
+	return false
+}
+
+// Request 挂起工具调用，等待用户确认
+// onPending 在请求创建后调用，用于推送确认提示，返回提示是否已送达用户；
+// onPending 和通知回调都未送达时无人可以确认，立即返回 DecisionUnreachable
+// 超时视为拒绝，ctx 取消时返回 DecisionCanceled
+func (m *Manager) Request(ctx context.Context, session entity.SessionKey, skill string, args map[string]any, onPending func(req *Request) bool) Decision {
+	now := time.Now()
+	req := &Request{
+		ID:        uuid.New().String(),
+		Skill:     skill,
+		Arguments: args,
+		Session:   session,
+		CreatedAt: now,
+		ExpiresAt: now.Add(m.timeout),
+	}
+	p := &pendingRequest{req: req, done: make(chan resolution, 1)}
+
+	m.mu.Lock()
+	m.pending[req.ID] = p
+	notifier := m.notifier
+	m.mu.Unlock()
+
+	m.logger.Info("工具调用等待确认",
+		logging.String("id", req.ID),
+		logging.String("skill", skill),
+		logging.String("session", session.String()))
+
+	delivered := false
+	if onPending != nil && onPending(req) {
+		delivered = true
+	}
+	if notifier != nil && notifier(req) {
+		delivered = true
+	}
+
+	var res resolution
+	if !delivered {
+		m.logger.Warn("工具调用确认无法送达用户，直接拒绝",
+			logging.String("id", req.ID),
+			logging.String("skill", skill),
+			logging.String("session", session.String()))
+		res = resolution{decision: DecisionUnreachable}
+	} else {
+		timer := time.NewTimer(m.timeout)
+		defer timer.Stop()
+
+		select {
+		case res = <-p.done:
+		case <-timer.C:
+			res = resolution{decision: DecisionTimeout}
+		case <-ctx.Done():
+			res = resolution{decision: DecisionCanceled}
+		}
+	}
+
+	m.mu.Lock()
+	delete(m.pending, req.ID)
+	m.mu.Unlock()
+
+	m.record(req, res)
+	return res.decision
+}
+
+// Deny 拒绝无人可以确认的工具调用（如外部 MCP 客户端经 MCP server 发起的调用），并记录审计日志
+func (m *Manager) Deny(session entity.SessionKey, skill string, args map[string]any, operator string) {
+	now := time.Now()
+	req := &Request{
+		ID:        uuid.New().String(),
+		Skill:     skill,
+		Arguments: args,
+		Session:   session,
+		CreatedAt: now,
+		ExpiresAt: now,
+	}
+	m.record(req, resolution{decision: DecisionDenied, operator: operator})
+}
+
+// Resolve 按请求 ID 作出决定
+func (m *Manager) Resolve(id string, approved bool, operator string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.pending[id]
+	if !ok {
+		return fmt.Errorf("approval request not found: %s", id)
+	}
+	m.resolveLocked(p, approved, operator)
+	return nil
+}
+
+// ResolveForSession 在会话范围内作出决定
+// id 为空时处理该会话最早的待确认请求；id 不为空时要求请求属于该会话
+func (m *Manager) ResolveForSession(session entity.SessionKey, id string, approved bool, operator string) (*Request, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var target *pendingRequest
+	if id != "" {
+		p, ok := m.pending[id]
+		if !ok || p.req.Session != session {
+			return nil, fmt.Errorf("approval request not found: %s", id)
+		}
+		target = p
+	} else {
+		for _, p := range m.pending {
+			if p.req.Session == session && (target == nil || p.req.CreatedAt.Before(target.req.CreatedAt)) {
+				target = p
+			}
+		}
+		if target == nil {
+			return nil, fmt.Errorf("no pending approval for session %s", session.String())
+		}
+	}
+
+	m.resolveLocked(target, approved, operator)
+	return target.req, nil
+}
+
+func (m *Manager) resolveLocked(p *pendingRequest, approved bool, operator string) {
+	decision := DecisionDenied
+	if approved {
+		decision = DecisionApproved
+	}
+	select {
+	case p.done <- resolution{decision: decision, operator: operator}:
+	default:
+		// 已有决定，忽略重复提交
+	}
+}
+
+// Pending 列出所有待确认的请求（按创建时间排序）
+func (m *Manager) Pending() []*Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]*Request, 0, len(m.pending))
+	for _, p := range m.pending {
+		result = append(result, p.req)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// HasPending 会话是否有待确认的请求
+func (m *Manager) HasPending(session entity.SessionKey) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.pending {
+		if p.req.Session == session {
+			return true
+		}
+	}
+	return false
+}
+
+// Audit 返回审计日志
+func (m *Manager) Audit() *AuditLog {
+	return m.audit
+}
+
+func (m *Manager) record(req *Request, res resolution) {
+	m.logger.Info("工具调用确认结果",
+		logging.String("id", req.ID),
+		logging.String("skill", req.Skill),
+		logging.String("session", req.Session.String()),
+		logging.String("decision", string(res.decision)),
+		logging.String("operator", res.operator))
+
+	if err := m.audit.Append(&AuditRecord{
+		Request:   *req,
+		Decision:  res.decision,
+		Operator:  res.operator,
+		DecidedAt: time.Now(),
+	}); err != nil {
+		m.logger.Warn("写入审批审计日志失败", logging.Err(err))
+	}
+}
+
+// ParseReply 识别 IM 会话中的确认回复
+// ok 为 false 表示不是确认回复，应作为普通消息处理
+func ParseReply(text string) (approved bool, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "/approve", "approve", "yes", "y", "同意", "批准", "确认":
+		return true, true
+	case "/deny", "deny", "no", "n", "拒绝", "取消":
+		return false, true
+	}
+	return false, false
+}
+
+func isTruthy(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		return strings.EqualFold(b, "true")
+	}
+	return false
+}
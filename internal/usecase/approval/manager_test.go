@@ -0,0 +1,158 @@
+package approval
+
+import (
+	"context"
+	"mindx/internal/config"
+	"mindx/internal/entity"
+	"mindx/pkg/logging"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T, cfg config.ApprovalConfig) *Manager {
+	t.Helper()
+	mgr, err := NewManager(cfg, filepath.Join(t.TempDir(), "audit.jsonl"), logging.GetSystemLogger().Named("approval_test"))
+	require.NoError(t, err)
+	return mgr
+}
+
+func TestManager_RequiresApproval(t *testing.T) {
+	mgr := newTestManager(t, config.ApprovalConfig{
+		Rules: []config.ApprovalRule{
+			{Skill: "file_*", Args: `"path":"/etc/`},
+			{Skill: "send_email"},
+		},
+	})
+
+	assert.True(t, mgr.RequiresApproval("custom", &entity.SkillDef{RequiresApproval: true}, nil))
+	assert.False(t, mgr.RequiresApproval("custom", &entity.SkillDef{}, nil))
+	assert.True(t, mgr.RequiresApproval("terminal", nil, map[string]any{"dangerous": true}))
+	assert.False(t, mgr.RequiresApproval("terminal", nil, map[string]any{"command": "ls"}))
+	assert.True(t, mgr.RequiresApproval("file_write", nil, map[string]any{"path": "/etc/hosts"}))
+	assert.False(t, mgr.RequiresApproval("file_write", nil, map[string]any{"path": "/tmp/a.txt"}))
+	assert.True(t, mgr.RequiresApproval("send_email", nil, map[string]any{"to": "a@b.c"}))
+}
+
+func TestNewManager_InvalidRule(t *testing.T) {
+	logger := logging.GetSystemLogger().Named("approval_test")
+
+	_, err := NewManager(config.ApprovalConfig{Rules: []config.ApprovalRule{{Args: "x"}}}, "", logger)
+	assert.Error(t, err)
+
+	_, err = NewManager(config.ApprovalConfig{Rules: []config.ApprovalRule{{Skill: "a", Args: "("}}}, "", logger)
+	assert.Error(t, err)
+}
+
+func TestManager_RequestResolve(t *testing.T) {
+	mgr := newTestManager(t, config.ApprovalConfig{})
+	session := entity.NewSessionKey("realtime", "s1")
+
+	var notified *Request
+	mgr.SetNotifier(func(req *Request) bool {
+		notified = req
+		return false
+	})
+
+	pending := make(chan *Request, 1)
+	result := make(chan Decision, 1)
+	go func() {
+		result <- mgr.Request(context.Background(), session, "terminal", map[string]any{"command": "rm -rf build"}, func(req *Request) bool {
+			pending <- req
+			return true
+		})
+	}()
+
+	req := <-pending
+	assert.True(t, mgr.HasPending(session))
+	assert.Len(t, mgr.Pending(), 1)
+
+	// 其他会话不能处理该请求
+	_, err := mgr.ResolveForSession(entity.NewSessionKey("realtime", "s2"), req.ID, true, "other")
+	assert.Error(t, err)
+
+	resolved, err := mgr.ResolveForSession(session, "", true, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, req.ID, resolved.ID)
+	assert.Equal(t, DecisionApproved, <-result)
+	assert.False(t, mgr.HasPending(session))
+	require.NotNil(t, notified)
+	assert.Equal(t, req.ID, notified.ID)
+
+	records, err := mgr.Audit().List(0)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, DecisionApproved, records[0].Decision)
+	assert.Equal(t, "alice", records[0].Operator)
+	assert.Equal(t, "terminal", records[0].Skill)
+}
+
+func TestManager_RequestUnreachable(t *testing.T) {
+	mgr := newTestManager(t, config.ApprovalConfig{Timeout: 60})
+	session := entity.NewSessionKey("openai", "")
+	mgr.SetNotifier(func(req *Request) bool { return false })
+
+	start := time.Now()
+	decision := mgr.Request(context.Background(), session, "send_email", nil, func(req *Request) bool { return false })
+	assert.Equal(t, DecisionUnreachable, decision)
+	assert.Less(t, time.Since(start), time.Second, "无人可以确认时不应等待超时")
+	assert.False(t, mgr.HasPending(session))
+
+	records, err := mgr.Audit().List(0)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, DecisionUnreachable, records[0].Decision)
+}
+
+func TestManager_RequestDenyTimeoutCancel(t *testing.T) {
+	mgr := newTestManager(t, config.ApprovalConfig{Timeout: 1})
+	session := entity.NewSessionKey("qq", "u1")
+
+	decision := mgr.Request(context.Background(), session, "send_email", nil, func(req *Request) bool {
+		require.NoError(t, mgr.Resolve(req.ID, false, "bob"))
+		return true
+	})
+	assert.Equal(t, DecisionDenied, decision)
+
+	delivered := func(*Request) bool { return true }
+	start := time.Now()
+	decision = mgr.Request(context.Background(), session, "send_email", nil, delivered)
+	assert.Equal(t, DecisionTimeout, decision)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	decision = mgr.Request(ctx, session, "send_email", nil, delivered)
+	assert.Equal(t, DecisionCanceled, decision)
+
+	assert.Error(t, mgr.Resolve("missing", true, ""))
+
+	records, err := mgr.Audit().List(2)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, DecisionCanceled, records[0].Decision)
+	assert.Equal(t, DecisionTimeout, records[1].Decision)
+}
+
+func TestParseReply(t *testing.T) {
+	tests := []struct {
+		text     string
+		approved bool
+		ok       bool
+	}{
+		{"/approve", true, true},
+		{" Yes ", true, true},
+		{"同意", true, true},
+		{"/deny", false, true},
+		{"拒绝", false, true},
+		{"帮我查下天气", false, false},
+	}
+	for _, tt := range tests {
+		approved, ok := ParseReply(tt.text)
+		assert.Equal(t, tt.ok, ok, tt.text)
+		assert.Equal(t, tt.approved, approved, tt.text)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"mindx/internal/core"
 	"mindx/internal/entity"
 	apperrors "mindx/internal/errors"
+	"mindx/internal/usecase/approval"
 	"mindx/internal/usecase/cron"
 	"mindx/internal/usecase/skills"
 	"mindx/pkg/i18n"
@@ -28,6 +29,12 @@ type BrainDeps struct {
 	ToolsRequest   core.OnToolsRequest
 	CapRequest     core.OnCapabilityRequest
 	HistoryRequest core.OnHistoryRequest
+	// ResourceRequest 检索外部资源（MCP resources）作为参考上下文，可为 nil
+	ResourceRequest core.OnResourceRequest
+	// PromptRequest 展开 "/名称" 快捷指令（MCP prompts），可为 nil
+	PromptRequest core.OnPromptRequest
+	// Approval 工具调用人工审批，可为 nil（不审批）
+	Approval       *approval.Manager
 	Logger         logging.Logger
 	TokenUsageRepo core.TokenUsageRepository
 	CronScheduler  cron.Scheduler
@@ -47,6 +54,7 @@ type BionicBrain struct {
 	toolsRequest     core.OnToolsRequest
 	capRequest       core.OnCapabilityRequest
 	historyRequest   core.OnHistoryRequest
+	promptRequest    core.OnPromptRequest
 	persona          *core.Persona
 	tokenUsageRepo   core.TokenUsageRepository
 	cronScheduler    cron.Scheduler
@@ -85,8 +93,10 @@ func NewBrain(deps BrainDeps) (*core.Brain, error) {
 	lbrain := NewThinking(leftModel, leftBrainPrompt, logger, tokenUsageRepo, &cfg.TokenBudget)
 	rbrain := NewThinking(rightModel, "", logger, tokenUsageRepo, &cfg.TokenBudget)
 
-	contextPreparer := NewContextPreparer(memory, historyRequest, logger)
+	contextPreparer := NewContextPreparer(memory, historyRequest, deps.ResourceRequest, logger)
 	toolCaller := NewToolCaller(skillMgr, logger)
+	toolCaller.SetApproval(deps.Approval)
+	toolCaller.SetExecConfig(cfg.ToolCall)
 	consciousnessMgr := NewConsciousnessManager(cfg, persona, tokenUsageRepo, logger)
 	responseBuilder := NewResponseBuilder()
 	fallbackHandler := NewFallbackHandler(rbrain, toolCaller, responseBuilder, logger)
@@ -105,17 +115,17 @@ func NewBrain(deps BrainDeps) (*core.Brain, error) {
 		toolsRequest:     toolsRequest,
 		capRequest:       capRequest,
 		historyRequest:   historyRequest,
+		promptRequest:    deps.PromptRequest,
 		persona:          persona,
 		tokenUsageRepo:   tokenUsageRepo,
 		cronScheduler:    cronScheduler,
 	}
 
 	brain := &core.Brain{
-		LeftBrain:     impl.leftBrain,
-		RightBrain:    impl.rightBrain,
-		Consciousness: impl.consciousnessMgr.Get(),
-		GetMemory:     impl.getMemory,
-		Post:          impl.post,
+		LeftBrain:  impl.leftBrain,
+		RightBrain: impl.rightBrain,
+		GetMemory:  impl.getMemory,
+		Post:       impl.post,
 	}
 
 	impl.brain = brain
@@ -130,34 +140,49 @@ func NewBrain(deps BrainDeps) (*core.Brain, error) {
 	return brain, nil
 }
 
-func (b *BionicBrain) post(req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
+func (b *BionicBrain) post(ctx context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
 	b.logger.Info(i18n.T("brain.start_process"), logging.String(i18n.T("brain.question"), req.Question))
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
+	ctx, state := withRequestState(ctx, req)
+	resp, err := b.think(ctx, req)
+	if resp != nil {
+		resp.Attachments = state.items()
+	}
+	return resp, err
+}
+
+func (b *BionicBrain) think(ctx context.Context, req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
 	question := req.Question
 	capabilityName, actualQuestion := b.parseCapabilityPrefix(question)
 	if capabilityName != "" {
+		if resp, handled, err := b.handlePromptShortcut(ctx, req, capabilityName, actualQuestion); handled {
+			return resp, err
+		}
 		b.logger.Info("检测到能力前缀，使用指定能力",
 			logging.String("capability", capabilityName),
 			logging.String("question", actualQuestion))
 		return b.handleWithConsciousness(ctx, req, capabilityName, actualQuestion)
 	}
 
-	pctx, err := b.contextPreparer.Prepare(req.Question, b.leftBrain)
+	if req.Model != "" {
+		b.logger.Info("请求指定模型，使用主意识直接回答", logging.String("model", req.Model))
+		return b.handleWithCapability(ctx, req, modelCapability(req.Model), question)
+	}
+
+	pctx, err := b.contextPreparer.Prepare(ctx, req.Question, req.SessionKey(), req.MemoryScope(), req.History, b.leftBrain)
 	if err != nil {
 		return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "failed to prepare context")
 	}
+	pctx.refs = withSystemPrompt(pctx.refs, req.SystemPrompt)
 
 	eventChan := req.EventChan
 
-	b.leftBrain.SetEventChan(eventChan)
-
 	thinkResult, err := b.leftBrain.Think(ctx, req.Question, pctx.historyDialogue, pctx.refs, true)
 
 	if err != nil {
-		b.leftBrain.SetEventChan(nil)
 		b.logger.Error(i18n.T("brain.left_think_failed"), logging.Err(err))
 		return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "left brain think failed")
 	}
@@ -176,10 +201,14 @@ func (b *BionicBrain) post(req *core.ThinkingRequest) (*core.ThinkingResponse, e
 			logging.String("message", thinkResult.ScheduleMessage))
 
 		if b.cronScheduler != nil {
+			// 记录发起请求的 Channel、会话和发送者，任务以发起者身份执行，结果发送回原会话
 			job := &cron.Job{
-				Name:    thinkResult.ScheduleName,
-				Cron:    thinkResult.ScheduleCron,
-				Message: thinkResult.ScheduleMessage,
+				Name:      thinkResult.ScheduleName,
+				Cron:      thinkResult.ScheduleCron,
+				Message:   thinkResult.ScheduleMessage,
+				Channel:   req.ChannelID,
+				SessionID: req.SessionID,
+				SenderID:  req.SenderID,
 			}
 
 			id, err := b.cronScheduler.Add(job)
@@ -195,7 +224,6 @@ func (b *BionicBrain) post(req *core.ThinkingRequest) (*core.ThinkingResponse, e
 			thinkResult.Answer = "抱歉，当前系统不支持定时任务功能。"
 		}
 
-		b.leftBrain.SetEventChan(nil)
 		return b.responseBuilder.BuildLeftBrainResponse(thinkResult, nil), nil
 	}
 
@@ -210,7 +238,6 @@ func (b *BionicBrain) post(req *core.ThinkingRequest) (*core.ThinkingResponse, e
 	if !thinkResult.Useless || hasValidIntent {
 		answer, tools, searchedTools := b.tryRightBrainProcess(ctx, req.Question, thinkResult, pctx.historyDialogue, req.SessionID, eventChan)
 		if answer != "" {
-			b.leftBrain.SetEventChan(nil)
 			return b.responseBuilder.BuildToolCallResponse(answer, tools, thinkResult.SendTo), nil
 		} else {
 			b.logger.Info(i18n.T("brain.right_no_result"))
@@ -222,17 +249,14 @@ func (b *BionicBrain) post(req *core.ThinkingRequest) (*core.ThinkingResponse, e
 		b.logger.Info("右脑找到工具但调用失败，用现有右脑重试",
 			logging.Int("searched_tools", len(leftBrainSearchedTools)))
 		resp, err := b.fallbackHandler.Handle(ctx, req.Question, thinkResult, pctx.historyDialogue, leftBrainSearchedTools)
-		b.leftBrain.SetEventChan(nil)
 		return resp, err
 	}
 
 	if !thinkResult.CanAnswer {
 		resp, err := b.activateConsciousness(ctx, req.Question, thinkResult, pctx.refs, pctx.historyDialogue, nil, req.SessionID, eventChan)
-		b.leftBrain.SetEventChan(nil)
 		return resp, err
 	}
 
-	b.leftBrain.SetEventChan(nil)
 	return b.responseBuilder.BuildLeftBrainResponse(thinkResult, nil), nil
 }
 
@@ -256,7 +280,7 @@ func (b *BionicBrain) tryRightBrainProcess(ctx context.Context, question string,
 		logging.String(i18n.T("brain.keywords"), fmt.Sprintf("%v", thinkResult.Keywords)),
 		logging.String("search_keywords", fmt.Sprintf("%v", searchKeywords)))
 
-	tools, err := b.toolsRequest(searchKeywords...)
+	tools, err := b.toolsRequest(ctx, searchKeywords...)
 	if err != nil {
 		b.logger.Warn("[右脑] 工具搜索失败", logging.Err(err))
 		return "", nil, nil
@@ -292,9 +316,7 @@ func (b *BionicBrain) tryRightBrainProcess(ctx context.Context, question string,
 		logging.String(i18n.T("brain.matched_tools"), fmt.Sprintf("%v", toolNames)),
 		logging.Int(i18n.T("brain.tools_count"), len(tools)))
 
-	b.rightBrain.SetEventChan(eventChan)
 	answer, err := b.toolCaller.ExecuteToolCall(ctx, b.rightBrain, question, historyDialogue, tools)
-	b.rightBrain.SetEventChan(nil)
 
 	if err != nil {
 		b.logger.Warn(i18n.T("brain.right_tool_call_failed"), logging.Err(err))
@@ -318,26 +340,17 @@ func (b *BionicBrain) activateConsciousness(ctx context.Context, question string
 	capability, err := b.capRequest(thinkResult.Intent)
 	if err == nil && capability != nil {
 		b.logger.Info(i18n.T("brain.found_capability"), logging.String("capability", capability.Name))
-		return b.activateConsciousnessWithCapability(ctx, question, thinkResult, refs, historyDialogue, leftBrainSearchedTools, sessionID, eventChan, capability)
+		return b.activateConsciousnessWithCapability(ctx, question, thinkResult, refs, historyDialogue, leftBrainSearchedTools, capability)
 	}
 
 	b.logger.Info(i18n.T("brain.no_capability_found"), logging.String("intent", thinkResult.Intent))
 	return b.activateConsciousnessDualBrain(ctx, question, refs, historyDialogue, eventChan)
 }
 
-func (b *BionicBrain) activateConsciousnessWithCapability(ctx context.Context, question string, thinkResult *core.ThinkingResult, refs string, historyDialogue []*core.DialogueMessage, leftBrainSearchedTools []*core.ToolSchema, sessionID string, eventChan chan<- ThinkingEvent, capability *entity.Capability) (*core.ThinkingResponse, error) {
-	if b.consciousnessMgr.IsNil() {
-		b.consciousnessMgr.Create(capability)
-	}
-
-	if b.consciousnessMgr.IsNil() {
-		b.logger.Warn(i18n.T("brain.consciousness_create_failed"))
-		return b.fallbackHandler.Handle(ctx, question, thinkResult, historyDialogue, leftBrainSearchedTools)
-	}
-
+func (b *BionicBrain) activateConsciousnessWithCapability(ctx context.Context, question string, thinkResult *core.ThinkingResult, refs string, historyDialogue []*core.DialogueMessage, leftBrainSearchedTools []*core.ToolSchema, capability *entity.Capability) (*core.ThinkingResponse, error) {
 	var tools []*core.ToolSchema
 	if len(capability.Tools) > 0 {
-		tools, err := b.toolsRequest(capability.Tools...)
+		tools, err := b.toolsRequest(ctx, capability.Tools...)
 		if err != nil {
 			b.logger.Warn(i18n.T("brain.get_consciousness_tool_failed"), logging.Err(err))
 			tools = make([]*core.ToolSchema, 0)
@@ -372,7 +385,7 @@ func (b *BionicBrain) activateConsciousnessWithCapability(ctx context.Context, q
 	}
 
 	if len(tools) > 0 {
-		resp, err := b.consciousnessWithTools(ctx, question, thinkResult, historyDialogue, tools, eventChan, capability.SystemPrompt)
+		resp, err := b.consciousnessWithTools(ctx, capability, question, thinkResult, historyDialogue, tools)
 		if err != nil {
 			b.logger.Warn(i18n.T("brain.consciousness_tool_call_failed"), logging.Err(err))
 			return b.fallbackHandler.Handle(ctx, question, thinkResult, historyDialogue, leftBrainSearchedTools)
@@ -380,9 +393,7 @@ func (b *BionicBrain) activateConsciousnessWithCapability(ctx context.Context, q
 		return resp, nil
 	}
 
-	b.consciousnessMgr.Get().SetEventChan(eventChan)
-	result, err := b.consciousnessMgr.Think(ctx, question, historyDialogue, refs)
-	b.consciousnessMgr.Get().SetEventChan(nil)
+	result, err := b.consciousnessMgr.Think(ctx, capability, question, historyDialogue, refs)
 
 	if err != nil {
 		b.logger.Warn(i18n.T("brain.consciousness_think_failed"), logging.Err(err))
@@ -395,22 +406,14 @@ func (b *BionicBrain) activateConsciousnessWithCapability(ctx context.Context, q
 func (b *BionicBrain) activateConsciousnessDualBrain(ctx context.Context, question string, refs string, historyDialogue []*core.DialogueMessage, eventChan chan<- ThinkingEvent) (*core.ThinkingResponse, error) {
 	b.logger.Info(i18n.T("brain.activating_consciousness_dual_brain"))
 
-	if !b.consciousnessMgr.HasDualBrain() {
-		err := b.consciousnessMgr.CreateDualBrain()
-		if err != nil {
-			b.logger.Warn(i18n.T("brain.consciousness_dual_brain_failed"), logging.Err(err))
-			return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "failed to create consciousness dual brain")
-		}
+	leftBrain, rightBrain, err := b.consciousnessMgr.DualBrain()
+	if err != nil {
+		b.logger.Warn(i18n.T("brain.consciousness_dual_brain_failed"), logging.Err(err))
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "failed to create consciousness dual brain")
 	}
 
-	leftBrain := b.consciousnessMgr.GetLeftBrain()
-	rightBrain := b.consciousnessMgr.GetRightBrain()
-
-	leftBrain.SetEventChan(eventChan)
-
 	thinkResult, err := leftBrain.Think(ctx, question, historyDialogue, refs, true)
 	if err != nil {
-		leftBrain.SetEventChan(nil)
 		b.logger.Error(i18n.T("brain.consciousness_left_think_failed"), logging.Err(err))
 		return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "consciousness left brain think failed")
 	}
@@ -422,13 +425,11 @@ func (b *BionicBrain) activateConsciousnessDualBrain(ctx context.Context, questi
 		logging.String(i18n.T("brain.send_to"), thinkResult.SendTo))
 
 	if thinkResult.Useless || thinkResult.Answer != "" {
-		leftBrain.SetEventChan(nil)
 		return b.responseBuilder.BuildLeftBrainResponse(thinkResult, nil), nil
 	}
 
 	hasValidIntent := thinkResult.Intent != "" && len(thinkResult.Keywords) > 0
 	if !hasValidIntent {
-		leftBrain.SetEventChan(nil)
 		return b.responseBuilder.BuildLeftBrainResponse(thinkResult, nil), nil
 	}
 
@@ -438,11 +439,9 @@ func (b *BionicBrain) activateConsciousnessDualBrain(ctx context.Context, questi
 
 	answer, tools, _ := b.tryConsciousnessRightBrainProcess(ctx, question, thinkResult, historyDialogue, eventChan, rightBrain)
 	if answer != "" {
-		leftBrain.SetEventChan(nil)
 		return b.responseBuilder.BuildToolCallResponse(answer, tools, thinkResult.SendTo), nil
 	}
 
-	leftBrain.SetEventChan(nil)
 	// 兜底：如果主意识也无法回答，返回友好提示而非空答案
 	if thinkResult.Answer == "" {
 		thinkResult.Answer = "抱歉，我暂时无法处理这个请求。"
@@ -468,7 +467,7 @@ func (b *BionicBrain) tryConsciousnessRightBrainProcess(ctx context.Context, que
 		logging.String(i18n.T("brain.keywords"), fmt.Sprintf("%v", thinkResult.Keywords)),
 		logging.String("search_keywords", fmt.Sprintf("%v", searchKeywords)))
 
-	tools, err := b.toolsRequest(searchKeywords...)
+	tools, err := b.toolsRequest(ctx, searchKeywords...)
 	if err != nil {
 		b.logger.Warn("[主意识右脑] 工具搜索失败", logging.Err(err))
 		return "", nil, nil
@@ -504,9 +503,7 @@ func (b *BionicBrain) tryConsciousnessRightBrainProcess(ctx context.Context, que
 		logging.String(i18n.T("brain.matched_tools"), fmt.Sprintf("%v", toolNames)),
 		logging.Int(i18n.T("brain.tools_count"), len(tools)))
 
-	rightBrain.SetEventChan(eventChan)
 	answer, err := b.toolCaller.ExecuteToolCall(ctx, rightBrain, question, historyDialogue, tools)
-	rightBrain.SetEventChan(nil)
 
 	if err != nil {
 		b.logger.Warn(i18n.T("brain.right_tool_call_failed"), logging.Err(err))
@@ -516,19 +513,22 @@ func (b *BionicBrain) tryConsciousnessRightBrainProcess(ctx context.Context, que
 	return answer, tools, tools
 }
 
-func (b *BionicBrain) consciousnessWithTools(ctx context.Context, question string, thinkResult *core.ThinkingResult, historyDialogue []*core.DialogueMessage, tools []*core.ToolSchema, eventChan chan<- ThinkingEvent, customSystemPrompt ...string) (*core.ThinkingResponse, error) {
+// consciousnessWithTools 使用能力的主意识调用工具，能力的系统提示作为工具调用的自定义系统提示
+func (b *BionicBrain) consciousnessWithTools(ctx context.Context, capability *entity.Capability, question string, thinkResult *core.ThinkingResult, historyDialogue []*core.DialogueMessage, tools []*core.ToolSchema) (*core.ThinkingResponse, error) {
 	b.logger.Info(i18n.T("brain.consciousness_use_tools"), logging.Int(i18n.T("brain.tools_count"), len(tools)))
 
-	b.consciousnessMgr.Get().SetEventChan(eventChan)
-	answer, err := b.toolCaller.ExecuteToolCall(ctx, b.consciousnessMgr.Get(), question, historyDialogue, tools, customSystemPrompt...)
-	b.consciousnessMgr.Get().SetEventChan(nil)
+	answer, err := b.toolCaller.ExecuteToolCall(ctx, b.consciousnessMgr.Get(capability), question, historyDialogue, tools, capability.SystemPrompt)
 
 	if err != nil {
 		b.logger.Error(i18n.T("brain.consciousness_tool_failed"), logging.Err(err))
 		return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "consciousness tool call failed")
 	}
 
-	return b.responseBuilder.BuildToolCallResponse(answer, tools, thinkResult.SendTo), nil
+	sendTo := ""
+	if thinkResult != nil {
+		sendTo = thinkResult.SendTo
+	}
+	return b.responseBuilder.BuildToolCallResponse(answer, tools, sendTo), nil
 }
 
 func (b *BionicBrain) getMemory() (core.Memory, error) {
@@ -607,15 +607,53 @@ func (b *BionicBrain) parseCapabilityPrefix(question string) (capabilityName, ac
 	return capabilityName, actualQuestion
 }
 
-// TODO: 需要在 SystemPrompt中补充的对技能的使用引导
-func (b *BionicBrain) handleWithConsciousness(ctx context.Context, req *core.ThinkingRequest, capabilityName, actualQuestion string) (*core.ThinkingResponse, error) {
-	pctx, err := b.contextPreparer.Prepare(actualQuestion, b.leftBrain)
+// modelCapability 为直接指定模型的请求构造临时能力，不携带工具和额外的系统提示
+func modelCapability(model string) *entity.Capability {
+	return &entity.Capability{
+		Name:    "model:" + model,
+		Model:   model,
+		Enabled: true,
+	}
+}
+
+// handlePromptShortcut 处理 "/名称 参数" 形式的快捷指令（如 MCP prompts）
+// 快捷指令展开后的最后一条消息作为问题，之前的消息作为历史对话，交给默认模型的主意识回答
+// handled 为 false 表示不是快捷指令，应按能力前缀继续处理
+func (b *BionicBrain) handlePromptShortcut(ctx context.Context, req *core.ThinkingRequest, name, args string) (resp *core.ThinkingResponse, handled bool, err error) {
+	if b.promptRequest == nil {
+		return nil, false, nil
+	}
+
+	messages, found, err := b.promptRequest(name, args)
+	if !found {
+		return nil, false, nil
+	}
 	if err != nil {
-		return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "准备上下文失败")
+		b.logger.Warn("展开快捷指令失败", logging.String("shortcut", name), logging.Err(err))
+		return b.responseBuilder.BuildToolCallResponse(fmt.Sprintf("抱歉，快捷指令 '%s' 执行失败：%v", name, err), nil, ""), true, nil
+	}
+	if len(messages) == 0 {
+		return b.responseBuilder.BuildToolCallResponse(fmt.Sprintf("抱歉，快捷指令 '%s' 没有生成任何内容。", name), nil, ""), true, nil
 	}
 
-	eventChan := req.EventChan
+	b.logger.Info("使用快捷指令", logging.String("shortcut", name), logging.Int("messages", len(messages)))
 
+	promptReq := *req
+	if prefix := messages[:len(messages)-1]; len(prefix) > 0 {
+		promptReq.History = append(append([]*core.DialogueMessage{}, req.History...), prefix...)
+	}
+
+	capability := &entity.Capability{
+		Name:    "prompt:" + name,
+		Model:   config.GetModelsManager().GetDefaultModel(),
+		Enabled: true,
+	}
+	resp, err = b.handleWithCapability(ctx, &promptReq, capability, messages[len(messages)-1].Content)
+	return resp, true, err
+}
+
+// TODO: 需要在 SystemPrompt中补充的对技能的使用引导
+func (b *BionicBrain) handleWithConsciousness(ctx context.Context, req *core.ThinkingRequest, capabilityName, actualQuestion string) (*core.ThinkingResponse, error) {
 	capability, err := b.capRequest(capabilityName)
 	if err != nil {
 		b.logger.Warn("获取能力失败", logging.String("capability", capabilityName), logging.Err(err))
@@ -633,14 +671,20 @@ func (b *BionicBrain) handleWithConsciousness(ctx context.Context, req *core.Thi
 	}
 
 	b.logger.Info("使用指定能力", logging.String("capability", capability.Name))
+	return b.handleWithCapability(ctx, req, capability, actualQuestion)
+}
 
-	if b.consciousnessMgr.IsNil() {
-		b.consciousnessMgr.Create(capability)
+// handleWithCapability 使用给定能力创建的主意识回答问题
+func (b *BionicBrain) handleWithCapability(ctx context.Context, req *core.ThinkingRequest, capability *entity.Capability, actualQuestion string) (*core.ThinkingResponse, error) {
+	pctx, err := b.contextPreparer.Prepare(ctx, actualQuestion, req.SessionKey(), req.MemoryScope(), req.History, b.leftBrain)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "准备上下文失败")
 	}
+	pctx.refs = withSystemPrompt(pctx.refs, req.SystemPrompt)
 
 	var tools []*core.ToolSchema
 	if len(capability.Tools) > 0 {
-		tools, err = b.toolsRequest(capability.Tools...)
+		tools, err = b.toolsRequest(ctx, capability.Tools...)
 		if err != nil {
 			b.logger.Warn(i18n.T("brain.get_consciousness_tool_failed"), logging.Err(err))
 			tools = make([]*core.ToolSchema, 0)
@@ -656,13 +700,11 @@ func (b *BionicBrain) handleWithConsciousness(ctx context.Context, req *core.Thi
 	}
 
 	if len(tools) > 0 {
-		resp, err := b.consciousnessWithTools(ctx, actualQuestion, nil, pctx.historyDialogue, tools, eventChan, capability.SystemPrompt)
+		resp, err := b.consciousnessWithTools(ctx, capability, actualQuestion, nil, pctx.historyDialogue, tools)
 		return resp, err
 	}
 
-	b.consciousnessMgr.Get().SetEventChan(eventChan)
-	result, err := b.consciousnessMgr.Think(ctx, actualQuestion, pctx.historyDialogue, pctx.refs)
-	b.consciousnessMgr.Get().SetEventChan(nil)
+	result, err := b.consciousnessMgr.Think(ctx, capability, actualQuestion, pctx.historyDialogue, pctx.refs)
 
 	if err != nil {
 		b.logger.Warn(i18n.T("brain.consciousness_think_failed"), logging.Err(err))
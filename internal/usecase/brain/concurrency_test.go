@@ -0,0 +1,253 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mindx/internal/adapters/channels"
+	"mindx/internal/config"
+	"mindx/internal/core"
+	"mindx/internal/entity"
+	"mindx/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capabilityPromptPrefix 测试能力系统提示的前缀，模拟服务据此识别请求来自哪个主意识
+const capabilityPromptPrefix = "capability:"
+
+// newEchoModelServer 模拟 OpenAI 兼容的流式对话接口
+// 回答由系统提示中的能力标识和用户问题组成，分多个片段返回，使并发请求的思考流相互交错
+func newEchoModelServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tag := "left"
+		if system := req.Messages[0].Content; strings.HasPrefix(system, capabilityPromptPrefix) {
+			tag = strings.SplitN(strings.TrimPrefix(system, capabilityPromptPrefix), "\n", 2)[0]
+		}
+		question := req.Messages[len(req.Messages)-1].Content
+
+		content, _ := json.Marshal(core.ThinkingResult{
+			Answer:    expectedAnswer(tag, question),
+			Keywords:  []string{},
+			CanAnswer: true,
+		})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for _, chunk := range splitChunks(string(content), 4) {
+			data, _ := json.Marshal(openai.ChatCompletionStreamResponse{
+				Choices: []openai.ChatCompletionStreamChoice{
+					{Delta: openai.ChatCompletionStreamChoiceDelta{Content: chunk}},
+				},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+			time.Sleep(time.Millisecond)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+		flusher.Flush()
+	}))
+}
+
+func expectedAnswer(tag, question string) string {
+	return tag + "|" + question
+}
+
+func splitChunks(s string, n int) []string {
+	size := (len(s) + n - 1) / n
+	chunks := make([]string, 0, n)
+	for start := 0; start < len(s); start += size {
+		end := start + size
+		if end > len(s) {
+			end = len(s)
+		}
+		chunks = append(chunks, s[start:end])
+	}
+	return chunks
+}
+
+type emptyMemory struct{}
+
+func (emptyMemory) Record(core.MemoryPoint) error { return nil }
+func (emptyMemory) Search(context.Context, core.MemoryScope, string) ([]core.MemoryPoint, error) {
+	return nil, nil
+}
+func (emptyMemory) Optimize() error                                     { return nil }
+func (emptyMemory) ClusterConversations([]entity.ConversationLog) error { return nil }
+
+func newStubBrain(t *testing.T, serverURL string, capabilities map[string]*entity.Capability) *core.Brain {
+	t.Helper()
+
+	cfg := &config.GlobalConfig{
+		DefaultModel: "stub",
+		TokenBudget: config.TokenBudgetConfig{
+			ReservedOutputTokens: 1024,
+			MinHistoryRounds:     2,
+			AvgTokensPerRound:    150,
+		},
+	}
+	previous := config.GetModelsManager()
+	config.OverrideModelsManager(config.NewModelsManager(&config.ModelsConfig{
+		Models: []config.ModelConfig{{Name: "stub", BaseURL: serverURL + "/v1", MaxTokens: 8192}},
+	}, cfg))
+	t.Cleanup(func() { config.OverrideModelsManager(previous) })
+
+	b, err := NewBrain(BrainDeps{
+		Cfg:     cfg,
+		Persona: &core.Persona{Name: "小柔"},
+		Memory:  emptyMemory{},
+		ToolsRequest: func(ctx context.Context, keywords ...string) ([]*core.ToolSchema, error) {
+			return nil, nil
+		},
+		CapRequest: func(keywords ...string) (*entity.Capability, error) {
+			return capabilities[keywords[0]], nil
+		},
+		Logger: logging.GetSystemLogger().Named("brain_concurrency_test"),
+	})
+	require.NoError(t, err)
+	return b
+}
+
+// TestBrain_ConcurrentRequests 多个会话经网关并发请求同一个大脑（包括左脑和不同能力的主意识），
+// 每个请求只能收到自己的思考流事件和回答
+func TestBrain_ConcurrentRequests(t *testing.T) {
+	server := newEchoModelServer(t)
+	defer server.Close()
+
+	capabilities := map[string]*entity.Capability{
+		"writer":     {Name: "writer", Model: "stub", SystemPrompt: capabilityPromptPrefix + "writer", Enabled: true},
+		"translator": {Name: "translator", Model: "stub", SystemPrompt: capabilityPromptPrefix + "translator", Enabled: true},
+	}
+	b := newStubBrain(t, server.URL, capabilities)
+
+	gateway := channels.NewGateway("realtime", nil)
+	channel := channels.NewMockChannel("test", entity.ChannelTypeRealTime, "Test")
+	gateway.Manager().AddChannel(channel)
+	require.NoError(t, channel.Start(context.Background()))
+	defer channel.Stop()
+
+	var mu sync.Mutex
+	leaked := make(map[string][]string)
+	gateway.SetOnMessage(func(ctx context.Context, msg *entity.IncomingMessage, _ chan<- entity.ThinkingEvent) (string, string, error) {
+		events := make(chan entity.ThinkingEvent, 256)
+		resp, err := b.Post(ctx, &core.ThinkingRequest{
+			Question:  msg.Content,
+			ChannelID: msg.ChannelID,
+			SessionID: msg.SessionID,
+			EventChan: events,
+		})
+		close(events)
+		if err != nil {
+			return "", "", err
+		}
+
+		// 思考流中的片段和完成事件必须都属于本次请求
+		var chunks strings.Builder
+		for event := range events {
+			switch event.Type {
+			case entity.ThinkingEventChunk:
+				chunks.WriteString(event.Content)
+			case entity.ThinkingEventComplete:
+				if event.Content != resp.Answer {
+					mu.Lock()
+					leaked[msg.SessionID] = append(leaked[msg.SessionID], event.Content)
+					mu.Unlock()
+				}
+			}
+		}
+		var streamed core.ThinkingResult
+		if err := json.Unmarshal([]byte(chunks.String()), &streamed); err != nil || streamed.Answer != resp.Answer {
+			mu.Lock()
+			leaked[msg.SessionID] = append(leaked[msg.SessionID], chunks.String())
+			mu.Unlock()
+		}
+		return resp.Answer, "", nil
+	})
+
+	const sessions = 30
+	expected := make(map[string]string, sessions)
+	var wg sync.WaitGroup
+	for i := 0; i < sessions; i++ {
+		sessionID := fmt.Sprintf("session-%d", i)
+		question := fmt.Sprintf("问题 %d", i)
+		content := question
+		switch i % 3 {
+		case 0:
+			expected[sessionID] = expectedAnswer("left", question)
+		case 1:
+			content = "/writer " + question
+			expected[sessionID] = expectedAnswer("writer", question)
+		case 2:
+			content = "/translator " + question
+			expected[sessionID] = expectedAnswer("translator", question)
+		}
+
+		wg.Add(1)
+		go func(msg *entity.IncomingMessage) {
+			defer wg.Done()
+			gateway.HandleMessage(context.Background(), msg)
+		}(&entity.IncomingMessage{
+			ChannelID: "test",
+			SessionID: sessionID,
+			Sender:    &entity.MessageSender{ID: sessionID, Name: sessionID},
+			Content:   content,
+			Timestamp: time.Now(),
+		})
+	}
+	wg.Wait()
+
+	assert.Empty(t, leaked, "思考流事件不应串到其他请求")
+
+	sent := channel.GetSentMessages()
+	require.Len(t, sent, sessions)
+	for _, msg := range sent {
+		assert.Equal(t, expected[msg.SessionID], msg.Content, "会话 %s 收到了错误的回答", msg.SessionID)
+	}
+}
+
+func TestConsciousnessManager_CachePerCapability(t *testing.T) {
+	cfg := &config.GlobalConfig{DefaultModel: "stub"}
+	previous := config.GetModelsManager()
+	config.OverrideModelsManager(config.NewModelsManager(&config.ModelsConfig{
+		Models: []config.ModelConfig{{Name: "stub", BaseURL: "http://127.0.0.1:0/v1"}},
+	}, cfg))
+	defer config.OverrideModelsManager(previous)
+
+	cm := NewConsciousnessManager(cfg, &core.Persona{}, nil, logging.GetSystemLogger().Named("consciousness_test"))
+	writer := &entity.Capability{Name: "writer", Model: "stub", SystemPrompt: "写作"}
+	translator := &entity.Capability{Name: "translator", Model: "stub", SystemPrompt: "翻译"}
+
+	var wg sync.WaitGroup
+	results := make([]core.Thinking, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = cm.Get(writer)
+		}(i)
+	}
+	wg.Wait()
+	for _, thinking := range results {
+		assert.Same(t, results[0], thinking, "同一能力应共享同一个主意识")
+	}
+
+	assert.NotSame(t, results[0], cm.Get(translator), "不同能力应使用不同的主意识")
+
+	updated := *writer
+	updated.SystemPrompt = "新的写作提示"
+	assert.NotSame(t, results[0], cm.Get(&updated), "能力定义变化后应重新创建主意识")
+}
@@ -8,16 +8,28 @@ import (
 	"mindx/internal/entity"
 	"mindx/pkg/i18n"
 	"mindx/pkg/logging"
+	"sync"
 )
 
+// ConsciousnessManager 管理主意识
+// 每个能力对应一个主意识，创建后不再修改，可被并发的请求共享；能力的模型或系统提示变化时重新创建
 type ConsciousnessManager struct {
 	cfg            *config.GlobalConfig
 	persona        *core.Persona
 	tokenUsageRepo core.TokenUsageRepository
 	logger         logging.Logger
-	consciousness  core.Thinking
-	leftBrain      core.Thinking
-	rightBrain     core.Thinking
+
+	mu            sync.Mutex
+	consciousness map[string]*consciousnessEntry // 能力名称 -> 主意识
+	leftBrain     core.Thinking
+	rightBrain    core.Thinking
+}
+
+// consciousnessEntry 缓存的主意识及创建它时的能力定义，用于判断能力是否已被修改
+type consciousnessEntry struct {
+	model        string
+	systemPrompt string
+	thinking     core.Thinking
 }
 
 func NewConsciousnessManager(
@@ -31,13 +43,11 @@ func NewConsciousnessManager(
 		persona:        persona,
 		tokenUsageRepo: tokenUsageRepo,
 		logger:         logger,
-		consciousness:  nil,
-		leftBrain:      nil,
-		rightBrain:     nil,
+		consciousness:  make(map[string]*consciousnessEntry),
 	}
 }
 
-func (cm *ConsciousnessManager) Create(capability *entity.Capability) {
+func (cm *ConsciousnessManager) create(capability *entity.Capability) core.Thinking {
 	cm.logger.Info(i18n.T("brain.create_consciousness"),
 		logging.String(i18n.T("brain.capability"), capability.Name),
 		logging.String(i18n.T("brain.model"), capability.Model))
@@ -50,16 +60,50 @@ func (cm *ConsciousnessManager) Create(capability *entity.Capability) {
 	modelConfig, err := modelsMgr.GetModel(capability.Model)
 	if err != nil {
 		modelConfig = &config.ModelConfig{
-			Name:    capability.Model,
-			BaseURL: "http://localhost:11434/v1",
+			Name:     capability.Model,
+			Provider: config.ProviderOllama,
+			BaseURL:  "http://localhost:11434/v1",
 		}
 	}
 
-	cm.consciousness = NewThinking(modelConfig, systemPrompt, cm.logger, cm.tokenUsageRepo, &cm.cfg.TokenBudget)
+	thinking := NewThinking(modelConfig, systemPrompt, cm.logger, cm.tokenUsageRepo, &cm.cfg.TokenBudget)
 	cm.logger.Info(i18n.T("brain.consciousness_created"))
+	return thinking
+}
+
+// Get 返回指定能力的主意识，不存在或能力定义已变化时创建
+func (cm *ConsciousnessManager) Get(capability *entity.Capability) core.Thinking {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	entry := cm.consciousness[capability.Name]
+	if entry != nil && entry.model == capability.Model && entry.systemPrompt == capability.SystemPrompt {
+		return entry.thinking
+	}
+
+	entry = &consciousnessEntry{
+		model:        capability.Model,
+		systemPrompt: capability.SystemPrompt,
+		thinking:     cm.create(capability),
+	}
+	cm.consciousness[capability.Name] = entry
+	return entry.thinking
+}
+
+// DualBrain 返回未匹配到能力时使用的主意识左右脑，首次调用时创建
+func (cm *ConsciousnessManager) DualBrain() (leftBrain, rightBrain core.Thinking, err error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if cm.leftBrain == nil || cm.rightBrain == nil {
+		if err := cm.createDualBrain(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return cm.leftBrain, cm.rightBrain, nil
 }
 
-func (cm *ConsciousnessManager) CreateDualBrain() error {
+func (cm *ConsciousnessManager) createDualBrain() error {
 	cm.logger.Info(i18n.T("brain.create_consciousness_dual_brain"))
 
 	modelsMgr := config.GetModelsManager()
@@ -98,32 +142,7 @@ func (cm *ConsciousnessManager) CreateDualBrain() error {
 	return nil
 }
 
-func (cm *ConsciousnessManager) Get() core.Thinking {
-	return cm.consciousness
-}
-
-func (cm *ConsciousnessManager) GetLeftBrain() core.Thinking {
-	return cm.leftBrain
-}
-
-func (cm *ConsciousnessManager) GetRightBrain() core.Thinking {
-	return cm.rightBrain
-}
-
-func (cm *ConsciousnessManager) IsNil() bool {
-	return cm.consciousness == nil && cm.leftBrain == nil
-}
-
-func (cm *ConsciousnessManager) HasDualBrain() bool {
-	return cm.leftBrain != nil && cm.rightBrain != nil
-}
-
-func (cm *ConsciousnessManager) Think(ctx context.Context, question string, historyDialogue []*core.DialogueMessage, refs string) (*core.ThinkingResult, error) {
-	if cm.consciousness != nil {
-		return cm.consciousness.Think(ctx, question, historyDialogue, refs, false)
-	}
-	if cm.leftBrain != nil {
-		return cm.leftBrain.Think(ctx, question, historyDialogue, refs, true)
-	}
-	return nil, fmt.Errorf("consciousness not initialized")
+// Think 使用指定能力的主意识回答问题
+func (cm *ConsciousnessManager) Think(ctx context.Context, capability *entity.Capability, question string, historyDialogue []*core.DialogueMessage, refs string) (*core.ThinkingResult, error) {
+	return cm.Get(capability).Think(ctx, question, historyDialogue, refs, false)
 }
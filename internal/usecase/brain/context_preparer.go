@@ -1,54 +1,80 @@
 package brain
 
 import (
+	"context"
 	"fmt"
 	"mindx/internal/core"
+	"mindx/internal/entity"
 	"mindx/pkg/i18n"
 	"mindx/pkg/logging"
 	"strings"
 )
 
 type ContextPreparer struct {
-	memory         core.Memory
-	historyRequest core.OnHistoryRequest
-	logger         logging.Logger
+	memory          core.Memory
+	historyRequest  core.OnHistoryRequest
+	resourceRequest core.OnResourceRequest
+	logger          logging.Logger
 }
 
-func NewContextPreparer(memory core.Memory, historyRequest core.OnHistoryRequest, logger logging.Logger) *ContextPreparer {
+func NewContextPreparer(memory core.Memory, historyRequest core.OnHistoryRequest, resourceRequest core.OnResourceRequest, logger logging.Logger) *ContextPreparer {
 	return &ContextPreparer{
-		memory:         memory,
-		historyRequest: historyRequest,
-		logger:         logger,
+		memory:          memory,
+		historyRequest:  historyRequest,
+		resourceRequest: resourceRequest,
+		logger:          logger,
 	}
 }
 
-func (cp *ContextPreparer) Prepare(question string, leftBrain core.Thinking) (*processingContext, error) {
-	ctx := &processingContext{
+// Prepare 准备思考所需的上下文（记忆参考、外部资源和历史对话）
+// 只参考 scope 可见的记忆，ctx 取消时中止记忆检索中的重排；history 非 nil 时表示调用方自带历史，直接截取使用而不再查询会话
+func (cp *ContextPreparer) Prepare(ctx context.Context, question string, key entity.SessionKey, scope core.MemoryScope, history []*core.DialogueMessage, leftBrain core.Thinking) (*processingContext, error) {
+	pctx := &processingContext{
 		historyDialogue: make([]*core.DialogueMessage, 0),
 	}
 
-	memories, err := cp.memory.Search(question)
+	memories, err := cp.memory.Search(ctx, scope, question)
 	if err != nil {
 		cp.logger.Warn(i18n.T("brain.get_memory_failed"), logging.Err(err))
 	} else {
 		cp.logger.Debug(i18n.T("brain.get_memory_success"), logging.String("count", fmt.Sprintf("%d", len(memories))))
 	}
 
-	ctx.refs = cp.buildReferencePrompt(memories)
+	pctx.refs = cp.buildReferencePrompt(memories)
 
-	if cp.historyRequest != nil {
+	if cp.resourceRequest != nil {
+		resources, err := cp.resourceRequest(question)
+		if err != nil {
+			cp.logger.Warn("获取外部资源失败", logging.Err(err))
+		} else if len(resources) > 0 {
+			cp.logger.Debug("获取外部资源成功", logging.Int("count", len(resources)))
+			pctx.refs = joinRefs(pctx.refs, cp.buildResourcePrompt(resources))
+		}
+	}
+
+	if history != nil {
+		pctx.historyDialogue = trimHistory(history, leftBrain.CalculateMaxHistoryCount())
+	} else if cp.historyRequest != nil {
 		maxRounds := leftBrain.CalculateMaxHistoryCount()
-		ctx.historyDialogue, err = cp.historyRequest(maxRounds)
+		pctx.historyDialogue, err = cp.historyRequest(key, maxRounds)
 		if err != nil {
 			cp.logger.Warn(i18n.T("brain.get_history_failed"), logging.Err(err))
 		} else {
 			cp.logger.Debug(i18n.T("brain.get_history_success"),
 				logging.Int("max_rounds", maxRounds),
-				logging.Int("actual_count", len(ctx.historyDialogue)))
+				logging.Int("actual_count", len(pctx.historyDialogue)))
 		}
 	}
 
-	return ctx, nil
+	return pctx, nil
+}
+
+// trimHistory 按最大轮数截取最近的历史对话（每轮包含一问一答）
+func trimHistory(history []*core.DialogueMessage, maxRounds int) []*core.DialogueMessage {
+	if maxRounds <= 0 || len(history) <= maxRounds*2 {
+		return history
+	}
+	return history[len(history)-maxRounds*2:]
 }
 
 func (cp *ContextPreparer) buildReferencePrompt(memories []core.MemoryPoint) string {
@@ -63,8 +89,43 @@ func (cp *ContextPreparer) buildReferencePrompt(memories []core.MemoryPoint) str
 		if i > 0 {
 			fmt.Fprintf(&context, "\n")
 		}
-		fmt.Fprintf(&context, "- %s", mem.Summary)
+		// 附上检索相关度，便于模型判断记忆与问题的相关程度
+		if mem.Score > 0 {
+			fmt.Fprintf(&context, "- [相关度 %.2f] %s", mem.Score, mem.Summary)
+		} else {
+			fmt.Fprintf(&context, "- %s", mem.Summary)
+		}
 	}
 
 	return context.String()
 }
+
+// buildResourcePrompt 将检索到的外部资源整理为参考上下文
+func (cp *ContextPreparer) buildResourcePrompt(resources []*core.ResourceContent) string {
+	var context strings.Builder
+	fmt.Fprintf(&context, "# 资源\n")
+
+	for i, res := range resources {
+		if i > 0 {
+			fmt.Fprintf(&context, "\n")
+		}
+		fmt.Fprintf(&context, "## %s (%s)\n%s\n", res.Name, res.URI, res.Text)
+	}
+
+	return context.String()
+}
+
+func joinRefs(refs, more string) string {
+	if refs == "" {
+		return more
+	}
+	return refs + "\n\n" + more
+}
+
+// withSystemPrompt 将调用方提供的系统提示并入参考上下文
+func withSystemPrompt(refs, systemPrompt string) string {
+	if strings.TrimSpace(systemPrompt) == "" {
+		return refs
+	}
+	return joinRefs(refs, "# 调用方指令\n"+strings.TrimSpace(systemPrompt))
+}
@@ -0,0 +1,74 @@
+package brain
+
+import (
+	"context"
+	"mindx/internal/config"
+	"mindx/internal/core"
+	"mindx/internal/entity"
+	"mindx/internal/infrastructure/persistence"
+	"mindx/internal/usecase/modelrouter"
+	"mindx/pkg/logging"
+	"mindx/pkg/retry"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestThinking_FallbackModel 原模型服务不可用时改用降级模型，
+// 思考流中通知已切换模型，token 用量按实际模型记录并保留原本请求的模型
+func TestThinking_FallbackModel(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"overloaded"}}`, http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	backup := newEchoModelServer(t)
+	defer backup.Close()
+
+	cfg := &config.GlobalConfig{DefaultModel: "primary"}
+	previous := config.GetModelsManager()
+	config.OverrideModelsManager(config.NewModelsManager(&config.ModelsConfig{
+		Models: []config.ModelConfig{
+			{Name: "primary", BaseURL: down.URL + "/v1", MaxTokens: 8192, Fallbacks: []string{"backup"}},
+			{Name: "backup", BaseURL: backup.URL + "/v1", MaxTokens: 8192},
+		},
+	}, cfg))
+	defer config.OverrideModelsManager(previous)
+
+	repo, err := persistence.NewSQLiteTokenUsageRepository(filepath.Join(t.TempDir(), "token_usage.db"))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	logger := logging.GetSystemLogger().Named("fallback_test")
+	thinking := NewThinking(config.GetModelsManager().MustGetModel("primary"), "", logger, repo, &cfg.TokenBudget)
+	thinking.router = modelrouter.New(config.ModelHealthConfig{}, logger, modelrouter.WithRetryConfig(retry.Config{
+		InitialWait: time.Millisecond,
+		MaxWait:     time.Millisecond,
+		Retryable:   modelrouter.IsProviderError,
+	}))
+
+	events := make(chan entity.ThinkingEvent, 64)
+	result, err := thinking.Think(core.ContextWithThinkingEvents(context.Background(), events), "你好", nil, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, expectedAnswer("left", "你好"), result.Answer)
+
+	close(events)
+	var fallback *entity.ThinkingEvent
+	for event := range events {
+		if event.Type == entity.ThinkingEventFallback {
+			fallback = &event
+		}
+	}
+	require.NotNil(t, fallback, "应通知已切换到降级模型")
+	assert.Equal(t, "primary", fallback.Metadata["requested_model"])
+	assert.Equal(t, "backup", fallback.Metadata["model"])
+
+	usages, err := repo.GetByModel("backup", 10)
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	assert.Equal(t, "primary", usages[0].RequestedModel)
+}
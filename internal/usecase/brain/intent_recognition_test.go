@@ -771,7 +771,7 @@ func (s *IntentRecognitionSuite) TestIntent_EndToEnd_ToolSearch() {
 					searchKeywords = append(searchKeywords, r.Keywords...)
 				}
 
-				found, err := searcher.Search(searchKeywords...)
+				found, err := searcher.Search(context.Background(), searchKeywords...)
 				if err != nil {
 					return false
 				}
@@ -805,7 +805,7 @@ func (s *IntentRecognitionSuite) TestIntent_EndToEnd_ToolSearch() {
 				searchKeywords = append(searchKeywords, result.Keywords...)
 			}
 
-			found, err := searcher.Search(searchKeywords...)
+			found, err := searcher.Search(context.Background(), searchKeywords...)
 			assert.NoError(s.T(), err)
 
 			foundNames := make([]string, 0, len(found))
@@ -1,6 +1,7 @@
 package brain
 
 import (
+	"context"
 	"fmt"
 	"mindx/internal/core"
 	"mindx/pkg/logging"
@@ -23,7 +24,7 @@ func (s *LongInputSuite) TestLongInput_SingleMessage() {
 	q := "请总结以下内容：\n" + longText
 	s.logger.Info("提问", logging.Int("input_length", len(q)))
 
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: q,
 		Timeout:  60, // 增加超时时间
 	})
@@ -62,7 +63,7 @@ func (s *LongInputSuite) TestLongInput_MultipleMessages() {
 		s.logger.Info("第%d次提问", logging.Int("index", i+1),
 			logging.Int("input_length", len(q)))
 
-		resp, err := s.brain.Post(&core.ThinkingRequest{
+		resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 			Question: q,
 			Timeout:  45,
 		})
@@ -90,7 +91,7 @@ func (s *LongInputSuite) TestLongInput_CodeBlock() {
 	s.logger.Info("提问", logging.Int("input_length", len(q)),
 		logging.Int("code_lines", 5000))
 
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: q,
 		Timeout:  60,
 	})
@@ -124,7 +125,7 @@ func (s *LongInputSuite) TestLongInput_ContextOverflow() {
 	s.logger.Info("提问", logging.Int("input_length", len(q)),
 		logging.String("estimated_tokens", "~40000"))
 
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: q,
 		Timeout:  90,
 	})
@@ -1,6 +1,7 @@
 package brain
 
 import (
+	"context"
 	"mindx/internal/core"
 	"mindx/pkg/logging"
 	"strings"
@@ -19,7 +20,7 @@ type MemoryReferenceSuite struct {
 func (s *MemoryReferenceSuite) TestMemory_ProgrammingPreference() {
 
 	// 验证记忆已经记录（在 SetupSuite 中）
-	memories, err := s.memory.Search("编程")
+	memories, err := s.memory.Search(context.Background(), core.MemoryScope{}, "编程")
 	s.Require().NoError(err)
 	s.GreaterOrEqual(len(memories), 1, "应该有编程相关的记忆")
 
@@ -117,7 +118,7 @@ func (s *MemoryReferenceSuite) TestMemory_UnknownQuestion() {
 	q := "今天天气怎么样？"
 	s.logger.Info("提问", logging.String("question", q))
 
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: q,
 		Timeout:  30,
 	})
@@ -1,13 +1,14 @@
 package brain
 
 import (
+	"context"
 	"mindx/internal/core"
 	"strings"
 )
 
 // TestPost_ChatDirect 闲聊直接回答（不触发工具）
 func (s *BrainIntegrationSuite) TestPost_ChatDirect() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "你好",
 		Timeout:  30,
 	})
@@ -21,7 +22,7 @@ func (s *BrainIntegrationSuite) TestPost_ChatDirect() {
 
 // TestPost_CommonKnowledge 常识问题直接回答
 func (s *BrainIntegrationSuite) TestPost_CommonKnowledge() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "法国的首都是哪里",
 		Timeout:  30,
 	})
@@ -32,7 +33,7 @@ func (s *BrainIntegrationSuite) TestPost_CommonKnowledge() {
 
 // TestPost_ToolExecution_Calculator 完整 post() 流程：计算器工具
 func (s *BrainIntegrationSuite) TestPost_ToolExecution_Calculator() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "帮我算一下 15 乘以 20",
 		Timeout:  60,
 	})
@@ -45,7 +46,7 @@ func (s *BrainIntegrationSuite) TestPost_ToolExecution_Calculator() {
 
 // TestPost_ToolExecution_Weather 完整 post() 流程：天气工具
 func (s *BrainIntegrationSuite) TestPost_ToolExecution_Weather() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "北京今天天气怎么样",
 		Timeout:  60,
 	})
@@ -58,7 +59,7 @@ func (s *BrainIntegrationSuite) TestPost_ToolExecution_Weather() {
 
 // TestPost_ToolExecution_Sysinfo 完整 post() 流程：系统信息工具
 func (s *BrainIntegrationSuite) TestPost_ToolExecution_Sysinfo() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "查看一下系统CPU使用率",
 		Timeout:  60,
 	})
@@ -73,7 +74,7 @@ func (s *BrainIntegrationSuite) TestPost_ToolExecution_Sysinfo() {
 func (s *BrainIntegrationSuite) TestPost_Schedule_Create() {
 	s.cronMock.reset()
 
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "每天早上9点提醒我喝水",
 		Timeout:  60,
 	})
@@ -97,7 +98,7 @@ func (s *BrainIntegrationSuite) TestPost_Schedule_Create() {
 // TestPost_Schedule_Cancel 完整 post() 流程：取消定时任务（预留）
 // brain.go 中 CancelSchedule 处理逻辑尚未实现，此测试验证意图识别
 func (s *BrainIntegrationSuite) TestPost_Schedule_Cancel() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "取消每日喝水提醒",
 		Timeout:  60,
 	})
@@ -109,7 +110,7 @@ func (s *BrainIntegrationSuite) TestPost_Schedule_Cancel() {
 
 // TestPost_SendTo 完整 post() 流程：转发意图
 func (s *BrainIntegrationSuite) TestPost_SendTo() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "帮我把这条消息转发到微信",
 		Timeout:  60,
 	})
@@ -155,7 +156,7 @@ func (s *BrainIntegrationSuite) TestPost_MultiRound() {
 // TestPost_ToolExecution_Contacts 完整 post() 流程：查询联系人电话
 // 验证向量搜索能匹配到 contacts 工具并执行
 func (s *BrainIntegrationSuite) TestPost_ToolExecution_Contacts() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "帮我查李靖文的电话",
 		Timeout:  60,
 	})
@@ -215,7 +216,7 @@ func (s *BrainIntegrationSuite) toolCallHelper(resp *core.ThinkingResponse, expe
 // TestPost_DeepSearchAndWriteFile 深度搜索+写入文件的复合场景
 // 验证：用户要求搜索并保存结果时，应调用 deep_search 工具
 func (s *BrainIntegrationSuite) TestPost_DeepSearchAndWriteFile() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "帮我到网上搜一下go语言如何安装，然后存到文件里",
 		Timeout:  120,
 	})
@@ -230,7 +231,7 @@ func (s *BrainIntegrationSuite) TestPost_DeepSearchAndWriteFile() {
 // TestPost_Reminder 提醒事项场景
 // 验证："明天记得提醒我交水费" 应调用 reminders 或 calendar 工具
 func (s *BrainIntegrationSuite) TestPost_Reminder() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "明天记得提醒我交水费",
 		Timeout:  60,
 	})
@@ -251,7 +252,7 @@ func (s *BrainIntegrationSuite) TestPost_Reminder() {
 // TestPost_PortUsage 端口占用查询场景
 // 验证："我想知道当前机器的端口占用情况" 应调用 sysinfo 或 portcheck 工具
 func (s *BrainIntegrationSuite) TestPost_PortUsage() {
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: "我想知道当前机器的端口占用情况",
 		Timeout:  60,
 	})
@@ -0,0 +1,220 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"mindx/internal/adapters/http/middleware"
+	"mindx/internal/config"
+	"mindx/internal/core"
+	"mindx/internal/usecase/skills"
+	"mindx/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolCaller_AnthropicProvider 模型使用 Anthropic Messages API 时，
+// tool_use 和 tool_result 内容块经 Thinking 转换后，ToolCaller 无需改动即可完成工具调用
+func TestToolCaller_AnthropicProvider(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content []struct {
+					Type      string `json:"type"`
+					ToolUseID string `json:"tool_use_id"`
+					Content   string `json:"content"`
+				} `json:"content"`
+			} `json:"messages"`
+			Tools []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.NotEmpty(t, req.Tools)
+		assert.Equal(t, "quick", req.Tools[0].Name)
+
+		if requests.Add(1) == 1 {
+			_, _ = w.Write([]byte(`{"id":"msg_1","stop_reason":"tool_use","content":[
+				{"type":"tool_use","id":"toolu_1","name":"quick","input":{"city":"北京"}}
+			],"usage":{"input_tokens":10,"output_tokens":5}}`))
+			return
+		}
+
+		last := req.Messages[len(req.Messages)-1]
+		require.Len(t, last.Content, 1)
+		assert.Equal(t, "tool_result", last.Content[0].Type)
+		assert.Equal(t, "toolu_1", last.Content[0].ToolUseID)
+		assert.Equal(t, "北京晴", last.Content[0].Content)
+		_, _ = w.Write([]byte(`{"id":"msg_2","stop_reason":"end_turn","content":[
+			{"type":"text","text":"北京今天晴"}
+		],"usage":{"input_tokens":20,"output_tokens":5}}`))
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	skillsDir := filepath.Join(tmpDir, "skills")
+	writeTestSkill(t, skillsDir, "quick", false)
+
+	logger := logging.GetSystemLogger().Named("provider_test")
+	mgr, err := skills.NewSkillMgr(skillsDir, tmpDir, nil, nil, logger)
+	require.NoError(t, err)
+	defer mgr.Close()
+	mgr.RegisterInternalSkill("quick", func(_ context.Context, args map[string]any) (string, error) {
+		return args["city"].(string) + "晴", nil
+	})
+
+	model := &config.ModelConfig{Name: "claude-test", Provider: config.ProviderAnthropic, BaseURL: server.URL + "/v1", MaxTokens: 8192}
+	thinking := NewThinking(model, "", logger, nil, &config.TokenBudgetConfig{})
+
+	tools := []*core.ToolSchema{{
+		Name:        "quick",
+		Description: "查询天气",
+		Params: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"city": map[string]any{"type": "string"}},
+		},
+	}}
+	answer, err := NewToolCaller(mgr, logger).ExecuteToolCall(context.Background(), thinking, "北京天气", nil, tools)
+	require.NoError(t, err)
+	assert.Equal(t, "北京今天晴", answer)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+// TestThinking_OllamaStructuredOutput 模型使用 Ollama 原生接口时，左脑以 JSON Schema 约束 ThinkingResult
+func TestThinking_OllamaStructuredOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		var req struct {
+			Format struct {
+				Type       string                     `json:"type"`
+				Properties map[string]json.RawMessage `json:"properties"`
+				Required   []string                   `json:"required"`
+			} `json:"format"`
+			Options map[string]any `json:"options"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "object", req.Format.Type)
+		assert.Contains(t, req.Format.Properties, "keywords")
+		assert.Contains(t, req.Format.Required, "answer")
+		assert.Equal(t, float64(8192), req.Options["num_ctx"])
+
+		content, _ := json.Marshal(core.ThinkingResult{Answer: "你好", Intent: "greeting", Keywords: []string{"问候"}, CanAnswer: true})
+		chunk, _ := json.Marshal(map[string]any{"message": map[string]any{"role": "assistant", "content": string(content)}, "done": false})
+		_, _ = w.Write(append(chunk, '\n'))
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":30,"eval_count":10}` + "\n"))
+	}))
+	defer server.Close()
+
+	model := &config.ModelConfig{Name: "qwen3:0.6b", Provider: config.ProviderOllama, BaseURL: server.URL, MaxTokens: 8192}
+	thinking := NewThinking(model, "", logging.GetSystemLogger().Named("provider_test"), nil, &config.TokenBudgetConfig{})
+
+	result, err := thinking.Think(context.Background(), "你好", nil, "", true)
+	require.NoError(t, err)
+	assert.Equal(t, "你好", result.Answer)
+	assert.Equal(t, "greeting", result.Intent)
+	assert.Equal(t, []string{"问候"}, result.Keywords)
+}
+
+// TestThinking_RepairResult 左脑回答不符合 Schema 时，带上校验错误让模型修正一次；仍失败则把原文作为回答
+func TestThinking_RepairResult(t *testing.T) {
+	valid, _ := json.Marshal(core.ThinkingResult{Answer: "你好", Intent: "greeting", Keywords: []string{"问候"}, CanAnswer: true})
+
+	tests := []struct {
+		name       string
+		repaired   string
+		status     string
+		wantAnswer string
+	}{
+		{name: "repaired", repaired: string(valid), status: "repaired", wantAnswer: "你好"},
+		{name: "failed", repaired: `{"answer":"你好"}`, status: "failed", wantAnswer: `{"answer": "你好"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Stream   bool `json:"stream"`
+					Messages []struct {
+						Role    string `json:"role"`
+						Content string `json:"content"`
+					} `json:"messages"`
+					Format json.RawMessage `json:"format"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.NotEmpty(t, req.Format, "修正请求同样以 Schema 约束输出")
+
+				if requests.Add(1) == 1 {
+					assert.True(t, req.Stream)
+					_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"{\"answer\": \"你好\""},"done":true,"done_reason":"stop"}` + "\n"))
+					return
+				}
+
+				assert.False(t, req.Stream)
+				require.GreaterOrEqual(t, len(req.Messages), 2)
+				assert.Equal(t, "assistant", req.Messages[len(req.Messages)-2].Role)
+				assert.Equal(t, `{"answer": "你好"`, req.Messages[len(req.Messages)-2].Content)
+				assert.Equal(t, "user", req.Messages[len(req.Messages)-1].Role)
+				message, _ := json.Marshal(map[string]any{"message": map[string]any{"role": "assistant", "content": tt.repaired}, "done": true})
+				_, _ = w.Write(message)
+			}))
+			defer server.Close()
+
+			model := &config.ModelConfig{Name: "repair-" + tt.name, Provider: config.ProviderOllama, BaseURL: server.URL}
+			thinking := NewThinking(model, "", logging.GetSystemLogger().Named("provider_test"), nil, &config.TokenBudgetConfig{})
+
+			result, err := thinking.Think(context.Background(), "你好", nil, "", true)
+			require.NoError(t, err)
+			assert.Equal(t, int32(2), requests.Load())
+			assert.Equal(t, tt.wantAnswer, result.Answer)
+			assert.Equal(t, float64(1), testutil.ToFloat64(middleware.ThinkingResultParseTotal.WithLabelValues(model.Name, tt.status)))
+		})
+	}
+}
+
+func TestThinkResponseFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		model config.ModelConfig
+		want  openai.ChatCompletionResponseFormatType
+	}{
+		{name: "ollama", model: config.ModelConfig{Provider: config.ProviderOllama}, want: openai.ChatCompletionResponseFormatTypeJSONSchema},
+		{name: "gemini", model: config.ModelConfig{Provider: config.ProviderGemini}, want: openai.ChatCompletionResponseFormatTypeJSONSchema},
+		{name: "openai structured", model: config.ModelConfig{StructuredOutput: true}, want: openai.ChatCompletionResponseFormatTypeJSONSchema},
+		{name: "openai", model: config.ModelConfig{}, want: openai.ChatCompletionResponseFormatTypeJSONObject},
+		{name: "anthropic", model: config.ModelConfig{Provider: config.ProviderAnthropic, StructuredOutput: true}, want: openai.ChatCompletionResponseFormatTypeJSONObject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, thinkResponseFormat(&tt.model, true).Type)
+		})
+	}
+
+	assert.Equal(t, openai.ChatCompletionResponseFormatTypeText, thinkResponseFormat(&config.ModelConfig{}, false).Type)
+	assert.ElementsMatch(t, []string{"answer", "intent", "keywords", "useless", "can_answer"}, thinkingResultSchema.Required)
+}
+
+func TestParseThinkingResult(t *testing.T) {
+	result, err := parseThinkingResult("<think>先想想 {草稿}</think>\n```json\n{\"answer\":\"你好\",\"intent\":\"greeting\",\"keywords\":[],\"useless\":false,\"can_answer\":true}\n```")
+	require.NoError(t, err)
+	assert.Equal(t, "greeting", result.Intent)
+
+	_, err = parseThinkingResult(`{"answer":"你好","useless":false}`)
+	assert.EqualError(t, err, "missing required fields: intent, keywords, can_answer")
+
+	_, err = parseThinkingResult(`{"answer":"你好","intent":"greeting","keywords":"问候","useless":false,"can_answer":true}`)
+	assert.ErrorContains(t, err, "keywords")
+
+	_, err = parseThinkingResult("你好")
+	assert.ErrorContains(t, err, "reply is not a JSON object")
+}
@@ -0,0 +1,55 @@
+package brain
+
+import (
+	"context"
+	"mindx/internal/core"
+	"mindx/internal/entity"
+	"sync"
+)
+
+type requestStateKey struct{}
+
+// requestState 一次思考请求的状态，通过 context 随请求传递
+// 记录发起请求的会话，并收集各条处理路径中工具产出的附件（如 MCP 工具返回的图片）
+// 思考流通道通过 core.ContextWithThinkingEvents 随 ctx 传递，左右脑和主意识实例不保存任何请求级状态
+type requestState struct {
+	key entity.SessionKey
+
+	mu          sync.Mutex
+	attachments []*entity.Attachment
+}
+
+func withRequestState(ctx context.Context, req *core.ThinkingRequest) (context.Context, *requestState) {
+	state := &requestState{
+		key: req.SessionKey(),
+	}
+	ctx = entity.ContextWithSessionKey(ctx, state.key)
+	ctx = core.ContextWithThinkingEvents(ctx, req.EventChan)
+	return context.WithValue(ctx, requestStateKey{}, state), state
+}
+
+// requestStateFrom 获取 ctx 中的请求状态；ctx 未携带时返回 nil
+func requestStateFrom(ctx context.Context) *requestState {
+	state, _ := ctx.Value(requestStateKey{}).(*requestState)
+	return state
+}
+
+// collectAttachments 将附件写入 ctx 中的请求状态；ctx 未携带请求状态时忽略
+func collectAttachments(ctx context.Context, attachments []*entity.Attachment) {
+	if len(attachments) == 0 {
+		return
+	}
+	state := requestStateFrom(ctx)
+	if state == nil {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.attachments = append(state.attachments, attachments...)
+}
+
+func (s *requestState) items() []*entity.Attachment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attachments
+}
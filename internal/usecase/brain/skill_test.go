@@ -1,6 +1,7 @@
 package brain
 
 import (
+	"context"
 	"mindx/internal/core"
 	"mindx/pkg/logging"
 )
@@ -16,7 +17,7 @@ type SkillExecutionSuite struct {
 func (s *SkillExecutionSuite) TestSkill_WeatherQuery() {
 
 	// 验证技能已加载
-	skills, err := s.skillMgr.SearchSkills("天气", "查询")
+	skills, err := s.skillMgr.SearchSkills(context.Background(), "天气", "查询")
 	s.Require().NoError(err)
 	s.GreaterOrEqual(len(skills), 1, "应该有天气相关的技能")
 
@@ -24,7 +25,7 @@ func (s *SkillExecutionSuite) TestSkill_WeatherQuery() {
 	q := "查询一下北京的天气"
 	s.logger.Info("提问", logging.String("question", q))
 
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: q,
 		Timeout:  30,
 	})
@@ -61,7 +62,7 @@ func (s *SkillExecutionSuite) TestSkill_TimeQuery() {
 	q := "现在几点了？"
 	s.logger.Info("提问", logging.String("question", q))
 
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: q,
 		Timeout:  30,
 	})
@@ -84,7 +85,7 @@ func (s *SkillExecutionSuite) TestSkill_MultipleSkills() {
 	q := "查一下北京天气，还有现在几点了"
 	s.logger.Info("提问", logging.String("question", q))
 
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: q,
 		Timeout:  30,
 	})
@@ -112,7 +113,7 @@ func (s *SkillExecutionSuite) TestSkill_NoIntent() {
 	q := "你今天心情怎么样？"
 	s.logger.Info("提问", logging.String("question", q))
 
-	resp, err := s.brain.Post(&core.ThinkingRequest{
+	resp, err := s.brain.Post(context.Background(), &core.ThinkingRequest{
 		Question: q,
 		Timeout:  30,
 	})
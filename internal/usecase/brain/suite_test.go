@@ -1,6 +1,7 @@
 package brain
 
 import (
+	"context"
 	"fmt"
 	"mindx/internal/config"
 	"mindx/internal/core"
@@ -207,8 +208,8 @@ func (s *BrainIntegrationSuite) SetupSuite() {
 	tokenUsageRepo, err := persistence.NewSQLiteTokenUsageRepository(filepath.Join(s.testData, "token_usage.db"))
 	s.Require().NoError(err)
 
-	historyRequest := func(maxCount int) ([]*core.DialogueMessage, error) {
-		messages := s.sessionMgr.GetHistory()
+	historyRequest := func(key entity.SessionKey, maxCount int) ([]*core.DialogueMessage, error) {
+		messages := s.sessionMgr.GetHistory(key)
 		dialogueMessages := make([]*core.DialogueMessage, len(messages))
 		for i, msg := range messages {
 			dialogueMessages[i] = &core.DialogueMessage{
@@ -223,8 +224,8 @@ func (s *BrainIntegrationSuite) SetupSuite() {
 	}
 
 	toolCaller := NewToolCaller(s.skillMgr, s.logger)
-	toolsRequest := func(keywords ...string) ([]*core.ToolSchema, error) {
-		schemas, err := toolCaller.SearchTools(keywords)
+	toolsRequest := func(ctx context.Context, keywords ...string) ([]*core.ToolSchema, error) {
+		schemas, err := toolCaller.SearchTools(ctx, keywords)
 		if err != nil {
 			return nil, err
 		}
@@ -287,7 +288,7 @@ func (s *BrainIntegrationSuite) recordTestMemories() {
 
 // postWithHistory 包装 Post 方法，自动记录对话到会话
 func (s *BrainIntegrationSuite) postWithHistory(req *core.ThinkingRequest) (*core.ThinkingResponse, error) {
-	err := s.sessionMgr.RecordMessage(entity.Message{
+	err := s.sessionMgr.RecordMessage(req.SessionKey(), entity.Message{
 		Role:    "user",
 		Content: req.Question,
 		Time:    time.Now(),
@@ -296,12 +297,12 @@ func (s *BrainIntegrationSuite) postWithHistory(req *core.ThinkingRequest) (*cor
 		s.logger.Warn("记录用户消息失败", logging.Err(err))
 	}
 
-	resp, err := s.brain.Post(req)
+	resp, err := s.brain.Post(context.Background(), req)
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.sessionMgr.RecordMessage(entity.Message{
+	err = s.sessionMgr.RecordMessage(req.SessionKey(), entity.Message{
 		Role:    "assistant",
 		Content: resp.Answer,
 		Time:    time.Now(),
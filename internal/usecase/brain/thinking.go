@@ -9,25 +9,26 @@ import (
 	"mindx/internal/core"
 	"mindx/internal/entity"
 	apperrors "mindx/internal/errors"
+	"mindx/internal/infrastructure/llm"
+	"mindx/internal/usecase/modelrouter"
 	"mindx/pkg/i18n"
 	"mindx/pkg/logging"
-	"mindx/pkg/retry"
 	"strings"
 	"time"
 
 	openai "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
 )
 
 type Thinking struct {
 	modelConfig        *config.ModelConfig
-	client             *openai.Client
+	router             *modelrouter.Router
 	prompt             string
 	systemPrompt       string
 	logger             logging.Logger
 	tokenUsageRepo     core.TokenUsageRepository
 	tokenBudget        *config.TokenBudgetConfig
 	tokenBudgetManager *TokenBudgetManager
-	eventChan          chan<- ThinkingEvent
 }
 
 func NewThinking(
@@ -37,11 +38,6 @@ func NewThinking(
 	tokenUsageRepo core.TokenUsageRepository,
 	tokenBudget *config.TokenBudgetConfig) *Thinking {
 
-	clientConfig := openai.DefaultConfig(modelConfig.APIKey)
-	if modelConfig.BaseURL != "" {
-		clientConfig.BaseURL = modelConfig.BaseURL
-	}
-
 	budgetManager := NewTokenBudgetManager(
 		modelConfig.MaxTokens,
 		tokenBudget.ReservedOutputTokens,
@@ -51,30 +47,176 @@ func NewThinking(
 	)
 
 	return &Thinking{
-		client:             openai.NewClientWithConfig(clientConfig),
+		router:             modelrouter.Default(),
 		modelConfig:        modelConfig,
 		prompt:             prompt,
 		logger:             logger,
 		tokenUsageRepo:     tokenUsageRepo,
 		tokenBudget:        tokenBudget,
 		tokenBudgetManager: budgetManager,
-		eventChan:          nil,
 	}
 }
 
-func (t *Thinking) SetEventChan(ch chan<- ThinkingEvent) {
-	t.eventChan = ch
-}
-
-func (t *Thinking) sendEvent(event ThinkingEvent) {
-	if t.eventChan != nil {
+// sendEvent 推送思考事件到 ctx 携带的事件通道，通道已满时丢弃，不阻塞思考
+func (t *Thinking) sendEvent(ctx context.Context, event ThinkingEvent) {
+	if ch := core.ThinkingEventsFromContext(ctx); ch != nil {
 		select {
-		case t.eventChan <- event:
+		case ch <- event:
 		default:
 		}
 	}
 }
 
+// notifyFallback 实际使用的模型不是配置的模型时，通知请求方已改用降级模型
+func (t *Thinking) notifyFallback(ctx context.Context, model *config.ModelConfig) {
+	if model.Name != t.modelConfig.Name {
+		t.sendEvent(ctx, NewFallbackEvent(t.modelConfig.Name, model.Name))
+	}
+}
+
+// saveTokenUsage 按实际使用的模型记录 token 用量，使用降级模型时同时记录原本请求的模型
+func (t *Thinking) saveTokenUsage(model *config.ModelConfig, duration int64, usage openai.Usage) {
+	if t.tokenUsageRepo == nil {
+		return
+	}
+	tokenUsage := &entity.TokenUsage{
+		Model:            model.Name,
+		Duration:         duration,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		PromptTokens:     usage.PromptTokens,
+		CreatedAt:        time.Now(),
+	}
+	if model.Name != t.modelConfig.Name {
+		tokenUsage.RequestedModel = t.modelConfig.Name
+	}
+	if err := t.tokenUsageRepo.Save(tokenUsage); err != nil {
+		t.logger.Warn(i18n.T("brain.save_token_failed"), logging.Err(err))
+		return
+	}
+	t.logger.Debug(i18n.T("brain.token_saved"),
+		logging.String(i18n.T("brain.model"), tokenUsage.Model),
+		logging.Int(i18n.T("brain.total_tokens"), tokenUsage.TotalTokens),
+		logging.Int64("duration_ms", tokenUsage.Duration))
+}
+
+// thinkingResultSchema 左脑回答（core.ThinkingResult）的 JSON Schema，由结构体字段生成
+var thinkingResultSchema = func() *jsonschema.Definition {
+	schema, err := jsonschema.GenerateSchemaForType(core.ThinkingResult{})
+	if err != nil {
+		panic(fmt.Sprintf("generate thinking result schema: %v", err))
+	}
+	return schema
+}()
+
+// thinkResponseFormat 左脑的输出格式
+// 服务支持时以 JSON Schema 约束输出为 ThinkingResult，否则只要求输出 JSON
+func thinkResponseFormat(model *config.ModelConfig, jsonResult bool) *openai.ChatCompletionResponseFormat {
+	if !jsonResult {
+		return &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeText}
+	}
+	return llm.JSONResponseFormat(model, "thinking_result", thinkingResultSchema)
+}
+
+// parseThinkingResult 从模型回答中解析 ThinkingResult，缺少必填字段或字段类型不符时返回错误，错误信息用于让模型修正
+func parseThinkingResult(content string) (core.ThinkingResult, error) {
+	var result core.ThinkingResult
+	// 跳过 <think> 思考过程，避免把其中的花括号当作 JSON
+	if i := strings.LastIndex(content, "</think"); i >= 0 {
+		if j := strings.Index(content[i:], ">"); j >= 0 {
+			content = content[i+j+1:]
+		}
+	}
+	data := []byte(extractJSON(content))
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return result, fmt.Errorf("reply is not a JSON object: %w", err)
+	}
+	var missing []string
+	for _, name := range thinkingResultSchema.Required {
+		if _, ok := fields[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return result, fmt.Errorf("missing required fields: %s", strings.Join(missing, ", "))
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// resolveThinkingResult 解析左脑的 JSON 回答，不符合 Schema 时带上错误信息让模型修正一次，仍失败则把原文作为回答
+func (t *Thinking) resolveThinkingResult(ctx context.Context, model *config.ModelConfig, messages []openai.ChatCompletionMessage, content string) core.ThinkingResult {
+	result, err := parseThinkingResult(content)
+	if err == nil {
+		middleware.ThinkingResultParseTotal.WithLabelValues(model.Name, "ok").Inc()
+		return result
+	}
+
+	t.logger.Warn(i18n.T("brain.repair_result"),
+		logging.String(i18n.T("brain.model"), model.Name),
+		logging.Err(err))
+	result, err = t.repairThinkingResult(ctx, model, messages, content, err)
+	if err == nil {
+		middleware.ThinkingResultParseTotal.WithLabelValues(model.Name, "repaired").Inc()
+		return result
+	}
+
+	middleware.ThinkingResultParseTotal.WithLabelValues(model.Name, "failed").Inc()
+	t.logger.Warn(i18n.T("brain.parse_result_failed"),
+		logging.Err(err),
+		logging.String(i18n.T("brain.raw_content"), content))
+	return core.ThinkingResult{
+		Answer:    content,
+		Intent:    "",
+		Keywords:  []string{},
+		CanAnswer: true,
+	}
+}
+
+// repairThinkingResult 把不合格的回答和错误信息发回模型，要求只输出修正后的 JSON
+func (t *Thinking) repairThinkingResult(ctx context.Context, model *config.ModelConfig, messages []openai.ChatCompletionMessage, content string, parseErr error) (core.ThinkingResult, error) {
+	repairMessages := append(append([]openai.ChatCompletionMessage{}, messages...),
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+		openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleUser,
+			Content: i18n.TWithData("brain.repair_result_prompt", map[string]interface{}{"Error": parseErr.Error()}),
+		},
+	)
+	req := openai.ChatCompletionRequest{Messages: repairMessages}
+
+	startTime := time.Now()
+	var resp openai.ChatCompletionResponse
+	used, err := t.router.Do(ctx, model, func(model *config.ModelConfig, provider llm.Provider) error {
+		req.Model = model.Name
+		req.ResponseFormat = thinkResponseFormat(model, true)
+		req.MaxTokens = 0
+		if model.MaxTokens > 0 {
+			req.MaxTokens = model.MaxTokens
+		}
+		var err error
+		resp, err = provider.CreateChatCompletion(ctx, req)
+		return err
+	})
+	middleware.LlmCallDuration.WithLabelValues(used.Name).Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		middleware.LlmCallsTotal.WithLabelValues(used.Name, "error").Inc()
+		return core.ThinkingResult{}, err
+	}
+	middleware.LlmCallsTotal.WithLabelValues(used.Name, "success").Inc()
+	middleware.TokenUsageTotal.WithLabelValues(used.Name, "prompt").Add(float64(resp.Usage.PromptTokens))
+	middleware.TokenUsageTotal.WithLabelValues(used.Name, "completion").Add(float64(resp.Usage.CompletionTokens))
+	t.saveTokenUsage(used, time.Since(startTime).Milliseconds(), resp.Usage)
+
+	if len(resp.Choices) == 0 {
+		return core.ThinkingResult{}, fmt.Errorf("empty repair response")
+	}
+	return parseThinkingResult(resp.Choices[0].Message.Content)
+}
+
 func (t *Thinking) CalculateMaxHistoryCount() int {
 	if t.tokenBudgetManager == nil {
 		return t.calculateStaticMaxHistoryCount()
@@ -153,52 +295,48 @@ func (t *Thinking) Think(ctx context.Context, question string, history []*core.D
 		Content: question,
 	})
 
-	respFormat := openai.ChatCompletionResponseFormatTypeText
-	if jsonResult {
-		respFormat = openai.ChatCompletionResponseFormatTypeJSONObject
-	}
-
 	req := openai.ChatCompletionRequest{
 		Model:    t.modelConfig.Name,
 		Messages: messages,
-		ResponseFormat: &openai.ChatCompletionResponseFormat{
-			Type: respFormat,
-		},
-		Stream: true,
+		Stream:   true,
 		StreamOptions: &openai.StreamOptions{
 			IncludeUsage: true,
 		},
 	}
 
-	if t.modelConfig.Temperature > 0 {
-		temperature := float32(t.modelConfig.Temperature)
-		req.Temperature = temperature
-	}
-
-	if t.modelConfig.MaxTokens > 0 {
-		req.MaxTokens = t.modelConfig.MaxTokens
-	}
-
 	req.ChatTemplateKwargs = map[string]any{
 		"enable_thinking": true,
 	}
 
 	startTime := time.Now()
 
-	t.sendEvent(NewThinkingEvent(ThinkingEventStart, i18n.T("brain.start_thinking")))
+	t.sendEvent(ctx, NewThinkingEvent(ThinkingEventStart, i18n.T("brain.start_thinking")))
 
-	retryCfg := retry.DefaultConfig()
-	stream, err := retry.DoWithResult(ctx, retryCfg, func() (*openai.ChatCompletionStream, error) {
-		return t.client.CreateChatCompletionStream(ctx, req)
+	var stream llm.ChatStream
+	model, err := t.router.Do(ctx, t.modelConfig, func(model *config.ModelConfig, provider llm.Provider) error {
+		req.Model = model.Name
+		req.ResponseFormat = thinkResponseFormat(model, jsonResult)
+		req.Temperature = 0
+		if model.Temperature > 0 {
+			req.Temperature = float32(model.Temperature)
+		}
+		req.MaxTokens = 0
+		if model.MaxTokens > 0 {
+			req.MaxTokens = model.MaxTokens
+		}
+		var err error
+		stream, err = provider.CreateChatCompletionStream(ctx, req)
+		return err
 	})
 	if err != nil {
-		middleware.LlmCallsTotal.WithLabelValues(t.modelConfig.Name, "error").Inc()
-		middleware.LlmCallDuration.WithLabelValues(t.modelConfig.Name).Observe(time.Since(startTime).Seconds())
+		middleware.LlmCallsTotal.WithLabelValues(model.Name, "error").Inc()
+		middleware.LlmCallDuration.WithLabelValues(model.Name).Observe(time.Since(startTime).Seconds())
 		t.logger.Error(i18n.T("brain.think_failed"), logging.Err(err))
-		t.sendEvent(NewThinkingEvent(ThinkingEventError, err.Error()))
+		t.sendEvent(ctx, NewThinkingEvent(ThinkingEventError, err.Error()))
 		return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "think failed")
 	}
 	defer stream.Close()
+	t.notifyFallback(ctx, model)
 
 	var fullContent strings.Builder
 	var thinkingContent strings.Builder
@@ -212,10 +350,10 @@ func (t *Thinking) Think(ctx context.Context, question string, history []*core.D
 			if err.Error() == "EOF" {
 				break
 			}
-			middleware.LlmCallsTotal.WithLabelValues(t.modelConfig.Name, "error").Inc()
-			middleware.LlmCallDuration.WithLabelValues(t.modelConfig.Name).Observe(time.Since(startTime).Seconds())
+			middleware.LlmCallsTotal.WithLabelValues(model.Name, "error").Inc()
+			middleware.LlmCallDuration.WithLabelValues(model.Name).Observe(time.Since(startTime).Seconds())
 			t.logger.Error(i18n.T("brain.stream_recv_failed"), logging.Err(err))
-			t.sendEvent(NewThinkingEvent(ThinkingEventError, err.Error()))
+			t.sendEvent(ctx, NewThinkingEvent(ThinkingEventError, err.Error()))
 			return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "stream receive failed")
 		}
 
@@ -241,13 +379,13 @@ func (t *Thinking) Think(ctx context.Context, question string, history []*core.D
 
 		if inThinking {
 			thinkingContent.WriteString(chunk)
-			t.sendEvent(NewThinkingEvent(ThinkingEventChunk, chunk))
+			t.sendEvent(ctx, NewThinkingEvent(ThinkingEventChunk, chunk))
 			if strings.Contains(chunk, "</think") || strings.Contains(chunk, "</thinking") {
 				inThinking = false
 			}
 		} else {
 			contentContent.WriteString(chunk)
-			t.sendEvent(NewThinkingEvent(ThinkingEventChunk, chunk))
+			t.sendEvent(ctx, NewThinkingEvent(ThinkingEventChunk, chunk))
 		}
 	}
 
@@ -255,10 +393,10 @@ func (t *Thinking) Think(ctx context.Context, question string, history []*core.D
 	durationSec := time.Since(startTime).Seconds()
 
 	// Prometheus 指标埋点
-	middleware.LlmCallsTotal.WithLabelValues(t.modelConfig.Name, "success").Inc()
-	middleware.LlmCallDuration.WithLabelValues(t.modelConfig.Name).Observe(durationSec)
-	middleware.TokenUsageTotal.WithLabelValues(t.modelConfig.Name, "prompt").Add(float64(usage.PromptTokens))
-	middleware.TokenUsageTotal.WithLabelValues(t.modelConfig.Name, "completion").Add(float64(usage.CompletionTokens))
+	middleware.LlmCallsTotal.WithLabelValues(model.Name, "success").Inc()
+	middleware.LlmCallDuration.WithLabelValues(model.Name).Observe(durationSec)
+	middleware.TokenUsageTotal.WithLabelValues(model.Name, "prompt").Add(float64(usage.PromptTokens))
+	middleware.TokenUsageTotal.WithLabelValues(model.Name, "completion").Add(float64(usage.CompletionTokens))
 
 	content := strings.TrimSpace(fullContent.String())
 
@@ -272,8 +410,9 @@ func (t *Thinking) Think(ctx context.Context, question string, history []*core.D
 		logging.Int("content_length", len(content)))
 
 	var result core.ThinkingResult
-	jsonContent := extractJSON(content)
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
+	if jsonResult {
+		result = t.resolveThinkingResult(ctx, model, messages, content)
+	} else if err := json.Unmarshal([]byte(extractJSON(content)), &result); err != nil {
 		t.logger.Warn(i18n.T("brain.parse_result_failed"),
 			logging.Err(err),
 			logging.String(i18n.T("brain.raw_content"), content))
@@ -292,24 +431,7 @@ func (t *Thinking) Think(ctx context.Context, question string, history []*core.D
 		logging.Bool("can_answer", result.CanAnswer),
 		logging.String("answer", result.Answer))
 
-	if t.tokenUsageRepo != nil {
-		tokenUsage := &entity.TokenUsage{
-			Model:            t.modelConfig.Name,
-			Duration:         duration,
-			CompletionTokens: int(usage.CompletionTokens),
-			TotalTokens:      int(usage.TotalTokens),
-			PromptTokens:     int(usage.PromptTokens),
-			CreatedAt:        time.Now(),
-		}
-		if err := t.tokenUsageRepo.Save(tokenUsage); err != nil {
-			t.logger.Warn(i18n.T("brain.save_token_failed"), logging.Err(err))
-		} else {
-			t.logger.Debug(i18n.T("brain.token_saved"),
-				logging.String(i18n.T("brain.model"), tokenUsage.Model),
-				logging.Int(i18n.T("brain.total_tokens"), tokenUsage.TotalTokens),
-				logging.Int64("duration_ms", tokenUsage.Duration))
-		}
-	}
+	t.saveTokenUsage(model, duration, usage)
 
 	if t.tokenBudgetManager != nil {
 		t.tokenBudgetManager.RecordUsage(
@@ -323,7 +445,7 @@ func (t *Thinking) Think(ctx context.Context, question string, history []*core.D
 		logging.String(i18n.T("brain.keywords_count"), fmt.Sprintf("%d", len(result.Keywords))),
 		logging.String(i18n.T("brain.can_answer"), fmt.Sprintf("%v", result.CanAnswer)))
 
-	t.sendEvent(NewThinkingEventWithProgress(ThinkingEventComplete, result.Answer, 100))
+	t.sendEvent(ctx, NewThinkingEventWithProgress(ThinkingEventComplete, result.Answer, 100))
 
 	return &result, nil
 }
@@ -340,7 +462,7 @@ func (t *Thinking) ThinkWithTools(ctx context.Context, question string, history
 		logging.String(i18n.T("brain.question"), question),
 		logging.Int(i18n.T("brain.tools_count"), len(tools)))
 
-	t.sendEvent(NewThinkingEvent(ThinkingEventStart, i18n.T("brain.right_prepare_skill")))
+	t.sendEvent(ctx, NewThinkingEvent(ThinkingEventStart, i18n.T("brain.right_prepare_skill")))
 
 	if len(tools) == 0 {
 		t.logger.Warn(i18n.T("brain.no_skill"))
@@ -431,40 +553,32 @@ func (t *Thinking) ThinkWithTools(ctx context.Context, question string, history
 		logging.Int("tools_count", len(ollamaTools)),
 		logging.Any("tools", ollamaTools))
 
-	retryCfg := retry.DefaultConfig()
-	resp, err := retry.DoWithResult(ctx, retryCfg, func() (openai.ChatCompletionResponse, error) {
-		return t.client.CreateChatCompletion(ctx, req)
+	var resp openai.ChatCompletionResponse
+	model, err := t.router.Do(ctx, t.modelConfig, func(model *config.ModelConfig, provider llm.Provider) error {
+		req.Model = model.Name
+		var err error
+		resp, err = provider.CreateChatCompletion(ctx, req)
+		return err
 	})
 	duration := time.Since(startTime).Milliseconds()
 
 	// 记录 LLM 调用指标
 	durationSec := float64(duration) / 1000.0
 	if err != nil {
-		middleware.LlmCallsTotal.WithLabelValues(t.modelConfig.Name, "error").Inc()
-		middleware.LlmCallDuration.WithLabelValues(t.modelConfig.Name).Observe(durationSec)
+		middleware.LlmCallsTotal.WithLabelValues(model.Name, "error").Inc()
+		middleware.LlmCallDuration.WithLabelValues(model.Name).Observe(durationSec)
 		t.logger.Error(i18n.T("brain.right_skill_call_failed"), logging.Err(err))
-		t.sendEvent(NewThinkingEvent(ThinkingEventError, err.Error()))
+		t.sendEvent(ctx, NewThinkingEvent(ThinkingEventError, err.Error()))
 		return nil, apperrors.Wrap(err, apperrors.ErrTypeSkill, "skill call failed")
 	}
 
-	middleware.LlmCallsTotal.WithLabelValues(t.modelConfig.Name, "success").Inc()
-	middleware.LlmCallDuration.WithLabelValues(t.modelConfig.Name).Observe(durationSec)
-	middleware.TokenUsageTotal.WithLabelValues(t.modelConfig.Name, "prompt").Add(float64(resp.Usage.PromptTokens))
-	middleware.TokenUsageTotal.WithLabelValues(t.modelConfig.Name, "completion").Add(float64(resp.Usage.CompletionTokens))
+	middleware.LlmCallsTotal.WithLabelValues(model.Name, "success").Inc()
+	middleware.LlmCallDuration.WithLabelValues(model.Name).Observe(durationSec)
+	middleware.TokenUsageTotal.WithLabelValues(model.Name, "prompt").Add(float64(resp.Usage.PromptTokens))
+	middleware.TokenUsageTotal.WithLabelValues(model.Name, "completion").Add(float64(resp.Usage.CompletionTokens))
 
-	if t.tokenUsageRepo != nil {
-		usage := &entity.TokenUsage{
-			Model:            t.modelConfig.Name,
-			Duration:         duration,
-			CompletionTokens: int(resp.Usage.CompletionTokens),
-			TotalTokens:      int(resp.Usage.TotalTokens),
-			PromptTokens:     int(resp.Usage.PromptTokens),
-			CreatedAt:        time.Now(),
-		}
-		if err := t.tokenUsageRepo.Save(usage); err != nil {
-			t.logger.Warn("保存 token 用量失败", logging.Err(err))
-		}
-	}
+	t.notifyFallback(ctx, model)
+	t.saveTokenUsage(model, duration, resp.Usage)
 
 	if t.tokenBudgetManager != nil {
 		t.tokenBudgetManager.RecordUsage(
@@ -497,11 +611,11 @@ func (t *Thinking) ThinkWithTools(ctx context.Context, question string, history
 			if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
 				t.logger.Error(i18n.T("brain.parse_func_params_failed"), logging.Err(err),
 					logging.String("tool_call_id", toolCall.ID))
-				t.sendEvent(NewThinkingEvent(ThinkingEventError, err.Error()))
+				t.sendEvent(ctx, NewThinkingEvent(ThinkingEventError, err.Error()))
 				return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "parse func params failed")
 			}
 
-			t.sendEvent(NewToolCallEvent(toolCall.Function.Name, args))
+			t.sendEvent(ctx, NewToolCallEvent(toolCall.Function.Name, args))
 
 			items = append(items, core.ToolCallItem{
 				ToolCallID: toolCall.ID,
@@ -530,11 +644,11 @@ func (t *Thinking) ThinkWithTools(ctx context.Context, question string, history
 		var args map[string]interface{}
 		if err := json.Unmarshal([]byte(funcCall.Arguments), &args); err != nil {
 			t.logger.Error(i18n.T("brain.parse_func_params_failed"), logging.Err(err))
-			t.sendEvent(NewThinkingEvent(ThinkingEventError, err.Error()))
+			t.sendEvent(ctx, NewThinkingEvent(ThinkingEventError, err.Error()))
 			return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "parse func params failed")
 		}
 
-		t.sendEvent(NewToolCallEvent(funcCall.Name, args))
+		t.sendEvent(ctx, NewToolCallEvent(funcCall.Name, args))
 
 		return &core.ToolCallResult{
 			Function: &core.ToolCallFunction{
@@ -551,6 +665,8 @@ func (t *Thinking) ThinkWithTools(ctx context.Context, question string, history
 		return &core.ToolCallResult{Answer: "", NoCall: true}, nil
 	}
 
+	// 部分模型经 OpenAI 兼容接口（如 Ollama 的 /v1）调用时把工具调用写在回答文本中，按 JSON 格式识别
+	// 使用 Ollama 原生接口（provider: ollama）时工具调用已在 tool_calls 中返回
 	var ollamaToolCall struct {
 		Name      string                 `json:"name"`
 		Arguments map[string]interface{} `json:"arguments"`
@@ -582,7 +698,7 @@ func (t *Thinking) ReturnFuncResult(ctx context.Context, toolCallID string, name
 		logging.String(i18n.T("brain.result"), result),
 		logging.String("tool_call_id", toolCallID))
 
-	t.sendEvent(NewToolResultEvent(name, result))
+	t.sendEvent(ctx, NewToolResultEvent(name, result))
 
 	systemPrompt := `你是一个工具调用助手。你的职责是根据用户的请求，从可用的工具中选择合适的工具并调用。
 
@@ -616,6 +732,7 @@ func (t *Thinking) ReturnFuncResult(ctx context.Context, toolCallID string, name
 
 	toolCalls := []openai.ToolCall{
 		{
+			ID:   toolCallID,
 			Type: openai.ToolTypeFunction,
 			Function: openai.FunctionCall{
 				Name:      name,
@@ -645,39 +762,31 @@ func (t *Thinking) ReturnFuncResult(ctx context.Context, toolCallID string, name
 		"enable_thinking": true,
 	}
 
-	retryCfg2 := retry.DefaultConfig()
-	resp, err := retry.DoWithResult(ctx, retryCfg2, func() (openai.ChatCompletionResponse, error) {
-		return t.client.CreateChatCompletion(ctx, req)
+	var resp openai.ChatCompletionResponse
+	model, err := t.router.Do(ctx, t.modelConfig, func(model *config.ModelConfig, provider llm.Provider) error {
+		req.Model = model.Name
+		var err error
+		resp, err = provider.CreateChatCompletion(ctx, req)
+		return err
 	})
 	duration := time.Since(startTime).Milliseconds()
 	durationSec2 := float64(duration) / 1000.0
 
 	if err != nil {
-		middleware.LlmCallsTotal.WithLabelValues(t.modelConfig.Name, "error").Inc()
-		middleware.LlmCallDuration.WithLabelValues(t.modelConfig.Name).Observe(durationSec2)
+		middleware.LlmCallsTotal.WithLabelValues(model.Name, "error").Inc()
+		middleware.LlmCallDuration.WithLabelValues(model.Name).Observe(durationSec2)
 		t.logger.Error(i18n.T("brain.return_func_result_failed"), logging.Err(err))
-		t.sendEvent(NewThinkingEvent(ThinkingEventError, err.Error()))
+		t.sendEvent(ctx, NewThinkingEvent(ThinkingEventError, err.Error()))
 		return "", apperrors.Wrap(err, apperrors.ErrTypeModel, "return func result failed")
 	}
 
-	middleware.LlmCallsTotal.WithLabelValues(t.modelConfig.Name, "success").Inc()
-	middleware.LlmCallDuration.WithLabelValues(t.modelConfig.Name).Observe(durationSec2)
-	middleware.TokenUsageTotal.WithLabelValues(t.modelConfig.Name, "prompt").Add(float64(resp.Usage.PromptTokens))
-	middleware.TokenUsageTotal.WithLabelValues(t.modelConfig.Name, "completion").Add(float64(resp.Usage.CompletionTokens))
+	middleware.LlmCallsTotal.WithLabelValues(model.Name, "success").Inc()
+	middleware.LlmCallDuration.WithLabelValues(model.Name).Observe(durationSec2)
+	middleware.TokenUsageTotal.WithLabelValues(model.Name, "prompt").Add(float64(resp.Usage.PromptTokens))
+	middleware.TokenUsageTotal.WithLabelValues(model.Name, "completion").Add(float64(resp.Usage.CompletionTokens))
 
-	if t.tokenUsageRepo != nil {
-		usage := &entity.TokenUsage{
-			Model:            t.modelConfig.Name,
-			Duration:         duration,
-			CompletionTokens: int(resp.Usage.CompletionTokens),
-			TotalTokens:      int(resp.Usage.TotalTokens),
-			PromptTokens:     int(resp.Usage.PromptTokens),
-			CreatedAt:        time.Now(),
-		}
-		if err := t.tokenUsageRepo.Save(usage); err != nil {
-			t.logger.Warn("保存 token 用量失败", logging.Err(err))
-		}
-	}
+	t.notifyFallback(ctx, model)
+	t.saveTokenUsage(model, duration, resp.Usage)
 
 	if len(resp.Choices) == 0 {
 		return "", apperrors.New(apperrors.ErrTypeModel, "no response result")
@@ -686,7 +795,7 @@ func (t *Thinking) ReturnFuncResult(ctx context.Context, toolCallID string, name
 	content := strings.TrimSpace(resp.Choices[0].Message.Content)
 	t.logger.Info(i18n.T("brain.get_final_response"), logging.String(i18n.T("brain.content"), content))
 
-	t.sendEvent(NewThinkingEventWithProgress(ThinkingEventComplete, content, 100))
+	t.sendEvent(ctx, NewThinkingEventWithProgress(ThinkingEventComplete, content, 100))
 
 	return content, nil
 }
@@ -696,7 +805,7 @@ func (t *Thinking) ReturnFuncResults(ctx context.Context, results []core.ToolExe
 	t.logger.Info("批量回传工具结果", logging.Int("count", len(results)))
 
 	for _, r := range results {
-		t.sendEvent(NewToolResultEvent(r.FunctionName, r.Result))
+		t.sendEvent(ctx, NewToolResultEvent(r.FunctionName, r.Result))
 	}
 
 	systemPrompt := `你是一个工具调用助手。你的职责是根据用户的请求，从可用的工具中选择合适的工具并调用。
@@ -780,39 +889,31 @@ func (t *Thinking) ReturnFuncResults(ctx context.Context, results []core.ToolExe
 		"enable_thinking": true,
 	}
 
-	retryCfg := retry.DefaultConfig()
-	resp, err := retry.DoWithResult(ctx, retryCfg, func() (openai.ChatCompletionResponse, error) {
-		return t.client.CreateChatCompletion(ctx, req)
+	var resp openai.ChatCompletionResponse
+	model, err := t.router.Do(ctx, t.modelConfig, func(model *config.ModelConfig, provider llm.Provider) error {
+		req.Model = model.Name
+		var err error
+		resp, err = provider.CreateChatCompletion(ctx, req)
+		return err
 	})
 	duration := time.Since(startTime).Milliseconds()
 	durationSec := float64(duration) / 1000.0
 
 	if err != nil {
-		middleware.LlmCallsTotal.WithLabelValues(t.modelConfig.Name, "error").Inc()
-		middleware.LlmCallDuration.WithLabelValues(t.modelConfig.Name).Observe(durationSec)
+		middleware.LlmCallsTotal.WithLabelValues(model.Name, "error").Inc()
+		middleware.LlmCallDuration.WithLabelValues(model.Name).Observe(durationSec)
 		t.logger.Error("批量回传结果失败", logging.Err(err))
-		t.sendEvent(NewThinkingEvent(ThinkingEventError, err.Error()))
+		t.sendEvent(ctx, NewThinkingEvent(ThinkingEventError, err.Error()))
 		return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "return func results failed")
 	}
 
-	middleware.LlmCallsTotal.WithLabelValues(t.modelConfig.Name, "success").Inc()
-	middleware.LlmCallDuration.WithLabelValues(t.modelConfig.Name).Observe(durationSec)
-	middleware.TokenUsageTotal.WithLabelValues(t.modelConfig.Name, "prompt").Add(float64(resp.Usage.PromptTokens))
-	middleware.TokenUsageTotal.WithLabelValues(t.modelConfig.Name, "completion").Add(float64(resp.Usage.CompletionTokens))
+	middleware.LlmCallsTotal.WithLabelValues(model.Name, "success").Inc()
+	middleware.LlmCallDuration.WithLabelValues(model.Name).Observe(durationSec)
+	middleware.TokenUsageTotal.WithLabelValues(model.Name, "prompt").Add(float64(resp.Usage.PromptTokens))
+	middleware.TokenUsageTotal.WithLabelValues(model.Name, "completion").Add(float64(resp.Usage.CompletionTokens))
 
-	if t.tokenUsageRepo != nil {
-		usage := &entity.TokenUsage{
-			Model:            t.modelConfig.Name,
-			Duration:         duration,
-			CompletionTokens: int(resp.Usage.CompletionTokens),
-			TotalTokens:      int(resp.Usage.TotalTokens),
-			PromptTokens:     int(resp.Usage.PromptTokens),
-			CreatedAt:        time.Now(),
-		}
-		if err := t.tokenUsageRepo.Save(usage); err != nil {
-			t.logger.Warn("保存 token 用量失败", logging.Err(err))
-		}
-	}
+	t.notifyFallback(ctx, model)
+	t.saveTokenUsage(model, duration, resp.Usage)
 
 	if len(resp.Choices) == 0 {
 		return nil, apperrors.New(apperrors.ErrTypeModel, "no response result")
@@ -828,7 +929,7 @@ func (t *Thinking) ReturnFuncResults(ctx context.Context, results []core.ToolExe
 			if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
 				return nil, apperrors.Wrap(err, apperrors.ErrTypeModel, "parse func params failed")
 			}
-			t.sendEvent(NewToolCallEvent(toolCall.Function.Name, args))
+			t.sendEvent(ctx, NewToolCallEvent(toolCall.Function.Name, args))
 			items = append(items, core.ToolCallItem{
 				ToolCallID: toolCall.ID,
 				Function: &core.ToolCallFunction{
@@ -847,7 +948,7 @@ func (t *Thinking) ReturnFuncResults(ctx context.Context, results []core.ToolExe
 
 	content := strings.TrimSpace(choice.Message.Content)
 	t.logger.Info(i18n.T("brain.get_final_response"), logging.String(i18n.T("brain.content"), content))
-	t.sendEvent(NewThinkingEventWithProgress(ThinkingEventComplete, content, 100))
+	t.sendEvent(ctx, NewThinkingEventWithProgress(ThinkingEventComplete, content, 100))
 
 	return &core.ToolCallResult{
 		Answer: content,
@@ -17,6 +17,8 @@ const (
 	ThinkingEventToolResult = entity.ThinkingEventToolResult
 	ThinkingEventComplete   = entity.ThinkingEventComplete
 	ThinkingEventError      = entity.ThinkingEventError
+	ThinkingEventApproval   = entity.ThinkingEventApproval
+	ThinkingEventFallback   = entity.ThinkingEventFallback
 )
 
 type ThinkingEvent = entity.ThinkingEvent
@@ -70,3 +72,16 @@ func NewToolResultEvent(toolName string, result string) ThinkingEvent {
 		Timestamp: time.Now(),
 	}
 }
+
+// NewFallbackEvent 模型服务不可用、改用降级链中的其他模型时发送
+func NewFallbackEvent(requestedModel, model string) ThinkingEvent {
+	return ThinkingEvent{
+		Type:    ThinkingEventFallback,
+		Content: fmt.Sprintf(i18n.T("model.fallback_event"), requestedModel, model),
+		Metadata: map[string]any{
+			"requested_model": requestedModel,
+			"model":           model,
+		},
+		Timestamp: time.Now(),
+	}
+}
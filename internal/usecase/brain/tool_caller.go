@@ -3,27 +3,49 @@ package brain
 import (
 	"context"
 	"fmt"
+	"mindx/internal/config"
 	"mindx/internal/core"
+	"mindx/internal/entity"
 	apperrors "mindx/internal/errors"
+	"mindx/internal/usecase/approval"
 	"mindx/internal/usecase/skills"
 	"mindx/pkg/i18n"
 	"mindx/pkg/logging"
+	"sync"
+	"time"
 )
 
 const maxToolCalls = 10
 
 type ToolCaller struct {
-	skillMgr *skills.SkillMgr
-	logger   logging.Logger
+	skillMgr    *skills.SkillMgr
+	approval    *approval.Manager
+	maxParallel int
+	callTimeout time.Duration
+	logger      logging.Logger
 }
 
 func NewToolCaller(skillMgr *skills.SkillMgr, logger logging.Logger) *ToolCaller {
+	var defaults config.ToolCallConfig
 	return &ToolCaller{
-		skillMgr: skillMgr,
-		logger:   logger,
+		skillMgr:    skillMgr,
+		maxParallel: defaults.GetMaxParallel(),
+		callTimeout: defaults.GetTimeout(),
+		logger:      logger,
 	}
 }
 
+// SetExecConfig 设置同一轮工具调用的并发数和单个调用的超时
+func (tc *ToolCaller) SetExecConfig(cfg config.ToolCallConfig) {
+	tc.maxParallel = cfg.GetMaxParallel()
+	tc.callTimeout = cfg.GetTimeout()
+}
+
+// SetApproval 设置工具调用审批管理器，为 nil 时所有工具直接执行
+func (tc *ToolCaller) SetApproval(mgr *approval.Manager) {
+	tc.approval = mgr
+}
+
 func (tc *ToolCaller) ExecuteToolCall(
 	ctx context.Context,
 	thinking core.Thinking,
@@ -71,41 +93,14 @@ func (tc *ToolCaller) ExecuteToolCall(
 			logging.Int("count", len(pendingCalls)),
 			logging.Int("round_call_count", callCount))
 
-		execResults := make([]core.ToolExecResult, 0, len(pendingCalls))
-		for _, item := range pendingCalls {
-			if callCount >= maxToolCalls {
-				tc.logger.Warn("达到最大工具调用次数，跳过剩余工具")
-				break
-			}
-			callCount++
-
-			tc.logger.Info(i18n.T("brain.execute_skill"),
-				logging.String(i18n.T("brain.function"), item.Function.Name),
-				logging.String(i18n.T("brain.arguments"), fmt.Sprintf("%v", item.Function.Arguments)))
-
-			funcResult, execErr := tc.skillMgr.ExecuteFunc(core.ToolCallFunction{
-				Name:      item.Function.Name,
-				Arguments: item.Function.Arguments,
-			})
-
-			er := core.ToolExecResult{
-				ToolCallID:   item.ToolCallID,
-				FunctionName: item.Function.Name,
-				Arguments:    item.Function.Arguments,
-			}
-			if execErr != nil {
-				tc.logger.Warn("工具执行失败",
-					logging.String("function", item.Function.Name),
-					logging.Err(execErr))
-				er.Error = execErr.Error()
-				er.Result = fmt.Sprintf("执行失败: %s", execErr.Error())
-			} else {
-				tc.logger.Info(i18n.T("brain.skill_exec_success"),
-					logging.String(i18n.T("brain.result"), funcResult))
-				er.Result = funcResult
-			}
-			execResults = append(execResults, er)
+		if remaining := maxToolCalls - callCount; len(pendingCalls) > remaining {
+			tc.logger.Warn("达到最大工具调用次数，跳过剩余工具",
+				logging.Int("skipped", len(pendingCalls)-remaining))
+			pendingCalls = pendingCalls[:remaining]
 		}
+		callCount += len(pendingCalls)
+
+		execResults := tc.executeBatch(ctx, pendingCalls)
 
 		// 批量回传所有结果给 LLM
 		batchResult, err := thinking.ReturnFuncResults(ctx, execResults, currentHistory, tools, question)
@@ -138,8 +133,193 @@ func (tc *ToolCaller) ExecuteToolCall(
 	return finalAnswer, nil
 }
 
-func (tc *ToolCaller) SearchTools(keywords []string) ([]core.ToolSchema, error) {
-	skills, err := tc.skillMgr.SearchSkills(keywords...)
+// executeBatch 执行模型同一轮返回的工具调用
+// 可并发的调用受 maxParallel 限制并行执行，non_concurrent 技能的调用按原顺序依次执行；
+// 返回结果与 calls 一一对应，保持 ToolCallID 顺序
+func (tc *ToolCaller) executeBatch(ctx context.Context, calls []core.ToolCallItem) []core.ToolExecResult {
+	results := make([]core.ToolExecResult, len(calls))
+	if len(calls) == 1 || tc.maxParallel <= 1 {
+		for i, item := range calls {
+			results[i] = tc.executeOne(ctx, item)
+		}
+		return results
+	}
+
+	sem := make(chan struct{}, tc.maxParallel)
+	run := func(i int) {
+		sem <- struct{}{}
+		defer func() { <-sem }()
+		results[i] = tc.executeOne(ctx, calls[i])
+	}
+
+	var wg sync.WaitGroup
+	var serial []int
+	for i, item := range calls {
+		if tc.isNonConcurrent(item.Function.Name) {
+			serial = append(serial, i)
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			run(i)
+		}(i)
+	}
+	if len(serial) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, i := range serial {
+				run(i)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// executeOne 执行单个工具调用，失败、超时或未获批准时把原因作为结果回传给模型
+func (tc *ToolCaller) executeOne(ctx context.Context, item core.ToolCallItem) core.ToolExecResult {
+	tc.logger.Info(i18n.T("brain.execute_skill"),
+		logging.String(i18n.T("brain.function"), item.Function.Name),
+		logging.String(i18n.T("brain.arguments"), fmt.Sprintf("%v", item.Function.Arguments)))
+
+	er := core.ToolExecResult{
+		ToolCallID:   item.ToolCallID,
+		FunctionName: item.Function.Name,
+		Arguments:    item.Function.Arguments,
+	}
+
+	// 需要确认的工具未获批准时不执行
+	if reason, approved := tc.awaitApproval(ctx, item.Function.Name, item.Function.Arguments); !approved {
+		er.Error = reason
+		er.Result = reason
+		return er
+	}
+
+	funcResult, attachments, execErr := tc.executeWithTimeout(ctx, core.ToolCallFunction{
+		Name:      item.Function.Name,
+		Arguments: item.Function.Arguments,
+	})
+	collectAttachments(ctx, attachments)
+
+	if execErr != nil {
+		tc.logger.Warn("工具执行失败",
+			logging.String("function", item.Function.Name),
+			logging.Err(execErr))
+		er.Error = execErr.Error()
+		er.Result = fmt.Sprintf("执行失败: %s", execErr.Error())
+	} else {
+		tc.logger.Info(i18n.T("brain.skill_exec_success"),
+			logging.String(i18n.T("brain.result"), funcResult))
+		er.Result = funcResult
+	}
+	return er
+}
+
+// executeWithTimeout 在单个调用超时内执行技能
+// 超时或请求取消时通过 ctx 通知技能中止，并且不再等待不响应 ctx 的技能，其产出的附件随之丢弃
+func (tc *ToolCaller) executeWithTimeout(ctx context.Context, function core.ToolCallFunction) (string, []*entity.Attachment, error) {
+	type outcome struct {
+		result      string
+		attachments []*entity.Attachment
+		err         error
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, tc.callTimeout)
+	defer cancel()
+
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: fmt.Errorf("tool %s panicked: %v", function.Name, r)}
+			}
+		}()
+		result, attachments, err := tc.skillMgr.ExecuteFuncWithAttachments(callCtx, function)
+		done <- outcome{result: result, attachments: attachments, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.attachments, o.err
+	case <-callCtx.Done():
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+		return "", nil, fmt.Errorf("tool %s timed out after %s", function.Name, tc.callTimeout)
+	}
+}
+
+// isNonConcurrent 技能是否声明了不能并发执行
+func (tc *ToolCaller) isNonConcurrent(name string) bool {
+	info, ok := tc.skillMgr.GetSkillInfo(name)
+	return ok && info.Def != nil && info.Def.NonConcurrent
+}
+
+// awaitApproval 需要确认的工具调用挂起等待用户决定
+// 通过思考流推送 approval 事件，用户经 WebSocket、TUI、IM 回复或 API 确认；未批准时返回原因
+func (tc *ToolCaller) awaitApproval(ctx context.Context, name string, args map[string]any) (string, bool) {
+	if tc.approval == nil {
+		return "", true
+	}
+
+	var def *entity.SkillDef
+	if info, ok := tc.skillMgr.GetSkillInfo(name); ok {
+		def = info.Def
+	}
+	if !tc.approval.RequiresApproval(name, def, args) {
+		return "", true
+	}
+
+	var key entity.SessionKey
+	if state := requestStateFrom(ctx); state != nil {
+		key = state.key
+	}
+	eventChan := core.ThinkingEventsFromContext(ctx)
+
+	decision := tc.approval.Request(ctx, key, name, args, func(req *approval.Request) bool {
+		if eventChan == nil {
+			return false
+		}
+		event := ThinkingEvent{
+			Type:      ThinkingEventApproval,
+			Content:   fmt.Sprintf(i18n.T("brain.approval_required"), name),
+			Timestamp: time.Now(),
+			Metadata: map[string]any{
+				"approval_id": req.ID,
+				"skill":       name,
+				"arguments":   args,
+				"expires_at":  req.ExpiresAt.Unix(),
+			},
+		}
+		// 与其他思考事件一样不阻塞：思考流已满时丢弃，视为提示未送达
+		select {
+		case eventChan <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		default:
+			return false
+		}
+	})
+
+	switch decision {
+	case approval.DecisionApproved:
+		return "", true
+	case approval.DecisionTimeout:
+		return fmt.Sprintf("用户未在规定时间内确认，工具 %s 未执行", name), false
+	case approval.DecisionCanceled:
+		return fmt.Sprintf("请求已取消，工具 %s 未执行", name), false
+	case approval.DecisionUnreachable:
+		return fmt.Sprintf("当前请求无法向用户发起确认，需要确认的工具 %s 未执行", name), false
+	default:
+		return fmt.Sprintf("用户拒绝执行工具 %s", name), false
+	}
+}
+
+func (tc *ToolCaller) SearchTools(ctx context.Context, keywords []string) ([]core.ToolSchema, error) {
+	skills, err := tc.skillMgr.SearchSkills(ctx, keywords...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,172 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"mindx/internal/config"
+	"mindx/internal/core"
+	"mindx/internal/usecase/skills"
+	"mindx/pkg/logging"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedThinking 按预设返回工具调用，并记录回传的执行结果
+type scriptedThinking struct {
+	calls   []core.ToolCallItem
+	mu      sync.Mutex
+	results []core.ToolExecResult
+}
+
+func (t *scriptedThinking) Think(context.Context, string, []*core.DialogueMessage, string, bool) (*core.ThinkingResult, error) {
+	return &core.ThinkingResult{}, nil
+}
+
+func (t *scriptedThinking) ThinkWithTools(context.Context, string, []*core.DialogueMessage, []*core.ToolSchema, ...string) (*core.ToolCallResult, error) {
+	return &core.ToolCallResult{
+		Function:  t.calls[0].Function,
+		ToolCalls: t.calls,
+	}, nil
+}
+
+func (t *scriptedThinking) ReturnFuncResult(context.Context, string, string, string, map[string]interface{}, []*core.DialogueMessage, []*core.ToolSchema, string) (string, error) {
+	return "", nil
+}
+
+func (t *scriptedThinking) ReturnFuncResults(_ context.Context, results []core.ToolExecResult, _ []*core.DialogueMessage, _ []*core.ToolSchema, _ string) (*core.ToolCallResult, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.results = append(t.results, results...)
+	return &core.ToolCallResult{NoCall: true, Answer: "done"}, nil
+}
+
+func (t *scriptedThinking) CalculateMaxHistoryCount() int { return 0 }
+func (t *scriptedThinking) GetSystemPrompt() string       { return "" }
+
+func writeTestSkill(t *testing.T, dir, name string, nonConcurrent bool) {
+	t.Helper()
+	content := fmt.Sprintf("---\nname: %s\ndescription: test skill %s\nenabled: true\ntimeout: 10\nis_internal: true\nnon_concurrent: %v\n---\n", name, name, nonConcurrent)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name, "SKILL.md"), []byte(content), 0644))
+}
+
+func toolCall(id, name string) core.ToolCallItem {
+	return core.ToolCallItem{
+		ToolCallID: id,
+		Function:   &core.ToolCallFunction{Name: name, Arguments: map[string]any{}},
+	}
+}
+
+// TestToolCaller_ParallelExecution 验证同一轮工具调用并发执行、non_concurrent 技能串行执行，结果保持调用顺序
+func TestToolCaller_ParallelExecution(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillsDir := filepath.Join(tmpDir, "skills")
+	writeTestSkill(t, skillsDir, "slow_search", false)
+	writeTestSkill(t, skillsDir, "slow_weather", false)
+	writeTestSkill(t, skillsDir, "file_writer", true)
+
+	logger := logging.GetSystemLogger().Named("tool_caller_test")
+	mgr, err := skills.NewSkillMgr(skillsDir, tmpDir, nil, nil, logger)
+	require.NoError(t, err)
+	defer mgr.Close()
+
+	const delay = 200 * time.Millisecond
+	mgr.RegisterInternalSkill("slow_search", func(context.Context, map[string]any) (string, error) {
+		time.Sleep(delay)
+		return "search", nil
+	})
+	mgr.RegisterInternalSkill("slow_weather", func(context.Context, map[string]any) (string, error) {
+		time.Sleep(delay)
+		return "weather", nil
+	})
+
+	var running, maxRunning int32
+	mgr.RegisterInternalSkill("file_writer", func(context.Context, map[string]any) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(delay / 2)
+		return "written", nil
+	})
+
+	thinking := &scriptedThinking{calls: []core.ToolCallItem{
+		toolCall("call_1", "slow_search"),
+		toolCall("call_2", "file_writer"),
+		toolCall("call_3", "slow_weather"),
+		toolCall("call_4", "file_writer"),
+	}}
+
+	tc := NewToolCaller(mgr, logger)
+	tc.SetExecConfig(config.ToolCallConfig{MaxParallel: 4})
+
+	start := time.Now()
+	answer, err := tc.ExecuteToolCall(context.Background(), thinking, "q", nil, nil)
+	elapsed := time.Since(start)
+	require.NoError(t, err)
+	assert.Equal(t, "done", answer)
+
+	// 串行执行需要 3*delay，并发执行约为 delay
+	assert.Less(t, elapsed, 2*delay+delay/2)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&maxRunning))
+
+	require.Len(t, thinking.results, 4)
+	ids := make([]string, 0, 4)
+	for _, r := range thinking.results {
+		ids = append(ids, r.ToolCallID)
+	}
+	assert.Equal(t, []string{"call_1", "call_2", "call_3", "call_4"}, ids)
+	assert.Equal(t, "search", thinking.results[0].Result)
+	assert.Equal(t, "written", thinking.results[1].Result)
+	assert.Equal(t, "weather", thinking.results[2].Result)
+}
+
+// TestToolCaller_CallTimeout 验证单个工具调用超时后以错误结果回传，不阻塞其它调用
+func TestToolCaller_CallTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillsDir := filepath.Join(tmpDir, "skills")
+	writeTestSkill(t, skillsDir, "hang", false)
+	writeTestSkill(t, skillsDir, "quick", false)
+
+	logger := logging.GetSystemLogger().Named("tool_caller_test")
+	mgr, err := skills.NewSkillMgr(skillsDir, tmpDir, nil, nil, logger)
+	require.NoError(t, err)
+	defer mgr.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	mgr.RegisterInternalSkill("hang", func(context.Context, map[string]any) (string, error) {
+		<-release
+		return "late", nil
+	})
+	mgr.RegisterInternalSkill("quick", func(context.Context, map[string]any) (string, error) {
+		return "ok", nil
+	})
+
+	thinking := &scriptedThinking{calls: []core.ToolCallItem{
+		toolCall("call_1", "hang"),
+		toolCall("call_2", "quick"),
+	}}
+
+	tc := NewToolCaller(mgr, logger)
+	tc.SetExecConfig(config.ToolCallConfig{Timeout: 1})
+
+	_, err = tc.ExecuteToolCall(context.Background(), thinking, "q", nil, nil)
+	require.NoError(t, err)
+
+	require.Len(t, thinking.results, 2)
+	assert.Contains(t, thinking.results[0].Error, "timed out")
+	assert.Empty(t, thinking.results[1].Error)
+	assert.Equal(t, "ok", thinking.results[1].Result)
+}
@@ -142,7 +142,7 @@ func (s *ToolExecutionSuite) TestToolExecution_Calculator() {
 	}
 	searchKeywords = append(searchKeywords, result.Keywords...)
 
-	toolSchemas, err := s.toolCaller.SearchTools(searchKeywords)
+	toolSchemas, err := s.toolCaller.SearchTools(context.Background(), searchKeywords)
 	assert.NoError(s.T(), err)
 
 	s.T().Logf("搜索到 %d 个工具", len(toolSchemas))
@@ -189,7 +189,7 @@ func (s *ToolExecutionSuite) TestToolExecution_Sysinfo() {
 	}
 	searchKeywords = append(searchKeywords, result.Keywords...)
 
-	toolSchemas, err := s.toolCaller.SearchTools(searchKeywords)
+	toolSchemas, err := s.toolCaller.SearchTools(context.Background(), searchKeywords)
 	assert.NoError(s.T(), err)
 
 	if len(toolSchemas) == 0 {
@@ -237,7 +237,7 @@ func (s *ToolExecutionSuite) TestToolExecution_Weather() {
 	}
 	searchKeywords = append(searchKeywords, result.Keywords...)
 
-	toolSchemas, err := s.toolCaller.SearchTools(searchKeywords)
+	toolSchemas, err := s.toolCaller.SearchTools(context.Background(), searchKeywords)
 	assert.NoError(s.T(), err)
 
 	if len(toolSchemas) == 0 {
@@ -316,7 +316,7 @@ func (s *ToolExecutionSuite) TestToolExecution_MultiToolScenario() {
 	question := "帮我算一下 100 加 200"
 
 	// 故意传入多个工具，验证右脑能选对
-	allSchemas, err := s.toolCaller.SearchTools([]string{"calculator", "sysinfo", "weather"})
+	allSchemas, err := s.toolCaller.SearchTools(context.Background(), []string{"calculator", "sysinfo", "weather"})
 	if err != nil || len(allSchemas) == 0 {
 		s.T().Skip("搜索工具失败或无工具")
 	}
@@ -405,7 +405,7 @@ func (s *ToolExecutionSuite) TestToolExecution_FullPipeline() {
 			s.T().Logf("[搜索] 关键词: %v", searchKeywords)
 
 			// ===== 第3步：搜索工具 =====
-			toolSchemas, err := s.toolCaller.SearchTools(searchKeywords)
+			toolSchemas, err := s.toolCaller.SearchTools(context.Background(), searchKeywords)
 			assert.NoError(s.T(), err)
 			s.T().Logf("[搜索] 找到 %d 个工具: %v", len(toolSchemas), toolSchemaNames(toolSchemas))
 
@@ -5,18 +5,17 @@ import (
 	"mindx/internal/config"
 	"mindx/internal/core"
 	"mindx/internal/entity"
+	"mindx/internal/infrastructure/llm"
 	"mindx/internal/usecase/embedding"
+	"mindx/internal/usecase/modelrouter"
 	"os"
 	"path/filepath"
 	"sync"
-
-	openai "github.com/sashabaranov/go-openai"
 )
 
 // CapabilityManager 能力管理器
 type CapabilityManager struct {
 	capabilities map[string]*entity.Capability
-	clients      map[string]*openai.Client
 	embeddingSvc *embedding.EmbeddingService
 	vectorStore  core.Store
 	configPath   string
@@ -37,7 +36,6 @@ func NewManager(cfg *config.CapabilityConfig, vectorStore core.Store, embeddingS
 
 	mgr := &CapabilityManager{
 		capabilities: make(map[string]*entity.Capability),
-		clients:      make(map[string]*openai.Client),
 		embeddingSvc: embeddingSvc,
 		vectorStore:  vectorStore,
 		configPath:   configPath,
@@ -113,35 +111,9 @@ func NewManager(cfg *config.CapabilityConfig, vectorStore core.Store, embeddingS
 		}
 	}
 
-	// 初始化客户端
-	mgr.initClients()
-
 	return mgr, nil
 }
 
-// initClients 初始化 OpenAI 客户端
-// 注意：调用者必须持有 m.mu 锁
-func (m *CapabilityManager) initClients() {
-	modelsMgr := config.GetModelsManager()
-	for name, cap := range m.capabilities {
-		if !cap.Enabled {
-			continue
-		}
-
-		modelConfig, err := modelsMgr.GetModel(cap.Model)
-		if err != nil {
-			continue
-		}
-
-		var client *openai.Client
-		config := openai.DefaultConfig(modelConfig.APIKey)
-		config.BaseURL = modelConfig.BaseURL
-		client = openai.NewClientWithConfig(config)
-
-		m.clients[name] = client
-	}
-}
-
 // saveToConfigFile 保存配置到文件
 // 注意：调用者必须持有 m.mu 锁
 func (m *CapabilityManager) saveToConfigFile() error {
@@ -177,23 +149,27 @@ func (m *CapabilityManager) GetCapability(name string) (*entity.Capability, bool
 	return cap, exists
 }
 
-// GetClient 获取能力的客户端
-func (m *CapabilityManager) GetClient(name string) (*openai.Client, error) {
+// GetClient 获取能力的客户端，返回实际使用的模型和对话模型服务
+// 能力模型的服务熔断时，返回模型降级链中第一个可用的模型；请求的 Model 应使用返回的模型名称
+func (m *CapabilityManager) GetClient(name string) (*config.ModelConfig, llm.Provider, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	cap, exists := m.capabilities[name]
 	if !exists {
-		return nil, fmt.Errorf("能力 '%s' 不存在", name)
+		return nil, nil, fmt.Errorf("能力 '%s' 不存在", name)
 	}
 
 	if !cap.Enabled {
-		return nil, fmt.Errorf("能力 '%s' 已禁用", name)
+		return nil, nil, fmt.Errorf("能力 '%s' 已禁用", name)
 	}
 
-	client, exists := m.clients[name]
-	if !exists {
-		return nil, fmt.Errorf("能力 '%s' 的客户端未初始化", name)
+	modelConfig, err := config.GetModelsManager().GetModel(cap.Model)
+	if err != nil {
+		return nil, nil, fmt.Errorf("能力 '%s' 的客户端未初始化: %w", name, err)
 	}
 
-	return client, nil
+	return modelrouter.Default().Select(modelConfig)
 }
 
 // ListCapabilities 列出所有能力
@@ -250,7 +226,6 @@ func (m *CapabilityManager) EnableCapability(name string) error {
 	}
 
 	cap.Enabled = true
-	m.initClients()
 
 	return m.saveToConfigFile()
 }
@@ -266,7 +241,6 @@ func (m *CapabilityManager) DisableCapability(name string) error {
 	}
 
 	cap.Enabled = false
-	delete(m.clients, name)
 
 	return m.saveToConfigFile()
 }
@@ -283,10 +257,6 @@ func (m *CapabilityManager) AddCapability(cap entity.Capability) error {
 
 	m.capabilities[cap.Name] = &cap
 
-	if cap.Enabled {
-		m.initClients()
-	}
-
 	return m.saveToConfigFile()
 }
 
@@ -317,8 +287,6 @@ func (m *CapabilityManager) UpdateCapability(name string, updates entity.Capabil
 		cap.SystemPrompt = updates.SystemPrompt
 	}
 
-	m.initClients()
-
 	return m.saveToConfigFile()
 }
 
@@ -332,7 +300,6 @@ func (m *CapabilityManager) RemoveCapability(name string) error {
 	}
 
 	delete(m.capabilities, name)
-	delete(m.clients, name)
 
 	return m.saveToConfigFile()
 }
@@ -382,8 +349,8 @@ func validateConfig(config *config.CapabilityConfig) error {
 }
 
 // Close 关闭能力管理器
+// 客户端由 modelrouter 统一管理，无需释放
 func (m *CapabilityManager) Close() {
-	m.clients = make(map[string]*openai.Client)
 }
 
 // PrecomputeVectors 预计算所有能力的向量
@@ -0,0 +1,80 @@
+package capability
+
+import (
+	"fmt"
+	"mindx/internal/core"
+	"mindx/internal/usecase/embedding"
+	"strings"
+)
+
+// migrationSource 能力向量的嵌入模型迁移数据源
+type migrationSource struct {
+	m *CapabilityManager
+}
+
+// MigrationSource 返回能力的嵌入模型迁移数据源
+func (m *CapabilityManager) MigrationSource() embedding.MigrationSource {
+	return migrationSource{m: m}
+}
+
+func (s migrationSource) Collection() string {
+	return core.CollectionCapabilities
+}
+
+func (s migrationSource) Store() core.Store {
+	return s.m.vectorStore
+}
+
+func (s migrationSource) StoredDimension() int {
+	if s.m.vectorStore == nil {
+		return 0
+	}
+	return s.m.vectorStore.Dimension()
+}
+
+func (s migrationSource) ReembedKeys() ([]string, error) {
+	if s.m.vectorStore == nil {
+		return nil, nil
+	}
+	entries, err := s.m.vectorStore.Scan("capability:")
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	return keys, nil
+}
+
+// Reembed 重新生成能力的向量；能力已被删除时移除残留的向量
+func (s migrationSource) Reembed(key string) error {
+	if s.m.embeddingSvc == nil {
+		return fmt.Errorf("向量化服务未设置")
+	}
+
+	s.m.mu.RLock()
+	cap, exists := s.m.capabilities[strings.TrimPrefix(key, "capability:")]
+	s.m.mu.RUnlock()
+	if !exists {
+		return s.m.vectorStore.Delete(key)
+	}
+
+	vec, err := s.m.embeddingSvc.GenerateEmbedding(fmt.Sprintf("%s %s", cap.Description, cap.SystemPrompt))
+	if err != nil {
+		return err
+	}
+	if err := s.m.vectorStore.Put(key, vec, nil); err != nil {
+		return err
+	}
+
+	s.m.mu.Lock()
+	cap.Vector = vec
+	s.m.mu.Unlock()
+	return nil
+}
+
+// ReloadVectors 能力向量在 Reembed 中已同步更新
+func (s migrationSource) ReloadVectors() error {
+	return nil
+}
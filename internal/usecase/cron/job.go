@@ -11,15 +11,44 @@ const (
 	JobStatusError   JobStatus = "error"
 )
 
+// CatchUpPolicy 进程停止期间错过的执行如何补跑
+type CatchUpPolicy string
+
+const (
+	CatchUpSkip CatchUpPolicy = "skip" // 不补跑
+	CatchUpOnce CatchUpPolicy = "once" // 无论错过几次只补跑一次
+	CatchUpAll  CatchUpPolicy = "all"  // 逐次补跑每个错过的时间点
+)
+
 type Job struct {
-	ID         string     `json:"id"`
-	Name       string     `json:"name"`
-	Cron       string     `json:"cron"`
-	Message    string     `json:"message"`
-	Command    string     `json:"command"`
-	Enabled    bool       `json:"enabled"`
-	CreatedAt  time.Time  `json:"created_at"`
-	LastRun    *time.Time `json:"last_run,omitempty"`
-	LastStatus JobStatus  `json:"last_status"`
-	LastError  *string    `json:"last_error,omitempty"`
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Cron       string        `json:"cron"`
+	Message    string        `json:"message"`
+	Command    string        `json:"command"`
+	Channel    string        `json:"channel,omitempty"`    // 投递结果的 Channel，为空时使用调度器默认 Channel
+	SessionID  string        `json:"session_id,omitempty"` // 投递结果的会话，即 Channel 中的接收方（如 Telegram chat ID）
+	SenderID   string        `json:"sender_id,omitempty"`  // 创建任务的用户在 Channel 中的 ID，执行时以该用户身份提问
+	Capability string        `json:"capability,omitempty"` // 处理任务消息使用的能力，为空时由大脑自动判断
+	Timezone   string        `json:"timezone,omitempty"`   // IANA 时区，如 Asia/Shanghai，为空时使用调度器默认时区
+	CatchUp    CatchUpPolicy `json:"catch_up,omitempty"`   // 为空时使用调度器默认策略
+	Enabled    bool          `json:"enabled"`
+	CreatedAt  time.Time     `json:"created_at"`
+	LastRun    *time.Time    `json:"last_run,omitempty"`
+	LastStatus JobStatus     `json:"last_status"`
+	LastError  *string       `json:"last_error,omitempty"`
+	NextRun    *time.Time    `json:"next_run,omitempty"` // 下次执行时间，由进程内调度器在查询时填充
+}
+
+// Execution 一次任务执行记录
+type Execution struct {
+	JobID       string    `json:"job_id"`
+	JobName     string    `json:"job_name,omitempty"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Status      JobStatus `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	CatchUp     bool      `json:"catch_up,omitempty"` // 是否为补跑
+	Manual      bool      `json:"manual,omitempty"`   // 是否为手动触发
 }
@@ -12,6 +12,11 @@ type Scheduler interface {
 	UpdateLastRun(id string, status JobStatus, errMsg *string) error
 }
 
+// HistoryProvider 提供任务执行历史的调度器
+type HistoryProvider interface {
+	History(id string, limit int) ([]*Execution, error)
+}
+
 type SkillInfoProvider interface {
 	GetSkillInfo(name string) (isInternal bool, command string, dir string, err error)
 }
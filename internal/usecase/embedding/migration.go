@@ -0,0 +1,482 @@
+package embedding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mindx/internal/core"
+	"mindx/pkg/i18n"
+	"mindx/pkg/logging"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetaKey 集合中记录嵌入模型与向量维度的条目 key，该条目没有向量，不参与检索
+const MetaKey = "embedding_meta"
+
+// ErrMigrationRunning 已有迁移任务在运行
+var ErrMigrationRunning = errors.New("embedding migration is already running")
+
+const (
+	// saveStateEvery 每处理多少个条目保存一次迁移进度
+	saveStateEvery = 20
+	// maxConsecutiveFailures 连续失败达到该次数时中止迁移，保留进度等待下次继续
+	maxConsecutiveFailures = 5
+)
+
+// 迁移任务状态
+const (
+	MigrationIdle      = "idle"
+	MigrationRunning   = "running"
+	MigrationCompleted = "completed"
+	MigrationFailed    = "failed"
+)
+
+// Meta 集合中向量使用的嵌入模型和维度
+type Meta struct {
+	Model     string    `json:"model"`
+	Dimension int       `json:"dimension"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReadMeta 读取集合的嵌入元数据，没有记录时返回 nil
+func ReadMeta(store core.Store) (*Meta, error) {
+	entry, err := store.Get(MetaKey)
+	if err != nil || entry == nil || len(entry.Metadata) == 0 {
+		// 各存储实现对 key 不存在的处理不同，统一视为没有记录
+		return nil, nil
+	}
+	var meta Meta
+	if err := json.Unmarshal(entry.Metadata, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// WriteMeta 记录集合的嵌入模型和维度
+func WriteMeta(store core.Store, meta Meta) error {
+	meta.UpdatedAt = time.Now()
+	return store.Put(MetaKey, []float64{}, meta)
+}
+
+// MigrationSource 需要随嵌入模型迁移的向量数据，由记忆、技能和能力各自提供
+type MigrationSource interface {
+	// Collection 数据所在集合的名称
+	Collection() string
+	// Store 数据所在的集合，用于读写嵌入元数据
+	Store() core.Store
+	// StoredDimension 已存储向量的维度，没有向量时为 0
+	StoredDimension() int
+	// ReembedKeys 返回需要重新生成向量的全部条目
+	ReembedKeys() ([]string, error)
+	// Reembed 用当前的嵌入模型重新生成一个条目的向量并写回
+	Reembed(key string) error
+	// ReloadVectors 集合迁移完成后刷新内存中的向量
+	ReloadVectors() error
+}
+
+// Mismatch 集合中已存储的向量与当前嵌入模型不一致
+type Mismatch struct {
+	Collection      string `json:"collection"`
+	StoredModel     string `json:"stored_model,omitempty"` // 没有元数据记录时为空
+	StoredDimension int    `json:"stored_dimension"`
+	Model           string `json:"model"`
+	Dimension       int    `json:"dimension"`
+}
+
+// CollectionProgress 单个集合的迁移进度
+type CollectionProgress struct {
+	Collection string `json:"collection"`
+	Total      int    `json:"total"`
+	Done       int    `json:"done"`
+	Failed     int    `json:"failed"`
+}
+
+// MigrationProgress 迁移任务的进度
+type MigrationProgress struct {
+	Status      string               `json:"status"`
+	Model       string               `json:"model"`
+	Dimension   int                  `json:"dimension"`
+	Collections []CollectionProgress `json:"collections"`
+	StartedAt   time.Time            `json:"started_at,omitempty"`
+	FinishedAt  time.Time            `json:"finished_at,omitempty"`
+	Error       string               `json:"error,omitempty"`
+}
+
+// migrationState 持久化的迁移进度，进程重启后从剩余的条目继续
+type migrationState struct {
+	Model       string               `json:"model"`
+	Dimension   int                  `json:"dimension"`
+	StartedAt   time.Time            `json:"started_at"`
+	Pending     map[string][]string  `json:"pending"` // 集合名 -> 尚未处理的 key
+	Collections []CollectionProgress `json:"collections"`
+}
+
+// Migrator 嵌入模型迁移任务
+// 更换嵌入模型后，已存储的向量与新模型生成的查询向量不可比较（维度不同时甚至无法写入），
+// 迁移任务在后台为记忆、技能和能力重新生成向量，进度保存在 stateFile 中，中断后可继续
+type Migrator struct {
+	service   *EmbeddingService
+	model     string
+	stateFile string
+	sources   []MigrationSource
+	logger    logging.Logger
+
+	mu       sync.Mutex
+	running  bool
+	progress MigrationProgress
+}
+
+// NewMigrator 创建迁移任务，model 为当前配置的嵌入模型
+func NewMigrator(service *EmbeddingService, model string, stateFile string, logger logging.Logger, sources ...MigrationSource) *Migrator {
+	return &Migrator{
+		service:   service,
+		model:     model,
+		stateFile: stateFile,
+		sources:   sources,
+		logger:    logger.Named("EmbeddingMigrator"),
+		progress:  MigrationProgress{Status: MigrationIdle, Model: model},
+	}
+}
+
+// Dimension 探测当前嵌入模型的向量维度
+func (m *Migrator) Dimension() (int, error) {
+	if m.service == nil {
+		return 0, fmt.Errorf("embedding service not set")
+	}
+	vec, err := m.service.GenerateEmbedding("dimension probe")
+	if err != nil {
+		return 0, err
+	}
+	return len(vec), nil
+}
+
+// Check 检查各集合的向量是否与当前嵌入模型一致，只读取不写入
+func (m *Migrator) Check() ([]Mismatch, error) {
+	return m.check(false)
+}
+
+// check 检查各集合的向量是否与当前嵌入模型一致
+// 没有元数据记录且向量维度一致（或尚无向量）的集合视为使用当前模型，writeMeta 为 true 时补写元数据
+func (m *Migrator) check(writeMeta bool) ([]Mismatch, error) {
+	dim, err := m.Dimension()
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []Mismatch
+	for _, source := range m.sources {
+		meta, err := ReadMeta(source.Store())
+		if err != nil {
+			return nil, err
+		}
+		stored := source.StoredDimension()
+
+		mismatch := Mismatch{Collection: source.Collection(), StoredDimension: stored, Model: m.model, Dimension: dim}
+		if meta != nil {
+			mismatch.StoredModel = meta.Model
+			if stored == 0 {
+				mismatch.StoredDimension = meta.Dimension
+			}
+		}
+
+		switch {
+		case stored != 0 && stored != dim:
+			mismatches = append(mismatches, mismatch)
+		case meta != nil && stored != 0 && meta.Model != m.model:
+			mismatches = append(mismatches, mismatch)
+		case writeMeta && (meta == nil || meta.Model != m.model || meta.Dimension != dim):
+			// 尚无向量，或旧版本没有记录元数据
+			if err := WriteMeta(source.Store(), Meta{Model: m.model, Dimension: dim}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return mismatches, nil
+}
+
+// StartIfNeeded 启动时调用：有未完成的迁移时继续，否则检查各集合，不一致时开始迁移
+func (m *Migrator) StartIfNeeded() (bool, error) {
+	if resumed, err := m.Resume(); resumed || err != nil {
+		return resumed, err
+	}
+
+	mismatches, err := m.check(true)
+	if err != nil {
+		return false, err
+	}
+	if len(mismatches) == 0 {
+		return false, nil
+	}
+	collections := make([]string, 0, len(mismatches))
+	for _, mismatch := range mismatches {
+		m.logger.Warn(i18n.T("embedding.model_mismatch"),
+			logging.String("collection", mismatch.Collection),
+			logging.String("stored_model", mismatch.StoredModel),
+			logging.Int("stored_dimension", mismatch.StoredDimension),
+			logging.String("model", mismatch.Model),
+			logging.Int("dimension", mismatch.Dimension))
+		collections = append(collections, mismatch.Collection)
+	}
+	return true, m.Start(collections...)
+}
+
+// Resume 继续上次未完成的迁移，返回是否有未完成的迁移
+// 迁移中途又更换了嵌入模型时丢弃旧进度
+func (m *Migrator) Resume() (bool, error) {
+	state, err := m.loadState()
+	if err != nil {
+		m.logger.Warn(i18n.T("embedding.load_state_failed"), logging.Err(err))
+		m.removeState()
+		return false, nil
+	}
+	if state == nil {
+		return false, nil
+	}
+	if state.Model != m.model {
+		m.removeState()
+		return false, nil
+	}
+	m.logger.Info(i18n.T("embedding.migration_resumed"), logging.String("model", state.Model))
+	return true, m.run(state)
+}
+
+// Start 在后台为指定集合重新生成向量，未指定时迁移全部集合
+func (m *Migrator) Start(collections ...string) error {
+	m.mu.Lock()
+	running := m.running
+	m.mu.Unlock()
+	if running {
+		return ErrMigrationRunning
+	}
+
+	dim, err := m.Dimension()
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(collections))
+	for _, name := range collections {
+		wanted[name] = true
+	}
+	state := &migrationState{Model: m.model, Dimension: dim, StartedAt: time.Now(), Pending: make(map[string][]string)}
+	for _, source := range m.sources {
+		if len(wanted) > 0 && !wanted[source.Collection()] {
+			continue
+		}
+		keys, err := source.ReembedKeys()
+		if err != nil {
+			return err
+		}
+		state.Pending[source.Collection()] = keys
+		state.Collections = append(state.Collections, CollectionProgress{Collection: source.Collection(), Total: len(keys)})
+	}
+	if len(state.Collections) == 0 {
+		return fmt.Errorf("unknown collections: %v", collections)
+	}
+	if err := m.saveState(state); err != nil {
+		return err
+	}
+	return m.run(state)
+}
+
+// Progress 返回迁移进度
+func (m *Migrator) Progress() MigrationProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	progress := m.progress
+	progress.Collections = append([]CollectionProgress(nil), m.progress.Collections...)
+	return progress
+}
+
+// run 在后台执行迁移
+func (m *Migrator) run(state *migrationState) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return ErrMigrationRunning
+	}
+	m.running = true
+	m.progress = MigrationProgress{
+		Status:      MigrationRunning,
+		Model:       state.Model,
+		Dimension:   state.Dimension,
+		Collections: append([]CollectionProgress(nil), state.Collections...),
+		StartedAt:   state.StartedAt,
+	}
+	m.mu.Unlock()
+
+	go func() {
+		err := m.migrate(state)
+
+		m.mu.Lock()
+		m.running = false
+		m.progress.FinishedAt = time.Now()
+		if err != nil {
+			m.progress.Status = MigrationFailed
+			m.progress.Error = err.Error()
+		} else {
+			m.progress.Status = MigrationCompleted
+		}
+		m.mu.Unlock()
+
+		if err != nil {
+			m.logger.Error(i18n.T("embedding.migration_failed"), logging.Err(err))
+			return
+		}
+		m.logger.Info(i18n.T("embedding.migration_completed"), logging.String("model", state.Model), logging.Int("dimension", state.Dimension))
+	}()
+	return nil
+}
+
+// migrate 逐个集合重新生成向量
+// 失败的条目留在待处理列表中，集合的条目全部成功后才记录新的嵌入元数据；
+// 有条目失败时迁移以失败结束并保留进度，下次继续时重试
+func (m *Migrator) migrate(state *migrationState) error {
+	var incomplete []string
+	for _, source := range m.sources {
+		name := source.Collection()
+		keys, ok := state.Pending[name]
+		if !ok {
+			continue
+		}
+		progressIdx := -1
+		for i := range state.Collections {
+			if state.Collections[i].Collection == name {
+				progressIdx = i
+			}
+		}
+		updateProgress := func(done, failed int) {
+			if progressIdx < 0 {
+				return
+			}
+			state.Collections[progressIdx].Done += done
+			state.Collections[progressIdx].Failed = failed
+			m.mu.Lock()
+			m.progress.Collections[progressIdx] = state.Collections[progressIdx]
+			m.mu.Unlock()
+		}
+
+		m.logger.Info(i18n.T("embedding.migration_collection"), logging.String("collection", name), logging.Int("pending", len(keys)))
+		if err := m.clearStaleVectors(source.Store(), keys, state.Dimension); err != nil {
+			return err
+		}
+
+		var failed []string
+		failures := 0
+		for i, key := range keys {
+			if err := source.Reembed(key); err != nil {
+				failures++
+				failed = append(failed, key)
+				m.logger.Warn(i18n.T("embedding.reembed_failed"), logging.String("collection", name), logging.String("key", key), logging.Err(err))
+				if failures >= maxConsecutiveFailures {
+					// 连续失败多半是嵌入服务不可用，中止迁移，失败和未处理的条目都留待下次继续
+					state.Pending[name] = append(failed, keys[i+1:]...)
+					updateProgress(0, len(failed))
+					_ = m.saveState(state)
+					return fmt.Errorf("%s: %w", name, err)
+				}
+				updateProgress(0, len(failed))
+			} else {
+				failures = 0
+				updateProgress(1, len(failed))
+			}
+
+			state.Pending[name] = append(append([]string(nil), failed...), keys[i+1:]...)
+			if (i+1)%saveStateEvery == 0 {
+				if err := m.saveState(state); err != nil {
+					m.logger.Warn(i18n.T("embedding.save_state_failed"), logging.Err(err))
+				}
+			}
+		}
+
+		if err := source.ReloadVectors(); err != nil {
+			m.logger.Warn(i18n.T("embedding.reload_failed"), logging.String("collection", name), logging.Err(err))
+		}
+		if len(failed) > 0 {
+			// 集合中仍有条目使用旧向量或没有向量，不能标记为新模型
+			state.Pending[name] = failed
+			incomplete = append(incomplete, fmt.Sprintf("%s: %d entries failed", name, len(failed)))
+			if err := m.saveState(state); err != nil {
+				m.logger.Warn(i18n.T("embedding.save_state_failed"), logging.Err(err))
+			}
+			continue
+		}
+
+		if err := WriteMeta(source.Store(), Meta{Model: state.Model, Dimension: state.Dimension}); err != nil {
+			return err
+		}
+		delete(state.Pending, name)
+		if err := m.saveState(state); err != nil {
+			m.logger.Warn(i18n.T("embedding.save_state_failed"), logging.Err(err))
+		}
+	}
+
+	if len(incomplete) > 0 {
+		return fmt.Errorf("re-embedding incomplete: %s", strings.Join(incomplete, "; "))
+	}
+	m.removeState()
+	return nil
+}
+
+// clearStaleVectors 维度变化时先移除集合中旧维度的向量，否则新向量无法写入集合
+// 元数据保留，条目在重新生成向量前仍可通过 key 或全文检索访问
+func (m *Migrator) clearStaleVectors(store core.Store, keys []string, dim int) error {
+	if current := store.Dimension(); current == 0 || current == dim {
+		return nil
+	}
+	for _, key := range keys {
+		entry, err := store.Get(key)
+		if err != nil || entry == nil || len(entry.Vector) == 0 || len(entry.Vector) == dim {
+			continue
+		}
+		if err := store.Put(key, []float64{}, entry.Metadata); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) loadState() (*migrationState, error) {
+	if m.stateFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(m.stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state migrationState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if state.Pending == nil {
+		state.Pending = make(map[string][]string)
+	}
+	return &state, nil
+}
+
+func (m *Migrator) saveState(state *migrationState) error {
+	if m.stateFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.stateFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(m.stateFile, data, 0644)
+}
+
+func (m *Migrator) removeState() {
+	if m.stateFile != "" {
+		os.Remove(m.stateFile)
+	}
+}
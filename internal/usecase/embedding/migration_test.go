@@ -0,0 +1,274 @@
+package embedding
+
+import (
+	"encoding/json"
+	"errors"
+	"mindx/internal/core"
+	"mindx/internal/infrastructure/persistence"
+	"mindx/pkg/logging"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider 按文本长度生成固定维度的向量
+type fakeProvider struct {
+	dim  int
+	fail bool
+}
+
+func (p *fakeProvider) GenerateEmbedding(text string) ([]float64, error) {
+	if p.fail {
+		return nil, errors.New("provider unavailable")
+	}
+	vec := make([]float64, p.dim)
+	for i := range vec {
+		vec[i] = float64(len(text) + i + 1)
+	}
+	return vec, nil
+}
+
+func (p *fakeProvider) GenerateBatchEmbeddings(texts []string) ([][]float64, error) {
+	result := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		vec, err := p.GenerateEmbedding(text)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, vec)
+	}
+	return result, nil
+}
+
+// testSource 以 metadata 中的文本生成向量的数据源
+type testSource struct {
+	store    core.Store
+	service  *EmbeddingService
+	reloaded int
+	failKeys map[string]bool // 重新生成向量时失败的条目
+}
+
+func (s *testSource) Collection() string   { return "docs" }
+func (s *testSource) Store() core.Store    { return s.store }
+func (s *testSource) StoredDimension() int { return s.store.Dimension() }
+func (s *testSource) ReloadVectors() error { s.reloaded++; return nil }
+func (s *testSource) ReembedKeys() ([]string, error) {
+	entries, err := s.store.Scan("doc_")
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	return keys, nil
+}
+
+func (s *testSource) Reembed(key string) error {
+	if s.failKeys[key] {
+		return errors.New("entry too long")
+	}
+	entry, err := s.store.Get(key)
+	if err != nil {
+		return err
+	}
+	var text string
+	if err := json.Unmarshal(entry.Metadata, &text); err != nil {
+		return err
+	}
+	vec, err := s.service.GenerateEmbedding(text)
+	if err != nil {
+		return err
+	}
+	return s.store.Put(key, vec, text)
+}
+
+func newTestSource(t *testing.T, docs map[string]string, dim int) *testSource {
+	store := persistence.NewMemoryStore(nil)
+	for key, text := range docs {
+		require.NoError(t, store.Put(key, make([]float64, dim), text))
+	}
+	return &testSource{store: store}
+}
+
+func waitMigration(t *testing.T, m *Migrator) MigrationProgress {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if progress := m.Progress(); progress.Status != MigrationRunning {
+			return progress
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("migration did not finish")
+	return MigrationProgress{}
+}
+
+func testLogger() logging.Logger {
+	return logging.GetSystemLogger().Named("migration_test")
+}
+
+func TestMigrator_DimensionChange(t *testing.T) {
+	source := newTestSource(t, map[string]string{"doc_1": "alpha", "doc_2": "beta", "doc_3": "gamma"}, 3)
+	require.NoError(t, WriteMeta(source.store, Meta{Model: "old-model", Dimension: 3}))
+
+	service := NewEmbeddingService(&fakeProvider{dim: 2})
+	source.service = service
+	stateFile := filepath.Join(t.TempDir(), "migration.json")
+	m := NewMigrator(service, "new-model", stateFile, testLogger(), source)
+
+	mismatches, err := m.Check()
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, Mismatch{Collection: "docs", StoredModel: "old-model", StoredDimension: 3, Model: "new-model", Dimension: 2}, mismatches[0])
+
+	started, err := m.StartIfNeeded()
+	require.NoError(t, err)
+	assert.True(t, started)
+
+	progress := waitMigration(t, m)
+	assert.Equal(t, MigrationCompleted, progress.Status)
+	assert.Equal(t, []CollectionProgress{{Collection: "docs", Total: 3, Done: 3}}, progress.Collections)
+	assert.Equal(t, 2, source.store.Dimension())
+	assert.Equal(t, 1, source.reloaded)
+
+	meta, err := ReadMeta(source.store)
+	require.NoError(t, err)
+	assert.Equal(t, "new-model", meta.Model)
+	assert.Equal(t, 2, meta.Dimension)
+	assert.NoFileExists(t, stateFile)
+
+	// 元数据条目不会被当作数据扫描或检索到
+	results, err := source.store.Search([]float64{1, 1}, 10)
+	require.NoError(t, err)
+	for _, entry := range results {
+		assert.True(t, strings.HasPrefix(entry.Key, "doc_"))
+	}
+
+	mismatches, err = m.Check()
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+}
+
+func TestMigrator_SameDimensionModelChange(t *testing.T) {
+	source := newTestSource(t, map[string]string{"doc_1": "alpha"}, 2)
+	require.NoError(t, WriteMeta(source.store, Meta{Model: "old-model", Dimension: 2}))
+	service := NewEmbeddingService(&fakeProvider{dim: 2})
+	source.service = service
+
+	m := NewMigrator(service, "new-model", "", testLogger(), source)
+	mismatches, err := m.Check()
+	require.NoError(t, err)
+	assert.Len(t, mismatches, 1, "维度相同但模型不同的向量同样不可比较")
+
+	// 没有元数据的旧集合维度一致时视为当前模型，启动检查时补写元数据，Check 只读取
+	legacy := newTestSource(t, map[string]string{"doc_1": "alpha"}, 2)
+	m = NewMigrator(service, "new-model", "", testLogger(), legacy)
+	mismatches, err = m.Check()
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+	meta, err := ReadMeta(legacy.store)
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+
+	started, err := m.StartIfNeeded()
+	require.NoError(t, err)
+	assert.False(t, started)
+	meta, err = ReadMeta(legacy.store)
+	require.NoError(t, err)
+	require.NotNil(t, meta)
+	assert.Equal(t, "new-model", meta.Model)
+}
+
+// TestMigrator_PartialFailure 个别条目失败时不标记集合为新模型，失败的条目留待下次继续
+func TestMigrator_PartialFailure(t *testing.T) {
+	source := newTestSource(t, map[string]string{"doc_1": "alpha", "doc_2": "beta", "doc_3": "gamma"}, 3)
+	require.NoError(t, WriteMeta(source.store, Meta{Model: "old-model", Dimension: 3}))
+	service := NewEmbeddingService(&fakeProvider{dim: 2})
+	source.service = service
+	source.failKeys = map[string]bool{"doc_2": true}
+	stateFile := filepath.Join(t.TempDir(), "migration.json")
+
+	m := NewMigrator(service, "new-model", stateFile, testLogger(), source)
+	require.NoError(t, m.Start())
+	progress := waitMigration(t, m)
+	assert.Equal(t, MigrationFailed, progress.Status)
+	assert.Equal(t, []CollectionProgress{{Collection: "docs", Total: 3, Done: 2, Failed: 1}}, progress.Collections)
+	meta, err := ReadMeta(source.store)
+	require.NoError(t, err)
+	assert.Equal(t, "old-model", meta.Model, "仍有条目没有新向量时不能标记为新模型")
+	require.FileExists(t, stateFile)
+
+	// 下次继续时只重试失败的条目
+	source.failKeys = nil
+	m = NewMigrator(service, "new-model", stateFile, testLogger(), source)
+	resumed, err := m.Resume()
+	require.NoError(t, err)
+	assert.True(t, resumed)
+	progress = waitMigration(t, m)
+	assert.Equal(t, MigrationCompleted, progress.Status)
+	assert.Equal(t, []CollectionProgress{{Collection: "docs", Total: 3, Done: 3}}, progress.Collections)
+
+	entry, err := source.store.Get("doc_2")
+	require.NoError(t, err)
+	assert.Len(t, entry.Vector, 2)
+	meta, err = ReadMeta(source.store)
+	require.NoError(t, err)
+	assert.Equal(t, "new-model", meta.Model)
+	assert.NoFileExists(t, stateFile)
+}
+
+func TestMigrator_AbortAndResume(t *testing.T) {
+	docs := map[string]string{}
+	for _, key := range []string{"doc_1", "doc_2", "doc_3", "doc_4", "doc_5", "doc_6"} {
+		docs[key] = "text of " + key
+	}
+	source := newTestSource(t, docs, 3)
+	provider := &fakeProvider{dim: 2}
+	service := NewEmbeddingService(provider)
+	source.service = service
+	stateFile := filepath.Join(t.TempDir(), "migration.json")
+
+	m := NewMigrator(service, "new-model", stateFile, testLogger(), source)
+	// 维度探测成功后嵌入服务不可用
+	_, err := m.Dimension()
+	require.NoError(t, err)
+	provider.fail = true
+
+	require.NoError(t, m.Start())
+	progress := waitMigration(t, m)
+	assert.Equal(t, MigrationFailed, progress.Status)
+	assert.NotEmpty(t, progress.Error)
+	require.FileExists(t, stateFile, "中止时保留进度")
+
+	// 新进程从保存的进度继续
+	provider.fail = false
+	m = NewMigrator(service, "new-model", stateFile, testLogger(), source)
+	resumed, err := m.StartIfNeeded()
+	require.NoError(t, err)
+	assert.True(t, resumed)
+
+	progress = waitMigration(t, m)
+	assert.Equal(t, MigrationCompleted, progress.Status)
+	assert.Equal(t, 2, source.store.Dimension())
+	entries, err := source.store.Scan("doc_")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.Len(t, entry.Vector, 2, entry.Key)
+	}
+	assert.NoFileExists(t, stateFile)
+}
+
+func TestMigrator_Running(t *testing.T) {
+	m := NewMigrator(nil, "model", "", testLogger())
+	assert.Equal(t, MigrationIdle, m.Progress().Status)
+	_, err := m.Check()
+	assert.Error(t, err, "没有嵌入服务时无法探测维度")
+
+	m.running = true
+	assert.ErrorIs(t, m.Start(), ErrMigrationRunning)
+}
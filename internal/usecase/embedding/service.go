@@ -58,20 +58,52 @@ func (s *EmbeddingService) GenerateEmbedding(text string) ([]float64, error) {
 	return vec, nil
 }
 
-// GenerateBatchEmbeddings 批量生成向量
+// GenerateBatchEmbeddings 批量生成向量（带缓存）
+// 未命中缓存的文本一次交给 provider 批量生成，支持批量接口的 provider 可以减少请求次数
 func (s *EmbeddingService) GenerateBatchEmbeddings(texts []string) ([][]float64, error) {
 	if len(texts) == 0 {
 		return nil, fmt.Errorf("texts cannot be empty")
 	}
 
 	embeddings := make([][]float64, len(texts))
+	var missTexts []string
+	var missIdx []int
+	s.mutex.RLock()
 	for i, text := range texts {
-		vec, err := s.GenerateEmbedding(text)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate embedding for text %d: %w", i, err)
+		if text == "" {
+			s.mutex.RUnlock()
+			return nil, fmt.Errorf("text %d cannot be empty", i)
+		}
+		if vec, exists := s.cache.Get(text); exists {
+			embeddings[i] = vec
+			continue
 		}
-		embeddings[i] = vec
+		missTexts = append(missTexts, text)
+		missIdx = append(missIdx, i)
+	}
+	s.mutex.RUnlock()
+
+	if len(missTexts) == 0 {
+		return embeddings, nil
 	}
+	if s.provider == nil {
+		return nil, fmt.Errorf("embedding provider is not configured")
+	}
+
+	vecs, err := s.provider.GenerateBatchEmbeddings(missTexts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != len(missTexts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(missTexts), len(vecs))
+	}
+
+	s.mutex.Lock()
+	for j, vec := range vecs {
+		embeddings[missIdx[j]] = vec
+		s.cache.Add(missTexts[j], vec)
+	}
+	s.mutex.Unlock()
 
 	return embeddings, nil
 }
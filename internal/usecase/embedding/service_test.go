@@ -0,0 +1,40 @@
+package embedding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchCountingProvider 记录批量请求的文本
+type batchCountingProvider struct {
+	fakeProvider
+	batches [][]string
+}
+
+func (p *batchCountingProvider) GenerateBatchEmbeddings(texts []string) ([][]float64, error) {
+	p.batches = append(p.batches, texts)
+	return p.fakeProvider.GenerateBatchEmbeddings(texts)
+}
+
+func TestGenerateBatchEmbeddings_Cache(t *testing.T) {
+	provider := &batchCountingProvider{fakeProvider: fakeProvider{dim: 2}}
+	service := NewEmbeddingService(provider)
+
+	cached, err := service.GenerateEmbedding("bb")
+	require.NoError(t, err)
+
+	vecs, err := service.GenerateBatchEmbeddings([]string{"a", "bb", "ccc"})
+	require.NoError(t, err)
+	require.Len(t, vecs, 3)
+	assert.Equal(t, cached, vecs[1])
+	assert.Equal(t, [][]string{{"a", "ccc"}}, provider.batches, "只为未命中缓存的文本发送一次批量请求")
+
+	_, err = service.GenerateBatchEmbeddings([]string{"a", "ccc"})
+	require.NoError(t, err)
+	assert.Len(t, provider.batches, 1)
+
+	_, err = service.GenerateBatchEmbeddings([]string{"a", ""})
+	assert.Error(t, err)
+}
@@ -1,7 +1,6 @@
 package memory
 
 import (
-	"encoding/json"
 	"mindx/internal/core"
 	"mindx/pkg/i18n"
 	"mindx/pkg/logging"
@@ -9,6 +8,9 @@ import (
 	"time"
 )
 
+// dedupCandidates 去重时取回的相似记忆数，其中可能包含其他用户的记忆
+const dedupCandidates = 5
+
 // DeduplicateMemory 语义去重
 // 搜索相似度 > 0.85 的已有记忆，如果找到则合并内容
 // 返回合并后的记忆点和是否发生了合并
@@ -17,15 +19,22 @@ func (m *Memory) DeduplicateMemory(newPoint *core.MemoryPoint) (*core.MemoryPoin
 		return newPoint, false
 	}
 
-	// 搜索高相似度的已有记忆
-	results, err := m.store.SearchWithThreshold(newPoint.Vector, 1, 0.85)
+	// 搜索高相似度的已有记忆，只与同一所有者、同一可见范围的记忆合并
+	results, err := m.store.SearchWithThreshold(newPoint.Vector, dedupCandidates, 0.85)
 	if err != nil || len(results) == 0 {
 		return newPoint, false
 	}
 
-	// 解析已有记忆点
 	var existingPoint core.MemoryPoint
-	if err := json.Unmarshal(results[0].Metadata, &existingPoint); err != nil {
+	found := false
+	for _, result := range results {
+		point, err := m.parseMemoryPoint(result)
+		if err == nil && sameOwnership(point, *newPoint) {
+			existingPoint, found = point, true
+			break
+		}
+	}
+	if !found {
 		return newPoint, false
 	}
 
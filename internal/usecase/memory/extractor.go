@@ -39,7 +39,7 @@ func NewLLMExtractor(brain core.Thinking, memory core.Memory) *LLMExtractor {
 	}
 }
 
-// Extract 从会话中提取记忆点并存储
+// Extract 从会话中提取记忆点并存储，记忆点归属会话的发言用户
 func (e *LLMExtractor) Extract(session entity.Session) bool {
 	if len(session.Messages) == 0 {
 		return true
@@ -65,14 +65,17 @@ func (e *LLMExtractor) Extract(session entity.Session) bool {
 	}
 
 	// 5. 存储记忆点到Memory
+	owner := sessionOwner(session)
 	for _, mem := range result.Memories {
 		memoryPoint := core.MemoryPoint{
-			Keywords:  mem.Keywords,
-			Content:   mem.Content,
-			Summary:   mem.Summary,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			Keywords:   mem.Keywords,
+			Content:    mem.Content,
+			Summary:    mem.Summary,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+			Visibility: core.MemoryPrivate,
 		}
+		memoryPoint.SetOwner(owner)
 		if err := e.memory.Record(memoryPoint); err != nil {
 			// 记录失败但继续处理其他记忆点
 			logging.GetSystemLogger().Warn("存储记忆点失败", logging.Err(err))
@@ -82,6 +85,25 @@ func (e *LLMExtractor) Extract(session entity.Session) bool {
 	return len(result.Memories) > 0
 }
 
+// sessionOwner 确定会话中提取的记忆的所有者
+// 会话中只有一位用户发言时归属该用户，否则（如多人群聊）归属会话本身
+func sessionOwner(session entity.Session) core.MemoryOwner {
+	owner := core.MemoryOwner{ChannelID: session.ChannelID, UserID: session.SenderID}
+
+	senders := make(map[string]bool)
+	for _, msg := range session.Messages {
+		if msg.Role == "user" && msg.SenderID != "" {
+			senders[msg.SenderID] = true
+		}
+	}
+	if len(senders) == 1 {
+		for id := range senders {
+			owner.UserID = id
+		}
+	}
+	return owner
+}
+
 // formatConversation 格式化会话内容
 func (e *LLMExtractor) formatConversation(msgs []entity.Message) string {
 	var sb strings.Builder
@@ -137,12 +159,14 @@ func (e *LLMExtractor) createFallbackMemory(session entity.Session) bool {
 	}
 
 	memoryPoint := core.MemoryPoint{
-		Keywords:  []string{"对话"},
-		Content:   content.String(),
-		Summary:   fmt.Sprintf("包含%d条消息的对话", len(session.Messages)),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Keywords:   []string{"对话"},
+		Content:    content.String(),
+		Summary:    fmt.Sprintf("包含%d条消息的对话", len(session.Messages)),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		Visibility: core.MemoryPrivate,
 	}
+	memoryPoint.SetOwner(sessionOwner(session))
 
 	return e.memory.Record(memoryPoint) == nil
 }
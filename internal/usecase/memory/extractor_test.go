@@ -41,8 +41,6 @@ func (m *MockThinking) SetStream(stream any) {
 	m.stream = stream
 }
 
-func (m *MockThinking) SetEventChan(ch chan<- core.ThinkingEvent) {}
-
 func (m *MockThinking) GetSystemPrompt() string {
 	return ""
 }
@@ -70,7 +68,7 @@ func (m *MockMemory) RecordBatch(mems []core.MemoryPoint) error {
 	return nil
 }
 
-func (m *MockMemory) Search(query string) ([]core.MemoryPoint, error) {
+func (m *MockMemory) Search(ctx context.Context, scope core.MemoryScope, query string) ([]core.MemoryPoint, error) {
 	// 简单的关键词匹配
 	var result []core.MemoryPoint
 	for _, mem := range m.memories {
@@ -487,3 +485,27 @@ func TestLLMExtractor_createFallbackMemory(t *testing.T) {
 		t.Error("内容应该包含第二条消息")
 	}
 }
+
+func TestSessionOwner(t *testing.T) {
+	session := entity.Session{
+		ChannelID: "telegram",
+		SenderID:  "group_1",
+		Messages: []entity.Message{
+			{Role: "user", Content: "我叫张三", SenderID: "zhangsan"},
+			{Role: "assistant", Content: "你好"},
+		},
+	}
+
+	// 只有一个发送者时，记忆归属该用户
+	owner := sessionOwner(session)
+	if owner.ChannelID != "telegram" || owner.UserID != "zhangsan" {
+		t.Errorf("期望所有者为 telegram:zhangsan，实际为 %s", owner)
+	}
+
+	// 多个发送者时，记忆归属会话
+	session.Messages = append(session.Messages, entity.Message{Role: "user", Content: "我叫李四", SenderID: "lisi"})
+	owner = sessionOwner(session)
+	if owner.UserID != "group_1" {
+		t.Errorf("期望所有者为会话 group_1，实际为 %s", owner.UserID)
+	}
+}
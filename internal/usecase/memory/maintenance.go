@@ -24,8 +24,7 @@ func (m *Memory) CleanupExpiredMemories() error {
 	deletedCount := 0
 	for _, memoryPoint := range allMemories {
 		if memoryPoint.TotalWeight < 0.1 && time.Since(memoryPoint.CreatedAt).Hours() > 30*24 {
-			key := m.generateMemoryKey(memoryPoint.CreatedAt)
-			if err := m.store.Delete(key); err != nil {
+			if err := m.deleteMemoryEntry(memoryKey(memoryPoint.ID)); err != nil {
 				m.logger.Error(i18n.T("memory.del_low_weight_failed"), logging.Err(err), logging.Int(i18n.T("memory.id"), memoryPoint.ID))
 				continue
 			}
@@ -34,8 +33,7 @@ func (m *Memory) CleanupExpiredMemories() error {
 		}
 
 		if strings.TrimSpace(memoryPoint.Content) == "" || len(memoryPoint.Keywords) == 0 {
-			key := m.generateMemoryKey(memoryPoint.CreatedAt)
-			if err := m.store.Delete(key); err != nil {
+			if err := m.deleteMemoryEntry(memoryKey(memoryPoint.ID)); err != nil {
 				m.logger.Error(i18n.T("memory.del_invalid_failed"), logging.Err(err), logging.Int(i18n.T("memory.id"), memoryPoint.ID))
 				continue
 			}
@@ -50,26 +48,10 @@ func (m *Memory) CleanupExpiredMemories() error {
 func (m *Memory) AdjustMemoryWeight(id int, multiple float64) error {
 	m.logger.Info(i18n.T("memory.start_adjust_weight"), logging.Int(i18n.T("memory.id"), id), logging.Float64(i18n.T("memory.multiple"), multiple))
 
-	allMemories, err := m.getAllMemories()
+	targetPoint, err := m.GetMemoryPoint(id)
 	if err != nil {
-		m.logger.Error(i18n.T("memory.get_memories_failed"), logging.Err(err))
-		return apperrors.Wrap(err, apperrors.ErrTypeMemory, "获取记忆点失败")
-	}
-
-	var targetPoint core.MemoryPoint
-	found := false
-
-	for _, mem := range allMemories {
-		if mem.ID == id {
-			targetPoint = mem
-			found = true
-			break
-		}
-	}
-
-	if !found {
 		m.logger.Error(i18n.T("memory.target_not_found"), logging.Int(i18n.T("memory.id"), id))
-		return apperrors.New(apperrors.ErrTypeMemory, fmt.Sprintf("未找到ID为%d的记忆点", id))
+		return err
 	}
 
 	targetPoint.TotalWeight = targetPoint.TotalWeight * multiple
@@ -124,8 +106,7 @@ func (m *Memory) DecayWeights() error {
 		mem.UpdatedAt = now
 
 		if mem.TotalWeight < 0.05 {
-			key := m.generateMemoryKey(mem.CreatedAt)
-			if err := m.store.Delete(key); err != nil {
+			if err := m.deleteMemoryEntry(memoryKey(mem.ID)); err != nil {
 				m.logger.Warn("删除低权重记忆失败", logging.Err(err))
 				continue
 			}
@@ -158,8 +139,8 @@ func (m *Memory) getAllMemories() ([]core.MemoryPoint, error) {
 
 	memoryPoints := make([]core.MemoryPoint, 0, len(allMemories))
 	for _, mem := range allMemories {
-		var memoryPoint core.MemoryPoint
-		if err := json.Unmarshal(mem.Metadata, &memoryPoint); err != nil {
+		memoryPoint, err := m.parseMemoryPoint(mem)
+		if err != nil {
 			continue
 		}
 		memoryPoints = append(memoryPoints, memoryPoint)
@@ -172,25 +153,131 @@ func (m *Memory) GetAllMemoryPoints() ([]core.MemoryPoint, error) {
 	return m.getAllMemories()
 }
 
+// parseMemoryPoint 从向量条目解析记忆点
+// 存储时元数据包装为 {"memory_point": {...}}，同时兼容直接序列化的旧格式；
+// 非记忆条目（如技能索引）返回错误
 func (m *Memory) parseMemoryPoint(entry entity.VectorEntry) (core.MemoryPoint, error) {
+	if entry.Key != "" && !strings.HasPrefix(entry.Key, memoryKeyPrefix) {
+		return core.MemoryPoint{}, fmt.Errorf("not a memory entry: %s", entry.Key)
+	}
+
+	var wrapped struct {
+		MemoryPoint *core.MemoryPoint `json:"memory_point"`
+	}
+	if err := json.Unmarshal(entry.Metadata, &wrapped); err != nil {
+		return core.MemoryPoint{}, err
+	}
+	if wrapped.MemoryPoint != nil {
+		return *wrapped.MemoryPoint, nil
+	}
+
 	var memoryPoint core.MemoryPoint
 	err := json.Unmarshal(entry.Metadata, &memoryPoint)
 	return memoryPoint, err
 }
 
-func (m *Memory) generateMemoryKey(t time.Time) string {
-	return fmt.Sprintf("memory_%d", t.UnixNano())
+// memoryKeyPrefix 记忆条目在向量存储中的 key 前缀
+const memoryKeyPrefix = "memory_"
+
+// memoryKey 记忆点在向量存储中的 key，由记忆点 ID 决定，更新时覆盖原条目
+func memoryKey(id int) string {
+	return fmt.Sprintf("%s%d", memoryKeyPrefix, id)
+}
+
+// nextMemoryID 分配新的记忆点 ID
+func (m *Memory) nextMemoryID() int {
+	return int(m.lastID.Add(1))
+}
+
+// observeMemoryID 记录已使用的 ID，避免之后分配重复的 ID
+func (m *Memory) observeMemoryID(id int) {
+	for {
+		last := m.lastID.Load()
+		if int64(id) <= last || m.lastID.CompareAndSwap(last, int64(id)) {
+			return
+		}
+	}
 }
 
+// storeMemory 写入记忆点，没有 ID 的记忆点分配新的 ID
 func (m *Memory) storeMemory(point core.MemoryPoint) error {
 	if m.store == nil {
 		return nil
 	}
 
-	key := fmt.Sprintf("memory_%d", time.Now().UnixNano())
+	if point.ID == 0 {
+		point.ID = m.nextMemoryID()
+	} else {
+		m.observeMemoryID(point.ID)
+	}
+	point.Score = 0
 	metadata := map[string]any{
 		"memory_point": point,
 	}
 
-	return m.store.Put(key, point.Vector, metadata)
+	key := memoryKey(point.ID)
+	if err := m.store.Put(key, point.Vector, metadata); err != nil {
+		return err
+	}
+	m.textIndex().Add(key, embeddingText(point))
+	return nil
+}
+
+// deleteMemoryEntry 从向量存储和全文索引中删除记忆条目
+func (m *Memory) deleteMemoryEntry(key string) error {
+	if err := m.store.Delete(key); err != nil {
+		return err
+	}
+	m.textIndex().Remove(key)
+	return nil
+}
+
+// migrateMemoryKeys 为旧记忆分配 ID，并将按写入时间命名的条目移动到按 ID 命名的 key
+// 旧版本写入的记忆 ID 都为 0，每次更新都会产生新条目，迁移后才能按 ID 修改和删除
+func (m *Memory) migrateMemoryKeys() error {
+	if m.store == nil {
+		return nil
+	}
+
+	entries, err := m.store.Scan(memoryKeyPrefix)
+	if err != nil {
+		return err
+	}
+
+	points := make([]core.MemoryPoint, len(entries))
+	valid := make([]bool, len(entries))
+	for i, entry := range entries {
+		point, err := m.parseMemoryPoint(entry)
+		if err != nil {
+			continue
+		}
+		points[i], valid[i] = point, true
+		if entry.Key == memoryKey(point.ID) {
+			m.observeMemoryID(point.ID)
+		}
+	}
+
+	migrated := 0
+	for i, entry := range entries {
+		point := points[i]
+		if !valid[i] || entry.Key == memoryKey(point.ID) {
+			continue
+		}
+		if _, err := m.store.Get(memoryKey(point.ID)); point.ID == 0 || err == nil {
+			point.ID = m.nextMemoryID()
+		}
+		point.Vector = entry.Vector
+		if err := m.storeMemory(point); err != nil {
+			return err
+		}
+		if err := m.deleteMemoryEntry(entry.Key); err != nil {
+			return err
+		}
+		migrated++
+	}
+
+	if migrated > 0 {
+		m.logger.Info(i18n.T("memory.key_migrated"), logging.Int(i18n.T("memory.count"), migrated))
+	}
+	return nil
 }
@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"mindx/internal/core"
+	apperrors "mindx/internal/errors"
+	"mindx/pkg/i18n"
+	"mindx/pkg/logging"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrMemoryNotFound 指定 ID 的记忆点不存在
+var ErrMemoryNotFound = errors.New("memory not found")
+
+// MemoryFilter 记忆点列表的筛选条件，空字段不筛选
+type MemoryFilter struct {
+	ChannelID  string
+	OwnerID    string
+	Visibility core.MemoryVisibility
+	Query      string // 全文检索：摘要、内容或关键词包含该文本（不区分大小写）
+}
+
+// matches 记忆点是否满足筛选条件
+func (f MemoryFilter) matches(point core.MemoryPoint) bool {
+	if f.ChannelID != "" && point.ChannelID != f.ChannelID {
+		return false
+	}
+	if f.OwnerID != "" && point.OwnerID != f.OwnerID {
+		return false
+	}
+	if f.Visibility != "" && point.Visibility != f.Visibility {
+		return false
+	}
+	if f.Query == "" {
+		return true
+	}
+
+	query := strings.ToLower(f.Query)
+	if strings.Contains(strings.ToLower(point.Summary), query) || strings.Contains(strings.ToLower(point.Content), query) {
+		return true
+	}
+	for _, kw := range point.Keywords {
+		if strings.Contains(strings.ToLower(kw), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// MemoryUpdate 记忆点的修改内容，nil 字段保持不变
+type MemoryUpdate struct {
+	Summary    *string
+	Content    *string
+	Keywords   []string
+	Visibility *core.MemoryVisibility
+}
+
+// ListMemories 列出满足条件的记忆点，按 ID 倒序（最新的在前）
+// 不区分所有者，供管理界面使用
+func (m *Memory) ListMemories(filter MemoryFilter) ([]core.MemoryPoint, error) {
+	if m.store == nil {
+		return []core.MemoryPoint{}, nil
+	}
+
+	entries, err := m.store.Scan(memoryKeyPrefix)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeMemory, "获取记忆点失败")
+	}
+
+	points := make([]core.MemoryPoint, 0, len(entries))
+	for _, entry := range entries {
+		point, err := m.parseMemoryPoint(entry)
+		if err != nil || !filter.matches(point) {
+			continue
+		}
+		points = append(points, point)
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].ID > points[j].ID
+	})
+	return points, nil
+}
+
+// GetMemoryPoint 获取指定 ID 的记忆点
+func (m *Memory) GetMemoryPoint(id int) (core.MemoryPoint, error) {
+	if m.store == nil {
+		return core.MemoryPoint{}, apperrors.Wrap(ErrMemoryNotFound, apperrors.ErrTypeMemory, fmt.Sprintf("未找到ID为%d的记忆点", id))
+	}
+
+	entry, err := m.store.Get(memoryKey(id))
+	if err != nil {
+		return core.MemoryPoint{}, apperrors.Wrap(ErrMemoryNotFound, apperrors.ErrTypeMemory, fmt.Sprintf("未找到ID为%d的记忆点", id))
+	}
+	point, err := m.parseMemoryPoint(*entry)
+	if err != nil {
+		return core.MemoryPoint{}, apperrors.Wrap(err, apperrors.ErrTypeMemory, "解析记忆点失败")
+	}
+	point.Vector = entry.Vector
+	return point, nil
+}
+
+// UpdateMemory 修改记忆点，摘要、内容或关键词变化时重新生成向量
+func (m *Memory) UpdateMemory(id int, update MemoryUpdate) (core.MemoryPoint, error) {
+	point, err := m.GetMemoryPoint(id)
+	if err != nil {
+		return core.MemoryPoint{}, err
+	}
+
+	changed := false
+	if update.Summary != nil && *update.Summary != point.Summary {
+		point.Summary = *update.Summary
+		changed = true
+	}
+	if update.Content != nil && *update.Content != point.Content {
+		point.Content = *update.Content
+		changed = true
+	}
+	if update.Keywords != nil {
+		keywords := make([]string, 0, len(update.Keywords))
+		for _, kw := range update.Keywords {
+			if kw = strings.TrimSpace(kw); kw != "" {
+				keywords = append(keywords, kw)
+			}
+		}
+		if strings.Join(keywords, "\x00") != strings.Join(point.Keywords, "\x00") {
+			point.Keywords = keywords
+			changed = true
+		}
+	}
+	if update.Visibility != nil {
+		if !update.Visibility.IsValid() {
+			return core.MemoryPoint{}, apperrors.New(apperrors.ErrTypeMemory, fmt.Sprintf("无效的可见范围: %s", *update.Visibility))
+		}
+		point.Visibility = *update.Visibility
+	}
+
+	if changed && m.embeddingService != nil {
+		vector, err := m.embeddingService.GenerateEmbedding(embeddingText(point))
+		if err != nil {
+			return core.MemoryPoint{}, apperrors.Wrap(err, apperrors.ErrTypeMemory, "生成记忆向量失败")
+		}
+		point.Vector = vector
+	}
+	point.UpdatedAt = time.Now()
+
+	if err := m.storeMemory(point); err != nil {
+		return core.MemoryPoint{}, apperrors.Wrap(err, apperrors.ErrTypeMemory, "存储记忆失败")
+	}
+
+	m.logger.Info(i18n.T("memory.updated"), logging.Int(i18n.T("memory.id"), id), logging.Bool("reembedded", changed))
+	return point, nil
+}
+
+// DeleteMemory 删除指定 ID 的记忆点
+func (m *Memory) DeleteMemory(id int) error {
+	if _, err := m.GetMemoryPoint(id); err != nil {
+		return err
+	}
+	if err := m.deleteMemoryEntry(memoryKey(id)); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrTypeMemory, "删除记忆失败")
+	}
+
+	m.logger.Info(i18n.T("memory.deleted_point"), logging.Int(i18n.T("memory.id"), id))
+	return nil
+}
+
+// SemanticSearch 按语义相似度检索记忆点，不区分所有者，供管理界面使用
+// 结果的 Score 为与检索内容的余弦相似度
+func (m *Memory) SemanticSearch(terms string, topN int) ([]core.MemoryPoint, error) {
+	if m.store == nil || m.embeddingService == nil {
+		return []core.MemoryPoint{}, nil
+	}
+
+	vector, err := m.embeddingService.GenerateEmbedding(terms)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeMemory, "生成检索向量失败")
+	}
+
+	entries, err := m.store.SearchWithThreshold(vector, topN, 0)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrTypeMemory, "检索记忆失败")
+	}
+
+	results := make([]core.MemoryPoint, 0, len(entries))
+	for _, entry := range entries {
+		point, err := m.parseMemoryPoint(entry)
+		if err != nil {
+			continue
+		}
+		point.Score = m.calculateCosineSimilarity(vector, entry.Vector)
+		results = append(results, point)
+	}
+	return results, nil
+}
+
+// embeddingText 生成记忆点向量所用的文本
+func embeddingText(point core.MemoryPoint) string {
+	return strings.Join(point.Keywords, " ") + " " + point.Summary + " " + point.Content
+}
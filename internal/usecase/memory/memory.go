@@ -5,11 +5,13 @@ import (
 	"mindx/internal/core"
 	apperrors "mindx/internal/errors"
 	"mindx/internal/usecase/embedding"
+	"mindx/pkg/bm25"
 	"mindx/pkg/i18n"
 	"mindx/pkg/logging"
 	"os"
 	"path/filepath"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	openai "github.com/sashabaranov/go-openai"
@@ -23,6 +25,24 @@ type Memory struct {
 	config           *config.VectorStoreConfig
 	logger           logging.Logger
 	embeddingService *embedding.EmbeddingService
+
+	// defaultOwner 默认所有者，localChannels 中的用户与旧记忆归属该所有者
+	defaultOwner  core.MemoryOwner
+	localChannels map[string]bool
+
+	// lastID 最近分配的记忆点 ID
+	lastID atomic.Int64
+
+	// searchTopN、searchThreshold 为检索参数，零值时使用默认值
+	searchTopN      int
+	searchThreshold float64
+
+	// bm25Index 记忆的全文索引，首次使用时由向量存储中的记忆重建
+	bm25Index *bm25.Index
+	bm25Once  sync.Once
+
+	// reranker 检索结果注入提示词前的重排器，为 nil 时按融合得分排序
+	reranker core.Reranker
 }
 
 func NewMemory(
@@ -50,6 +70,20 @@ func NewMemory(
 		config:           &cfg.VectorStore,
 		logger:           logger,
 		embeddingService: embeddingService,
+		defaultOwner:     core.ParseMemoryOwner(cfg.Memory.DefaultOwner),
+		localChannels:    make(map[string]bool),
+		searchTopN:       cfg.Memory.GetSearchTopN(),
+		searchThreshold:  cfg.Memory.GetSearchThreshold(),
+	}
+	for _, channelID := range cfg.Memory.GetLocalChannels() {
+		memory.localChannels[channelID] = true
+	}
+
+	if err := memory.migrateMemoryKeys(); err != nil {
+		logger.Warn(i18n.T("memory.key_migrate_failed"), logging.Err(err))
+	}
+	if err := memory.migrateOwners(); err != nil {
+		logger.Warn(i18n.T("memory.owner_migrate_failed"), logging.Err(err))
 	}
 
 	logger.Info(i18n.T("memory.init_success"),
@@ -59,18 +93,38 @@ func NewMemory(
 	return memory, nil
 }
 
+// SetReranker 设置检索结果的重排器，nil 表示不重排；应在开始检索前调用
+func (m *Memory) SetReranker(reranker core.Reranker) {
+	m.reranker = reranker
+}
+
 func (m *Memory) Record(point core.MemoryPoint) error {
+	if _, err := m.record(point); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := m.CleanupExpiredMemories(); err != nil {
+			m.logger.Error(i18n.T("memory.cleanup_failed"), logging.Err(err))
+		}
+	}()
+
+	return nil
+}
+
+// record 补全并存储记忆点，与已有的相似记忆合并，返回是否发生了合并
+func (m *Memory) record(point core.MemoryPoint) (bool, error) {
 	m.logger.Debug(i18n.T("memory.start_record"), logging.Int(i18n.T("memory.keywords_count"), len(point.Keywords)))
 
 	if point.CreatedAt.IsZero() {
 		point.CreatedAt = time.Now()
 	}
 	point.UpdatedAt = time.Now()
+	m.normalizeOwnership(&point)
 
 	if len(point.Vector) == 0 {
 		if m.embeddingService != nil {
-			combinedText := strings.Join(point.Keywords, " ") + " " + point.Summary + " " + point.Content
-			vector, err := m.embeddingService.GenerateEmbedding(combinedText)
+			vector, err := m.embeddingService.GenerateEmbedding(embeddingText(point))
 			if err != nil {
 				m.logger.Warn(i18n.T("memory.gen_vector_failed"), logging.Err(err))
 				vector = []float64{}
@@ -86,10 +140,13 @@ func (m *Memory) Record(point core.MemoryPoint) error {
 	if wasMerged {
 		point = *mergedPoint
 	}
+	if point.ID == 0 {
+		point.ID = m.nextMemoryID()
+	}
 
 	if err := m.storeMemory(point); err != nil {
 		m.logger.Error(i18n.T("memory.store_failed"), logging.Err(err))
-		return apperrors.Wrap(err, apperrors.ErrTypeMemory, "存储记忆失败")
+		return false, apperrors.Wrap(err, apperrors.ErrTypeMemory, "存储记忆失败")
 	}
 
 	m.logger.Info(i18n.T("memory.record_success"),
@@ -97,13 +154,7 @@ func (m *Memory) Record(point core.MemoryPoint) error {
 		logging.Int(i18n.T("memory.keywords_count"), len(point.Keywords)),
 		logging.Float64(i18n.T("memory.total_weight"), point.TotalWeight))
 
-	go func() {
-		if err := m.CleanupExpiredMemories(); err != nil {
-			m.logger.Error(i18n.T("memory.cleanup_failed"), logging.Err(err))
-		}
-	}()
-
-	return nil
+	return wasMerged, nil
 }
 
 func (m *Memory) Close() error {
@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"mindx/internal/core"
 	"mindx/internal/entity"
 	infraEmbedding "mindx/internal/infrastructure/embedding"
@@ -63,7 +64,13 @@ func TestRecord(t *testing.T) {
 			TotalWeight: 0.7,
 		}
 
-		err := m.Record(point)
+		// 生成的向量维度与上面手写的 3 维向量不同，使用独立存储避免维度冲突
+		generated := &Memory{
+			logger:           newTestLogger(),
+			embeddingService: embedding.NewEmbeddingService(provider),
+			store:            persistence.NewMemoryStore(provider),
+		}
+		err := generated.Record(point)
 		assert.NoError(t, err)
 	})
 
@@ -131,32 +138,32 @@ func TestSearch(t *testing.T) {
 	}
 
 	t.Run("正常搜索", func(t *testing.T) {
-		results, err := m.Search("测试搜索")
+		results, err := m.Search(context.Background(), core.MemoryScope{}, "测试搜索")
 		assert.NoError(t, err)
 		assert.NotNil(t, results)
 	})
 
 	t.Run("空搜索词", func(t *testing.T) {
-		results, err := m.Search("")
+		results, err := m.Search(context.Background(), core.MemoryScope{}, "")
 		assert.NoError(t, err)
 		assert.NotNil(t, results)
 	})
 
 	t.Run("无匹配结果", func(t *testing.T) {
-		results, err := m.Search("不存在的关键词xyz123")
+		results, err := m.Search(context.Background(), core.MemoryScope{}, "不存在的关键词xyz123")
 		assert.NoError(t, err)
 		assert.NotNil(t, results)
 	})
 
 	t.Run("搜索词包含特殊字符", func(t *testing.T) {
-		results, err := m.Search("测试!@#$%^&*()")
+		results, err := m.Search(context.Background(), core.MemoryScope{}, "测试!@#$%^&*()")
 		assert.NoError(t, err)
 		assert.NotNil(t, results)
 	})
 
 	t.Run("长搜索词", func(t *testing.T) {
 		longText := strings.Repeat("测试内容 ", 50)
-		results, err := m.Search(longText)
+		results, err := m.Search(context.Background(), core.MemoryScope{}, longText)
 		assert.NoError(t, err)
 		assert.NotNil(t, results)
 	})
@@ -1,12 +1,18 @@
 package memory
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"mindx/internal/core"
 	"mindx/internal/entity"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestCalculateTimeWeight 测试时间权重计算功能
@@ -281,111 +287,6 @@ func TestSimpleTokenize(t *testing.T) {
 	}
 }
 
-// TestCalculateKeywordSimilarity 测试关键词相似度计算功能
-// 测试目的：验证 calculateKeywordSimilarity 方法计算关键词与搜索词相似度的正确性
-// 测试效果：确保完全匹配、部分匹配、无匹配、大小写不敏感等场景都能正确计算
-func TestCalculateKeywordSimilarity(t *testing.T) {
-	m := &Memory{
-		logger: newTestLogger(),
-	}
-
-	tests := []struct {
-		name     string
-		keywords []string
-		terms    string
-		expected float64
-	}{
-		{
-			name:     "完全匹配",
-			keywords: []string{"hello", "world"},
-			terms:    "hello world",
-			expected: 1.0,
-		},
-		{
-			name:     "部分匹配",
-			keywords: []string{"hello", "world", "test"},
-			terms:    "hello",
-			expected: 1.0 / 3.0,
-		},
-		{
-			name:     "无匹配",
-			keywords: []string{"hello", "world"},
-			terms:    "test",
-			expected: 0.0,
-		},
-		{
-			name:     "空关键词",
-			keywords: []string{},
-			terms:    "hello",
-			expected: 0.0,
-		},
-		{
-			name:     "大小写不敏感",
-			keywords: []string{"Hello", "World"},
-			terms:    "hello",
-			expected: 0.5,
-		},
-		{
-			name:     "包含关系",
-			keywords: []string{"hello"},
-			terms:    "hello world",
-			expected: 1.0,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := m.calculateKeywordSimilarity(tt.keywords, tt.terms)
-			assert.InDelta(t, tt.expected, result, 0.01)
-		})
-	}
-}
-
-// TestSortByWeight 测试按权重排序功能
-// 测试目的：验证 sortByWeight 方法按权重降序排序并返回指定数量的正确性
-// 测试效果：确保能正确降序排序、返回前N个、处理边界情况（空数组、topN大于数组长度）
-func TestSortByWeight(t *testing.T) {
-	m := &Memory{
-		logger: newTestLogger(),
-	}
-
-	points := []core.MemoryPoint{
-		{TotalWeight: 0.5},
-		{TotalWeight: 0.9},
-		{TotalWeight: 0.3},
-		{TotalWeight: 0.7},
-		{TotalWeight: 0.1},
-	}
-
-	t.Run("按权重降序排序", func(t *testing.T) {
-		result := m.sortByWeight(points, 5)
-		assert.Len(t, result, 5)
-		assert.Equal(t, 0.9, result[0].TotalWeight)
-		assert.Equal(t, 0.7, result[1].TotalWeight)
-		assert.Equal(t, 0.5, result[2].TotalWeight)
-		assert.Equal(t, 0.3, result[3].TotalWeight)
-		assert.Equal(t, 0.1, result[4].TotalWeight)
-	})
-
-	t.Run("返回前3个", func(t *testing.T) {
-		result := m.sortByWeight(points, 3)
-		assert.Len(t, result, 3)
-		assert.Equal(t, 0.9, result[0].TotalWeight)
-		assert.Equal(t, 0.7, result[1].TotalWeight)
-		assert.Equal(t, 0.5, result[2].TotalWeight)
-	})
-
-	t.Run("topN大于数组长度", func(t *testing.T) {
-		result := m.sortByWeight(points, 10)
-		assert.Len(t, result, 5)
-	})
-
-	t.Run("空数组", func(t *testing.T) {
-		result := m.sortByWeight([]core.MemoryPoint{}, 3)
-		assert.Len(t, result, 0)
-	})
-}
-
 // TestGenerateSummary 测试摘要生成功能
 // 测试目的：验证 generateSummary 方法在无LLM客户端时的降级处理
 // 测试效果：确保短文本直接返回，长文本截取前200字符并添加省略号
@@ -429,20 +330,6 @@ func TestGenerateKeywords(t *testing.T) {
 	})
 }
 
-// TestFilterByKeywords 测试关键词过滤功能
-// 测试目的：验证 filterByKeywords 方法根据关键词相似度过滤记忆点的正确性
-// 测试效果：确保能正确处理空entries等边界情况
-func TestFilterByKeywords(t *testing.T) {
-	m := &Memory{
-		logger: newTestLogger(),
-	}
-
-	t.Run("空entries", func(t *testing.T) {
-		result := m.filterByKeywords([]entity.VectorEntry{}, "hello")
-		assert.Len(t, result, 0)
-	})
-}
-
 // TestCalculateCosineSimilarity 测试余弦相似度计算功能
 // 测试目的：验证 calculateCosineSimilarity 方法计算向量之间余弦相似度的正确性
 // 测试效果：确保相似度计算在0-1之间，相同向量相似度为1.0，完全不同向量相似度为0.0
@@ -552,3 +439,403 @@ func TestDetermineOptimalK(t *testing.T) {
 		})
 	}
 }
+
+// TestParseMemoryPoint 测试记忆点解析
+// 测试目的：验证 parseMemoryPoint 同时支持包装格式与旧的直接序列化格式，并跳过非记忆条目
+func TestParseMemoryPoint(t *testing.T) {
+	m := &Memory{
+		logger: newTestLogger(),
+	}
+
+	wrapped := entity.VectorEntry{Key: "memory_1", Metadata: []byte(`{"memory_point":{"id":1,"content":"包装格式"}}`)}
+	point, err := m.parseMemoryPoint(wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, "包装格式", point.Content)
+
+	plain := entity.VectorEntry{Key: "memory_2", Metadata: []byte(`{"id":2,"content":"旧格式"}`)}
+	point, err = m.parseMemoryPoint(plain)
+	assert.NoError(t, err)
+	assert.Equal(t, "旧格式", point.Content)
+
+	_, err = m.parseMemoryPoint(entity.VectorEntry{Key: "skill_vector:weather", Metadata: []byte(`{}`)})
+	assert.Error(t, err)
+}
+
+// TestSearch_UsesVectorStore 测试搜索经由向量存储检索已记录的记忆
+func TestSearch_UsesVectorStore(t *testing.T) {
+	m := NewTestMemory(newTestLogger())
+
+	assert.NoError(t, m.storeMemory(core.MemoryPoint{
+		Keywords:    []string{"coffee"},
+		Summary:     "user likes coffee",
+		Content:     "drinks coffee every morning",
+		TotalWeight: 1.0,
+		Vector:      mustEmbed(t, m, "coffee user likes coffee"),
+	}))
+	assert.NoError(t, m.storeMemory(core.MemoryPoint{
+		Keywords:    []string{"basketball"},
+		Summary:     "user plays basketball",
+		Content:     "plays basketball on weekends",
+		TotalWeight: 1.0,
+		Vector:      mustEmbed(t, m, "basketball user plays basketball"),
+	}))
+
+	results, err := m.Search(context.Background(), core.MemoryScope{}, "coffee")
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "user likes coffee", results[0].Summary)
+	}
+}
+
+func mustEmbed(t *testing.T, m *Memory, text string) []float64 {
+	t.Helper()
+	vec, err := m.embeddingService.GenerateEmbedding(text)
+	assert.NoError(t, err)
+	return vec
+}
+
+// TestSearch_Ownership 测试记忆按所有者和可见范围隔离
+func TestSearch_Ownership(t *testing.T) {
+	m := NewTestMemory(newTestLogger())
+	m.localChannels = map[string]bool{"realtime": true}
+
+	record := func(summary string, owner core.MemoryOwner, visibility core.MemoryVisibility) {
+		point := core.MemoryPoint{
+			Keywords:    []string{"coffee"},
+			Summary:     summary,
+			Content:     summary,
+			TotalWeight: 1.0,
+			Vector:      mustEmbed(t, m, "coffee "+summary),
+			Visibility:  visibility,
+		}
+		point.SetOwner(owner)
+		m.normalizeOwnership(&point)
+		assert.NoError(t, m.storeMemory(point))
+	}
+	record("alice likes coffee", core.MemoryOwner{ChannelID: "telegram", UserID: "alice"}, core.MemoryPrivate)
+	record("bob likes coffee", core.MemoryOwner{ChannelID: "telegram", UserID: "bob"}, "")
+	record("household coffee", core.MemoryOwner{ChannelID: "telegram", UserID: "alice"}, core.MemoryShared)
+	record("everyone coffee", core.MemoryOwner{ChannelID: "feishu", UserID: "carol"}, core.MemoryGlobal)
+	record("local coffee", core.MemoryOwner{ChannelID: "realtime", UserID: "user_1"}, "")
+
+	summaries := func(scope core.MemoryScope) []string {
+		m.logger = newTestLogger()
+		points, err := m.Search(context.Background(), scope, "coffee")
+		assert.NoError(t, err)
+		var result []string
+		for _, p := range points {
+			result = append(result, p.Summary)
+		}
+		return result
+	}
+
+	// 检索只返回前 3 条，这里只检查是否越权
+	for _, s := range summaries(core.MemoryScope{ChannelID: "telegram", UserID: "bob", SessionID: "bob"}) {
+		assert.NotEqual(t, "alice likes coffee", s)
+		assert.NotEqual(t, "local coffee", s)
+	}
+	for _, s := range summaries(core.MemoryScope{ChannelID: "feishu", UserID: "dave"}) {
+		assert.Equal(t, "everyone coffee", s)
+	}
+	local := summaries(core.MemoryScope{ChannelID: "realtime", UserID: "user_2"})
+	assert.ElementsMatch(t, []string{"local coffee", "everyone coffee"}, local, "本地 Channel 的用户都是默认所有者")
+}
+
+// TestSearch_OwnMemoryBehindForeignCandidates 测试其他用户的记忆占满向量检索候选时仍能找到自己的记忆
+func TestSearch_OwnMemoryBehindForeignCandidates(t *testing.T) {
+	m := NewTestMemory(newTestLogger())
+	vector := mustEmbed(t, m, "coffee")
+
+	for i := 0; i < searchCandidates+10; i++ {
+		point := core.MemoryPoint{
+			Keywords:    []string{"coffee"},
+			Summary:     fmt.Sprintf("bob coffee %d", i),
+			Content:     "coffee",
+			TotalWeight: 1.0,
+			Vector:      vector,
+			Visibility:  core.MemoryPrivate,
+		}
+		point.SetOwner(core.MemoryOwner{ChannelID: "telegram", UserID: "bob"})
+		assert.NoError(t, m.storeMemory(point))
+	}
+
+	// 自己的记忆只能由向量检索找到，相似度略低于其他用户的记忆
+	own := make([]float64, len(vector))
+	copy(own, vector)
+	for i := range own {
+		if own[i] == 0 {
+			own[i] = 0.05
+			break
+		}
+	}
+	point := core.MemoryPoint{Summary: "alice espresso", Content: "espresso", TotalWeight: 1.0, Vector: own, Visibility: core.MemoryPrivate}
+	point.SetOwner(core.MemoryOwner{ChannelID: "telegram", UserID: "alice"})
+	assert.NoError(t, m.storeMemory(point))
+
+	results, err := m.Search(context.Background(), core.MemoryScope{ChannelID: "telegram", UserID: "alice", SessionID: "alice"}, "coffee")
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "alice espresso", results[0].Summary)
+	}
+}
+
+// TestDeduplicateMemory_SameOwnerOnly 测试去重不会合并其他用户的记忆
+func TestDeduplicateMemory_SameOwnerOnly(t *testing.T) {
+	m := NewTestMemory(newTestLogger())
+	vector := mustEmbed(t, m, "coffee every morning")
+
+	existing := core.MemoryPoint{Summary: "alice", Vector: vector, Visibility: core.MemoryPrivate}
+	existing.SetOwner(core.MemoryOwner{ChannelID: "telegram", UserID: "alice"})
+	assert.NoError(t, m.storeMemory(existing))
+
+	other := core.MemoryPoint{Summary: "bob", Vector: vector, Visibility: core.MemoryPrivate}
+	other.SetOwner(core.MemoryOwner{ChannelID: "telegram", UserID: "bob"})
+	_, merged := m.DeduplicateMemory(&other)
+	assert.False(t, merged)
+
+	same := core.MemoryPoint{Summary: "alice again", Vector: vector, Visibility: core.MemoryPrivate}
+	same.SetOwner(existing.Owner())
+	result, merged := m.DeduplicateMemory(&same)
+	assert.True(t, merged)
+	assert.Equal(t, existing.Owner(), result.Owner())
+}
+
+// TestMigrateOwners 测试旧记忆迁移到默认所有者
+func TestMigrateOwners(t *testing.T) {
+	m := NewTestMemory(newTestLogger())
+	m.defaultOwner = core.ParseMemoryOwner("telegram:owner")
+
+	// 旧版本写入的记忆没有所有者和可见范围
+	assert.NoError(t, m.store.Put("memory_1", []float64{1, 0}, map[string]any{
+		"memory_point": core.MemoryPoint{Summary: "legacy"},
+	}))
+	shared := core.MemoryPoint{Summary: "shared", Visibility: core.MemoryShared, ChannelID: "feishu", OwnerID: "x"}
+	assert.NoError(t, m.store.Put("memory_2", []float64{0, 1}, map[string]any{"memory_point": shared}))
+
+	assert.NoError(t, m.migrateOwners())
+
+	points, err := m.getAllMemories()
+	assert.NoError(t, err)
+	assert.Len(t, points, 2)
+	for _, p := range points {
+		switch p.Summary {
+		case "legacy":
+			assert.Equal(t, core.MemoryOwner{ChannelID: "telegram", UserID: "owner"}, p.Owner())
+			assert.Equal(t, core.MemoryPrivate, p.Visibility)
+		case "shared":
+			assert.Equal(t, shared.Owner(), p.Owner(), "已有可见范围的记忆不迁移")
+		}
+	}
+
+	entry, err := m.store.Get("memory_1")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 0}, entry.Vector, "迁移保留原向量")
+}
+
+// TestMigrateMemoryKeys 测试旧记忆分配 ID 并移动到按 ID 命名的 key
+func TestMigrateMemoryKeys(t *testing.T) {
+	m := NewTestMemory(newTestLogger())
+
+	// 旧版本按写入时间生成 key，ID 都为 0
+	assert.NoError(t, m.store.Put("memory_1700000000000000001", []float64{1, 0}, map[string]any{
+		"memory_point": core.MemoryPoint{Summary: "first"},
+	}))
+	assert.NoError(t, m.store.Put("memory_1700000000000000002", []float64{0, 1}, map[string]any{
+		"memory_point": core.MemoryPoint{Summary: "second"},
+	}))
+	assert.NoError(t, m.store.Put(memoryKey(5), []float64{1, 1}, map[string]any{
+		"memory_point": core.MemoryPoint{ID: 5, Summary: "current"},
+	}))
+
+	assert.NoError(t, m.migrateMemoryKeys())
+
+	points, err := m.ListMemories(MemoryFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, points, 3)
+	ids := make(map[int]string)
+	for _, p := range points {
+		ids[p.ID] = p.Summary
+	}
+	assert.Equal(t, "current", ids[5])
+	assert.Len(t, ids, 3, "迁移后 ID 不重复")
+	for id := range ids {
+		assert.Greater(t, id, 0)
+		_, err := m.store.Get(memoryKey(id))
+		assert.NoError(t, err)
+	}
+
+	_, err = m.store.Get("memory_1700000000000000001")
+	assert.Error(t, err, "旧 key 已删除")
+	assert.Greater(t, m.nextMemoryID(), 7, "新 ID 不与已有 ID 重复")
+}
+
+// TestExportImport 测试记忆导出为 JSONL 后导入到另一个记忆库
+func TestExportImport(t *testing.T) {
+	src := NewTestMemory(newTestLogger())
+	for _, summary := range []string{"user likes green tea", "user lives in hangzhou"} {
+		_, err := src.record(core.MemoryPoint{
+			Keywords:    strings.Fields(summary)[1:],
+			Summary:     summary,
+			Content:     summary,
+			TotalWeight: 1.0,
+			Visibility:  core.MemoryShared,
+			ChannelID:   "telegram",
+			OwnerID:     "alice",
+		})
+		assert.NoError(t, err)
+	}
+
+	var buf bytes.Buffer
+	count, err := src.Export(&buf, ExportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	var header ExportHeader
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+	assert.Equal(t, ExportFormat, header.Format)
+	assert.Equal(t, 2, header.Count)
+	assert.Contains(t, lines[1], `"vector":null`, "不导出向量")
+
+	// 目标库已有一条相同的记忆，导入时合并
+	dst := NewTestMemory(newTestLogger())
+	_, err = dst.record(core.MemoryPoint{
+		Keywords:   []string{"likes", "green", "tea"},
+		Summary:    "user likes green tea",
+		Content:    "user likes green tea",
+		Visibility: core.MemoryShared,
+		ChannelID:  "telegram",
+		OwnerID:    "alice",
+	})
+	assert.NoError(t, err)
+
+	result, err := dst.Import(bytes.NewReader(buf.Bytes()), ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResult{Imported: 1, Merged: 1}, result)
+
+	points, err := dst.ListMemories(MemoryFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, points, 2)
+	for _, p := range points {
+		assert.Equal(t, core.MemoryOwner{ChannelID: "telegram", UserID: "alice"}, p.Owner(), "导入保留所有者")
+		assert.Equal(t, core.MemoryShared, p.Visibility)
+		full, err := dst.GetMemoryPoint(p.ID)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, full.Vector, "导入时重新生成向量")
+	}
+}
+
+// TestImport_InvalidInput 测试导入格式错误的文件
+func TestImport_InvalidInput(t *testing.T) {
+	m := NewTestMemory(newTestLogger())
+
+	_, err := m.Import(strings.NewReader(`{"format":"other","version":1}`+"\n"), ImportOptions{})
+	assert.Error(t, err)
+
+	// 不带文件头、包含无法解析的行，以及维度与存储不一致的向量
+	input := `{"summary":"first","content":"first","keywords":["first"]}` + "\n" +
+		"not json\n" +
+		`{"summary":"second","content":"second","keywords":["second"],"vector":[1,2,3]}` + "\n"
+	result, err := m.Import(strings.NewReader(input), ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, 256, m.store.Dimension(), "维度不一致的向量重新生成")
+}
+
+// TestSearch_Hybrid 测试向量检索与全文检索的融合
+func TestSearch_Hybrid(t *testing.T) {
+	m := NewTestMemory(newTestLogger())
+	for _, summary := range []string{
+		"用户的车牌号是沪A12345",
+		"用户的女儿叫王小雨",
+		"user prefers dark roast coffee",
+		"user plays basketball on weekends",
+	} {
+		_, err := m.record(core.MemoryPoint{Summary: summary, Content: summary, TotalWeight: 1.0})
+		assert.NoError(t, err)
+	}
+
+	// 中文和数字由全文检索命中（测试用的 TF-IDF 嵌入不支持中文）
+	results, err := m.Search(context.Background(), core.MemoryScope{}, "A12345 是谁的车")
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, results) {
+		assert.Equal(t, "用户的车牌号是沪A12345", results[0].Summary)
+		assert.Greater(t, results[0].Score, 0.0)
+		assert.LessOrEqual(t, results[0].Score, 1.0)
+	}
+
+	results, err = m.Search(context.Background(), core.MemoryScope{}, "小雨")
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, results) {
+		assert.Equal(t, "用户的女儿叫王小雨", results[0].Summary)
+	}
+
+	// 两种检索都排第一的记忆相关度为 1
+	results, err = m.Search(context.Background(), core.MemoryScope{}, "dark roast coffee")
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, results) {
+		assert.Equal(t, "user prefers dark roast coffee", results[0].Summary)
+		assert.InDelta(t, 1.0, results[0].Score, 1e-9)
+	}
+
+	// 删除后不再被全文检索命中
+	points, err := m.ListMemories(MemoryFilter{Query: "小雨"})
+	assert.NoError(t, err)
+	assert.NoError(t, m.DeleteMemory(points[0].ID))
+	results, err = m.Search(context.Background(), core.MemoryScope{}, "小雨")
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	m.searchTopN = 1
+	results, err = m.Search(context.Background(), core.MemoryScope{}, "user")
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+// reverseReranker 将候选倒序，用于验证重排结果决定最终顺序；ctx 取消时返回错误
+type reverseReranker struct{}
+
+func (reverseReranker) Name() string { return "reverse" }
+
+func (reverseReranker) Rerank(ctx context.Context, _ string, docs []core.RerankDocument) ([]core.RerankDocument, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	results := make([]core.RerankDocument, len(docs))
+	for i, doc := range docs {
+		doc.Score = float64(i+1) / float64(len(docs))
+		results[len(docs)-1-i] = doc
+	}
+	return results, nil
+}
+
+func TestSearch_Rerank(t *testing.T) {
+	m := NewTestMemory(newTestLogger())
+	for _, summary := range []string{"user likes green tea", "the tea shop near the office opens at nine"} {
+		_, err := m.record(core.MemoryPoint{Summary: summary, Content: summary, TotalWeight: 1.0})
+		assert.NoError(t, err)
+	}
+
+	before, err := m.Search(context.Background(), core.MemoryScope{}, "tea")
+	assert.NoError(t, err)
+	require.Len(t, before, 2)
+
+	m.SetReranker(reverseReranker{})
+	after, err := m.Search(context.Background(), core.MemoryScope{}, "tea")
+	assert.NoError(t, err)
+	require.Len(t, after, 2)
+	assert.Equal(t, before[1].ID, after[0].ID)
+	assert.Equal(t, before[0].ID, after[1].ID)
+	assert.InDelta(t, 1.0, after[0].Score, 1e-9)
+
+	// 请求已取消时重排使用请求的 ctx 中止，沿用融合顺序
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	canceled, err := m.Search(ctx, core.MemoryScope{}, "tea")
+	assert.NoError(t, err)
+	require.Len(t, canceled, 2)
+	assert.Equal(t, before[0].ID, canceled[0].ID)
+}
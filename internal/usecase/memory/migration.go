@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"fmt"
+	"mindx/internal/core"
+	"mindx/internal/usecase/embedding"
+)
+
+// migrationSource 记忆的嵌入模型迁移数据源
+type migrationSource struct {
+	m *Memory
+}
+
+// MigrationSource 返回记忆的嵌入模型迁移数据源
+func (m *Memory) MigrationSource() embedding.MigrationSource {
+	return migrationSource{m: m}
+}
+
+func (s migrationSource) Collection() string {
+	return core.CollectionMemories
+}
+
+func (s migrationSource) Store() core.Store {
+	return s.m.store
+}
+
+func (s migrationSource) StoredDimension() int {
+	if s.m.store == nil {
+		return 0
+	}
+	return s.m.store.Dimension()
+}
+
+func (s migrationSource) ReembedKeys() ([]string, error) {
+	if s.m.store == nil {
+		return nil, nil
+	}
+	entries, err := s.m.store.Scan(memoryKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	return keys, nil
+}
+
+// Reembed 按记忆的摘要和内容重新生成向量；迁移期间已被删除的记忆直接跳过
+func (s migrationSource) Reembed(key string) error {
+	if s.m.embeddingService == nil {
+		return fmt.Errorf("embedding service not set")
+	}
+	entry, err := s.m.store.Get(key)
+	if err != nil || entry == nil {
+		return nil
+	}
+	point, err := s.m.parseMemoryPoint(*entry)
+	if err != nil {
+		return err
+	}
+	vector, err := s.m.embeddingService.GenerateEmbedding(embeddingText(point))
+	if err != nil {
+		return err
+	}
+	point.Vector = vector
+	return s.m.storeMemory(point)
+}
+
+// ReloadVectors 记忆检索直接读取向量存储，无需刷新
+func (s migrationSource) ReloadVectors() error {
+	return nil
+}
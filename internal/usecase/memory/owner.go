@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"mindx/internal/core"
+	"mindx/pkg/i18n"
+	"mindx/pkg/logging"
+)
+
+// resolveOwner 本地 Channel 与未指定 Channel 的所有者归为默认所有者
+func (m *Memory) resolveOwner(owner core.MemoryOwner) core.MemoryOwner {
+	if owner.ChannelID == "" || m.localChannels[owner.ChannelID] {
+		return m.defaultOwner
+	}
+	return owner
+}
+
+// resolveScope 本地 Channel 与未指定 Channel 的请求按默认所有者检索
+func (m *Memory) resolveScope(scope core.MemoryScope) core.MemoryScope {
+	if scope.ChannelID == "" || m.localChannels[scope.ChannelID] {
+		return core.MemoryScope{ChannelID: m.defaultOwner.ChannelID, UserID: m.defaultOwner.UserID}
+	}
+	return scope
+}
+
+// normalizeOwnership 写入前补全记忆点的所有者与可见范围
+func (m *Memory) normalizeOwnership(point *core.MemoryPoint) {
+	point.SetOwner(m.resolveOwner(point.Owner()))
+	if !point.Visibility.IsValid() {
+		point.Visibility = core.MemoryPrivate
+	}
+}
+
+// sameOwnership 两个记忆点是否属于同一所有者且可见范围相同，只有这样的记忆才能合并
+func sameOwnership(a, b core.MemoryPoint) bool {
+	return a.Owner() == b.Owner() && a.Visibility == b.Visibility
+}
+
+// migrateOwners 将没有可见范围的旧记忆归属默认所有者，设为私有
+// 迁移后的记忆都带有可见范围，重复执行不会再次修改
+func (m *Memory) migrateOwners() error {
+	if m.store == nil {
+		return nil
+	}
+
+	entries, err := m.store.Scan(memoryKeyPrefix)
+	if err != nil {
+		return err
+	}
+
+	migrated := 0
+	for _, entry := range entries {
+		point, err := m.parseMemoryPoint(entry)
+		if err != nil || point.Visibility != "" {
+			continue
+		}
+		point.SetOwner(m.defaultOwner)
+		point.Visibility = core.MemoryPrivate
+		if err := m.store.Put(entry.Key, entry.Vector, map[string]any{"memory_point": point}); err != nil {
+			return err
+		}
+		migrated++
+	}
+
+	if migrated > 0 {
+		m.logger.Info(i18n.T("memory.owner_migrated"),
+			logging.Int(i18n.T("memory.count"), migrated),
+			logging.String("owner", m.defaultOwner.String()))
+	}
+	return nil
+}
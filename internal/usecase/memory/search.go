@@ -1,127 +1,217 @@
 package memory
 
 import (
-	"encoding/json"
+	"context"
 	"math"
+	"mindx/internal/config"
 	"mindx/internal/core"
 	"mindx/internal/entity"
 	apperrors "mindx/internal/errors"
+	"mindx/internal/usecase/rerank"
+	"mindx/pkg/bm25"
 	"mindx/pkg/i18n"
 	"mindx/pkg/logging"
 	"sort"
 	"strings"
 )
 
-func (m *Memory) Search(terms string) ([]core.MemoryPoint, error) {
+// searchCandidates 向量检索和全文检索各自取回的候选记忆数
+const searchCandidates = 50
+
+// rrfK 倒数排名融合的平滑常数，值越大排名靠后的结果影响越大
+const rrfK = 60
+
+// rerankCandidates 交给重排器的融合结果数
+const rerankCandidates = 10
+
+// Search 搜索请求方可见的相似记忆
+// 分别由向量存储（余弦相似度不低于阈值）和 BM25 全文索引取回候选，按倒数排名融合（RRF）。
+// 向量检索擅长语义相近的表达，全文检索擅长人名、数字等精确匹配；融合得分相同时权重高、较新的记忆优先。
+// 设置了重排器时，融合结果的前若干条经重排后再截取 topN，Score 为重排得分
+func (m *Memory) Search(ctx context.Context, scope core.MemoryScope, terms string) ([]core.MemoryPoint, error) {
 	m.logger.Debug(i18n.T("memory.start_search"), logging.String(i18n.T("memory.terms"), terms))
+	scope = m.resolveScope(scope)
 
-	if m.embeddingService == nil {
+	if m.store == nil {
 		return []core.MemoryPoint{}, nil
 	}
 
-	termVector, err := m.embeddingService.GenerateEmbedding(terms)
-	if err != nil {
-		m.logger.Error(i18n.T("memory.gen_search_vector_failed"), logging.Err(err))
-		if m.store == nil {
-			return []core.MemoryPoint{}, nil
-		}
-		entries, err := m.store.Search(nil, 10)
+	points := make(map[string]core.MemoryPoint)
+	var rankings [][]string
+
+	if m.embeddingService != nil {
+		termVector, err := m.embeddingService.GenerateEmbedding(terms)
 		if err != nil {
-			m.logger.Error(i18n.T("memory.vector_search_failed"), logging.Err(err))
-			return nil, apperrors.Wrap(err, apperrors.ErrTypeMemory, "向量搜索失败")
+			// 向量生成失败时只使用全文检索
+			m.logger.Error(i18n.T("memory.gen_search_vector_failed"), logging.Err(err))
+		} else {
+			ranking, err := m.vectorRanking(termVector, scope, points)
+			if err != nil {
+				m.logger.Error(i18n.T("memory.vector_search_failed"), logging.Err(err))
+				return nil, apperrors.Wrap(err, apperrors.ErrTypeMemory, "向量搜索失败")
+			}
+			rankings = append(rankings, ranking)
 		}
-		filtered := m.filterByKeywords(entries, terms)
-		sorted := m.sortByWeight(filtered, 3)
-		return sorted, nil
 	}
 
-	allMemories, err := m.getAllMemories()
-	if err != nil {
-		m.logger.Error(i18n.T("memory.get_memories_failed"), logging.Err(err))
-		return nil, apperrors.Wrap(err, apperrors.ErrTypeMemory, "获取记忆点失败")
-	}
-	var candidatePoints []core.MemoryPoint
-	for _, memoryPoint := range allMemories {
-		if len(memoryPoint.Vector) == 0 || len(termVector) == 0 {
-			continue
-		}
+	rankings = append(rankings, m.textRanking(terms, scope, points))
 
-		similarity := m.calculateCosineSimilarity(memoryPoint.Vector, termVector)
-		if similarity >= 0.5 {
-			candidatePoints = append(candidatePoints, memoryPoint)
-		}
+	results := m.rerank(ctx, terms, m.fuseRankings(rankings, points))
+	if topN := m.topN(); len(results) > topN {
+		results = results[:topN]
 	}
+	m.logger.Info(i18n.T("memory.search_complete"), logging.Int(i18n.T("memory.found"), len(results)))
+
+	return results, nil
+}
 
-	var candidateEntries []entity.VectorEntry
-	for _, point := range candidatePoints {
-		metadataBytes, err := json.Marshal(point)
+// vectorRanking 按向量相似度返回请求方可见的前 searchCandidates 条记忆 key
+// 存储中的记忆属于所有用户，可见记忆不足时成倍扩大检索数量重新检索，直到凑足或相似度达标的记忆已全部取回，
+// 与全文检索一样取回足量的可见候选，两路排名才能融合
+func (m *Memory) vectorRanking(termVector []float64, scope core.MemoryScope, points map[string]core.MemoryPoint) ([]string, error) {
+	for limit := searchCandidates; ; limit *= 2 {
+		entries, err := m.store.SearchWithThreshold(termVector, limit, m.threshold())
 		if err != nil {
-			continue
+			return nil, err
+		}
+		ranking := m.visibleRanking(entries, scope, points)
+		if len(ranking) >= searchCandidates || len(entries) < limit {
+			if len(ranking) > searchCandidates {
+				ranking = ranking[:searchCandidates]
+			}
+			return ranking, nil
 		}
-		candidateEntries = append(candidateEntries, entity.VectorEntry{
-			Metadata: metadataBytes,
-		})
 	}
-
-	filteredPoints := m.filterByKeywords(candidateEntries, terms)
-	m.logger.Debug(i18n.T("memory.after_filter"), logging.Int(i18n.T("memory.count"), len(filteredPoints)))
-
-	sorted := m.sortByWeight(filteredPoints, 3)
-
-	m.logger.Info(i18n.T("memory.search_complete"), logging.Int(i18n.T("memory.found"), len(sorted)))
-
-	return sorted, nil
 }
 
-func (m *Memory) filterByKeywords(entries []entity.VectorEntry, terms string) []core.MemoryPoint {
-	var filtered []core.MemoryPoint
-	termsLower := strings.ToLower(terms)
-
+// visibleRanking 按向量检索的顺序返回请求方可见的记忆 key，并缓存解析出的记忆点
+func (m *Memory) visibleRanking(entries []entity.VectorEntry, scope core.MemoryScope, points map[string]core.MemoryPoint) []string {
+	ranking := make([]string, 0, len(entries))
 	for _, entry := range entries {
-		var point core.MemoryPoint
-		if err := json.Unmarshal(entry.Metadata, &point); err != nil {
+		point, err := m.parseMemoryPoint(entry)
+		if err != nil || !scope.CanSee(point) {
 			continue
 		}
+		points[entry.Key] = point
+		ranking = append(ranking, entry.Key)
+	}
+	return ranking
+}
 
-		similarity := m.calculateKeywordSimilarity(point.Keywords, termsLower)
-		if similarity > 0.6 {
-			filtered = append(filtered, point)
+// textRanking 按 BM25 得分返回请求方可见的记忆 key
+func (m *Memory) textRanking(terms string, scope core.MemoryScope, points map[string]core.MemoryPoint) []string {
+	ranking := make([]string, 0, searchCandidates)
+	for _, hit := range m.textIndex().Search(terms, 0, nil) {
+		if len(ranking) >= searchCandidates {
+			break
 		}
+		if _, ok := points[hit.Key]; !ok {
+			entry, err := m.store.Get(hit.Key)
+			if err != nil {
+				continue
+			}
+			point, err := m.parseMemoryPoint(*entry)
+			if err != nil || !scope.CanSee(point) {
+				continue
+			}
+			points[hit.Key] = point
+		}
+		ranking = append(ranking, hit.Key)
 	}
-
-	return filtered
+	return ranking
 }
 
-func (m *Memory) calculateKeywordSimilarity(keywords []string, terms string) float64 {
-	if len(keywords) == 0 {
-		return 0
+// fuseRankings 倒数排名融合，按融合得分降序返回全部记忆
+// Score 归一化到 0~1：在所有检索方式中都排第一的记忆为 1
+func (m *Memory) fuseRankings(rankings [][]string, points map[string]core.MemoryPoint) []core.MemoryPoint {
+	scores := make(map[string]float64)
+	for _, ranking := range rankings {
+		for rank, key := range ranking {
+			scores[key] += 1.0 / float64(rrfK+rank+1)
+		}
 	}
+	maxScore := float64(len(rankings)) / float64(rrfK+1)
 
-	matchCount := 0
-	for _, kw := range keywords {
-		kwLower := strings.ToLower(kw)
-		if strings.Contains(terms, kwLower) || strings.Contains(kwLower, terms) {
-			matchCount++
+	results := make([]core.MemoryPoint, 0, len(scores))
+	for key, score := range scores {
+		point := points[key]
+		point.Score = score / maxScore
+		results = append(results, point)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].TotalWeight != results[j].TotalWeight {
+			return results[i].TotalWeight > results[j].TotalWeight
 		}
+		return results[i].ID > results[j].ID
+	})
+	return results
+}
+
+// rerank 使用重排器调整融合结果前 rerankCandidates 条的顺序，未设置重排器时原样返回
+func (m *Memory) rerank(ctx context.Context, terms string, results []core.MemoryPoint) []core.MemoryPoint {
+	if m.reranker == nil || len(results) < 2 {
+		return results
+	}
+	n := len(results)
+	if n > rerankCandidates {
+		n = rerankCandidates
 	}
 
-	return float64(matchCount) / float64(len(keywords))
-}
+	docs := make([]core.RerankDocument, n)
+	byKey := make(map[string]core.MemoryPoint, n)
+	for i, point := range results[:n] {
+		key := memoryKey(point.ID)
+		docs[i] = core.RerankDocument{ID: key, Text: embeddingText(point), Score: point.Score}
+		byKey[key] = point
+	}
 
-func (m *Memory) sortByWeight(points []core.MemoryPoint, topN int) []core.MemoryPoint {
-	if len(points) == 0 {
-		return []core.MemoryPoint{}
+	reranked := make([]core.MemoryPoint, 0, len(results))
+	for _, doc := range rerank.Apply(ctx, m.reranker, terms, docs, m.logger) {
+		point := byKey[doc.ID]
+		point.Score = doc.Score
+		reranked = append(reranked, point)
 	}
+	return append(reranked, results[n:]...)
+}
 
-	sort.Slice(points, func(i, j int) bool {
-		return points[i].TotalWeight > points[j].TotalWeight
+// textIndex 返回记忆的 BM25 索引，首次调用时由向量存储中的记忆重建
+func (m *Memory) textIndex() *bm25.Index {
+	m.bm25Once.Do(func() {
+		m.bm25Index = bm25.New(bm25.DefaultConfig())
+		if m.store == nil {
+			return
+		}
+		entries, err := m.store.Scan(memoryKeyPrefix)
+		if err != nil {
+			m.logger.Warn(i18n.T("memory.text_index_failed"), logging.Err(err))
+			return
+		}
+		for _, entry := range entries {
+			if point, err := m.parseMemoryPoint(entry); err == nil {
+				m.bm25Index.Add(entry.Key, embeddingText(point))
+			}
+		}
 	})
+	return m.bm25Index
+}
 
-	if topN > len(points) {
-		topN = len(points)
+// topN 每次检索返回的记忆数
+func (m *Memory) topN() int {
+	if m.searchTopN > 0 {
+		return m.searchTopN
 	}
+	return config.MemoryConfig{}.GetSearchTopN()
+}
 
-	return points[:topN]
+// threshold 向量检索候选的最低余弦相似度
+func (m *Memory) threshold() float64 {
+	if m.searchThreshold > 0 {
+		return m.searchThreshold
+	}
+	return config.MemoryConfig{}.GetSearchThreshold()
 }
 
 func (m *Memory) calculateCosineSimilarity(vec1, vec2 []float64) float64 {
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mindx/internal/core"
+	apperrors "mindx/internal/errors"
+	"mindx/pkg/i18n"
+	"mindx/pkg/logging"
+	"time"
+)
+
+// 导出文件格式：JSONL，第一行为 ExportHeader，之后每行一个 core.MemoryPoint
+const (
+	ExportFormat  = "mindx-memory"
+	ExportVersion = 1
+)
+
+// maxImportLine 导入时单行的最大长度，包含向量的记忆点可能较长
+const maxImportLine = 16 * 1024 * 1024
+
+// ExportHeader 导出文件的第一行
+type ExportHeader struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Count      int       `json:"count"`
+	Dimension  int       `json:"dimension,omitempty"` // 向量维度，不含向量时为 0
+}
+
+// ExportOptions 导出选项
+type ExportOptions struct {
+	IncludeVectors bool // 是否导出向量；不导出时文件与嵌入模型无关，导入时重新生成
+}
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	ReEmbed bool // 忽略文件中的向量，使用当前的嵌入模型重新生成
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Imported int      `json:"imported"` // 新增的记忆点
+	Merged   int      `json:"merged"`   // 与已有相似记忆合并的记忆点
+	Skipped  int      `json:"skipped"`  // 无法解析或写入的行
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// Export 将所有记忆点按 ID 顺序导出为 JSONL，返回导出的记忆点数
+func (m *Memory) Export(w io.Writer, opts ExportOptions) (int, error) {
+	points, err := m.ListMemories(MemoryFilter{})
+	if err != nil {
+		return 0, err
+	}
+
+	header := ExportHeader{
+		Format:     ExportFormat,
+		Version:    ExportVersion,
+		ExportedAt: time.Now(),
+		Count:      len(points),
+	}
+	if opts.IncludeVectors && m.store != nil {
+		header.Dimension = m.store.Dimension()
+	}
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return 0, apperrors.Wrap(err, apperrors.ErrTypeMemory, "写入导出文件失败")
+	}
+	// ListMemories 按 ID 倒序，导出时按写入顺序排列
+	for i := len(points) - 1; i >= 0; i-- {
+		point := points[i]
+		if !opts.IncludeVectors {
+			point.Vector = nil
+		}
+		if err := enc.Encode(point); err != nil {
+			return 0, apperrors.Wrap(err, apperrors.ErrTypeMemory, "写入导出文件失败")
+		}
+	}
+
+	m.logger.Info(i18n.T("memory.exported"), logging.Int(i18n.T("memory.count"), len(points)), logging.Bool("vectors", opts.IncludeVectors))
+	return len(points), nil
+}
+
+// Import 从 JSONL 导入记忆点
+// 记忆点使用新的 ID；没有向量、向量维度与当前存储不一致或指定重新生成时，用当前的嵌入模型生成向量。
+// 与已有记忆重复时按 DeduplicateMemory 的规则合并，不会产生重复记忆
+func (m *Memory) Import(r io.Reader, opts ImportOptions) (ImportResult, error) {
+	var result ImportResult
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+
+	// 当前存储的向量维度，存储为空时为当前嵌入模型的维度，在遇到第一个带向量的记忆点时确定
+	dim := 0
+	if m.store != nil {
+		dim = m.store.Dimension()
+	}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		if line == 1 {
+			var header ExportHeader
+			if err := json.Unmarshal(data, &header); err == nil && header.Format != "" {
+				if header.Format != ExportFormat || header.Version > ExportVersion {
+					return result, apperrors.New(apperrors.ErrTypeMemory, fmt.Sprintf("不支持的导入格式: %s v%d", header.Format, header.Version))
+				}
+				continue
+			}
+		}
+
+		var point core.MemoryPoint
+		if err := json.Unmarshal(data, &point); err != nil {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+
+		point.ID = 0
+		if dim == 0 && len(point.Vector) > 0 && m.embeddingService != nil {
+			if vector, err := m.embeddingService.GenerateEmbedding(embeddingText(point)); err == nil {
+				dim = len(vector)
+			}
+		}
+		if opts.ReEmbed || (dim != 0 && len(point.Vector) != dim) {
+			point.Vector = nil
+		}
+
+		merged, err := m.record(point)
+		if err != nil {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		if merged {
+			result.Merged++
+		} else {
+			result.Imported++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, apperrors.Wrap(err, apperrors.ErrTypeMemory, "读取导入文件失败")
+	}
+
+	m.logger.Info(i18n.T("memory.imported"),
+		logging.Int("imported", result.Imported),
+		logging.Int("merged", result.Merged),
+		logging.Int("skipped", result.Skipped))
+	return result, nil
+}
@@ -1,8 +1,6 @@
 package memory
 
 import (
-	"encoding/json"
-	"mindx/internal/core"
 	"mindx/pkg/i18n"
 	"mindx/pkg/logging"
 	"strings"
@@ -31,8 +29,8 @@ func (m *Memory) calculateRepeatWeight(text string) float64 {
 	textLower := strings.ToLower(text)
 
 	for _, mem := range allMemories {
-		var memoryPoint core.MemoryPoint
-		if err := json.Unmarshal(mem.Metadata, &memoryPoint); err != nil {
+		memoryPoint, err := m.parseMemoryPoint(mem)
+		if err != nil {
 			continue
 		}
 		kwMatch := false
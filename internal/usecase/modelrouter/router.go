@@ -0,0 +1,349 @@
+// Package modelrouter 按模型的降级链选择可用的模型服务
+//
+// 每个模型服务（按 base_url 区分）有独立的熔断器：调用连续失败或健康探测失败时熔断，
+// 熔断期间请求直接改用降级链中的下一个模型，等待一段时间后再重新尝试原模型。
+package modelrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mindx/internal/config"
+	"mindx/internal/infrastructure/llm"
+	"mindx/pkg/circuitbreaker"
+	"mindx/pkg/i18n"
+	"mindx/pkg/logging"
+	"mindx/pkg/retry"
+	"net/http"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+var (
+	defaultRouter *Router
+	defaultMu     sync.Mutex
+)
+
+// Default 返回进程共享的路由器，熔断状态在所有调用方之间共享
+func Default() *Router {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultRouter == nil {
+		defaultRouter = New(config.ModelHealthConfig{}, logging.GetSystemLogger().Named("model_router"))
+	}
+	return defaultRouter
+}
+
+// SetDefault 替换进程共享的路由器，应在创建大脑和能力管理器之前调用
+func SetDefault(r *Router) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultRouter = r
+}
+
+// Option 配置 Router
+type Option func(*Router)
+
+// WithRetryConfig 设置同一模型的重试策略，重试耗尽后才改用降级链中的下一个模型
+func WithRetryConfig(cfg retry.Config) Option {
+	return func(r *Router) { r.retryCfg = cfg }
+}
+
+// Router 模型路由器
+// 为 Thinking、主意识和能力管理器选择降级链中第一个可用的模型
+type Router struct {
+	cfg        config.ModelHealthConfig
+	retryCfg   retry.Config
+	logger     logging.Logger
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	breakers  map[string]*circuitbreaker.CircuitBreaker // 模型服务 -> 熔断器
+	providers map[string]llm.Provider                   // 接口类型、模型服务和密钥 -> 对话模型服务
+	cancel    context.CancelFunc
+}
+
+// New 创建模型路由器
+func New(cfg config.ModelHealthConfig, logger logging.Logger, opts ...Option) *Router {
+	retryCfg := retry.DefaultConfig()
+	retryCfg.Retryable = IsProviderError
+
+	r := &Router{
+		cfg:        cfg,
+		retryCfg:   retryCfg,
+		logger:     logger,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		breakers:   make(map[string]*circuitbreaker.CircuitBreaker),
+		providers:  make(map[string]llm.Provider),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Chain 返回模型的降级链：模型本身，以及 fallbacks 中按顺序列出的已配置模型
+// 降级模型自身的 fallbacks 不再展开，避免循环
+func (r *Router) Chain(model *config.ModelConfig) []*config.ModelConfig {
+	chain := []*config.ModelConfig{model}
+	modelsMgr := config.GetModelsManager()
+	if len(model.Fallbacks) == 0 || modelsMgr == nil {
+		return chain
+	}
+
+	seen := map[string]bool{model.Name: true}
+	for _, name := range model.Fallbacks {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		fallback, err := modelsMgr.GetModel(name)
+		if err != nil {
+			r.logger.Warn(i18n.T("model.fallback_not_found"),
+				logging.String("model", model.Name),
+				logging.String("fallback", name))
+			continue
+		}
+		chain = append(chain, fallback)
+	}
+	return chain
+}
+
+// Healthy 模型所在的服务是否可用（熔断器未打开）
+func (r *Router) Healthy(model *config.ModelConfig) bool {
+	return r.breaker(model).State() != circuitbreaker.StateOpen
+}
+
+// Select 返回降级链中第一个服务可用的模型及其对话模型服务；全部不可用时返回模型本身
+func (r *Router) Select(model *config.ModelConfig) (*config.ModelConfig, llm.Provider, error) {
+	selected := model
+	for _, candidate := range r.Chain(model) {
+		if r.Healthy(candidate) {
+			selected = candidate
+			break
+		}
+	}
+	provider, err := r.provider(selected)
+	return selected, provider, err
+}
+
+// Do 沿模型的降级链执行 fn，返回实际使用的模型
+// 熔断中的模型直接跳过；模型服务不可用（见 IsProviderError）时重试耗尽后改用下一个模型，
+// 其它错误（如请求参数错误）直接返回，不再降级
+func (r *Router) Do(ctx context.Context, model *config.ModelConfig, fn func(model *config.ModelConfig, provider llm.Provider) error) (*config.ModelConfig, error) {
+	var lastErr error
+	for _, candidate := range r.Chain(model) {
+		if err := ctx.Err(); err != nil {
+			return candidate, err
+		}
+
+		var callErr error
+		err := r.breaker(candidate).Execute(func() error {
+			provider, err := r.provider(candidate)
+			if err != nil {
+				callErr = err
+				return nil
+			}
+			callErr = retry.Do(ctx, r.retryCfg, func() error {
+				return fn(candidate, provider)
+			})
+			if IsProviderError(callErr) {
+				return callErr
+			}
+			return nil
+		})
+
+		switch {
+		case errors.Is(err, circuitbreaker.ErrCircuitOpen):
+			r.logger.Debug(i18n.T("model.circuit_open_skip"), logging.String("model", candidate.Name))
+			lastErr = fmt.Errorf("model %s: %w", candidate.Name, err)
+			continue
+		case callErr == nil:
+			if candidate.Name != model.Name {
+				r.logger.Info(i18n.T("model.fallback_used"),
+					logging.String("model", model.Name),
+					logging.String("fallback", candidate.Name))
+			}
+			return candidate, nil
+		case !IsProviderError(callErr):
+			return candidate, callErr
+		}
+
+		r.logger.Warn(i18n.T("model.provider_failed"),
+			logging.String("model", candidate.Name),
+			logging.String("base_url", candidate.BaseURL),
+			logging.Err(callErr))
+		lastErr = callErr
+	}
+	return model, lastErr
+}
+
+// IsProviderError 判断错误是否由模型服务不可用引起：网络错误、限流（429）、服务端错误（5xx）或熔断
+// 这类错误计入熔断器，并改用降级链中的下一个模型
+func IsProviderError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		return true
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests || apiErr.HTTPStatusCode >= 500
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests || reqErr.HTTPStatusCode >= 500
+	}
+	return retry.DefaultRetryable(err)
+}
+
+// Start 启动健康探测，定期探测降级链中模型的服务；ctx 取消或调用 Stop 后停止
+func (r *Router) Start(ctx context.Context) {
+	interval := r.cfg.GetProbeInterval()
+	if interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r.mu.Lock()
+	r.cancel = cancel
+	r.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			r.ProbeAll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止健康探测
+func (r *Router) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+// ProbeAll 探测降级链中模型的服务，每个服务只探测一次
+// 只有配置了 fallbacks 的模型及其降级模型需要熔断状态来选择模型，其余模型不探测
+// 探测结果计入熔断器：服务恢复后熔断器关闭，请求重新使用原模型
+func (r *Router) ProbeAll(ctx context.Context) {
+	probed := make(map[string]bool)
+	for _, model := range probeTargets() {
+		key := providerKey(&model)
+		if key == "" || probed[key] {
+			continue
+		}
+		probed[key] = true
+
+		model := model
+		breaker := r.breaker(&model)
+		before := breaker.State()
+		_ = breaker.Execute(func() error {
+			return r.probe(ctx, &model)
+		})
+		after := breaker.State()
+		if before == after {
+			continue
+		}
+		if after == circuitbreaker.StateOpen {
+			r.logger.Warn(i18n.T("model.provider_unhealthy"), logging.String("base_url", model.BaseURL))
+		} else if after == circuitbreaker.StateClosed {
+			r.logger.Info(i18n.T("model.provider_recovered"), logging.String("base_url", model.BaseURL))
+		}
+	}
+}
+
+// probeTargets 返回出现在某个降级链中的模型：配置了 fallbacks 的模型，以及被列为 fallback 的模型
+func probeTargets() []config.ModelConfig {
+	modelsMgr := config.GetModelsManager()
+	if modelsMgr == nil {
+		return nil
+	}
+
+	models := modelsMgr.ListModels()
+	inChain := make(map[string]bool)
+	for _, model := range models {
+		if len(model.Fallbacks) == 0 {
+			continue
+		}
+		inChain[model.Name] = true
+		for _, name := range model.Fallbacks {
+			inChain[name] = true
+		}
+	}
+
+	var targets []config.ModelConfig
+	for _, model := range models {
+		if inChain[model.Name] {
+			targets = append(targets, model)
+		}
+	}
+	return targets
+}
+
+// probe 请求模型服务的模型列表接口，只有网络错误和 5xx 视为不可用（鉴权失败也说明服务在线）
+func (r *Router) probe(ctx context.Context, model *config.ModelConfig) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, llm.ProbeURL(model), nil)
+	if err != nil {
+		return err
+	}
+	llm.SetAuthHeader(req, model)
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("probe %s: status code: %d", model.BaseURL, resp.StatusCode)
+	}
+	return nil
+}
+
+func (r *Router) breaker(model *config.ModelConfig) *circuitbreaker.CircuitBreaker {
+	key := providerKey(model)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cb, ok := r.breakers[key]; ok {
+		return cb
+	}
+	cb := circuitbreaker.New(key,
+		circuitbreaker.WithMaxFailures(r.cfg.GetMaxFailures()),
+		circuitbreaker.WithResetTimeout(r.cfg.GetResetTimeout()))
+	r.breakers[key] = cb
+	return cb
+}
+
+func (r *Router) provider(model *config.ModelConfig) (llm.Provider, error) {
+	key := model.Provider + "\x00" + providerKey(model) + "\x00" + model.APIKey
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if provider, ok := r.providers[key]; ok {
+		return provider, nil
+	}
+	provider, err := llm.NewProvider(model)
+	if err != nil {
+		return nil, err
+	}
+	r.providers[key] = provider
+	return provider, nil
+}
+
+// providerKey 模型服务的标识，同一地址的模型共享熔断器
+func providerKey(model *config.ModelConfig) string {
+	return llm.BaseURL(model)
+}
@@ -0,0 +1,202 @@
+package modelrouter
+
+import (
+	"context"
+	"encoding/json"
+	"mindx/internal/config"
+	"mindx/internal/infrastructure/llm"
+	"mindx/pkg/logging"
+	"mindx/pkg/retry"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider 模拟 OpenAI 兼容的模型服务，status 非 200 时所有接口返回该状态码
+type fakeProvider struct {
+	*httptest.Server
+	status atomic.Int32
+	calls  atomic.Int32
+	probes atomic.Int32
+}
+
+func newFakeProvider(t *testing.T, status int) *fakeProvider {
+	t.Helper()
+	p := &fakeProvider{}
+	p.status.Store(int32(status))
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			p.calls.Add(1)
+		case "/v1/models":
+			p.probes.Add(1)
+		}
+		if status := int(p.status.Load()); status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"error":{"message":"unavailable"}}`))
+			return
+		}
+		if r.URL.Path == "/v1/models" {
+			_, _ = w.Write([]byte(`{"data":[]}`))
+			return
+		}
+		var req openai.ChatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Model: req.Model,
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "ok"}},
+			},
+		})
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func useModels(t *testing.T, models ...config.ModelConfig) {
+	t.Helper()
+	previous := config.GetModelsManager()
+	config.OverrideModelsManager(config.NewModelsManager(&config.ModelsConfig{Models: models}, &config.GlobalConfig{DefaultModel: models[0].Name}))
+	t.Cleanup(func() { config.OverrideModelsManager(previous) })
+}
+
+func newTestRouter(cfg config.ModelHealthConfig) *Router {
+	return New(cfg, logging.GetSystemLogger().Named("model_router_test"), WithRetryConfig(retry.Config{
+		MaxRetries:  1,
+		InitialWait: time.Millisecond,
+		MaxWait:     time.Millisecond,
+		Retryable:   IsProviderError,
+	}))
+}
+
+func chat(ctx context.Context, r *Router, model *config.ModelConfig) (*config.ModelConfig, error) {
+	return r.Do(ctx, model, func(m *config.ModelConfig, provider llm.Provider) error {
+		_, err := provider.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model:    m.Name,
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+		})
+		return err
+	})
+}
+
+func TestRouter_FallbackOnServerError(t *testing.T) {
+	primary := newFakeProvider(t, http.StatusInternalServerError)
+	backup := newFakeProvider(t, http.StatusOK)
+	useModels(t,
+		config.ModelConfig{Name: "primary", BaseURL: primary.URL + "/v1", Fallbacks: []string{"missing", "backup"}},
+		config.ModelConfig{Name: "backup", BaseURL: backup.URL + "/v1"},
+	)
+	r := newTestRouter(config.ModelHealthConfig{MaxFailures: 2})
+	model := config.GetModelsManager().MustGetModel("primary")
+
+	used, err := chat(context.Background(), r, model)
+	require.NoError(t, err)
+	assert.Equal(t, "backup", used.Name)
+	assert.Equal(t, int32(2), primary.calls.Load(), "原模型应先按重试策略重试")
+	assert.True(t, r.Healthy(model), "失败次数未达阈值，不应熔断")
+
+	used, err = chat(context.Background(), r, model)
+	require.NoError(t, err)
+	assert.Equal(t, "backup", used.Name)
+	assert.False(t, r.Healthy(model), "连续失败后应熔断")
+
+	// 熔断期间不再请求原模型
+	primary.calls.Store(0)
+	used, err = chat(context.Background(), r, model)
+	require.NoError(t, err)
+	assert.Equal(t, "backup", used.Name)
+	assert.Zero(t, primary.calls.Load())
+
+	selected, provider, err := r.Select(model)
+	require.NoError(t, err)
+	assert.Equal(t, "backup", selected.Name)
+	assert.NotNil(t, provider)
+}
+
+func TestRouter_NoFallbackOnClientError(t *testing.T) {
+	primary := newFakeProvider(t, http.StatusBadRequest)
+	backup := newFakeProvider(t, http.StatusOK)
+	useModels(t,
+		config.ModelConfig{Name: "primary", BaseURL: primary.URL + "/v1", Fallbacks: []string{"backup"}},
+		config.ModelConfig{Name: "backup", BaseURL: backup.URL + "/v1"},
+	)
+	r := newTestRouter(config.ModelHealthConfig{MaxFailures: 1})
+	model := config.GetModelsManager().MustGetModel("primary")
+
+	used, err := chat(context.Background(), r, model)
+	require.Error(t, err)
+	assert.Equal(t, "primary", used.Name)
+	assert.Equal(t, int32(1), primary.calls.Load(), "请求参数错误不应重试")
+	assert.Zero(t, backup.calls.Load(), "请求参数错误不应降级")
+	assert.True(t, r.Healthy(model), "请求参数错误不应计入熔断")
+}
+
+func TestRouter_AllModelsUnavailable(t *testing.T) {
+	primary := newFakeProvider(t, http.StatusServiceUnavailable)
+	backup := newFakeProvider(t, http.StatusTooManyRequests)
+	useModels(t,
+		config.ModelConfig{Name: "primary", BaseURL: primary.URL + "/v1", Fallbacks: []string{"backup"}},
+		config.ModelConfig{Name: "backup", BaseURL: backup.URL + "/v1"},
+	)
+	r := newTestRouter(config.ModelHealthConfig{})
+	model := config.GetModelsManager().MustGetModel("primary")
+
+	used, err := chat(context.Background(), r, model)
+	require.Error(t, err)
+	assert.True(t, IsProviderError(err))
+	assert.Equal(t, "primary", used.Name)
+	assert.Equal(t, int32(2), backup.calls.Load(), "限流也应重试后再放弃")
+}
+
+func TestRouter_ProbeRecovery(t *testing.T) {
+	primary := newFakeProvider(t, http.StatusBadGateway)
+	backup := newFakeProvider(t, http.StatusOK)
+	useModels(t,
+		config.ModelConfig{Name: "primary", BaseURL: primary.URL + "/v1/", Fallbacks: []string{"backup"}},
+		config.ModelConfig{Name: "backup", BaseURL: backup.URL + "/v1/"},
+	)
+	r := newTestRouter(config.ModelHealthConfig{MaxFailures: 1, ResetTimeout: 1})
+	model := config.GetModelsManager().MustGetModel("primary")
+
+	r.ProbeAll(context.Background())
+	assert.False(t, r.Healthy(model), "探测失败后应熔断")
+
+	primary.status.Store(http.StatusOK)
+	r.ProbeAll(context.Background())
+	assert.False(t, r.Healthy(model), "熔断等待期间不应探测")
+
+	time.Sleep(1100 * time.Millisecond)
+	r.ProbeAll(context.Background())
+	assert.True(t, r.Healthy(model), "服务恢复后应关闭熔断")
+}
+
+func TestRouter_ProbeOnlyFallbackChains(t *testing.T) {
+	primary := newFakeProvider(t, http.StatusOK)
+	backup := newFakeProvider(t, http.StatusOK)
+	standalone := newFakeProvider(t, http.StatusOK)
+	useModels(t,
+		config.ModelConfig{Name: "primary", BaseURL: primary.URL + "/v1/", Fallbacks: []string{"backup"}},
+		config.ModelConfig{Name: "backup", BaseURL: backup.URL + "/v1/"},
+		config.ModelConfig{Name: "standalone", BaseURL: standalone.URL + "/v1/"},
+	)
+	r := newTestRouter(config.ModelHealthConfig{})
+
+	r.ProbeAll(context.Background())
+	assert.Equal(t, int32(1), primary.probes.Load())
+	assert.Equal(t, int32(1), backup.probes.Load(), "降级模型应被探测")
+	assert.Equal(t, int32(0), standalone.probes.Load(), "不在降级链中的模型不应被探测")
+}
+
+func TestIsProviderError(t *testing.T) {
+	assert.True(t, IsProviderError(&openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}))
+	assert.True(t, IsProviderError(&openai.RequestError{HTTPStatusCode: http.StatusBadGateway}))
+	assert.False(t, IsProviderError(&openai.APIError{HTTPStatusCode: http.StatusUnauthorized}))
+	assert.False(t, IsProviderError(context.Canceled))
+	assert.False(t, IsProviderError(nil))
+}
@@ -0,0 +1,83 @@
+package rerank
+
+import (
+	"context"
+	"mindx/internal/core"
+	"mindx/pkg/bm25"
+	"sort"
+	"strings"
+)
+
+// phraseBonus 文档包含完整查询文本时额外增加的相关度
+const phraseBonus = 0.2
+
+// HeuristicReranker 本地启发式重排器，无需调用模型
+// 将召回得分与查询词覆盖率加权混合：召回阶段的向量相似度擅长语义，
+// 覆盖率弥补向量对人名、数字等精确词不敏感的问题
+type HeuristicReranker struct {
+	// recallWeight 召回得分所占权重，其余为查询词覆盖率
+	recallWeight float64
+}
+
+// NewHeuristicReranker 创建启发式重排器
+func NewHeuristicReranker() *HeuristicReranker {
+	return &HeuristicReranker{recallWeight: 0.5}
+}
+
+func (r *HeuristicReranker) Name() string {
+	return "heuristic"
+}
+
+func (r *HeuristicReranker) Rerank(_ context.Context, query string, docs []core.RerankDocument) ([]core.RerankDocument, error) {
+	queryTerms := uniqueTerms(query)
+	queryLower := strings.ToLower(strings.TrimSpace(query))
+
+	results := make([]core.RerankDocument, len(docs))
+	for i, doc := range docs {
+		textScore := coverage(queryTerms, doc.Text)
+		if queryLower != "" && strings.Contains(strings.ToLower(doc.Text), queryLower) {
+			textScore += phraseBonus
+		}
+		doc.Score = r.recallWeight*clamp(doc.Score) + (1-r.recallWeight)*clamp(textScore)
+		results[i] = doc
+	}
+
+	// 稳定排序，得分相同时保持召回顺序
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results, nil
+}
+
+// coverage 文档包含的查询词占全部查询词的比例
+func coverage(queryTerms map[string]bool, text string) float64 {
+	if len(queryTerms) == 0 {
+		return 0
+	}
+	docTerms := uniqueTerms(text)
+	hits := 0
+	for term := range queryTerms {
+		if docTerms[term] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(queryTerms))
+}
+
+func uniqueTerms(text string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, token := range bm25.Tokenize(text) {
+		terms[token] = true
+	}
+	return terms
+}
+
+func clamp(score float64) float64 {
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
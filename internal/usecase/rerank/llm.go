@@ -0,0 +1,128 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mindx/internal/config"
+	"mindx/internal/core"
+	"mindx/internal/infrastructure/llm"
+	"sort"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+const (
+	// llmTimeout 单次重排请求的超时时间，超时后调用方沿用召回顺序
+	llmTimeout = 15 * time.Second
+	// maxDocRunes 每个候选文档提交给模型的最大字符数
+	maxDocRunes = 300
+)
+
+const llmRerankPrompt = `你是检索结果的相关度评估器。给定用户查询和若干编号的候选文档，
+为每个文档给出 0 到 1 之间的相关度：1 表示能直接回答或完成查询，0 表示完全无关。
+以 JSON 对象 {"scores": [...]} 返回，scores 按文档编号顺序排列，长度必须与文档数相同。`
+
+// rerankScoresSchema 重排结果 {"scores": [...]} 的 JSON Schema
+var rerankScoresSchema = &jsonschema.Definition{
+	Type: jsonschema.Object,
+	Properties: map[string]jsonschema.Definition{
+		"scores": {
+			Type:  jsonschema.Array,
+			Items: &jsonschema.Definition{Type: jsonschema.Number},
+		},
+	},
+	Required: []string{"scores"},
+}
+
+// LLMReranker 使用对话模型逐一评估候选文档与查询的相关度
+// 一次请求评估全部候选，效果接近交叉编码器，但会增加一次模型调用的延迟
+type LLMReranker struct {
+	provider llm.Provider
+	model    *config.ModelConfig
+}
+
+// NewLLMReranker 创建 LLM 重排器，provider 为模型对应的对话模型服务
+func NewLLMReranker(provider llm.Provider, model *config.ModelConfig) *LLMReranker {
+	return &LLMReranker{provider: provider, model: model}
+}
+
+func (r *LLMReranker) Name() string {
+	return "llm:" + r.model.Name
+}
+
+func (r *LLMReranker) Rerank(ctx context.Context, query string, docs []core.RerankDocument) ([]core.RerankDocument, error) {
+	if len(docs) == 0 {
+		return docs, nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "查询：%s\n\n候选文档：\n", query)
+	for i, doc := range docs {
+		fmt.Fprintf(&sb, "[%d] %s\n", i+1, truncateRunes(doc.Text, maxDocRunes))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, llmTimeout)
+	defer cancel()
+
+	resp, err := r.provider.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: r.model.Name,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: llmRerankPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: sb.String()},
+		},
+		Temperature:    0,
+		ResponseFormat: llm.JSONResponseFormat(r.model, "rerank_scores", rerankScoresSchema),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rerank request failed: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("rerank response has no choices")
+	}
+
+	scores, err := parseScores(resp.Choices[0].Message.Content)
+	if err != nil {
+		return nil, err
+	}
+	if len(scores) != len(docs) {
+		return nil, fmt.Errorf("rerank returned %d scores for %d documents", len(scores), len(docs))
+	}
+
+	results := make([]core.RerankDocument, len(docs))
+	for i, doc := range docs {
+		doc.Score = clamp(scores[i])
+		results[i] = doc
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results, nil
+}
+
+// parseScores 解析模型返回的 {"scores": [...]}，兼容被代码块包裹的输出
+func parseScores(content string) ([]float64, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid rerank response: %s", content)
+	}
+	var result struct {
+		Scores []float64 `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("invalid rerank response: %w", err)
+	}
+	return result.Scores, nil
+}
+
+func truncateRunes(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "..."
+}
@@ -0,0 +1,89 @@
+// Package rerank 对召回的技能和记忆按与查询的相关度重新排序
+//
+// 召回阶段只依据向量余弦相似度（记忆还融合了 BM25），重排阶段可以使用更精细但更慢的判断：
+// heuristic 在本地按查询词覆盖率调整顺序，llm 使用配置的对话模型评估每个候选的相关度。
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"mindx/internal/config"
+	"mindx/internal/core"
+	"mindx/internal/infrastructure/llm"
+	"mindx/pkg/i18n"
+	"mindx/pkg/logging"
+	"time"
+)
+
+// New 根据配置创建重排器，类型为 none 时返回 nil
+func New(cfg config.RerankConfig, modelsMgr *config.ModelsManager) (core.Reranker, error) {
+	switch cfg.GetType() {
+	case "none":
+		return nil, nil
+	case "heuristic":
+		return NewHeuristicReranker(), nil
+	case "llm":
+		modelName := cfg.Model
+		if modelName == "" {
+			modelName = modelsMgr.GetDefaultModel()
+		}
+		model, err := modelsMgr.GetModel(modelName)
+		if err != nil {
+			return nil, err
+		}
+		provider, err := llm.NewProvider(model)
+		if err != nil {
+			return nil, err
+		}
+		return NewLLMReranker(provider, model), nil
+	default:
+		return nil, fmt.Errorf("unknown rerank type: %s", cfg.Type)
+	}
+}
+
+// Apply 使用 reranker 重排候选文档，记录耗时和排序变化
+// reranker 为 nil、候选少于两个或重排失败时返回原顺序
+func Apply(ctx context.Context, reranker core.Reranker, query string, docs []core.RerankDocument, logger logging.Logger) []core.RerankDocument {
+	if reranker == nil || len(docs) < 2 {
+		return docs
+	}
+
+	start := time.Now()
+	results, err := reranker.Rerank(ctx, query, docs)
+	elapsed := time.Since(start)
+	if err != nil {
+		logger.Warn(i18n.T("rerank.failed"),
+			logging.String("reranker", reranker.Name()),
+			logging.Duration("elapsed", elapsed),
+			logging.Err(err))
+		return docs
+	}
+
+	originalRank := make(map[string]int, len(docs))
+	originalScore := make(map[string]float64, len(docs))
+	for i, doc := range docs {
+		originalRank[doc.ID] = i
+		originalScore[doc.ID] = doc.Score
+	}
+	moved := 0
+	for i, doc := range results {
+		if originalRank[doc.ID] != i {
+			moved++
+		}
+		logger.Debug(i18n.T("rerank.score_changed"),
+			logging.String("id", doc.ID),
+			logging.Int("from_rank", originalRank[doc.ID]+1),
+			logging.Int("to_rank", i+1),
+			logging.Float64("from_score", originalScore[doc.ID]),
+			logging.Float64("to_score", doc.Score))
+	}
+
+	logger.Info(i18n.T("rerank.applied"),
+		logging.String("reranker", reranker.Name()),
+		logging.Int("candidates", len(docs)),
+		logging.Int("moved", moved),
+		logging.String("top_before", docs[0].ID),
+		logging.String("top_after", results[0].ID),
+		logging.Duration("elapsed", elapsed))
+	return results
+}
@@ -0,0 +1,144 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mindx/internal/config"
+	"mindx/internal/core"
+	"mindx/internal/infrastructure/llm"
+	"mindx/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger() logging.Logger {
+	return logging.GetSystemLogger().Named("rerank_test")
+}
+
+func ids(docs []core.RerankDocument) []string {
+	result := make([]string, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.ID
+	}
+	return result
+}
+
+func TestHeuristicReranker(t *testing.T) {
+	docs := []core.RerankDocument{
+		{ID: "weather", Text: "查询天气预报", Score: 0.62},
+		{ID: "phone", Text: "用户的手机号是 13800138000", Score: 0.58},
+		{ID: "coffee", Text: "用户喜欢喝咖啡", Score: 0.55},
+	}
+
+	results, err := NewHeuristicReranker().Rerank(context.Background(), "13800138000 是谁的手机号", docs)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "phone", results[0].ID, "包含精确查询词的文档排到最前")
+	for _, doc := range results {
+		assert.GreaterOrEqual(t, doc.Score, 0.0)
+		assert.LessOrEqual(t, doc.Score, 1.0)
+	}
+
+	// 查询词都不命中时保持召回顺序
+	results, err = NewHeuristicReranker().Rerank(context.Background(), "basketball", docs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"weather", "phone", "coffee"}, ids(results))
+}
+
+func newTestLLMServer(t *testing.T, content string) *httptest.Server {
+	return newFormatCheckingLLMServer(t, content, openai.ChatCompletionResponseFormatTypeJSONObject)
+}
+
+// newFormatCheckingLLMServer 模拟模型服务，并检查请求的 response_format 类型
+func newFormatCheckingLLMServer(t *testing.T, content string, format openai.ChatCompletionResponseFormatType) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "rerank-model", req.Model)
+		if assert.NotNil(t, req.ResponseFormat) {
+			assert.Equal(t, format, req.ResponseFormat.Type)
+		}
+		assert.True(t, strings.Contains(req.Messages[1].Content, "[2] 用户住在上海"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content}}},
+		})
+	}))
+}
+
+func newTestLLMReranker(t *testing.T, url string) *LLMReranker {
+	return newTestLLMRerankerWithModel(t, &config.ModelConfig{Name: "rerank-model", BaseURL: url, APIKey: "test"})
+}
+
+func newTestLLMRerankerWithModel(t *testing.T, model *config.ModelConfig) *LLMReranker {
+	t.Helper()
+	provider, err := llm.NewProvider(model)
+	require.NoError(t, err)
+	return NewLLMReranker(provider, model)
+}
+
+func TestLLMReranker(t *testing.T) {
+	docs := []core.RerankDocument{
+		{ID: "a", Text: "用户喜欢喝咖啡", Score: 0.9},
+		{ID: "b", Text: "用户住在上海", Score: 0.8},
+	}
+
+	ts := newTestLLMServer(t, "```json\n{\"scores\": [0.1, 0.95]}\n```")
+	defer ts.Close()
+
+	results, err := newTestLLMReranker(t, ts.URL).Rerank(context.Background(), "用户住在哪里", docs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "a"}, ids(results))
+	assert.InDelta(t, 0.95, results[0].Score, 1e-9)
+
+	// 得分数量与文档不一致时返回错误
+	bad := newTestLLMServer(t, `{"scores": [0.5]}`)
+	defer bad.Close()
+	_, err = newTestLLMReranker(t, bad.URL).Rerank(context.Background(), "用户住在哪里", docs)
+	assert.Error(t, err)
+}
+
+func TestLLMReranker_StructuredOutput(t *testing.T) {
+	docs := []core.RerankDocument{
+		{ID: "a", Text: "用户喜欢喝咖啡", Score: 0.9},
+		{ID: "b", Text: "用户住在上海", Score: 0.8},
+	}
+
+	// 声明支持 json_schema 的服务以 JSON Schema 约束输出，其余只要求输出 JSON 对象
+	ts := newFormatCheckingLLMServer(t, `{"scores": [0.1, 0.95]}`, openai.ChatCompletionResponseFormatTypeJSONSchema)
+	defer ts.Close()
+
+	reranker := newTestLLMRerankerWithModel(t, &config.ModelConfig{Name: "rerank-model", BaseURL: ts.URL, StructuredOutput: true})
+	results, err := reranker.Rerank(context.Background(), "用户住在哪里", docs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "a"}, ids(results))
+}
+
+type failingReranker struct{}
+
+func (failingReranker) Name() string { return "failing" }
+
+func (failingReranker) Rerank(context.Context, string, []core.RerankDocument) ([]core.RerankDocument, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestApply(t *testing.T) {
+	docs := []core.RerankDocument{
+		{ID: "a", Text: "apple pie", Score: 0.7},
+		{ID: "b", Text: "banana bread", Score: 0.6},
+	}
+
+	assert.Equal(t, docs, Apply(context.Background(), nil, "banana", docs, testLogger()))
+	assert.Equal(t, docs, Apply(context.Background(), failingReranker{}, "banana", docs, testLogger()), "重排失败时沿用召回顺序")
+
+	results := Apply(context.Background(), NewHeuristicReranker(), "banana bread", docs, testLogger())
+	assert.Equal(t, []string{"b", "a"}, ids(results))
+}
@@ -13,17 +13,20 @@ import (
 	"time"
 )
 
+// SessionMgr 会话管理器
+// 会话按 entity.SessionKey（Channel + 发送者/会话标识）隔离，
+// 每个键拥有独立的活跃会话、历史、Token 预算和会话结束时的记忆提取
 type SessionMgr struct {
-	sessions       map[string]*entity.Session
-	mutex          sync.RWMutex
-	currentSession *entity.Session
-	maxTokens      int
-	checkPoints    map[string]bool
-	storage        SessionStorage
-	onSessionEnd   OnSessionEndFunc
-	logger         logging.Logger
-	cleaner        *HistoryCleaner
-	splitter       *MessageSplitter
+	sessions     map[string]*entity.Session            // 按会话 ID 索引的全部会话
+	active       map[entity.SessionKey]*entity.Session // 每个隔离键当前活跃的会话
+	mutex        sync.RWMutex
+	maxTokens    int
+	checkPoints  map[string]bool
+	storage      SessionStorage
+	onSessionEnd OnSessionEndFunc
+	logger       logging.Logger
+	cleaner      *HistoryCleaner
+	splitter     *MessageSplitter
 }
 
 type OnSessionEndFunc func(session entity.Session) bool
@@ -117,6 +120,7 @@ func NewSessionMgr(
 
 	return &SessionMgr{
 		sessions:     make(map[string]*entity.Session),
+		active:       make(map[entity.SessionKey]*entity.Session),
 		maxTokens:    maxTokens,
 		checkPoints:  make(map[string]bool),
 		storage:      storage,
@@ -127,6 +131,8 @@ func NewSessionMgr(
 	}
 }
 
+// RestoreSession 从存储中恢复所有会话
+// 每个隔离键最近创建且未结束的会话恢复为该键的活跃会话，默认键始终保证存在活跃会话
 func (sm *SessionMgr) RestoreSession() error {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
@@ -136,162 +142,204 @@ func (sm *SessionMgr) RestoreSession() error {
 		return fmt.Errorf("failed to load sessions: %w", err)
 	}
 
-	var lastActive *entity.Session
 	for _, session := range allSessions {
 		sessionCopy := session
 		sm.sessions[session.ID] = &sessionCopy
-		if !session.IsEnded {
-			if lastActive == nil || session.CreatedAt.After(lastActive.CreatedAt) {
-				lastActive = &sessionCopy
-			}
+		if session.IsEnded {
+			continue
+		}
+		key := sessionCopy.Key()
+		if last, ok := sm.active[key]; !ok || session.CreatedAt.After(last.CreatedAt) {
+			sm.active[key] = &sessionCopy
 		}
 	}
 
-	if lastActive != nil {
-		sm.currentSession = lastActive
+	for key, session := range sm.active {
 		sm.logger.Info(i18n.T("session.restore_session"),
-			logging.String(i18n.T("session.session_id"), lastActive.ID),
-			logging.Int(i18n.T("session.messages"), len(lastActive.Messages)),
-			logging.Int(i18n.T("session.tokens"), lastActive.TokensUsed),
+			logging.String(i18n.T("session.session_key"), key.String()),
+			logging.String(i18n.T("session.session_id"), session.ID),
+			logging.Int(i18n.T("session.messages"), len(session.Messages)),
+			logging.Int(i18n.T("session.tokens"), session.TokensUsed),
 		)
-	} else {
-		return sm.startNewSessionLocked()
+	}
+
+	if _, ok := sm.active[entity.DefaultSessionKey]; !ok {
+		sm.startNewSessionLocked(entity.DefaultSessionKey)
 	}
 
 	return nil
 }
 
-func (sm *SessionMgr) RecordMessage(msg entity.Message) error {
+// RecordMessage 记录消息到指定会话，Token 达到上限时结束该会话并提取记忆
+func (sm *SessionMgr) RecordMessage(key entity.SessionKey, msg entity.Message) error {
 	sm.mutex.Lock()
-	defer sm.mutex.Unlock()
-
-	if sm.currentSession == nil {
-		return sm.startNewSessionLocked()
-	}
 
+	session := sm.activeSessionLocked(key)
 	messages := sm.splitter.Split(msg)
 
 	for _, m := range messages {
-		sm.currentSession.Messages = append(sm.currentSession.Messages, m)
+		session.Messages = append(session.Messages, m)
 
 		msgTokens := sm.calculateTokens(m.Content)
-		sm.currentSession.TokensUsed += msgTokens
+		session.TokensUsed += msgTokens
 
 		sm.logger.Debug(i18n.T("session.record_message"),
+			logging.String(i18n.T("session.session_key"), key.String()),
 			logging.String(i18n.T("session.role"), m.Role),
 			logging.Int(i18n.T("session.tokens"), msgTokens),
-			logging.Int(i18n.T("session.total_tokens"), sm.currentSession.TokensUsed),
+			logging.Int(i18n.T("session.total_tokens"), session.TokensUsed),
 			logging.Int(i18n.T("session.max_tokens"), sm.maxTokens),
 			logging.Int("content_length", len(m.Content)),
 		)
 	}
 
-	if err := sm.storage.Save(*sm.currentSession); err != nil {
+	if err := sm.storage.Save(*session); err != nil {
 		sm.logger.Error(i18n.T("session.persist_failed"),
 			logging.Err(err),
-			logging.String(i18n.T("session.session_id"), sm.currentSession.ID),
+			logging.String(i18n.T("session.session_id"), session.ID),
 		)
 	}
 
-	if sm.currentSession.TokensUsed >= sm.maxTokens {
-		return sm.endSessionLocked()
+	var ended *entity.Session
+	if session.TokensUsed >= sm.maxTokens {
+		ended = sm.endSessionLocked(key)
+	}
+	sm.mutex.Unlock()
+
+	// 记忆提取可能耗时较长，在锁外执行，避免阻塞其它会话
+	if ended != nil {
+		sm.extractMemory(*ended)
 	}
 
 	return nil
 }
 
-func (sm *SessionMgr) UpdateTokensFromModel(usage core.TokenUsage) {
+// UpdateTokensFromModel 将模型返回的 Token 消耗累加到指定会话
+func (sm *SessionMgr) UpdateTokensFromModel(key entity.SessionKey, usage core.TokenUsage) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	if sm.currentSession != nil {
-		sm.currentSession.TokensUsed += usage.TotalTokens
-		sm.logger.Debug(i18n.T("session.update_tokens"),
-			logging.Int(i18n.T("session.tokens"), usage.TotalTokens),
-			logging.Int(i18n.T("session.total_tokens"), sm.currentSession.TokensUsed),
-		)
+	session, ok := sm.active[key]
+	if !ok {
+		return
+	}
 
-		if err := sm.storage.Save(*sm.currentSession); err != nil {
-			sm.logger.Error(i18n.T("session.persist_failed"),
-				logging.Err(err),
-				logging.String(i18n.T("session.session_id"), sm.currentSession.ID),
-			)
-		}
+	session.TokensUsed += usage.TotalTokens
+	sm.logger.Debug(i18n.T("session.update_tokens"),
+		logging.String(i18n.T("session.session_key"), key.String()),
+		logging.Int(i18n.T("session.tokens"), usage.TotalTokens),
+		logging.Int(i18n.T("session.total_tokens"), session.TokensUsed),
+	)
+
+	if err := sm.storage.Save(*session); err != nil {
+		sm.logger.Error(i18n.T("session.persist_failed"),
+			logging.Err(err),
+			logging.String(i18n.T("session.session_id"), session.ID),
+		)
 	}
 }
 
-func (sm *SessionMgr) GetHistory() []entity.Message {
+// GetHistory 获取指定会话的对话历史（已去重清洗）
+func (sm *SessionMgr) GetHistory(key entity.SessionKey) []entity.Message {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
-	if sm.currentSession == nil {
+	session, ok := sm.active[key]
+	if !ok {
 		return []entity.Message{}
 	}
 
-	return sm.cleaner.Clean(sm.currentSession.Messages)
+	return sm.cleaner.Clean(session.Messages)
 }
 
-func (sm *SessionMgr) GetCurrentSession() (*entity.Session, bool) {
+// GetCurrentSession 获取指定隔离键当前活跃的会话
+func (sm *SessionMgr) GetCurrentSession(key entity.SessionKey) (*entity.Session, bool) {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
-	if sm.currentSession == nil {
+	session, ok := sm.active[key]
+	if !ok {
 		return nil, false
 	}
-	return sm.currentSession, true
+	return session, true
 }
 
 func (sm *SessionMgr) GetAllSessions() ([]entity.Session, error) {
 	return sm.storage.LoadAll()
 }
 
-func (sm *SessionMgr) endSessionLocked() error {
-	if sm.currentSession == nil {
+// activeSessionLocked 获取隔离键的活跃会话，不存在时创建
+func (sm *SessionMgr) activeSessionLocked(key entity.SessionKey) *entity.Session {
+	if session, ok := sm.active[key]; ok {
+		return session
+	}
+	return sm.startNewSessionLocked(key)
+}
+
+// endSessionLocked 结束隔离键的活跃会话并开启新会话，返回已结束会话的副本
+func (sm *SessionMgr) endSessionLocked(key entity.SessionKey) *entity.Session {
+	session, ok := sm.active[key]
+	if !ok {
 		return nil
 	}
 
 	sm.logger.Info(i18n.T("session.session_end_limit"),
-		logging.String(i18n.T("session.session_id"), sm.currentSession.ID),
-		logging.Int(i18n.T("session.messages"), len(sm.currentSession.Messages)),
-		logging.Int(i18n.T("session.tokens"), sm.currentSession.TokensUsed),
+		logging.String(i18n.T("session.session_key"), key.String()),
+		logging.String(i18n.T("session.session_id"), session.ID),
+		logging.Int(i18n.T("session.messages"), len(session.Messages)),
+		logging.Int(i18n.T("session.tokens"), session.TokensUsed),
 	)
 
-	sm.currentSession.IsEnded = true
-	sm.currentSession.EndedAt = time.Now()
+	session.IsEnded = true
+	session.EndedAt = time.Now()
 
-	var success bool
-	if sm.onSessionEnd != nil {
-		success = sm.onSessionEnd(*sm.currentSession)
+	if err := sm.storage.Save(*session); err != nil {
+		sm.logger.Error(i18n.T("session.persist_failed"),
+			logging.Err(err),
+			logging.String(i18n.T("session.session_id"), session.ID),
+		)
+	}
+
+	ended := *session
+	ended.Messages = append([]entity.Message(nil), session.Messages...)
+
+	sm.startNewSessionLocked(key)
+
+	return &ended
+}
+
+// extractMemory 对已结束的会话执行记忆提取，成功后记录 CheckPoint
+func (sm *SessionMgr) extractMemory(session entity.Session) {
+	sm.mutex.RLock()
+	onSessionEnd := sm.onSessionEnd
+	sm.mutex.RUnlock()
+
+	success := true
+	if onSessionEnd != nil {
+		success = onSessionEnd(session)
 		if success {
 			sm.logger.Info(i18n.T("session.memory_extract_success"),
-				logging.String(i18n.T("session.session_id"), sm.currentSession.ID),
+				logging.String(i18n.T("session.session_id"), session.ID),
 			)
 		} else {
 			sm.logger.Warn(i18n.T("session.memory_extract_failed"),
-				logging.String(i18n.T("session.session_id"), sm.currentSession.ID),
+				logging.String(i18n.T("session.session_id"), session.ID),
 			)
 		}
-	} else {
-		success = true
 	}
 
 	if success {
-		sm.checkPoints[sm.currentSession.ID] = true
-	}
-
-	if err := sm.storage.Save(*sm.currentSession); err != nil {
-		sm.logger.Error(i18n.T("session.persist_failed"),
-			logging.Err(err),
-			logging.String(i18n.T("session.session_id"), sm.currentSession.ID),
-		)
+		sm.mutex.Lock()
+		sm.checkPoints[session.ID] = true
+		sm.mutex.Unlock()
 	}
-
-	return sm.startNewSessionLocked()
 }
 
-func (sm *SessionMgr) startNewSessionLocked() error {
+func (sm *SessionMgr) startNewSessionLocked(key entity.SessionKey) *entity.Session {
 	newSession := &entity.Session{
 		ID:         sm.generateSessionID(),
+		ChannelID:  key.ChannelID,
+		SenderID:   key.SenderID,
 		Messages:   []entity.Message{},
 		TokensUsed: 0,
 		IsEnded:    false,
@@ -299,9 +347,10 @@ func (sm *SessionMgr) startNewSessionLocked() error {
 	}
 
 	sm.sessions[newSession.ID] = newSession
-	sm.currentSession = newSession
+	sm.active[key] = newSession
 
 	sm.logger.Info(i18n.T("session.start_new_session"),
+		logging.String(i18n.T("session.session_key"), key.String()),
 		logging.String(i18n.T("session.session_id"), newSession.ID),
 		logging.Int(i18n.T("session.max_tokens"), sm.maxTokens),
 	)
@@ -313,7 +362,7 @@ func (sm *SessionMgr) startNewSessionLocked() error {
 		)
 	}
 
-	return nil
+	return newSession
 }
 
 func (sm *SessionMgr) RecordCheckPoint(sessionID string) {
@@ -345,14 +394,22 @@ func (sm *SessionMgr) calculateTokens(content string) int {
 	return calculateTokens(content)
 }
 
+// activeKeyLocked 查找会话 ID 当前作为哪个隔离键的活跃会话
+func (sm *SessionMgr) activeKeyLocked(id string) (entity.SessionKey, bool) {
+	for key, session := range sm.active {
+		if session.ID == id {
+			return key, true
+		}
+	}
+	return entity.SessionKey{}, false
+}
+
 func (sm *SessionMgr) DeleteSession(id string) error {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	if sm.currentSession != nil && sm.currentSession.ID == id {
-		if err := sm.startNewSessionLocked(); err != nil {
-			return err
-		}
+	if key, ok := sm.activeKeyLocked(id); ok {
+		sm.startNewSessionLocked(key)
 	}
 
 	delete(sm.sessions, id)
@@ -373,10 +430,23 @@ func (sm *SessionMgr) DeleteSession(id string) error {
 	return nil
 }
 
-func (sm *SessionMgr) SwitchSession(id string) (*entity.Session, error) {
+// SwitchSession 将历史会话切换为隔离键的活跃会话
+// 若该会话正作为其它隔离键的活跃会话，则拒绝切换，避免两个发送者共享同一段历史
+func (sm *SessionMgr) SwitchSession(key entity.SessionKey, id string) (*entity.Session, error) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
+	if owner, ok := sm.activeKeyLocked(id); ok {
+		if owner == key {
+			return sm.active[key], nil
+		}
+		sm.logger.Warn(i18n.T("session.switch_conflict"),
+			logging.String(i18n.T("session.session_id"), id),
+			logging.String(i18n.T("session.session_key"), owner.String()),
+		)
+		return nil, fmt.Errorf("failed to switch session: session %s is active under %s", id, owner.String())
+	}
+
 	session, err := sm.storage.Load(id)
 	if err != nil {
 		sm.logger.Error(i18n.T("session.switch_failed"),
@@ -387,9 +457,11 @@ func (sm *SessionMgr) SwitchSession(id string) (*entity.Session, error) {
 	}
 
 	session.IsEnded = false
+	session.ChannelID = key.ChannelID
+	session.SenderID = key.SenderID
 
 	sm.sessions[session.ID] = session
-	sm.currentSession = session
+	sm.active[key] = session
 
 	if err := sm.storage.Save(*session); err != nil {
 		sm.logger.Error(i18n.T("session.persist_failed"),
@@ -399,6 +471,7 @@ func (sm *SessionMgr) SwitchSession(id string) (*entity.Session, error) {
 	}
 
 	sm.logger.Info(i18n.T("session.switch_success"),
+		logging.String(i18n.T("session.session_key"), key.String()),
 		logging.String(i18n.T("session.session_id"), session.ID),
 		logging.Int(i18n.T("session.messages"), len(session.Messages)),
 	)
@@ -406,24 +479,21 @@ func (sm *SessionMgr) SwitchSession(id string) (*entity.Session, error) {
 	return session, nil
 }
 
-func (sm *SessionMgr) CreateNewSession() (*entity.Session, error) {
+// CreateNewSession 结束隔离键的当前会话并开启新会话
+func (sm *SessionMgr) CreateNewSession(key entity.SessionKey) (*entity.Session, error) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	if sm.currentSession != nil && len(sm.currentSession.Messages) > 0 {
-		sm.currentSession.IsEnded = true
-		sm.currentSession.EndedAt = time.Now()
-		if err := sm.storage.Save(*sm.currentSession); err != nil {
+	if current, ok := sm.active[key]; ok && len(current.Messages) > 0 {
+		current.IsEnded = true
+		current.EndedAt = time.Now()
+		if err := sm.storage.Save(*current); err != nil {
 			sm.logger.Error(i18n.T("session.persist_failed"),
 				logging.Err(err),
-				logging.String(i18n.T("session.session_id"), sm.currentSession.ID),
+				logging.String(i18n.T("session.session_id"), current.ID),
 			)
 		}
 	}
 
-	if err := sm.startNewSessionLocked(); err != nil {
-		return nil, err
-	}
-
-	return sm.currentSession, nil
+	return sm.startNewSessionLocked(key), nil
 }
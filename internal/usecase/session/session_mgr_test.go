@@ -42,7 +42,7 @@ func TestSessionMgr_RestoreSession(t *testing.T) {
 	}
 
 	// 验证创建了当前会话
-	current, ok := sm.GetCurrentSession()
+	current, ok := sm.GetCurrentSession(entity.DefaultSessionKey)
 	if !ok || current == nil {
 		t.Fatal("应该创建当前会话")
 	}
@@ -66,13 +66,13 @@ func TestSessionMgr_RecordMessage(t *testing.T) {
 		Time:    time.Now(),
 	}
 
-	err := sm.RecordMessage(msg1)
+	err := sm.RecordMessage(entity.DefaultSessionKey, msg1)
 	if err != nil {
 		t.Fatalf("RecordMessage 失败: %v", err)
 	}
 
 	// 验证消息已添加
-	current, _ := sm.GetCurrentSession()
+	current, _ := sm.GetCurrentSession(entity.DefaultSessionKey)
 	if len(current.Messages) != 1 {
 		t.Errorf("期望 1 条消息, 实际=%d", len(current.Messages))
 	}
@@ -88,8 +88,8 @@ func TestSessionMgr_RecordMessage(t *testing.T) {
 		Time:    time.Now(),
 	}
 
-	_ = sm.RecordMessage(msg2)
-	current, _ = sm.GetCurrentSession()
+	_ = sm.RecordMessage(entity.DefaultSessionKey, msg2)
+	current, _ = sm.GetCurrentSession(entity.DefaultSessionKey)
 
 	if len(current.Messages) != 2 {
 		t.Errorf("期望 2 条消息, 实际=%d", len(current.Messages))
@@ -126,7 +126,7 @@ func TestSessionMgr_SessionEnding(t *testing.T) {
 			Time:    time.Now(),
 		}
 
-		_ = sm.RecordMessage(msg)
+		_ = sm.RecordMessage(entity.DefaultSessionKey, msg)
 	}
 
 	// 验证会话已结束
@@ -143,7 +143,7 @@ func TestSessionMgr_SessionEnding(t *testing.T) {
 	}
 
 	// 验证开启了新会话
-	current, _ := sm.GetCurrentSession()
+	current, _ := sm.GetCurrentSession(entity.DefaultSessionKey)
 	if current.ID == endedSession.ID {
 		t.Error("应该创建新会话")
 	}
@@ -162,7 +162,7 @@ func TestSessionMgr_GetHistory(t *testing.T) {
 	_ = sm.RestoreSession()
 
 	// 初始应该为空
-	history := sm.GetHistory()
+	history := sm.GetHistory(entity.DefaultSessionKey)
 	if len(history) != 0 {
 		t.Errorf("初始历史应该为空, 实际长度=%d", len(history))
 	}
@@ -173,10 +173,10 @@ func TestSessionMgr_GetHistory(t *testing.T) {
 		Content: "测试消息",
 		Time:    time.Now(),
 	}
-	_ = sm.RecordMessage(msg)
+	_ = sm.RecordMessage(entity.DefaultSessionKey, msg)
 
 	// 验证历史
-	history = sm.GetHistory()
+	history = sm.GetHistory(entity.DefaultSessionKey)
 	if len(history) != 1 {
 		t.Errorf("期望 1 条历史记录, 实际=%d", len(history))
 	}
@@ -186,6 +186,106 @@ func TestSessionMgr_GetHistory(t *testing.T) {
 	}
 }
 
+func TestSessionMgr_IsolationBySessionKey(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := NewFileSessionStorage(tempDir)
+	logger := logging.GetSystemLogger().Named("test")
+
+	sm := NewSessionMgr(1000, storage, logger)
+	_ = sm.RestoreSession()
+
+	telegramKey := entity.NewSessionKey("telegram", "10001")
+	feishuKey := entity.NewSessionKey("feishu", "oc_group")
+
+	_ = sm.RecordMessage(telegramKey, entity.Message{Role: "user", Content: "我是 Telegram 用户", Time: time.Now()})
+	_ = sm.RecordMessage(feishuKey, entity.Message{Role: "user", Content: "我是飞书群", Time: time.Now()})
+	_ = sm.RecordMessage(feishuKey, entity.Message{Role: "assistant", Content: "你好飞书群", Time: time.Now()})
+
+	// 各会话历史互不可见
+	telegramHistory := sm.GetHistory(telegramKey)
+	if len(telegramHistory) != 1 || telegramHistory[0].Content != "我是 Telegram 用户" {
+		t.Errorf("Telegram 会话历史不正确: %+v", telegramHistory)
+	}
+
+	feishuHistory := sm.GetHistory(feishuKey)
+	if len(feishuHistory) != 2 {
+		t.Errorf("期望飞书会话 2 条历史, 实际=%d", len(feishuHistory))
+	}
+
+	if len(sm.GetHistory(entity.DefaultSessionKey)) != 0 {
+		t.Error("默认会话不应包含其它 Channel 的消息")
+	}
+
+	// Token 预算独立累计
+	sm.UpdateTokensFromModel(telegramKey, core.TokenUsage{TotalTokens: 500})
+	telegramSession, _ := sm.GetCurrentSession(telegramKey)
+	feishuSession, _ := sm.GetCurrentSession(feishuKey)
+	if feishuSession.TokensUsed >= telegramSession.TokensUsed {
+		t.Errorf("Token 统计应独立, telegram=%d feishu=%d", telegramSession.TokensUsed, feishuSession.TokensUsed)
+	}
+
+	// 会话记录所属 Channel 与发送者
+	if telegramSession.ChannelID != "telegram" || telegramSession.SenderID != "10001" {
+		t.Errorf("会话归属不正确: channel=%s sender=%s", telegramSession.ChannelID, telegramSession.SenderID)
+	}
+
+	// 重启后按隔离键恢复各自的活跃会话
+	sm2 := NewSessionMgr(1000, storage, logger)
+	if err := sm2.RestoreSession(); err != nil {
+		t.Fatalf("RestoreSession 失败: %v", err)
+	}
+
+	restored, ok := sm2.GetCurrentSession(feishuKey)
+	if !ok || restored.ID != feishuSession.ID {
+		t.Error("飞书会话应在重启后恢复")
+	}
+
+	// 活跃会话不能被其它隔离键切换占用
+	if _, err := sm2.SwitchSession(telegramKey, feishuSession.ID); err == nil {
+		t.Error("切换到其它隔离键的活跃会话应该失败")
+	}
+}
+
+func TestSessionMgr_SessionEndingPerKey(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := NewFileSessionStorage(tempDir)
+	logger := logging.GetSystemLogger().Named("test")
+
+	sm := NewSessionMgr(10, storage, logger)
+	_ = sm.RestoreSession()
+
+	var endedSessions []entity.Session
+	SetOnSessionEnd(sm, func(sess entity.Session) bool {
+		endedSessions = append(endedSessions, sess)
+		return true
+	})
+
+	busyKey := entity.NewSessionKey("telegram", "busy")
+	quietKey := entity.NewSessionKey("telegram", "quiet")
+
+	_ = sm.RecordMessage(quietKey, entity.Message{Role: "user", Content: "你好", Time: time.Now()})
+	quietSession, _ := sm.GetCurrentSession(quietKey)
+
+	for _, content := range []string{"你好", "今天天气怎么样", "明天呢"} {
+		_ = sm.RecordMessage(busyKey, entity.Message{Role: "user", Content: content, Time: time.Now()})
+	}
+
+	if len(endedSessions) == 0 {
+		t.Fatal("busy 会话应该已经结束")
+	}
+
+	for _, sess := range endedSessions {
+		if sess.Key() != busyKey {
+			t.Errorf("只有 busy 会话应该结束, 实际结束=%s", sess.Key().String())
+		}
+	}
+
+	current, _ := sm.GetCurrentSession(quietKey)
+	if current.ID != quietSession.ID {
+		t.Error("quiet 会话不应受其它会话 Token 上限影响")
+	}
+}
+
 func TestSessionMgr_UpdateTokensFromModel(t *testing.T) {
 	tempDir := t.TempDir()
 	storage := NewFileSessionStorage(tempDir)
@@ -198,14 +298,14 @@ func TestSessionMgr_UpdateTokensFromModel(t *testing.T) {
 	// 这样可以避免 RecordMessage 中的 token 计算影响测试
 
 	// 从模型更新 Token
-	sm.UpdateTokensFromModel(core.TokenUsage{
+	sm.UpdateTokensFromModel(entity.DefaultSessionKey, core.TokenUsage{
 		PromptTokens:     50,
 		CompletionTokens: 30,
 		TotalTokens:      80,
 	})
 
 	// 验证 Token 已累积
-	current, _ := sm.GetCurrentSession()
+	current, _ := sm.GetCurrentSession(entity.DefaultSessionKey)
 	if current.TokensUsed != 80 {
 		t.Errorf("期望 TokensUsed=80, 实际=%d", current.TokensUsed)
 	}
@@ -271,10 +371,10 @@ func TestSessionMgr_SessionPersistence(t *testing.T) {
 		Content: "测试持久化",
 		Time:    time.Now(),
 	}
-	_ = sm.RecordMessage(msg)
+	_ = sm.RecordMessage(entity.DefaultSessionKey, msg)
 
 	// 获取当前会话 ID
-	sess1, _ := sm.GetCurrentSession()
+	sess1, _ := sm.GetCurrentSession(entity.DefaultSessionKey)
 	sessionID := sess1.ID
 
 	// 手动保存会话到存储（模拟会话结束后）
@@ -288,7 +388,7 @@ func TestSessionMgr_SessionPersistence(t *testing.T) {
 	}
 
 	// 验证会话已恢复
-	sess2, _ := sm2.GetCurrentSession()
+	sess2, _ := sm2.GetCurrentSession(entity.DefaultSessionKey)
 	if sess2.ID != sessionID {
 		t.Errorf("会话 ID 应该一致, 期望=%s, 实际=%s", sessionID, sess2.ID)
 	}
@@ -1,8 +1,10 @@
 package builtins
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"mindx/internal/entity"
 	"mindx/internal/usecase/cron"
 )
 
@@ -14,7 +16,7 @@ func NewCronSkillProvider(scheduler cron.Scheduler) *CronSkillProvider {
 	return &CronSkillProvider{scheduler: scheduler}
 }
 
-func (p *CronSkillProvider) Cron(params map[string]any) (string, error) {
+func (p *CronSkillProvider) Cron(ctx context.Context, params map[string]any) (string, error) {
 	if p.scheduler == nil {
 		return "", fmt.Errorf("cron scheduler not initialized")
 	}
@@ -26,7 +28,7 @@ func (p *CronSkillProvider) Cron(params map[string]any) (string, error) {
 
 	switch action {
 	case "add":
-		return p.cronAdd(params)
+		return p.cronAdd(ctx, params)
 	case "list":
 		return p.cronList(params)
 	case "delete":
@@ -40,7 +42,7 @@ func (p *CronSkillProvider) Cron(params map[string]any) (string, error) {
 	}
 }
 
-func (p *CronSkillProvider) cronAdd(params map[string]any) (string, error) {
+func (p *CronSkillProvider) cronAdd(ctx context.Context, params map[string]any) (string, error) {
 	name, _ := params["name"].(string)
 	cronExpr, _ := params["cron"].(string)
 	message, _ := params["message"].(string)
@@ -49,10 +51,23 @@ func (p *CronSkillProvider) cronAdd(params map[string]any) (string, error) {
 		return "", fmt.Errorf("name, cron, and message are required for add action")
 	}
 
+	timezone, _ := params["timezone"].(string)
+	capability, _ := params["capability"].(string)
+
+	// 执行结果发送回发起请求的会话
+	var channel, sessionID string
+	if key, ok := entity.SessionKeyFromContext(ctx); ok && !key.IsDefault() {
+		channel, sessionID = key.ChannelID, key.SenderID
+	}
+
 	job := &cron.Job{
-		Name:    name,
-		Cron:    cronExpr,
-		Message: message,
+		Name:       name,
+		Cron:       cronExpr,
+		Message:    message,
+		Timezone:   timezone,
+		Channel:    channel,
+		SessionID:  sessionID,
+		Capability: capability,
 	}
 
 	id, err := p.scheduler.Add(job)
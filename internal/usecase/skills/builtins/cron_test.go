@@ -0,0 +1,51 @@
+package builtins
+
+import (
+	"context"
+	"mindx/internal/entity"
+	"mindx/internal/usecase/cron"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingScheduler struct {
+	cron.Scheduler
+	added []*cron.Job
+}
+
+func (s *recordingScheduler) Add(job *cron.Job) (string, error) {
+	s.added = append(s.added, job)
+	return "job-1", nil
+}
+
+func TestCron_AddRecordsOrigin(t *testing.T) {
+	scheduler := &recordingScheduler{}
+	provider := NewCronSkillProvider(scheduler)
+
+	ctx := entity.ContextWithSessionKey(context.Background(), entity.NewSessionKey("telegram", "chat-42"))
+	_, err := provider.Cron(ctx, map[string]any{
+		"action":     "add",
+		"name":       "晨间提醒",
+		"cron":       "0 8 * * *",
+		"message":    "提醒我喝水",
+		"timezone":   "Asia/Shanghai",
+		"capability": "assistant",
+	})
+	require.NoError(t, err)
+	require.Len(t, scheduler.added, 1)
+
+	job := scheduler.added[0]
+	assert.Equal(t, "telegram", job.Channel)
+	assert.Equal(t, "chat-42", job.SessionID)
+	assert.Equal(t, "assistant", job.Capability)
+	assert.Equal(t, "Asia/Shanghai", job.Timezone)
+
+	// 没有请求来源时使用调度器默认目标
+	_, err = provider.Cron(context.Background(), map[string]any{
+		"action": "add", "name": "n", "cron": "0 8 * * *", "message": "m",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, scheduler.added[1].Channel)
+}
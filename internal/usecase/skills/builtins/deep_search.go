@@ -13,8 +13,8 @@ import (
 
 // DeepSearch 深度搜索
 // 此搜索可以根据用户输入的问题，通过搜索引擎获取相关文章，然后使用LLM对文章内容进行总结。
-func NewDeepSearch(baseUrl string, apiKey string, model string, langName string) func(params map[string]any) (string, error) {
-	return func(params map[string]any) (string, error) {
+func NewDeepSearch(baseUrl string, apiKey string, model string, langName string) func(ctx context.Context, params map[string]any) (string, error) {
+	return func(ctx context.Context, params map[string]any) (string, error) {
 		terms, ok := params["terms"].(string)
 		if !ok {
 			return "", fmt.Errorf("terms is not a string")
@@ -45,7 +45,7 @@ func NewDeepSearch(baseUrl string, apiKey string, model string, langName string)
 		config.BaseURL = baseUrl
 		client := openai.NewClientWithConfig(config)
 
-		filteredResults, err := filterResultsWithLLM(client, terms, results, model)
+		filteredResults, err := filterResultsWithLLM(ctx, client, terms, results, model)
 		if err != nil {
 			return "", fmt.Errorf("filter failed: %w", err)
 		}
@@ -67,7 +67,7 @@ func NewDeepSearch(baseUrl string, apiKey string, model string, langName string)
 			return "", fmt.Errorf("no page content found")
 		}
 
-		summary, err := summarizeWithLLM(client, terms, pageContents, model, langName)
+		summary, err := summarizeWithLLM(ctx, client, terms, pageContents, model, langName)
 		if err != nil {
 			return "", fmt.Errorf("summarize failed: %w", err)
 		}
@@ -90,7 +90,7 @@ type DeepSearchResult struct {
 	ElapsedMs    int64         `json:"elapsed_ms"`
 }
 
-func filterResultsWithLLM(client *openai.Client, query string, results []utils.SearchResult, model string) ([]utils.SearchResult, error) {
+func filterResultsWithLLM(ctx context.Context, client *openai.Client, query string, results []utils.SearchResult, model string) ([]utils.SearchResult, error) {
 	prompt := fmt.Sprintf(getFilterPrompt(), query)
 
 	for i, result := range results {
@@ -104,7 +104,7 @@ Description: %s
 	prompt += getFilterPromptEnd()
 
 	resp, err := client.CreateChatCompletion(
-		context.Background(),
+		ctx,
 		openai.ChatCompletionRequest{
 			Model: model,
 			Messages: []openai.ChatCompletionMessage{
@@ -146,7 +146,7 @@ Description: %s
 	return filtered, nil
 }
 
-func summarizeWithLLM(client *openai.Client, query string, pageContents []PageContent, model string, langName string) (string, error) {
+func summarizeWithLLM(ctx context.Context, client *openai.Client, query string, pageContents []PageContent, model string, langName string) (string, error) {
 	prompt := fmt.Sprintf(getSummarizePrompt(), query)
 
 	for i, page := range pageContents {
@@ -165,7 +165,7 @@ Content: %s
 	prompt += getSummarizePromptEnd(langName)
 
 	resp, err := client.CreateChatCompletion(
-		context.Background(),
+		ctx,
 		openai.ChatCompletionRequest{
 			Model: model,
 			Messages: []openai.ChatCompletionMessage{
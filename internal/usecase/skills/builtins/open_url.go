@@ -1,6 +1,7 @@
 package builtins
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"mindx/internal/utils"
@@ -8,7 +9,7 @@ import (
 	"time"
 )
 
-func OpenURL(params map[string]any) (string, error) {
+func OpenURL(_ context.Context, params map[string]any) (string, error) {
 	url, ok := params["url"].(string)
 	if !ok {
 		return "", fmt.Errorf("invalid param: url")
@@ -1,6 +1,7 @@
 package builtins
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -10,7 +11,7 @@ import (
 
 // ReadFile reads content from a file
 // Absolute paths are used directly; relative paths resolve against MINDX_WORKSPACE
-func ReadFile(params map[string]any) (string, error) {
+func ReadFile(_ context.Context, params map[string]any) (string, error) {
 	path, ok := params["path"].(string)
 	if !ok || path == "" {
 		return "", fmt.Errorf("invalid param: path")
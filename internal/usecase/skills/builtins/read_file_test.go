@@ -1,6 +1,7 @@
 package builtins
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -21,7 +22,7 @@ func TestReadFile_ValidFile(t *testing.T) {
 		"path": "test.txt",
 	}
 
-	result, err := ReadFile(params)
+	result, err := ReadFile(context.Background(), params)
 	assert.NoError(t, err)
 	assert.Contains(t, result, "hello world")
 	assert.Contains(t, result, `"success": true`)
@@ -41,7 +42,7 @@ func TestReadFile_AbsolutePath(t *testing.T) {
 		"path": testFile,
 	}
 
-	result, err := ReadFile(params)
+	result, err := ReadFile(context.Background(), params)
 	assert.NoError(t, err)
 	assert.Contains(t, result, "absolute content")
 }
@@ -61,7 +62,7 @@ func TestReadFile_AbsolutePathOutsideWorkspaceDeniedByDefault(t *testing.T) {
 		"path": outsideFile,
 	}
 
-	result, err := ReadFile(params)
+	result, err := ReadFile(context.Background(), params)
 	// ReadFile reports business failures inside the JSON payload, while Go errors are reserved for invalid inputs/system failures.
 	assert.NoError(t, err)
 	assert.Contains(t, result, `"success": false`)
@@ -82,7 +83,7 @@ func TestReadFile_RelativePathResolvesToWorkspace(t *testing.T) {
 		"path": "documents/note.txt",
 	}
 
-	result, err := ReadFile(params)
+	result, err := ReadFile(context.Background(), params)
 	assert.NoError(t, err)
 	assert.Contains(t, result, "note content")
 	assert.Contains(t, result, `"success": true`)
@@ -97,7 +98,7 @@ func TestReadFile_FileNotFound(t *testing.T) {
 		"path": "nonexistent.txt",
 	}
 
-	result, err := ReadFile(params)
+	result, err := ReadFile(context.Background(), params)
 	assert.NoError(t, err) // Returns JSON with error info, not a Go error
 	assert.Contains(t, result, `"success": false`)
 	assert.Contains(t, result, "文件不存在")
@@ -106,7 +107,7 @@ func TestReadFile_FileNotFound(t *testing.T) {
 func TestReadFile_MissingParam(t *testing.T) {
 	params := map[string]any{}
 
-	_, err := ReadFile(params)
+	_, err := ReadFile(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid param")
 }
@@ -120,7 +121,7 @@ func TestReadFile_FileAccessEnabled_DefaultWorkspaceOnly(t *testing.T) {
 	outsideFile := filepath.Join(t.TempDir(), "outside.txt")
 	require.NoError(t, os.WriteFile(outsideFile, []byte("outside content"), 0644))
 
-	result, err := ReadFile(map[string]any{"path": outsideFile})
+	result, err := ReadFile(context.Background(), map[string]any{"path": outsideFile})
 	assert.NoError(t, err)
 	assert.Contains(t, result, `"success": false`)
 	assert.Contains(t, result, "读取路径超出允许范围")
@@ -136,7 +137,7 @@ func TestReadFile_FileAccessEnabled_AllowsConfiguredExternalPath(t *testing.T) {
 	require.NoError(t, os.WriteFile(allowedFile, []byte("allowed content"), 0644))
 	writeFileAccessConfigForTest(t, tmpDir, true, []string{allowedDir})
 
-	result, err := ReadFile(map[string]any{"path": allowedFile})
+	result, err := ReadFile(context.Background(), map[string]any{"path": allowedFile})
 	assert.NoError(t, err)
 	assert.Contains(t, result, `"success": true`)
 	assert.Contains(t, result, "allowed content")
@@ -28,7 +28,7 @@ var dangerousCommands = map[string]bool{
 var dangerousChars = []string{";", "&", "|", "`", "$", "$(", "${", ">", ">>", "<", "\n", "\r"}
 
 // Terminal executes a terminal command with security validation
-func Terminal(params map[string]any) (string, error) {
+func Terminal(ctx context.Context, params map[string]any) (string, error) {
 	command, ok := params["command"].(string)
 	if !ok || command == "" {
 		return "", fmt.Errorf("invalid param: command")
@@ -66,8 +66,8 @@ func Terminal(params map[string]any) (string, error) {
 		return "", fmt.Errorf("dangerous command '%s' requires dangerous=true parameter", baseCmd)
 	}
 
-	// Execute command with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
+	// Execute command with timeout; cancelling the request kills the command
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSec)*time.Second)
 	defer cancel()
 
 	var cmd *exec.Cmd
@@ -77,6 +77,9 @@ func Terminal(params map[string]any) (string, error) {
 		cmd = exec.CommandContext(ctx, "sh", "-c", command)
 	}
 
+	killProcessGroup(cmd)
+	cmd.WaitDelay = time.Second
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -86,6 +89,9 @@ func Terminal(params map[string]any) (string, error) {
 	if ctx.Err() == context.DeadlineExceeded {
 		return getJSONTerminalResult("", "Command timed out", 124, time.Since(startTime))
 	}
+	if ctx.Err() == context.Canceled {
+		return "", fmt.Errorf("command canceled: %w", ctx.Err())
+	}
 
 	exitCode := 0
 	if err != nil {
@@ -1,7 +1,9 @@
 package builtins
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,7 +13,7 @@ func TestTerminal_SafeCommand(t *testing.T) {
 		"command": "echo hello",
 	}
 
-	result, err := Terminal(params)
+	result, err := Terminal(context.Background(), params)
 	assert.NoError(t, err)
 	assert.Contains(t, result, "hello")
 	assert.Contains(t, result, `"exit_code": 0`)
@@ -22,7 +24,7 @@ func TestTerminal_DangerousCharacters(t *testing.T) {
 		"command": "echo hello; cat /etc/passwd",
 	}
 
-	_, err := Terminal(params)
+	_, err := Terminal(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "dangerous characters")
 }
@@ -32,7 +34,7 @@ func TestTerminal_DangerousCommand(t *testing.T) {
 		"command": "rm -rf /tmp/test",
 	}
 
-	_, err := Terminal(params)
+	_, err := Terminal(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "dangerous command")
 }
@@ -44,7 +46,7 @@ func TestTerminal_DangerousCommandWithFlag(t *testing.T) {
 		"dangerous": true,
 	}
 
-	result, err := Terminal(params)
+	result, err := Terminal(context.Background(), params)
 	assert.NoError(t, err)
 	assert.Contains(t, result, "dangerous-test")
 }
@@ -52,7 +54,7 @@ func TestTerminal_DangerousCommandWithFlag(t *testing.T) {
 func TestTerminal_MissingParam(t *testing.T) {
 	params := map[string]any{}
 
-	_, err := Terminal(params)
+	_, err := Terminal(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid param")
 }
@@ -62,7 +64,7 @@ func TestTerminal_PipeCharBlocked(t *testing.T) {
 		"command": "ls | grep test",
 	}
 
-	_, err := Terminal(params)
+	_, err := Terminal(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "dangerous characters")
 }
@@ -72,7 +74,7 @@ func TestTerminal_RedirectBlocked(t *testing.T) {
 		"command": "echo test > /tmp/hack",
 	}
 
-	_, err := Terminal(params)
+	_, err := Terminal(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "dangerous characters")
 }
@@ -82,7 +84,7 @@ func TestTerminal_NewlineInjectionBlocked(t *testing.T) {
 		"command": "echo hello\nrm -rf /",
 	}
 
-	_, err := Terminal(params)
+	_, err := Terminal(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "dangerous characters")
 }
@@ -92,7 +94,7 @@ func TestTerminal_VariableExpansionBlocked(t *testing.T) {
 		"command": "echo ${PATH}",
 	}
 
-	_, err := Terminal(params)
+	_, err := Terminal(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "dangerous characters")
 }
@@ -102,7 +104,18 @@ func TestTerminal_DollarExpansionBlocked(t *testing.T) {
 		"command": "echo $SHELL",
 	}
 
-	_, err := Terminal(params)
+	_, err := Terminal(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "dangerous characters")
 }
+
+func TestTerminal_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := Terminal(ctx, map[string]any{"command": "sleep 5"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "canceled")
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
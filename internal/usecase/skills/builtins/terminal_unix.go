@@ -0,0 +1,16 @@
+//go:build !windows
+
+package builtins
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroup 让命令在独立进程组中运行，取消时结束整个进程组（包括 sh 启动的子进程）
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
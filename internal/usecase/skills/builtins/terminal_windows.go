@@ -0,0 +1,9 @@
+//go:build windows
+
+package builtins
+
+import "os/exec"
+
+func killProcessGroup(_ *exec.Cmd) {
+	// Windows 使用 exec.CommandContext 默认的 Kill
+}
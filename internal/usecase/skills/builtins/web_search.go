@@ -1,13 +1,14 @@
 package builtins
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"mindx/internal/utils"
 	"time"
 )
 
-func Search(params map[string]any) (string, error) {
+func Search(_ context.Context, params map[string]any) (string, error) {
 	terms, ok := params["terms"].(string)
 	if !ok {
 		return "", fmt.Errorf("terms is not a string")
@@ -1,6 +1,7 @@
 package builtins
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -11,7 +12,7 @@ import (
 
 // WriteFile writes content to a file
 // Supports absolute paths directly; relative paths resolve against MINDX_WORKSPACE
-func WriteFile(params map[string]any) (string, error) {
+func WriteFile(_ context.Context, params map[string]any) (string, error) {
 	filename, ok := params["filename"].(string)
 	if !ok || filename == "" {
 		return "", fmt.Errorf("invalid param: filename")
@@ -1,6 +1,7 @@
 package builtins
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -19,7 +20,7 @@ func TestWriteFile_ValidWrite(t *testing.T) {
 		"content":  "hello world",
 	}
 
-	result, err := WriteFile(params)
+	result, err := WriteFile(context.Background(), params)
 	assert.NoError(t, err)
 	assert.Contains(t, result, "test.txt")
 
@@ -40,7 +41,7 @@ func TestWriteFile_ValidWriteWithRelativePath(t *testing.T) {
 		"path":     "subdir",
 	}
 
-	result, err := WriteFile(params)
+	result, err := WriteFile(context.Background(), params)
 	assert.NoError(t, err)
 	assert.Contains(t, result, "test.txt")
 
@@ -62,7 +63,7 @@ func TestWriteFile_AbsolutePathInFilename(t *testing.T) {
 		"content":  "absolute write",
 	}
 
-	_, err := WriteFile(params)
+	_, err := WriteFile(context.Background(), params)
 	assert.NoError(t, err)
 
 	content, err := os.ReadFile(targetFile)
@@ -83,7 +84,7 @@ func TestWriteFile_AbsolutePathParam(t *testing.T) {
 		"path":     targetDir,
 	}
 
-	_, err := WriteFile(params)
+	_, err := WriteFile(context.Background(), params)
 	assert.NoError(t, err)
 
 	content, err := os.ReadFile(filepath.Join(targetDir, "result.txt"))
@@ -105,7 +106,7 @@ func TestWriteFile_AbsolutePathWithDangerousFlag(t *testing.T) {
 		"dangerous": true,
 	}
 
-	result, err := WriteFile(params)
+	result, err := WriteFile(context.Background(), params)
 	assert.NoError(t, err)
 	assert.Contains(t, result, "result.txt")
 
@@ -119,7 +120,7 @@ func TestWriteFile_MissingFilename(t *testing.T) {
 		"content": "hello",
 	}
 
-	_, err := WriteFile(params)
+	_, err := WriteFile(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid param: filename")
 }
@@ -129,7 +130,7 @@ func TestWriteFile_MissingContent(t *testing.T) {
 		"filename": "test.txt",
 	}
 
-	_, err := WriteFile(params)
+	_, err := WriteFile(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid param: content")
 }
@@ -145,7 +146,7 @@ func TestWriteFile_DocumentsSubdir(t *testing.T) {
 		"path":     "documents/notes",
 	}
 
-	result, err := WriteFile(params)
+	result, err := WriteFile(context.Background(), params)
 	assert.NoError(t, err)
 	assert.Contains(t, result, "note.txt")
 
@@ -166,7 +167,7 @@ func TestWriteFile_PathTraversalBlockedByFilename(t *testing.T) {
 		"content":  "blocked",
 	}
 
-	_, err := WriteFile(params)
+	_, err := WriteFile(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "outside allowed scope")
 }
@@ -184,7 +185,7 @@ func TestWriteFile_PathTraversalBlockedByPath(t *testing.T) {
 		"path":     "../escape",
 	}
 
-	_, err := WriteFile(params)
+	_, err := WriteFile(context.Background(), params)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "outside allowed scope")
 }
@@ -196,7 +197,7 @@ func TestWriteFile_FileAccessEnabled_DefaultWorkspaceOnly(t *testing.T) {
 	writeFileAccessConfigForTest(t, tmpDir, true, nil)
 
 	outsideFile := filepath.Join(t.TempDir(), "blocked.txt")
-	_, err := WriteFile(map[string]any{
+	_, err := WriteFile(context.Background(), map[string]any{
 		"filename": outsideFile,
 		"content":  "blocked",
 	})
@@ -213,7 +214,7 @@ func TestWriteFile_FileAccessEnabled_AllowsConfiguredExternalDir(t *testing.T) {
 	writeFileAccessConfigForTest(t, tmpDir, true, []string{allowedDir})
 
 	targetFile := filepath.Join(allowedDir, "allowed.txt")
-	_, err := WriteFile(map[string]any{
+	_, err := WriteFile(context.Background(), map[string]any{
 		"filename": targetFile,
 		"content":  "allowed",
 	})
@@ -230,7 +231,7 @@ func TestWriteFile_DefaultPolicyDeniesAbsoluteOutsideWorkspace(t *testing.T) {
 	defer os.Unsetenv("MINDX_WORKSPACE")
 
 	outsideFile := filepath.Join(t.TempDir(), "blocked.txt")
-	_, err := WriteFile(map[string]any{
+	_, err := WriteFile(context.Background(), map[string]any{
 		"filename": outsideFile,
 		"content":  "blocked",
 	})
@@ -248,7 +249,7 @@ func TestWriteFile_FileAccessEnabled_AllowsNonExistentExternalDirPattern(t *test
 	writeFileAccessConfigForTest(t, tmpDir, true, []string{allowedDir + "/**"})
 
 	targetFile := filepath.Join(allowedDir, "nested", "allowed.txt")
-	_, err := WriteFile(map[string]any{
+	_, err := WriteFile(context.Background(), map[string]any{
 		"filename": targetFile,
 		"content":  "allowed",
 	})
@@ -28,7 +28,8 @@ type SkillExecutor struct {
 	mcpMgr         *MCPManager
 }
 
-type InternalSkillFunc func(params map[string]any) (string, error)
+// InternalSkillFunc 内置技能函数，ctx 随请求取消或超时
+type InternalSkillFunc func(ctx context.Context, params map[string]any) (string, error)
 
 func NewSkillExecutor(skillsDir string, envMgr *EnvManager, store core.Store, mcpMgr *MCPManager, logger logging.Logger) *SkillExecutor {
 	return &SkillExecutor{
@@ -55,7 +56,13 @@ func (e *SkillExecutor) RegisterInternalSkill(name string, fn InternalSkillFunc)
 	e.logger.Info(i18n.T("skill.register_internal_success"), logging.String(i18n.T("skill.name"), name))
 }
 
-func (e *SkillExecutor) Execute(name string, def *entity.SkillDef, params map[string]any) (string, error) {
+func (e *SkillExecutor) Execute(ctx context.Context, name string, def *entity.SkillDef, params map[string]any) (string, error) {
+	result, _, err := e.ExecuteWithAttachments(ctx, name, def, params)
+	return result, err
+}
+
+// ExecuteWithAttachments 执行技能，同时返回技能产出的附件（目前仅 MCP 工具会产生附件）
+func (e *SkillExecutor) ExecuteWithAttachments(ctx context.Context, name string, def *entity.SkillDef, params map[string]any) (string, []*entity.Attachment, error) {
 	e.logger.Info(i18n.T("skill.start_execute"), logging.String(i18n.T("skill.name"), name), logging.Any(i18n.T("skill.params"), params))
 
 	e.mu.RLock()
@@ -63,23 +70,25 @@ func (e *SkillExecutor) Execute(name string, def *entity.SkillDef, params map[st
 	e.mu.RUnlock()
 
 	if !exists {
-		return "", fmt.Errorf("skill not found: %s", name)
+		return "", nil, fmt.Errorf("skill not found: %s", name)
 	}
 
 	startTime := time.Now()
 
 	if def.IsInternal {
-		return e.executeInternal(name, params, startTime)
+		result, err := e.executeInternal(ctx, name, params, startTime)
+		return result, nil, err
 	}
 
 	if IsMCPSkill(def) {
-		return e.executeMCP(name, def, params, startTime)
+		return e.executeMCP(ctx, name, def, params, startTime)
 	}
 
-	return e.executeExternal(name, def, params, startTime)
+	result, err := e.executeExternal(ctx, name, def, params, startTime)
+	return result, nil, err
 }
 
-func (e *SkillExecutor) executeInternal(name string, params map[string]any, startTime time.Time) (string, error) {
+func (e *SkillExecutor) executeInternal(ctx context.Context, name string, params map[string]any, startTime time.Time) (string, error) {
 	e.mu.RLock()
 	fn, exists := e.internalSkills[name]
 	e.mu.RUnlock()
@@ -89,7 +98,7 @@ func (e *SkillExecutor) executeInternal(name string, params map[string]any, star
 		return "", fmt.Errorf("internal skill not registered: %s", name)
 	}
 
-	result, err := fn(params)
+	result, err := fn(ctx, params)
 	duration := time.Since(startTime).Milliseconds()
 
 	if err != nil {
@@ -103,40 +112,42 @@ func (e *SkillExecutor) executeInternal(name string, params map[string]any, star
 	return result, nil
 }
 
-func (e *SkillExecutor) executeMCP(name string, def *entity.SkillDef, params map[string]any, startTime time.Time) (string, error) {
+func (e *SkillExecutor) executeMCP(ctx context.Context, name string, def *entity.SkillDef, params map[string]any, startTime time.Time) (string, []*entity.Attachment, error) {
 	if e.mcpMgr == nil {
 		e.UpdateStats(name, false, time.Since(startTime).Milliseconds())
-		return "", fmt.Errorf("mcp manager not initialized")
+		return "", nil, fmt.Errorf("mcp manager not initialized")
 	}
 
 	mcpMeta, ok := GetMCPSkillMetadata(def)
 	if !ok {
 		e.UpdateStats(name, false, time.Since(startTime).Milliseconds())
-		return "", fmt.Errorf("invalid mcp skill metadata")
+		return "", nil, fmt.Errorf("invalid mcp skill metadata")
 	}
 
 	timeout := time.Duration(def.Timeout) * time.Second
 	if timeout == 0 {
 		timeout = 30 * time.Second
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	result, err := e.mcpMgr.CallTool(ctx, mcpMeta.Server, mcpMeta.Tool, params)
+	result, attachments, err := e.mcpMgr.CallTool(ctx, mcpMeta.Server, mcpMeta.Tool, params)
 	duration := time.Since(startTime).Milliseconds()
 
 	if err != nil {
 		e.UpdateStats(name, false, duration)
 		e.logger.Error(i18n.T("skill.execute_failed"), logging.String(i18n.T("skill.output"), result), logging.Err(err))
-		return result, err
+		return result, nil, err
 	}
 
 	e.UpdateStats(name, true, duration)
-	e.logger.Info(i18n.T("skill.execute_success"), logging.String(i18n.T("skill.output"), result))
-	return result, nil
+	e.logger.Info(i18n.T("skill.execute_success"),
+		logging.String(i18n.T("skill.output"), result),
+		logging.Int("attachments", len(attachments)))
+	return result, attachments, nil
 }
 
-func (e *SkillExecutor) executeExternal(name string, def *entity.SkillDef, params map[string]any, startTime time.Time) (string, error) {
+func (e *SkillExecutor) executeExternal(ctx context.Context, name string, def *entity.SkillDef, params map[string]any, startTime time.Time) (string, error) {
 	cmd, err := e.buildCommand(def, params)
 	if err != nil {
 		e.UpdateStats(name, false, time.Since(startTime).Milliseconds())
@@ -148,7 +159,7 @@ func (e *SkillExecutor) executeExternal(name string, def *entity.SkillDef, param
 		timeout = time.Duration(def.Timeout) * time.Second
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	cmd = exec.CommandContext(ctx, cmd.Path, cmd.Args[1:]...)
@@ -195,7 +206,13 @@ func (e *SkillExecutor) executeExternal(name string, def *entity.SkillDef, param
 	return string(output), nil
 }
 
-func (e *SkillExecutor) ExecuteFunc(function core.ToolCallFunction) (string, error) {
+func (e *SkillExecutor) ExecuteFunc(ctx context.Context, function core.ToolCallFunction) (string, error) {
+	result, _, err := e.ExecuteFuncWithAttachments(ctx, function)
+	return result, err
+}
+
+// ExecuteFuncWithAttachments 按函数调用执行技能，同时返回技能产出的附件
+func (e *SkillExecutor) ExecuteFuncWithAttachments(ctx context.Context, function core.ToolCallFunction) (string, []*entity.Attachment, error) {
 	e.logger.Info(i18n.T("skill.exec_func"),
 		logging.String(i18n.T("skill.function"), function.Name),
 		logging.Any(i18n.T("skill.arguments"), function.Arguments))
@@ -210,10 +227,10 @@ func (e *SkillExecutor) ExecuteFunc(function core.ToolCallFunction) (string, err
 	e.mu.RUnlock()
 
 	if !exists {
-		return "", fmt.Errorf("skill not found: %s", function.Name)
+		return "", nil, fmt.Errorf("skill not found: %s", function.Name)
 	}
 
-	return e.Execute(function.Name, info.Def, params)
+	return e.ExecuteWithAttachments(ctx, function.Name, info.Def, params)
 }
 
 func (e *SkillExecutor) buildCommand(def *entity.SkillDef, params map[string]any) (*exec.Cmd, error) {
@@ -21,6 +21,8 @@ import (
 type skillIndexData struct {
 	Vectors [][]float64 `json:"vectors"`
 	Hash    string      `json:"hash"`
+	// Keywords 与 Vectors 一一对应的关键词，更换嵌入模型时据此重新生成向量
+	Keywords []string `json:"keywords,omitempty"`
 }
 
 type indexTask struct {
@@ -42,6 +44,7 @@ type SkillIndexer struct {
 
 	toolKeywordVectors map[string][][]float64
 	skillHashes        map[string]string
+	skillKeywords      map[string][]string
 
 	taskQueue    chan *indexTask
 	queueFile    string
@@ -64,6 +67,7 @@ func NewSkillIndexer(embedding *embedding.EmbeddingService, llama *infraLlama.Ol
 		dataPath:           dataPath,
 		toolKeywordVectors: make(map[string][][]float64),
 		skillHashes:        make(map[string]string),
+		skillKeywords:      make(map[string][]string),
 		taskQueue:          make(chan *indexTask, 100),
 		queueFile:          filepath.Join(dataPath, "index_queue.json"),
 		stopChan:           make(chan struct{}),
@@ -144,6 +148,7 @@ func (i *SkillIndexer) processTask(task *indexTask, systemPrompt string) {
 	}
 
 	var vectors [][]float64
+	var embedded []string
 	for _, word := range keywords {
 		word = strings.TrimSpace(word)
 		if word == "" {
@@ -155,6 +160,7 @@ func (i *SkillIndexer) processTask(task *indexTask, systemPrompt string) {
 			continue
 		}
 		vectors = append(vectors, vec)
+		embedded = append(embedded, word)
 	}
 
 	if len(vectors) == 0 {
@@ -165,9 +171,10 @@ func (i *SkillIndexer) processTask(task *indexTask, systemPrompt string) {
 	i.mu.Lock()
 	i.toolKeywordVectors[task.SkillName] = vectors
 	i.skillHashes[task.SkillName] = task.Hash
+	i.skillKeywords[task.SkillName] = embedded
 	i.mu.Unlock()
 
-	i.saveSingleIndex(task.SkillName, vectors, task.Hash)
+	i.saveSingleIndex(task.SkillName, vectors, task.Hash, embedded)
 
 	i.logger.Debug(i18n.T("skill.vector_precompute_complete"),
 		logging.String("skill", task.SkillName),
@@ -377,6 +384,9 @@ func (i *SkillIndexer) LoadFromStore() error {
 		if indexData.Hash != "" {
 			i.skillHashes[skillName] = indexData.Hash
 		}
+		if len(indexData.Keywords) == len(indexData.Vectors) {
+			i.skillKeywords[skillName] = indexData.Keywords
+		}
 		loadedCount++
 
 		i.logger.Debug(i18n.T("skill.vector_loaded"),
@@ -392,14 +402,15 @@ func (i *SkillIndexer) LoadFromStore() error {
 	return nil
 }
 
-func (i *SkillIndexer) saveSingleIndex(skillName string, vectors [][]float64, hash string) error {
+func (i *SkillIndexer) saveSingleIndex(skillName string, vectors [][]float64, hash string, keywords []string) error {
 	if i.store == nil {
 		return nil
 	}
 
 	indexData := skillIndexData{
-		Vectors: vectors,
-		Hash:    hash,
+		Vectors:  vectors,
+		Hash:     hash,
+		Keywords: keywords,
 	}
 
 	key := "skill_vector:" + skillName
@@ -418,8 +429,9 @@ func (i *SkillIndexer) saveVectorIndexToStore() error {
 
 	for skillName, vectors := range i.toolKeywordVectors {
 		indexData := skillIndexData{
-			Vectors: vectors,
-			Hash:    i.skillHashes[skillName],
+			Vectors:  vectors,
+			Hash:     i.skillHashes[skillName],
+			Keywords: i.skillKeywords[skillName],
 		}
 		metadata, err := json.Marshal(indexData)
 		if err != nil {
@@ -544,3 +556,54 @@ func containsString(slice []string, s string) bool {
 	}
 	return false
 }
+
+// Reembed 用当前的嵌入模型为技能已提取的关键词重新生成向量
+// 旧版本的索引没有保存关键词，此时删除该技能的索引，由下次 ReIndex 重新提取关键词
+func (i *SkillIndexer) Reembed(skillName string) error {
+	if i.embedding == nil {
+		return fmt.Errorf("embedding service not set")
+	}
+
+	i.mu.RLock()
+	keywords := i.skillKeywords[skillName]
+	hash := i.skillHashes[skillName]
+	i.mu.RUnlock()
+
+	if len(keywords) == 0 {
+		i.mu.Lock()
+		delete(i.toolKeywordVectors, skillName)
+		delete(i.skillHashes, skillName)
+		i.mu.Unlock()
+		if i.store != nil {
+			return i.store.Delete("skill_vector:" + skillName)
+		}
+		return nil
+	}
+
+	vectors := make([][]float64, 0, len(keywords))
+	for _, word := range keywords {
+		vec, err := i.embedding.GenerateEmbedding(word)
+		if err != nil {
+			return err
+		}
+		vectors = append(vectors, vec)
+	}
+
+	i.mu.Lock()
+	i.toolKeywordVectors[skillName] = vectors
+	i.mu.Unlock()
+
+	return i.saveSingleIndex(skillName, vectors, hash, keywords)
+}
+
+// Dimension 返回已索引的关键词向量维度，尚无向量时为 0
+func (i *SkillIndexer) Dimension() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, vectors := range i.toolKeywordVectors {
+		if len(vectors) > 0 {
+			return len(vectors[0])
+		}
+	}
+	return 0
+}
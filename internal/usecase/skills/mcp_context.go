@@ -0,0 +1,187 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"mindx/internal/core"
+	"mindx/pkg/logging"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// mcpResourceMaxChars 单个资源注入上下文的最大字符数
+	mcpResourceMaxChars = 4000
+	// mcpResourceReadTimeout 检索资源时读取内容的总超时
+	mcpResourceReadTimeout = 5 * time.Second
+)
+
+// MCPPromptShortcut 返回 MCP 提示词对应的快捷指令名（与 MCP 工具的技能命名规则一致）
+func MCPPromptShortcut(serverName, promptName string) string {
+	return fmt.Sprintf("mcp_%s_%s", serverName, promptName)
+}
+
+// SearchMCPResources 从已连接 MCP server 的资源中检索与问题相关的内容
+// 按资源名、标题、URI 与描述中的词在问题中的命中情况打分，读取得分最高的 limit 个资源
+func (m *SkillMgr) SearchMCPResources(question string, limit int) ([]*core.ResourceContent, error) {
+	type candidate struct {
+		server   string
+		resource *mcp.Resource
+		score    int
+	}
+
+	query := strings.ToLower(question)
+	var candidates []candidate
+	for server, resources := range m.mcpMgr.GetDiscoveredResources() {
+		for _, resource := range resources {
+			if score := scoreMCPResource(query, resource); score >= 2 {
+				candidates = append(candidates, candidate{server: server, resource: resource, score: score})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mcpResourceReadTimeout)
+	defer cancel()
+
+	results := make([]*core.ResourceContent, 0, len(candidates))
+	for _, c := range candidates {
+		text, _, err := m.mcpMgr.ReadResource(ctx, c.server, c.resource.URI)
+		if err != nil {
+			m.logger.Warn("读取 MCP 资源失败",
+				logging.String("server", c.server),
+				logging.String("uri", c.resource.URI),
+				logging.Err(err))
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		if runes := []rune(text); len(runes) > mcpResourceMaxChars {
+			text = string(runes[:mcpResourceMaxChars]) + "..."
+		}
+
+		name := c.resource.Title
+		if name == "" {
+			name = c.resource.Name
+		}
+		results = append(results, &core.ResourceContent{
+			Name: name,
+			URI:  c.resource.URI,
+			Text: text,
+		})
+	}
+	return results, nil
+}
+
+// scoreMCPResource 计算资源与问题（已转小写）的相关度
+// 名称或标题被提及记 3 分，URI 文件名被提及记 2 分，描述中的每个词被提及记 1 分
+func scoreMCPResource(query string, resource *mcp.Resource) int {
+	score := 0
+	for _, name := range []string{resource.Name, resource.Title} {
+		if name = strings.ToLower(name); len([]rune(name)) >= 2 && strings.Contains(query, name) {
+			score += 3
+			break
+		}
+	}
+
+	base := strings.ToLower(path.Base(resource.URI))
+	base = strings.TrimSuffix(base, path.Ext(base))
+	if len(base) >= 3 && strings.Contains(query, base) {
+		score += 2
+	}
+
+	seen := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(resource.Description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) < 4 || seen[word] {
+			continue
+		}
+		seen[word] = true
+		if strings.Contains(query, word) {
+			score++
+		}
+	}
+	return score
+}
+
+// ExpandMCPPrompt 将快捷指令展开为 MCP 提示词渲染出的对话消息
+// args 支持 "key=value key2=value2" 形式；提示词只有一个参数时，也可以直接把整段文本作为该参数
+// 快捷指令不存在时 found 返回 false
+func (m *SkillMgr) ExpandMCPPrompt(shortcut, args string) ([]*core.DialogueMessage, bool, error) {
+	for server, prompts := range m.mcpMgr.GetDiscoveredPrompts() {
+		for _, prompt := range prompts {
+			if MCPPromptShortcut(server, prompt.Name) != shortcut {
+				continue
+			}
+
+			arguments, err := parsePromptArgs(prompt, args)
+			if err != nil {
+				return nil, true, err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			result, err := m.mcpMgr.GetPrompt(ctx, server, prompt.Name, arguments)
+			if err != nil {
+				return nil, true, err
+			}
+
+			messages := make([]*core.DialogueMessage, 0, len(result.Messages))
+			for _, msg := range result.Messages {
+				text, _ := convertContent([]mcp.Content{msg.Content})
+				if text == "" {
+					continue
+				}
+				messages = append(messages, &core.DialogueMessage{Role: string(msg.Role), Content: text})
+			}
+			return messages, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// parsePromptArgs 解析快捷指令参数，并检查必填参数
+func parsePromptArgs(prompt *mcp.Prompt, raw string) (map[string]string, error) {
+	raw = strings.TrimSpace(raw)
+	args := make(map[string]string)
+
+	if len(prompt.Arguments) == 1 && raw != "" && !strings.HasPrefix(raw, prompt.Arguments[0].Name+"=") {
+		args[prompt.Arguments[0].Name] = raw
+	} else {
+		for _, field := range strings.Fields(raw) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid prompt argument %q, expected key=value", field)
+			}
+			args[key] = value
+		}
+	}
+
+	var missing []string
+	for _, arg := range prompt.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required prompt arguments: %s", strings.Join(missing, ", "))
+	}
+	return args, nil
+}
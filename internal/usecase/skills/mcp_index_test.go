@@ -1,6 +1,7 @@
 package skills
 
 import (
+	"context"
 	"mindx/internal/config"
 	"mindx/internal/entity"
 	"mindx/pkg/logging"
@@ -103,22 +104,22 @@ func TestMCPToolRegistrationChain(t *testing.T) {
 
 	// === Step 4: 验证 searcher 关键词搜索能找到 MCP 工具 ===
 	// 按 tag 搜索
-	results, err := mgr.SearchSkills("mcp")
+	results, err := mgr.SearchSkills(context.Background(), "mcp")
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(results), 2, "搜索 'mcp' 应找到 MCP 工具")
 
 	// 按 server name 搜索
-	results, err = mgr.SearchSkills("bijia")
+	results, err = mgr.SearchSkills(context.Background(), "bijia")
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(results), 1, "搜索 'bijia' 应找到 MCP 工具")
 
 	// 按 description 中的词搜索
-	results, err = mgr.SearchSkills("price")
+	results, err = mgr.SearchSkills(context.Background(), "price")
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(results), 1, "搜索 'price' 应找到 MCP 工具")
 
 	// 无关键词搜索应返回所有（包括 MCP）
-	results, err = mgr.SearchSkills()
+	results, err = mgr.SearchSkills(context.Background())
 	require.NoError(t, err)
 	foundMCP := false
 	for _, s := range results {
@@ -142,7 +143,7 @@ func TestMCPToolRegistrationChain(t *testing.T) {
 	assert.NotContains(t, allSkills, "mcp_bijia_compare_prices", "注销后不应存在")
 	assert.NotContains(t, allSkills, "mcp_bijia_get_history", "注销后不应存在")
 
-	results, err = mgr.SearchSkills("bijia")
+	results, err = mgr.SearchSkills(context.Background(), "bijia")
 	require.NoError(t, err)
 	assert.Equal(t, 0, len(results), "注销后搜索不应找到")
 }
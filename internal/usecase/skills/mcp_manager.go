@@ -2,12 +2,16 @@ package skills
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"mindx/internal/config"
+	"mindx/internal/entity"
 	"mindx/pkg/logging"
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"strings"
 	"sync"
 
@@ -23,20 +27,25 @@ const (
 )
 
 type MCPServerState struct {
-	Name   string                 `json:"name"`
-	Config config.MCPServerEntry  `json:"config"`
-	Status MCPServerStatus        `json:"status"`
-	Error  string                 `json:"error,omitempty"`
-	Tools  []*mcp.Tool            `json:"tools,omitempty"`
-
-	client  *mcp.Client
-	session *mcp.ClientSession
+	Name      string                `json:"name"`
+	Config    config.MCPServerEntry `json:"config"`
+	Status    MCPServerStatus       `json:"status"`
+	Error     string                `json:"error,omitempty"`
+	Tools     []*mcp.Tool           `json:"tools,omitempty"`
+	Resources []*mcp.Resource       `json:"resources,omitempty"`
+	Prompts   []*mcp.Prompt         `json:"prompts,omitempty"`
+
+	client       *mcp.Client
+	session      *mcp.ClientSession
+	reconnecting bool
 }
 
 type MCPManager struct {
 	logger  logging.Logger
 	mu      sync.RWMutex
 	servers map[string]*MCPServerState
+
+	onSessionLost func(name string, entry config.MCPServerEntry)
 }
 
 func NewMCPManager(logger logging.Logger) *MCPManager {
@@ -46,7 +55,14 @@ func NewMCPManager(logger logging.Logger) *MCPManager {
 	}
 }
 
-// ConnectServer 连接 MCP server（支持 stdio 和 sse 两种传输方式）
+// SetOnSessionLost 设置远程 server 会话失效时的回调，由调用方负责重新连接
+func (m *MCPManager) SetOnSessionLost(fn func(name string, entry config.MCPServerEntry)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onSessionLost = fn
+}
+
+// ConnectServer 连接 MCP server（支持 stdio、sse 和 Streamable HTTP 三种传输方式）
 func (m *MCPManager) ConnectServer(ctx context.Context, name string, entry config.MCPServerEntry) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -71,21 +87,17 @@ func (m *MCPManager) ConnectServer(ctx context.Context, name string, entry confi
 	// 根据传输类型构造 transport
 	var transport mcp.Transport
 	switch entry.GetType() {
-	case "sse":
-		sseTransport := &mcp.SSEClientTransport{
-			Endpoint: entry.URL,
+	case config.MCPTransportSSE:
+		transport = &mcp.SSEClientTransport{
+			Endpoint:   entry.URL,
+			HTTPClient: remoteHTTPClient(entry),
 		}
-		// 用 entry.Env 作为本地变量上下文解析 headers 中的 ${VAR} 占位符
-		if len(entry.Headers) > 0 {
-			resolvedHeaders := config.ResolveEnvVarsWithContext(entry.Headers, entry.Env)
-			sseTransport.HTTPClient = &http.Client{
-				Transport: &headerRoundTripper{
-					base:    http.DefaultTransport,
-					headers: resolvedHeaders,
-				},
-			}
+	case config.MCPTransportHTTP:
+		// 断线后由 SDK 按 Last-Event-ID 续传流；会话失效则交由 onSessionLost 重新初始化
+		transport = &mcp.StreamableClientTransport{
+			Endpoint:   entry.URL,
+			HTTPClient: remoteHTTPClient(entry),
 		}
-		transport = sseTransport
 	default: // "stdio"
 		cmd := exec.Command(entry.Command, entry.Args...)
 		// 继承当前进程的完整环境变量，再覆盖用户配置的变量
@@ -136,10 +148,59 @@ func (m *MCPManager) ConnectServer(ctx context.Context, name string, entry confi
 			logging.String("tools", strings.Join(toolNames, ", ")))
 	}
 
+	m.discoverResourcesAndPrompts(ctx, state)
+
 	m.servers[name] = state
 	return nil
 }
 
+// discoverResourcesAndPrompts 发现 server 提供的 resources 和 prompts
+// 只在 server 声明了对应能力时才请求，发现失败不影响工具的使用
+func (m *MCPManager) discoverResourcesAndPrompts(ctx context.Context, state *MCPServerState) {
+	var caps *mcp.ServerCapabilities
+	if init := state.session.InitializeResult(); init != nil {
+		caps = init.Capabilities
+	}
+	if caps == nil {
+		return
+	}
+
+	if caps.Resources != nil {
+		var resources []*mcp.Resource
+		for resource, err := range state.session.Resources(ctx, nil) {
+			if err != nil {
+				m.logger.Warn("MCP server 资源发现失败",
+					logging.String("server", state.Name),
+					logging.Err(err))
+				break
+			}
+			resources = append(resources, resource)
+		}
+		state.Resources = resources
+	}
+
+	if caps.Prompts != nil {
+		var prompts []*mcp.Prompt
+		for prompt, err := range state.session.Prompts(ctx, nil) {
+			if err != nil {
+				m.logger.Warn("MCP server 提示词发现失败",
+					logging.String("server", state.Name),
+					logging.Err(err))
+				break
+			}
+			prompts = append(prompts, prompt)
+		}
+		state.Prompts = prompts
+	}
+
+	if len(state.Resources) > 0 || len(state.Prompts) > 0 {
+		m.logger.Info("MCP server 资源与提示词发现完成",
+			logging.String("server", state.Name),
+			logging.Int("resources_count", len(state.Resources)),
+			logging.Int("prompts_count", len(state.Prompts)))
+	}
+}
+
 // DisconnectServer 断开 MCP server 连接
 func (m *MCPManager) DisconnectServer(name string) error {
 	m.mu.Lock()
@@ -162,21 +223,18 @@ func (m *MCPManager) DisconnectServer(name string) error {
 	state.client = nil
 	state.Status = MCPServerStatusDisconnected
 	state.Tools = nil
+	state.Resources = nil
+	state.Prompts = nil
 	state.Error = ""
 	return nil
 }
 
 // CallTool 调用 MCP server 上的工具
-func (m *MCPManager) CallTool(ctx context.Context, serverName, toolName string, args map[string]any) (string, error) {
-	m.mu.RLock()
-	state, ok := m.servers[serverName]
-	m.mu.RUnlock()
-
-	if !ok {
-		return "", fmt.Errorf("MCP server not found: %s", serverName)
-	}
-	if state.session == nil || state.Status != MCPServerStatusConnected {
-		return "", fmt.Errorf("MCP server not connected: %s (status: %s)", serverName, state.Status)
+// 返回文本内容，以及图片、音频、嵌入资源等非文本内容转换成的附件
+func (m *MCPManager) CallTool(ctx context.Context, serverName, toolName string, args map[string]any) (string, []*entity.Attachment, error) {
+	state, err := m.connectedState(serverName)
+	if err != nil {
+		return "", nil, err
 	}
 
 	m.logger.Info("调用 MCP 工具",
@@ -193,14 +251,108 @@ func (m *MCPManager) CallTool(ctx context.Context, serverName, toolName string,
 		state.Status = MCPServerStatusError
 		state.Error = err.Error()
 		m.mu.Unlock()
-		return "", fmt.Errorf("MCP tool call failed: %w", err)
+		if isMCPSessionLost(err) {
+			m.triggerReconnect(state)
+		}
+		return "", nil, fmt.Errorf("MCP tool call failed: %w", err)
 	}
 
 	if result.IsError {
-		return "", fmt.Errorf("MCP tool returned error: %s", extractTextContent(result.Content))
+		return "", nil, fmt.Errorf("MCP tool returned error: %s", extractTextContent(result.Content))
+	}
+
+	text, attachments := convertContent(result.Content)
+	return text, attachments, nil
+}
+
+// ReadResource 读取 MCP server 上的资源，返回文本内容和二进制内容转换成的附件
+func (m *MCPManager) ReadResource(ctx context.Context, serverName, uri string) (string, []*entity.Attachment, error) {
+	state, err := m.connectedState(serverName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	result, err := state.session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		if isMCPSessionLost(err) {
+			m.triggerReconnect(state)
+		}
+		return "", nil, fmt.Errorf("MCP resource read failed: %w", err)
+	}
+
+	var parts []string
+	var attachments []*entity.Attachment
+	for _, rc := range result.Contents {
+		if text, attachment := resourceContentsToAttachment(rc); attachment != nil {
+			attachments = append(attachments, attachment)
+		} else if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n"), attachments, nil
+}
+
+// GetPrompt 获取 MCP server 上的提示词，并按参数渲染为消息列表
+func (m *MCPManager) GetPrompt(ctx context.Context, serverName, promptName string, args map[string]string) (*mcp.GetPromptResult, error) {
+	state, err := m.connectedState(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := state.session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      promptName,
+		Arguments: args,
+	})
+	if err != nil {
+		if isMCPSessionLost(err) {
+			m.triggerReconnect(state)
+		}
+		return nil, fmt.Errorf("MCP prompt get failed: %w", err)
+	}
+	return result, nil
+}
+
+// connectedState 获取已连接的 server 状态；server 处于错误状态时触发后台重连
+func (m *MCPManager) connectedState(serverName string) (*MCPServerState, error) {
+	m.mu.RLock()
+	state, ok := m.servers[serverName]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("MCP server not found: %s", serverName)
 	}
+	if state.session == nil || state.Status != MCPServerStatusConnected {
+		if state.Status == MCPServerStatusError {
+			m.triggerReconnect(state)
+		}
+		return nil, fmt.Errorf("MCP server not connected: %s (status: %s)", serverName, state.Status)
+	}
+	return state, nil
+}
 
-	return extractTextContent(result.Content), nil
+// triggerReconnect 远程 server 会话失效后在后台触发一次重新连接（同一 server 不会并发重连）
+func (m *MCPManager) triggerReconnect(state *MCPServerState) {
+	m.mu.Lock()
+	cb := m.onSessionLost
+	if cb == nil || state.reconnecting || !state.Config.IsRemote() {
+		m.mu.Unlock()
+		return
+	}
+	state.reconnecting = true
+	m.mu.Unlock()
+
+	m.logger.Warn("MCP server 会话失效，后台重新连接", logging.String("server", state.Name))
+	go cb(state.Name, state.Config)
+}
+
+// isMCPSessionLost 判断错误是否表示与远程 server 的会话已失效（server 重启、会话过期、连接断开）
+func isMCPSessionLost(err error) bool {
+	if errors.Is(err, mcp.ErrConnectionClosed) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "session not found") ||
+		strings.Contains(msg, "connection closed")
 }
 
 // extractTextContent 从 MCP Content 列表中提取文本
@@ -214,6 +366,115 @@ func extractTextContent(contents []mcp.Content) string {
 	return strings.Join(parts, "\n")
 }
 
+// convertContent 将 MCP Content 列表转换为文本和附件
+// 文本与嵌入的文本资源合并为文本结果；图片、音频、二进制资源和资源链接转换为附件
+func convertContent(contents []mcp.Content) (string, []*entity.Attachment) {
+	var parts []string
+	var attachments []*entity.Attachment
+	for _, c := range contents {
+		switch v := c.(type) {
+		case *mcp.TextContent:
+			parts = append(parts, v.Text)
+		case *mcp.ImageContent:
+			attachments = append(attachments, &entity.Attachment{
+				Type:     "image",
+				URL:      dataURL(v.MIMEType, v.Data),
+				MIMEType: v.MIMEType,
+			})
+		case *mcp.AudioContent:
+			attachments = append(attachments, &entity.Attachment{
+				Type:     "audio",
+				URL:      dataURL(v.MIMEType, v.Data),
+				MIMEType: v.MIMEType,
+			})
+		case *mcp.EmbeddedResource:
+			if text, attachment := resourceContentsToAttachment(v.Resource); attachment != nil {
+				attachments = append(attachments, attachment)
+			} else if text != "" {
+				parts = append(parts, text)
+			}
+		case *mcp.ResourceLink:
+			name := v.Title
+			if name == "" {
+				name = v.Name
+			}
+			attachments = append(attachments, &entity.Attachment{
+				Type:     attachmentType(v.MIMEType),
+				URL:      v.URI,
+				Name:     name,
+				MIMEType: v.MIMEType,
+			})
+		}
+	}
+	return strings.Join(parts, "\n"), attachments
+}
+
+// resourceContentsToAttachment 文本资源返回文本内容，二进制资源转换为附件
+func resourceContentsToAttachment(rc *mcp.ResourceContents) (string, *entity.Attachment) {
+	if rc == nil {
+		return "", nil
+	}
+	if rc.Blob == nil {
+		return rc.Text, nil
+	}
+	return "", &entity.Attachment{
+		Type:     attachmentType(rc.MIMEType),
+		URL:      dataURL(rc.MIMEType, rc.Blob),
+		Name:     path.Base(rc.URI),
+		MIMEType: rc.MIMEType,
+	}
+}
+
+// attachmentType 按 MIME 类型推断附件类型
+func attachmentType(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "image"
+	case strings.HasPrefix(mimeType, "audio/"):
+		return "audio"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "video"
+	default:
+		return "file"
+	}
+}
+
+// dataURL 将二进制内容编码为 data URL，便于各 Channel 直接内联展示或自行落盘
+func dataURL(mimeType string, data []byte) string {
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// GetDiscoveredResources 获取所有已连接 server 发现的资源（server 名 -> 资源列表）
+func (m *MCPManager) GetDiscoveredResources() map[string][]*mcp.Resource {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[string][]*mcp.Resource)
+	for name, state := range m.servers {
+		if state.Status == MCPServerStatusConnected && len(state.Resources) > 0 {
+			result[name] = state.Resources
+		}
+	}
+	return result
+}
+
+// GetDiscoveredPrompts 获取所有已连接 server 发现的提示词（server 名 -> 提示词列表）
+func (m *MCPManager) GetDiscoveredPrompts() map[string][]*mcp.Prompt {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[string][]*mcp.Prompt)
+	for name, state := range m.servers {
+		if state.Status == MCPServerStatusConnected && len(state.Prompts) > 0 {
+			result[name] = state.Prompts
+		}
+	}
+	return result
+}
+
 // GetDiscoveredTools 获取某 server 发现的工具列表
 func (m *MCPManager) GetDiscoveredTools(serverName string) ([]*mcp.Tool, error) {
 	m.mu.RLock()
@@ -277,7 +538,22 @@ func (m *MCPManager) Close() error {
 	return nil
 }
 
-// headerRoundTripper 在每个 HTTP 请求中注入自定义 headers（用于 SSE 认证）
+// remoteHTTPClient 为远程 server（sse / http）构造 HTTP 客户端
+// 用 entry.Env 作为本地变量上下文解析 headers 中的 ${VAR} 占位符；无 headers 时返回 nil 使用默认客户端
+func remoteHTTPClient(entry config.MCPServerEntry) *http.Client {
+	if len(entry.Headers) == 0 {
+		return nil
+	}
+	resolvedHeaders := config.ResolveEnvVarsWithContext(entry.Headers, entry.Env)
+	return &http.Client{
+		Transport: &headerRoundTripper{
+			base:    http.DefaultTransport,
+			headers: resolvedHeaders,
+		},
+	}
+}
+
+// headerRoundTripper 在每个 HTTP 请求中注入自定义 headers（用于远程 server 认证）
 type headerRoundTripper struct {
 	base    http.RoundTripper
 	headers map[string]string
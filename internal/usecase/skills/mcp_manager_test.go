@@ -0,0 +1,210 @@
+package skills
+
+import (
+	"context"
+	"mindx/internal/config"
+	"mindx/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type echoArgs struct {
+	Text string `json:"text"`
+}
+
+func newEchoMCPServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "echo-server", Version: "v0.0.1"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo", Description: "echo back"},
+		func(ctx context.Context, req *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: args.Text}}}, nil, nil
+		})
+	return server
+}
+
+// closeTestServer 关闭测试 server，先断开仍保持中的 SSE 长连接，避免 Close 阻塞
+func closeTestServer(ts *httptest.Server) {
+	ts.CloseClientConnections()
+	ts.Close()
+}
+
+// TestMCPManager_StreamableHTTP 验证 Streamable HTTP 传输：headers 注入、工具发现与调用
+func TestMCPManager_StreamableHTTP(t *testing.T) {
+	server := newEchoMCPServer()
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+
+	var authorized atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer secret" {
+			authorized.Add(1)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer closeTestServer(ts)
+
+	mgr := NewMCPManager(logging.GetSystemLogger())
+	defer mgr.Close()
+
+	entry := config.MCPServerEntry{
+		Type:    "streamable-http",
+		URL:     ts.URL,
+		Headers: map[string]string{"Authorization": "Bearer ${TOKEN}"},
+		Env:     map[string]string{"TOKEN": "secret"},
+		Enabled: true,
+	}
+	require.NoError(t, mgr.ConnectServer(context.Background(), "echo", entry))
+
+	tools, err := mgr.GetDiscoveredTools("echo")
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "echo", tools[0].Name)
+
+	result, _, err := mgr.CallTool(context.Background(), "echo", "echo", map[string]any{"text": "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result)
+	assert.Greater(t, authorized.Load(), int32(0))
+}
+
+// TestMCPManager_ReconnectOnSessionLost 验证远程 server 连接失效后触发 onSessionLost 回调
+func TestMCPManager_ReconnectOnSessionLost(t *testing.T) {
+	server := newEchoMCPServer()
+	ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	defer closeTestServer(ts)
+
+	mgr := NewMCPManager(logging.GetSystemLogger())
+	defer mgr.Close()
+
+	lost := make(chan string, 1)
+	mgr.SetOnSessionLost(func(name string, entry config.MCPServerEntry) {
+		lost <- name
+	})
+
+	entry := config.MCPServerEntry{Type: "http", URL: ts.URL, Enabled: true}
+	require.NoError(t, mgr.ConnectServer(context.Background(), "echo", entry))
+
+	// 标记为错误状态后再次调用，应触发一次重新连接
+	state, ok := mgr.GetServerState("echo")
+	require.True(t, ok)
+	mgr.mu.Lock()
+	state.Status = MCPServerStatusError
+	mgr.mu.Unlock()
+
+	_, _, err := mgr.CallTool(context.Background(), "echo", "echo", map[string]any{"text": "hi"})
+	require.Error(t, err)
+	assert.Equal(t, "echo", <-lost)
+
+	// 重连进行中不会重复触发
+	_, _, _ = mgr.CallTool(context.Background(), "echo", "echo", map[string]any{"text": "hi"})
+	assert.Empty(t, lost)
+}
+
+// newRichMCPServer 提供资源、提示词，以及返回图片和嵌入资源的工具
+func newRichMCPServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "rich-server", Version: "v0.0.1"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "snapshot", Description: "take a snapshot"},
+		func(ctx context.Context, req *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{
+				&mcp.TextContent{Text: "done"},
+				&mcp.ImageContent{Data: []byte("png-bytes"), MIMEType: "image/png"},
+				&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///notes.txt", MIMEType: "text/plain", Text: "embedded note"}},
+				&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///report.pdf", MIMEType: "application/pdf", Blob: []byte("pdf")}},
+			}}, nil, nil
+		})
+	server.AddResource(&mcp.Resource{
+		URI:         "file:///docs/deploy-guide.md",
+		Name:        "deploy-guide",
+		Description: "How to deploy the service to production",
+		MIMEType:    "text/markdown",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+			{URI: req.Params.URI, MIMEType: "text/markdown", Text: "run make deploy"},
+		}}, nil
+	})
+	server.AddPrompt(&mcp.Prompt{
+		Name:      "review",
+		Arguments: []*mcp.PromptArgument{{Name: "code", Required: true}},
+	}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: "You are a code reviewer."}},
+			{Role: "assistant", Content: &mcp.TextContent{Text: "OK"}},
+			{Role: "user", Content: &mcp.TextContent{Text: "Review: " + req.Params.Arguments["code"]}},
+		}}, nil
+	})
+	return server
+}
+
+// TestMCPManager_ResourcesPromptsAndAttachments 验证资源与提示词发现，以及非文本工具结果转换为附件
+func TestMCPManager_ResourcesPromptsAndAttachments(t *testing.T) {
+	server := newRichMCPServer()
+	ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	defer closeTestServer(ts)
+
+	tmpDir := t.TempDir()
+	mgr, err := NewSkillMgr(tmpDir, tmpDir, nil, nil, logging.GetSystemLogger())
+	require.NoError(t, err)
+	defer mgr.Close()
+
+	entry := config.MCPServerEntry{Type: "http", URL: ts.URL, Enabled: true}
+	require.NoError(t, mgr.mcpMgr.ConnectServer(context.Background(), "rich", entry))
+
+	state, ok := mgr.mcpMgr.GetServerState("rich")
+	require.True(t, ok)
+	require.Len(t, state.Resources, 1)
+	require.Len(t, state.Prompts, 1)
+
+	text, attachments, err := mgr.mcpMgr.CallTool(context.Background(), "rich", "snapshot", map[string]any{"text": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, "done\nembedded note", text)
+	require.Len(t, attachments, 2)
+	assert.Equal(t, "image", attachments[0].Type)
+	assert.Equal(t, "data:image/png;base64,cG5nLWJ5dGVz", attachments[0].URL)
+	assert.Equal(t, "file", attachments[1].Type)
+	assert.Equal(t, "report.pdf", attachments[1].Name)
+
+	// 问题提及资源名时检索到资源内容
+	resources, err := mgr.SearchMCPResources("请按 deploy-guide 说明部署", 2)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "run make deploy", resources[0].Text)
+
+	resources, err = mgr.SearchMCPResources("今天天气怎么样", 2)
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+
+	// 提示词按快捷指令展开
+	messages, found, err := mgr.ExpandMCPPrompt(MCPPromptShortcut("rich", "review"), "fmt.Println(1)")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Len(t, messages, 3)
+	assert.Equal(t, "assistant", messages[1].Role)
+	assert.Equal(t, "Review: fmt.Println(1)", messages[2].Content)
+
+	_, found, err = mgr.ExpandMCPPrompt(MCPPromptShortcut("rich", "review"), "")
+	assert.True(t, found)
+	assert.Error(t, err)
+
+	_, found, _ = mgr.ExpandMCPPrompt("writer", "")
+	assert.False(t, found)
+}
+
+func TestParsePromptArgs(t *testing.T) {
+	prompt := &mcp.Prompt{Name: "p", Arguments: []*mcp.PromptArgument{
+		{Name: "lang", Required: true},
+		{Name: "style"},
+	}}
+
+	args, err := parsePromptArgs(prompt, "lang=go style=terse")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"lang": "go", "style": "terse"}, args)
+
+	_, err = parsePromptArgs(prompt, "style=terse")
+	assert.Error(t, err)
+
+	_, err = parsePromptArgs(prompt, "go")
+	assert.Error(t, err)
+}
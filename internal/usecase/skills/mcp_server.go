@@ -0,0 +1,163 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mindx/internal/config"
+	"mindx/internal/core"
+	"mindx/internal/entity"
+	"mindx/internal/usecase/approval"
+	"mindx/pkg/logging"
+	"net/http"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MCPServer 将 MindX 的技能以 MCP server 的形式对外暴露
+// 包括 SKILL.md 外部技能、内置技能以及已连接 MCP server 转出的工具，
+// 调用统一经由 SkillExecutor.ExecuteFunc 执行，执行统计照常记录
+// MCP 调用方无法交互确认，需要审批的工具调用一律拒绝
+type MCPServer struct {
+	mgr      *SkillMgr
+	approval *approval.Manager
+	logger   logging.Logger
+	server   *mcp.Server
+
+	mu    sync.Mutex
+	tools map[string]string // 已注册的工具名 -> schema 签名，用于增量同步
+}
+
+// NewMCPServer 创建技能 MCP server，并注册当前已启用的技能
+// approvalMgr 判断工具调用是否需要确认，需要确认的调用以 IsError 结果拒绝
+func NewMCPServer(mgr *SkillMgr, approvalMgr *approval.Manager, logger logging.Logger) *MCPServer {
+	version, _, _ := config.GetBuildInfo()
+	if version == "" {
+		version = "dev"
+	}
+
+	s := &MCPServer{
+		mgr:      mgr,
+		approval: approvalMgr,
+		logger:   logger.Named("MCPServer"),
+		server: mcp.NewServer(&mcp.Implementation{
+			Name:    "mindx",
+			Version: version,
+		}, nil),
+		tools: make(map[string]string),
+	}
+	s.Sync()
+	return s
+}
+
+// Sync 将已启用的技能同步为 MCP 工具
+// 新增或变更的技能重新注册，已禁用或移除的技能从工具列表中删除；客户端会收到 tools/list_changed 通知
+func (s *MCPServer) Sync() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := make(map[string]bool)
+	for name, info := range s.mgr.GetSkillInfos() {
+		if info == nil || info.Def == nil || !info.Def.Enabled {
+			continue
+		}
+		current[name] = true
+
+		schema := SkillDefToInputSchema(info.Def)
+		data, _ := json.Marshal(schema)
+		signature := info.Def.Description + string(data)
+		if s.tools[name] == signature {
+			continue
+		}
+
+		s.server.AddTool(&mcp.Tool{
+			Name:        name,
+			Description: info.Def.Description,
+			InputSchema: schema,
+		}, s.toolHandler(name))
+		s.tools[name] = signature
+	}
+
+	var removed []string
+	for name := range s.tools {
+		if !current[name] {
+			removed = append(removed, name)
+			delete(s.tools, name)
+		}
+	}
+	if len(removed) > 0 {
+		s.server.RemoveTools(removed...)
+	}
+
+	s.logger.Debug("MCP 工具同步完成",
+		logging.Int("tools_count", len(s.tools)),
+		logging.Int("removed_count", len(removed)))
+}
+
+// toolHandler 构造技能对应的 MCP 工具处理函数
+// 技能执行失败时以 IsError 结果返回，而不是协议错误，便于调用方的模型自行处理
+func (s *MCPServer) toolHandler(name string) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := make(map[string]interface{})
+		if len(req.Params.Arguments) > 0 {
+			if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments for %s: %w", name, err)
+			}
+		}
+
+		info, ok := s.mgr.GetSkillInfo(name)
+		if !ok || !info.Def.Enabled {
+			return errorResult(fmt.Sprintf("skill not available: %s", name)), nil
+		}
+
+		if s.approval.RequiresApproval(name, info.Def, args) {
+			s.logger.Warn("MCP 客户端调用需要确认的技能，已拒绝", logging.String("skill", name))
+			s.approval.Deny(entity.SessionKey{ChannelID: "mcp"}, name, args, "mcp")
+			return errorResult(fmt.Sprintf("skill %s requires user approval and cannot be called via MCP", name)), nil
+		}
+
+		s.logger.Info("MCP 客户端调用技能", logging.String("skill", name))
+
+		result, err := s.mgr.executor.ExecuteFunc(ctx, core.ToolCallFunction{
+			Name:      name,
+			Arguments: args,
+		})
+		if err != nil {
+			if result != "" {
+				return errorResult(fmt.Sprintf("%s\n%s", err.Error(), result)), nil
+			}
+			return errorResult(err.Error()), nil
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, nil
+	}
+}
+
+func errorResult(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: message}},
+		IsError: true,
+	}
+}
+
+// Server 返回底层的 MCP server
+func (s *MCPServer) Server() *mcp.Server {
+	return s.server
+}
+
+// RunStdio 通过标准输入输出提供 MCP 服务，阻塞直到客户端断开或 ctx 取消
+func (s *MCPServer) RunStdio(ctx context.Context) error {
+	return s.server.Run(ctx, &mcp.StdioTransport{})
+}
+
+// HTTPHandler 返回 Streamable HTTP 传输的处理器
+// 每次建立会话前同步一次技能，使运行期间启用/禁用的技能和新连接的 MCP 工具及时生效
+func (s *MCPServer) HTTPHandler() http.Handler {
+	return mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		s.Sync()
+		return s.server
+	}, nil)
+}
@@ -0,0 +1,154 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"mindx/internal/config"
+	"mindx/internal/usecase/approval"
+	"mindx/pkg/logging"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const echoSkillMD = `---
+name: echo
+description: 原样返回输入的文本
+enabled: true
+timeout: 10
+is_internal: true
+parameters:
+  text:
+    type: string
+    description: 要返回的文本
+    required: true
+---
+`
+
+// TestMCPServer_ListAndCallTools 验证技能通过 MCP 暴露后可被列出和调用，并记录执行统计
+func TestMCPServer_ListAndCallTools(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillsDir := filepath.Join(tmpDir, "skills")
+	require.NoError(t, os.MkdirAll(filepath.Join(skillsDir, "echo"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillsDir, "echo", "SKILL.md"), []byte(echoSkillMD), 0644))
+
+	logger := logging.GetSystemLogger().Named("mcp_server_test")
+	mgr, err := NewSkillMgr(skillsDir, tmpDir, nil, nil, logger)
+	require.NoError(t, err)
+	defer mgr.Close()
+
+	mgr.RegisterInternalSkill("echo", func(_ context.Context, params map[string]any) (string, error) {
+		text, _ := params["text"].(string)
+		if text == "" {
+			return "", fmt.Errorf("text is required")
+		}
+		return text, nil
+	})
+
+	approvalMgr, err := approval.NewManager(config.ApprovalConfig{}, "", logger)
+	require.NoError(t, err)
+	server := NewMCPServer(mgr, approvalMgr, logger)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Server().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "echo", tools.Tools[0].Name)
+	schema, ok := tools.Tools[0].InputSchema.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, []any{"text"}, schema["required"])
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "echo",
+		Arguments: map[string]any{"text": "你好"},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "你好", extractTextContent(result.Content))
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	info, ok := mgr.GetSkillInfo("echo")
+	require.True(t, ok)
+	assert.Equal(t, 1, info.SuccessCount)
+	assert.Equal(t, 1, info.ErrorCount)
+
+	// 禁用后同步，工具从列表中移除
+	require.NoError(t, mgr.Disable("echo"))
+	server.Sync()
+	tools, err = session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, tools.Tools)
+}
+
+// TestMCPServer_RequiresApproval MCP 调用方无法交互确认，需要确认的工具调用直接拒绝且不执行
+func TestMCPServer_RequiresApproval(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillsDir := filepath.Join(tmpDir, "skills")
+	require.NoError(t, os.MkdirAll(filepath.Join(skillsDir, "echo"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillsDir, "echo", "SKILL.md"), []byte(echoSkillMD), 0644))
+
+	logger := logging.GetSystemLogger().Named("mcp_server_test")
+	mgr, err := NewSkillMgr(skillsDir, tmpDir, nil, nil, logger)
+	require.NoError(t, err)
+	defer mgr.Close()
+
+	var calls int
+	mgr.RegisterInternalSkill("echo", func(_ context.Context, params map[string]any) (string, error) {
+		calls++
+		text, _ := params["text"].(string)
+		return text, nil
+	})
+
+	auditPath := filepath.Join(tmpDir, "approval_audit.jsonl")
+	approvalMgr, err := approval.NewManager(config.ApprovalConfig{
+		Rules: []config.ApprovalRule{{Skill: "echo", Args: "rm -rf"}},
+	}, auditPath, logger)
+	require.NoError(t, err)
+	server := NewMCPServer(mgr, approvalMgr, logger)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Server().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"text": "rm -rf /"}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, extractTextContent(result.Content), "requires user approval")
+	assert.Equal(t, 0, calls)
+
+	records, err := approvalMgr.Audit().List(0)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, approval.DecisionDenied, records[0].Decision)
+	assert.Equal(t, "mcp", records[0].Operator)
+
+	// 未命中规则的调用照常执行
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"text": "你好"}})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, 1, calls)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"mindx/internal/entity"
+	"sort"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -129,3 +130,34 @@ func extractParameters(schema map[string]any, params map[string]entity.Parameter
 		}
 	}
 }
+
+// SkillDefToInputSchema 将 SkillDef 的参数定义转换为 MCP Tool 的 JSON Schema（extractParameters 的逆过程）
+func SkillDefToInputSchema(def *entity.SkillDef) map[string]any {
+	properties := make(map[string]any, len(def.Parameters))
+	required := make([]string, 0)
+
+	for name, param := range def.Parameters {
+		paramType := param.Type
+		if paramType == "" {
+			paramType = "string"
+		}
+		prop := map[string]any{"type": paramType}
+		if param.Description != "" {
+			prop["description"] = param.Description
+		}
+		properties[name] = prop
+		if param.Required {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
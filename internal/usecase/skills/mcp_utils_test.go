@@ -312,3 +312,30 @@ func TestGetMCPSkillMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestSkillDefToInputSchema_RoundTrip(t *testing.T) {
+	def := &entity.SkillDef{
+		Name: "weather",
+		Parameters: map[string]entity.ParameterDef{
+			"city": {Type: "string", Description: "城市名", Required: true},
+			"days": {Type: "integer", Description: "天数"},
+			"raw":  {Description: "未声明类型"},
+		},
+	}
+
+	schema := SkillDefToInputSchema(def)
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, []string{"city"}, schema["required"])
+
+	// 经 JSON 序列化后再解析回参数定义，应与原定义一致（未声明类型按 string 处理）
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	params := make(map[string]entity.ParameterDef)
+	extractParameters(decoded, params)
+	assert.Equal(t, entity.ParameterDef{Type: "string", Description: "城市名", Required: true}, params["city"])
+	assert.Equal(t, entity.ParameterDef{Type: "integer", Description: "天数"}, params["days"])
+	assert.Equal(t, "string", params["raw"].Type)
+}
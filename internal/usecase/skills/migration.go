@@ -0,0 +1,58 @@
+package skills
+
+import (
+	"mindx/internal/core"
+	"mindx/internal/usecase/embedding"
+	"strings"
+)
+
+// migrationSource 技能关键词向量的嵌入模型迁移数据源
+type migrationSource struct {
+	m *SkillMgr
+}
+
+// MigrationSource 返回技能的嵌入模型迁移数据源
+func (m *SkillMgr) MigrationSource() embedding.MigrationSource {
+	return migrationSource{m: m}
+}
+
+func (s migrationSource) Collection() string {
+	return core.CollectionSkills
+}
+
+func (s migrationSource) Store() core.Store {
+	return s.m.indexer.store
+}
+
+// StoredDimension 技能向量保存在条目的元数据中，维度取自已加载的关键词向量
+func (s migrationSource) StoredDimension() int {
+	return s.m.indexer.Dimension()
+}
+
+func (s migrationSource) ReembedKeys() ([]string, error) {
+	if s.m.indexer.store == nil {
+		return nil, nil
+	}
+	entries, err := s.m.indexer.store.Scan("skill_vector:")
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	return keys, nil
+}
+
+func (s migrationSource) Reembed(key string) error {
+	return s.m.indexer.Reembed(strings.TrimPrefix(key, "skill_vector:"))
+}
+
+// ReloadVectors 将新向量同步到检索器；缺少关键词而被删除索引的技能在后台重新索引
+func (s migrationSource) ReloadVectors() error {
+	s.m.syncComponents()
+	if len(s.m.indexer.GetVectors()) < len(s.m.loader.GetSkillInfos()) {
+		s.m.StartReIndexInBackground()
+	}
+	return nil
+}
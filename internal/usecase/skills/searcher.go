@@ -1,10 +1,13 @@
 package skills
 
 import (
+	"context"
 	"fmt"
 	"mindx/internal/core"
 	"mindx/internal/entity"
 	"mindx/internal/usecase/embedding"
+	"mindx/internal/usecase/rerank"
+	"mindx/pkg/hnsw"
 	"mindx/pkg/i18n"
 	"mindx/pkg/logging"
 	"sort"
@@ -19,8 +22,18 @@ type SkillSearcher struct {
 	skills             map[string]*core.Skill
 	skillInfos         map[string]*entity.SkillInfo
 	toolKeywordVectors map[string][][]float64
+	// vectorIndex 技能关键词向量的 ANN 索引，用于在技能较多时快速筛选候选
+	vectorIndex *hnsw.Index
+	// reranker 向量检索后的重排器，为 nil 时按相似度排序
+	reranker core.Reranker
 }
 
+// annCandidates 每个查询向量从 ANN 索引取回的近邻数
+const annCandidates = 32
+
+// rerankCandidates 交给重排器的候选技能数
+const rerankCandidates = 10
+
 func NewSkillSearcher(embedding *embedding.EmbeddingService, logger logging.Logger) *SkillSearcher {
 	return &SkillSearcher{
 		embedding:          embedding,
@@ -37,14 +50,71 @@ func (s *SkillSearcher) SetData(skills map[string]*core.Skill, infos map[string]
 	s.skills = skills
 	s.skillInfos = infos
 	s.toolKeywordVectors = vectors
+	s.vectorIndex = s.buildVectorIndex(vectors)
 }
 
-func (s *SkillSearcher) Search(keywords ...string) ([]*core.Skill, error) {
+// SetReranker 设置向量检索后的重排器，nil 表示不重排
+func (s *SkillSearcher) SetReranker(reranker core.Reranker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reranker = reranker
+}
+
+// buildVectorIndex 以 "技能名#序号" 为 key 建立关键词向量索引
+// 向量维度不一致时返回 nil，检索退回逐个比较
+func (s *SkillSearcher) buildVectorIndex(vectors map[string][][]float64) *hnsw.Index {
+	if len(vectors) == 0 {
+		return nil
+	}
+	idx := hnsw.New(hnsw.DefaultConfig())
+	for skillName, kwVectors := range vectors {
+		for i, vec := range kwVectors {
+			if err := idx.Add(fmt.Sprintf("%s#%d", skillName, i), vec); err != nil {
+				s.logger.Warn(i18n.T("skill.vector_index_failed"), logging.String("skill", skillName), logging.Err(err))
+				return nil
+			}
+		}
+	}
+	return idx
+}
+
+// candidateSkills 通过 ANN 索引取回与查询向量相近的技能；索引不可用时返回 nil 表示全部技能
+func (s *SkillSearcher) candidateSkills(queryVectors [][]float64) map[string]bool {
+	if s.vectorIndex == nil || s.vectorIndex.Len() == 0 {
+		return nil
+	}
+	candidates := make(map[string]bool)
+	for _, vec := range queryVectors {
+		if len(vec) != s.vectorIndex.Dim() {
+			return nil
+		}
+		for _, r := range s.vectorIndex.Search(vec, annCandidates, nil) {
+			if i := strings.LastIndex(r.Key, "#"); i > 0 {
+				candidates[r.Key[:i]] = true
+			}
+		}
+	}
+	return candidates
+}
+
+// Search 检索与关键词最相似的技能
+// 相似度在读锁内计算，重排可能请求模型，在释放锁后使用调用方的 ctx 进行
+func (s *SkillSearcher) Search(ctx context.Context, keywords ...string) ([]*core.Skill, error) {
+	matches, result, err := s.match(keywords)
+	if err != nil || matches == nil {
+		return result, err
+	}
+	return s.selectMatches(ctx, keywords, matches), nil
+}
+
+// match 在读锁内检索技能：向量检索时返回按相似度排序的候选，由调用方重排和筛选；
+// 无需重排时直接返回结果
+func (s *SkillSearcher) match(keywords []string) (*vectorMatches, []*core.Skill, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	if len(keywords) == 0 {
-		return s.getAllSkills(), nil
+		return nil, s.getAllSkills(), nil
 	}
 
 	s.logger.Debug(i18n.T("skill.search_start"),
@@ -58,7 +128,8 @@ func (s *SkillSearcher) Search(keywords ...string) ([]*core.Skill, error) {
 	}
 
 	s.logger.Warn(i18n.T("skill.fallback_to_keyword"))
-	return s.searchByKeywords(keywords)
+	result, err := s.searchByKeywords(keywords)
+	return nil, result, err
 }
 
 func (s *SkillSearcher) getAllSkills() []*core.Skill {
@@ -69,7 +140,24 @@ func (s *SkillSearcher) getAllSkills() []*core.Skill {
 	return result
 }
 
-func (s *SkillSearcher) searchByVector(keywords []string) ([]*core.Skill, error) {
+// scoredSkill 向量检索中技能与查询的相似度
+type scoredSkill struct {
+	skill      *core.Skill
+	skillName  string
+	score      float64
+	matchCount int
+}
+
+// vectorMatches 向量检索的候选技能，按相似度降序排列
+// docs 为交给 reranker 的前若干个候选，在读锁内生成，重排时不再访问技能数据
+type vectorMatches struct {
+	skills   []scoredSkill
+	docs     []core.RerankDocument
+	reranker core.Reranker
+}
+
+// searchByVector 按关键词向量计算每个技能的相似度，无法向量检索时退回关键词匹配并直接返回结果
+func (s *SkillSearcher) searchByVector(keywords []string) (*vectorMatches, []*core.Skill, error) {
 	s.logger.Info(i18n.T("skill.vector_search_start"),
 		logging.String("keywords", fmt.Sprintf("%v", keywords)),
 		logging.Int("indexed_skills", len(s.toolKeywordVectors)))
@@ -87,19 +175,18 @@ func (s *SkillSearcher) searchByVector(keywords []string) ([]*core.Skill, error)
 
 	if len(keywordVectors) == 0 {
 		s.logger.Warn(i18n.T("skill.no_search_vectors"))
-		return s.searchByKeywords(keywords)
-	}
-
-	type scoredSkill struct {
-		skill      *core.Skill
-		skillName  string
-		score      float64
-		matchCount int
+		result, err := s.searchByKeywords(keywords)
+		return nil, result, err
 	}
 
 	var scoredSkills []scoredSkill
 
+	candidates := s.candidateSkills(keywordVectors)
+
 	for skillName, skillKwVectors := range s.toolKeywordVectors {
+		if candidates != nil && !candidates[skillName] {
+			continue
+		}
 		if len(skillKwVectors) == 0 {
 			s.logger.Debug(i18n.T("skill.skill_no_vectors"), logging.String("skill", skillName))
 			continue
@@ -146,7 +233,8 @@ func (s *SkillSearcher) searchByVector(keywords []string) ([]*core.Skill, error)
 
 	if len(scoredSkills) == 0 {
 		s.logger.Debug(i18n.T("skill.no_skill_vectors"))
-		return s.searchByKeywords(keywords)
+		result, err := s.searchByKeywords(keywords)
+		return nil, result, err
 	}
 
 	sort.Slice(scoredSkills, func(i, j int) bool {
@@ -156,26 +244,52 @@ func (s *SkillSearcher) searchByVector(keywords []string) ([]*core.Skill, error)
 		return scoredSkills[i].matchCount > scoredSkills[j].matchCount
 	})
 
-	if len(scoredSkills) > 0 {
-		maxScore := scoredSkills[0].score
-		if maxScore < 0.6 {
-			topN := 3
-			if len(scoredSkills) < 3 {
-				topN = len(scoredSkills)
-			}
+	matches := &vectorMatches{skills: scoredSkills, reranker: s.reranker}
+	if s.reranker != nil {
+		n := len(scoredSkills)
+		if n > rerankCandidates {
+			n = rerankCandidates
+		}
+		matches.docs = make([]core.RerankDocument, n)
+		for i := 0; i < n; i++ {
+			matches.docs[i] = core.RerankDocument{ID: scoredSkills[i].skillName, Text: s.rerankText(scoredSkills[i].skillName), Score: scoredSkills[i].score}
+		}
+	}
+	return matches, nil, nil
+}
 
-			result := make([]*core.Skill, 0, topN)
-			for i := 0; i < topN; i++ {
-				result = append(result, scoredSkills[i].skill)
-			}
+// selectMatches 重排候选技能并筛选结果，不访问受锁保护的技能数据
+// 是否只返回最佳技能仍按向量相似度判断，重排只调整候选的先后顺序
+func (s *SkillSearcher) selectMatches(ctx context.Context, keywords []string, matches *vectorMatches) []*core.Skill {
+	scoredSkills := matches.skills
+	maxScore := scoredSkills[0].score
+	if matches.reranker != nil {
+		byName := make(map[string]scoredSkill, len(matches.docs))
+		for _, skill := range scoredSkills[:len(matches.docs)] {
+			byName[skill.skillName] = skill
+		}
+		for i, doc := range rerank.Apply(ctx, matches.reranker, strings.Join(keywords, " "), matches.docs, s.logger) {
+			scoredSkills[i] = byName[doc.ID]
+		}
+	}
 
-			s.logger.Debug(i18n.T("skill.vector_search_multi"),
-				logging.String("keywords", fmt.Sprintf("%v", keywords)),
-				logging.String("found", fmt.Sprintf("%d", len(result))),
-				logging.Float64("maxScore", maxScore))
+	if maxScore < 0.6 {
+		topN := 3
+		if len(scoredSkills) < 3 {
+			topN = len(scoredSkills)
+		}
 
-			return result, nil
+		result := make([]*core.Skill, 0, topN)
+		for i := 0; i < topN; i++ {
+			result = append(result, scoredSkills[i].skill)
 		}
+
+		s.logger.Debug(i18n.T("skill.vector_search_multi"),
+			logging.String("keywords", fmt.Sprintf("%v", keywords)),
+			logging.String("found", fmt.Sprintf("%d", len(result))),
+			logging.Float64("maxScore", maxScore))
+
+		return result
 	}
 
 	result := []*core.Skill{scoredSkills[0].skill}
@@ -184,7 +298,16 @@ func (s *SkillSearcher) searchByVector(keywords []string) ([]*core.Skill, error)
 		logging.String("keywords", fmt.Sprintf("%v", keywords)),
 		logging.Float64("score", scoredSkills[0].score))
 
-	return result, nil
+	return result
+}
+
+// rerankText 重排时代表技能的文本：名称、描述和标签
+func (s *SkillSearcher) rerankText(name string) string {
+	info := s.skillInfos[name]
+	if info == nil || info.Def == nil {
+		return name
+	}
+	return fmt.Sprintf("%s: %s %s", info.Def.Name, info.Def.Description, strings.Join(info.Def.Tags, " "))
 }
 
 func (s *SkillSearcher) searchByKeywords(keywords []string) ([]*core.Skill, error) {
@@ -1,11 +1,14 @@
 package skills
 
 import (
+	"context"
 	"mindx/internal/config"
 	"mindx/internal/core"
 	"mindx/internal/entity"
+	"mindx/internal/usecase/embedding"
 	"mindx/pkg/logging"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -117,7 +120,7 @@ func TestSearchByKeywords(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			results, err := searcher.Search(tc.keywords...)
+			results, err := searcher.Search(context.Background(), tc.keywords...)
 			require.NoError(t, err)
 
 			if tc.expectEmpty {
@@ -201,7 +204,7 @@ func TestSearchByKeywords_Scoring(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			results, err := searcher.Search(tc.keyword)
+			results, err := searcher.Search(context.Background(), tc.keyword)
 			require.NoError(t, err)
 			require.Greater(t, len(results), 0, "应有结果")
 			assert.Equal(t, tc.expect, results[0].GetName())
@@ -354,7 +357,7 @@ func TestIntentToToolSearch(t *testing.T) {
 			}
 			searchKeywords = append(searchKeywords, tc.keywords...)
 
-			results, err := searcher.Search(searchKeywords...)
+			results, err := searcher.Search(context.Background(), searchKeywords...)
 			require.NoError(t, err)
 
 			foundNames := make([]string, 0, len(results))
@@ -380,20 +383,87 @@ func TestSearchPriority(t *testing.T) {
 	searcher := newTestSearcher(t)
 
 	// "天气" 应该让 weather 排在第一位
-	results, err := searcher.Search("天气")
+	results, err := searcher.Search(context.Background(), "天气")
 	require.NoError(t, err)
 	require.NotEmpty(t, results)
 	assert.Equal(t, "weather", results[0].GetName(),
 		"搜索'天气'时 weather 应排第一")
 
 	// "A股" 应该让 finance 排在第一位
-	results, err = searcher.Search("A股")
+	results, err = searcher.Search(context.Background(), "A股")
 	require.NoError(t, err)
 	require.NotEmpty(t, results)
 	assert.Equal(t, "mcp_sina-finance_get-quote", results[0].GetName(),
 		"搜索'A股'时 finance 技能应排第一")
 }
 
+// fixedEmbedding 为所有文本返回相同的向量
+type fixedEmbedding struct{}
+
+func (fixedEmbedding) GenerateEmbedding(string) ([]float64, error) {
+	return []float64{1, 0}, nil
+}
+
+func (fixedEmbedding) GenerateBatchEmbeddings(texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i := range texts {
+		vectors[i] = []float64{1, 0}
+	}
+	return vectors, nil
+}
+
+type ctxKey struct{}
+
+// lockingReranker 重排时修改检索器的数据并反转候选顺序，记录收到的 ctx
+type lockingReranker struct {
+	searcher *SkillSearcher
+	ctx      context.Context
+}
+
+func (r *lockingReranker) Name() string { return "locking" }
+
+func (r *lockingReranker) Rerank(ctx context.Context, query string, docs []core.RerankDocument) ([]core.RerankDocument, error) {
+	r.ctx = ctx
+	r.searcher.SetReranker(r)
+	results := make([]core.RerankDocument, len(docs))
+	for i, doc := range docs {
+		results[len(docs)-1-i] = doc
+	}
+	return results, nil
+}
+
+func TestSearch_RerankOutsideLock(t *testing.T) {
+	_ = initTestLogging()
+	searcher := NewSkillSearcher(embedding.NewEmbeddingService(fixedEmbedding{}), logging.GetSystemLogger().Named("searcher_test"))
+	skills := map[string]*core.Skill{}
+	vectors := map[string][][]float64{}
+	for _, name := range []string{"weather", "calculator"} {
+		name := name
+		skills[name] = &core.Skill{GetName: func() string { return name }}
+		vectors[name] = [][]float64{{1, 0}}
+	}
+	searcher.SetData(skills, map[string]*entity.SkillInfo{}, vectors)
+	reranker := &lockingReranker{searcher: searcher}
+	searcher.SetReranker(reranker)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	done := make(chan []*core.Skill, 1)
+	go func() {
+		results, err := searcher.Search(ctx, "天气")
+		assert.NoError(t, err)
+		done <- results
+	}()
+
+	select {
+	case results := <-done:
+		require.Len(t, results, 1)
+		require.NotNil(t, reranker.ctx)
+		assert.Equal(t, "request", reranker.ctx.Value(ctxKey{}), "重排应使用调用方的 ctx")
+	case <-time.After(5 * time.Second):
+		t.Fatal("重排时仍持有检索器的读锁")
+	}
+}
+
 // initTestLogging 初始化测试日志（幂等）
 func initTestLogging() error {
 	logConfig := &config.LoggingConfig{
@@ -60,6 +60,7 @@ func NewSkillMgrWithStore(skillsDir string, workspaceDir string, embeddingSvc *e
 		envMgr:       envMgr,
 		mcpMgr:       mcpMgr,
 	}
+	mcpMgr.SetOnSessionLost(mgr.reconnectMCPServer)
 
 	if err := envMgr.LoadEnv(); err != nil {
 		logger.Warn(i18n.T("skill.load_env_failed"), logging.Err(err))
@@ -197,7 +198,7 @@ func (m *SkillMgr) Execute(skill *core.Skill, params map[string]any) error {
 		return fmt.Errorf("skill not found: %s", name)
 	}
 
-	_, err := m.executor.Execute(name, info.Def, params)
+	_, err := m.executor.Execute(context.Background(), name, info.Def, params)
 	return err
 }
 
@@ -207,26 +208,38 @@ func (m *SkillMgr) ExecuteByName(name string, params map[string]any) (string, er
 		return "", fmt.Errorf("skill not found: %s", name)
 	}
 
-	return m.executor.Execute(name, info.Def, params)
+	return m.executor.Execute(context.Background(), name, info.Def, params)
 }
 
-func (m *SkillMgr) ExecuteFunc(function core.ToolCallFunction) (string, error) {
+// ExecuteFunc 执行工具，ctx 取消时中止正在执行的技能
+func (m *SkillMgr) ExecuteFunc(ctx context.Context, function core.ToolCallFunction) (string, error) {
+	result, _, err := m.ExecuteFuncWithAttachments(ctx, function)
+	return result, err
+}
+
+// ExecuteFuncWithAttachments 执行工具，同时返回工具产出的附件（如 MCP 工具返回的图片）
+func (m *SkillMgr) ExecuteFuncWithAttachments(ctx context.Context, function core.ToolCallFunction) (string, []*entity.Attachment, error) {
 	m.logger.Info(i18n.T("skill.exec_func"),
 		logging.String(i18n.T("skill.function"), function.Name),
 		logging.Any(i18n.T("skill.arguments"), function.Arguments))
 
-	result, err := m.executor.ExecuteFunc(function)
+	result, attachments, err := m.executor.ExecuteFuncWithAttachments(ctx, function)
 	if err != nil {
 		m.logger.Error(i18n.T("skill.exec_func_failed"), logging.Err(err))
-		return "", err
+		return "", nil, err
 	}
 
 	m.logger.Info(i18n.T("skill.exec_func_success"), logging.String(i18n.T("skill.output"), result))
-	return result, nil
+	return result, attachments, nil
+}
+
+func (m *SkillMgr) SearchSkills(ctx context.Context, keywords ...string) ([]*core.Skill, error) {
+	return m.searcher.Search(ctx, keywords...)
 }
 
-func (m *SkillMgr) SearchSkills(keywords ...string) ([]*core.Skill, error) {
-	return m.searcher.Search(keywords...)
+// SetReranker 设置技能向量检索后的重排器，nil 表示不重排
+func (m *SkillMgr) SetReranker(reranker core.Reranker) {
+	m.searcher.SetReranker(reranker)
 }
 
 func (m *SkillMgr) ReIndex() error {
@@ -354,7 +367,7 @@ func (m *SkillMgr) GetMissingDependencies(name string) ([]string, []string, erro
 	return info.MissingBins, info.MissingEnv, nil
 }
 
-func (m *SkillMgr) RegisterInternalSkill(name string, fn func(params map[string]any) (string, error)) {
+func (m *SkillMgr) RegisterInternalSkill(name string, fn func(ctx context.Context, params map[string]any) (string, error)) {
 	m.executor.RegisterInternalSkill(name, fn)
 }
 
@@ -395,7 +408,7 @@ func (m *SkillMgr) InitMCPServers(ctx context.Context, cfg *config.MCPServersCon
 // mcpConnectTimeout 根据传输类型返回连接超时时间
 // stdio 类型使用 npx，冷启动需要下载+安装+启动，需要更长超时
 func mcpConnectTimeout(entry config.MCPServerEntry) time.Duration {
-	if entry.GetType() == "sse" {
+	if entry.IsRemote() {
 		return 30 * time.Second
 	}
 	return 120 * time.Second // stdio: npx 冷启动可能很慢
@@ -462,11 +475,25 @@ func isMCPRetryableError(err error) bool {
 		strings.Contains(msg, "connection refused") {
 		return true
 	}
+	// 远程 server 重启或网关暂不可用：值得重试
+	if strings.Contains(msg, "502 Bad Gateway") ||
+		strings.Contains(msg, "503 Service Unavailable") ||
+		strings.Contains(msg, "504 Gateway Timeout") {
+		return true
+	}
 	// EOF：子进程启动后立刻崩溃，重试无意义
 	// Method Not Allowed：协议不兼容，重试无意义
 	return false
 }
 
+// reconnectMCPServer 远程 MCP server 会话失效后重新连接并注册工具，沿用初始化时的重试策略
+func (m *SkillMgr) reconnectMCPServer(name string, entry config.MCPServerEntry) {
+	if _, ok := m.mcpMgr.GetServerState(name); !ok {
+		return // 已被移除
+	}
+	m.initMCPServerWithRetry(context.Background(), name, entry)
+}
+
 // connectAndRegisterMCP 连接 MCP server 并注册发现的工具
 func (m *SkillMgr) connectAndRegisterMCP(ctx context.Context, name string, entry config.MCPServerEntry) error {
 	if err := m.mcpMgr.ConnectServer(ctx, name, entry); err != nil {
@@ -1,6 +1,7 @@
 package skills
 
 import (
+	"context"
 	"mindx/internal/config"
 	"mindx/internal/core"
 	infraLlama "mindx/internal/infrastructure/llama"
@@ -105,7 +106,7 @@ func (s *SkillMgrIntegrationTestSuite) TestGetSkills() {
 
 // TestSearchSkills_NoKeywords 测试无关键词搜索（返回所有技能）
 func (s *SkillMgrIntegrationTestSuite) TestSearchSkills_NoKeywords() {
-	skills, err := s.mgr.SearchSkills()
+	skills, err := s.mgr.SearchSkills(context.Background())
 
 	assert.NoError(s.T(), err, "无关键词搜索应该成功")
 	assert.Greater(s.T(), len(skills), 0, "应该返回启用的技能")
@@ -168,7 +169,7 @@ func (s *SkillMgrIntegrationTestSuite) TestSearchSkills_WithKeywords() {
 
 	for _, tc := range testCases {
 		s.Run(tc.name, func() {
-			skills, err := s.mgr.SearchSkills(tc.keywords...)
+			skills, err := s.mgr.SearchSkills(context.Background(), tc.keywords...)
 
 			assert.NoError(s.T(), err, tc.description)
 
@@ -364,7 +365,7 @@ func (s *SkillMgrIntegrationTestSuite) TestConcurrentAccess() {
 		for i := 0; i < 10; i++ {
 			go func(idx int) {
 				keywords := []string{"weather", "calculator", "sysinfo"}
-				_, err := mgr.SearchSkills(keywords[idx%3])
+				_, err := mgr.SearchSkills(context.Background(), keywords[idx%3])
 				assert.NoError(s.T(), err)
 				done <- true
 			}(i)
@@ -1,6 +1,7 @@
 package skills
 
 import (
+	"context"
 	"mindx/internal/config"
 	infraEmbedding "mindx/internal/infrastructure/embedding"
 	infraLlama "mindx/internal/infrastructure/llama"
@@ -78,7 +79,7 @@ func TestVectorSearchPipeline_Health(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			results, err := mgr.SearchSkills(tc.keywords...)
+			results, err := mgr.SearchSkills(context.Background(), tc.keywords...)
 			require.NoError(t, err)
 
 			if len(results) == 0 {
@@ -0,0 +1,209 @@
+// Package bm25 实现基于倒排索引的 BM25 全文检索
+//
+// 分词兼顾中日韩文字：连续的字母、数字组成一个词（转为小写），
+// 连续的中日韩文字拆为单字和相邻二字组合，无需词典即可匹配人名、地名等专有名词。
+// 索引只保存在内存中，由调用方在启动时从持久化数据重建。
+package bm25
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Config BM25 参数
+type Config struct {
+	K1 float64 // 词频饱和度
+	B  float64 // 文档长度归一化程度
+}
+
+// DefaultConfig 返回常用的默认参数
+func DefaultConfig() Config {
+	return Config{K1: 1.2, B: 0.75}
+}
+
+// Result 检索结果
+type Result struct {
+	Key   string
+	Score float64
+}
+
+type document struct {
+	terms  map[string]int
+	length int
+}
+
+// Index BM25 倒排索引，并发安全
+type Index struct {
+	cfg Config
+
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string]int // term -> key -> 词频
+	totalLen int
+}
+
+// New 创建空索引
+func New(cfg Config) *Index {
+	return &Index{
+		cfg:      cfg,
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// Add 添加文档，key 已存在时替换原文档
+func (idx *Index) Add(key, text string) {
+	tokens := Tokenize(text)
+	terms := make(map[string]int, len(tokens))
+	for _, token := range tokens {
+		terms[token]++
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(key)
+	idx.docs[key] = &document{terms: terms, length: len(tokens)}
+	idx.totalLen += len(tokens)
+	for term, tf := range terms {
+		posting, ok := idx.postings[term]
+		if !ok {
+			posting = make(map[string]int)
+			idx.postings[term] = posting
+		}
+		posting[key] = tf
+	}
+}
+
+// Remove 删除文档，返回文档是否存在
+func (idx *Index) Remove(key string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.remove(key)
+}
+
+func (idx *Index) remove(key string) bool {
+	doc, ok := idx.docs[key]
+	if !ok {
+		return false
+	}
+	for term := range doc.terms {
+		posting := idx.postings[term]
+		delete(posting, key)
+		if len(posting) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLen -= doc.length
+	delete(idx.docs, key)
+	return true
+}
+
+// Len 返回文档数
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Search 返回与查询得分最高的 k 个文档（k <= 0 时返回全部命中的文档），按得分降序
+// filter 不为 nil 时只返回 filter 为 true 的文档
+func (idx *Index) Search(query string, k int, filter func(key string) bool) []Result {
+	queryTerms := make(map[string]bool)
+	for _, token := range Tokenize(query) {
+		queryTerms[token] = true
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := len(idx.docs)
+	if n == 0 || len(queryTerms) == 0 {
+		return nil
+	}
+	avgLen := float64(idx.totalLen) / float64(n)
+	if avgLen == 0 {
+		avgLen = 1
+	}
+
+	scores := make(map[string]float64)
+	for term := range queryTerms {
+		posting := idx.postings[term]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (float64(n)-df+0.5)/(df+0.5))
+		for key, tf := range posting {
+			if filter != nil && !filter(key) {
+				continue
+			}
+			norm := idx.cfg.K1 * (1 - idx.cfg.B + idx.cfg.B*float64(idx.docs[key].length)/avgLen)
+			scores[key] += idf * float64(tf) * (idx.cfg.K1 + 1) / (float64(tf) + norm)
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for key, score := range scores {
+		results = append(results, Result{Key: key, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Key < results[j].Key
+	})
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// Tokenize 将文本切分为检索词
+// 字母和数字组成的词转为小写，单个字母忽略；中日韩文字输出单字及相邻二字组合
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 1 || (len(word) == 1 && unicode.IsDigit(word[0])) {
+			tokens = append(tokens, strings.ToLower(string(word)))
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		for i, r := range cjk {
+			tokens = append(tokens, string(r))
+			if i+1 < len(cjk) {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
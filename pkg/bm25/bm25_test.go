@@ -0,0 +1,78 @@
+package bm25
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello, World!", []string{"hello", "world"}},
+		{"iPhone 15 Pro", []string{"iphone", "15", "pro"}},
+		{"a b 7", []string{"7"}},
+		{"张三", []string{"张", "张三", "三"}},
+		{"我用Go语言", []string{"我", "我用", "用", "go", "语", "语言", "言"}},
+		{"住在上海。", []string{"住", "住在", "在", "在上", "上", "上海", "海"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, Tokenize(tt.text))
+		})
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := New(DefaultConfig())
+	idx.Add("go", "用户喜欢用 Go 语言编程")
+	idx.Add("shanghai", "用户住在上海浦东")
+	idx.Add("phone", "用户的手机号是 13800138000")
+	idx.Add("tea", "用户喜欢喝绿茶，不喜欢咖啡")
+	require.Equal(t, 4, idx.Len())
+
+	results := idx.Search("浦东", 10, nil)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "shanghai", results[0].Key)
+
+	results = idx.Search("13800138000", 10, nil)
+	require.Len(t, results, 1)
+	assert.Equal(t, "phone", results[0].Key)
+
+	results = idx.Search("喜欢什么", 1, nil)
+	require.Len(t, results, 1)
+	assert.Contains(t, []string{"go", "tea"}, results[0].Key)
+
+	assert.Empty(t, idx.Search("basketball", 10, nil))
+	assert.Empty(t, idx.Search("", 10, nil))
+
+	// 较短的文档得分更高
+	idx.Add("long", "编程 "+strings.Repeat("其他内容 ", 50))
+	results = idx.Search("编程", 10, nil)
+	require.Len(t, results, 2)
+	assert.Equal(t, "go", results[0].Key)
+}
+
+func TestIndex_ReplaceRemoveFilter(t *testing.T) {
+	idx := New(DefaultConfig())
+	idx.Add("a", "apple banana")
+	idx.Add("b", "banana cherry")
+
+	idx.Add("a", "durian")
+	assert.Equal(t, 2, idx.Len())
+	assert.Empty(t, idx.Search("apple", 10, nil), "替换后旧内容不再命中")
+
+	results := idx.Search("banana durian", 10, func(key string) bool { return key != "b" })
+	require.Len(t, results, 1)
+	assert.Equal(t, "a", results[0].Key)
+
+	assert.True(t, idx.Remove("a"))
+	assert.False(t, idx.Remove("a"))
+	assert.Empty(t, idx.Search("durian", 10, nil))
+	assert.Equal(t, 1, idx.Len())
+}
@@ -0,0 +1,460 @@
+// Package hnsw 实现基于 HNSW（Hierarchical Navigable Small World）图的近似最近邻索引
+//
+// 相似度使用余弦相似度：向量写入时归一化为 float32，距离为 1 - 点积。
+// 删除采用墓碑标记，墓碑占比过高时自动重建图。
+package hnsw
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// ErrDimensionMismatch 向量维度与索引维度不一致
+var ErrDimensionMismatch = errors.New("vector dimension mismatch")
+
+// Config 索引参数
+type Config struct {
+	M              int // 每个节点在上层的最大邻居数，第 0 层为 2M
+	EfConstruction int // 构建时的候选集大小
+	EfSearch       int // 查询时的候选集大小，不小于 topK
+}
+
+// DefaultConfig 返回默认参数
+func DefaultConfig() Config {
+	return Config{M: 16, EfConstruction: 200, EfSearch: 64}
+}
+
+// Result 查询结果
+type Result struct {
+	Key   string
+	Score float64 // 余弦相似度
+}
+
+type node struct {
+	Key     string
+	Vec     []float32
+	Links   [][]uint32
+	Deleted bool
+}
+
+// Index HNSW 索引，并发安全
+type Index struct {
+	mu        sync.RWMutex
+	cfg       Config
+	dim       int
+	nodes     []*node
+	keys      map[string]uint32
+	entry     int
+	maxLevel  int
+	deleted   int
+	levelMult float64
+	rng       *rand.Rand
+}
+
+// New 创建空索引
+func New(cfg Config) *Index {
+	def := DefaultConfig()
+	if cfg.M <= 1 {
+		cfg.M = def.M
+	}
+	if cfg.EfConstruction <= 0 {
+		cfg.EfConstruction = def.EfConstruction
+	}
+	if cfg.EfSearch <= 0 {
+		cfg.EfSearch = def.EfSearch
+	}
+	return &Index{
+		cfg:       cfg,
+		keys:      make(map[string]uint32),
+		entry:     -1,
+		levelMult: 1 / math.Log(float64(cfg.M)),
+		rng:       rand.New(rand.NewSource(42)),
+	}
+}
+
+// Len 返回有效（未删除）向量数
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.keys)
+}
+
+// Dim 返回索引维度，没有有效向量时为 0
+func (x *Index) Dim() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if len(x.keys) == 0 {
+		return 0
+	}
+	return x.dim
+}
+
+// Has 是否包含 key
+func (x *Index) Has(key string) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	_, ok := x.keys[key]
+	return ok
+}
+
+// Add 写入向量，key 已存在时替换
+func (x *Index) Add(key string, vec []float64) error {
+	if len(vec) == 0 {
+		return fmt.Errorf("empty vector")
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if len(x.keys) == 0 && x.deleted == len(x.nodes) {
+		// 索引中没有有效向量时允许切换维度
+		x.reset(len(vec))
+	}
+	if len(vec) != x.dim {
+		return fmt.Errorf("%w: index %d, vector %d", ErrDimensionMismatch, x.dim, len(vec))
+	}
+
+	if id, ok := x.keys[key]; ok {
+		x.markDeleted(id)
+	}
+	x.insert(&node{Key: key, Vec: normalize(vec)})
+	x.maybeCompact()
+	return nil
+}
+
+// Remove 删除向量，返回 key 是否存在
+func (x *Index) Remove(key string) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	id, ok := x.keys[key]
+	if !ok {
+		return false
+	}
+	x.markDeleted(id)
+	x.maybeCompact()
+	return true
+}
+
+// Search 返回与 query 最相似的 k 个向量，按相似度降序
+// filter 不为 nil 时只返回 filter 返回 true 的 key
+func (x *Index) Search(query []float64, k int, filter func(key string) bool) []Result {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if k <= 0 || x.entry < 0 || len(query) != x.dim {
+		return nil
+	}
+
+	q := normalize(query)
+	ef := x.cfg.EfSearch
+	if ef < k {
+		ef = k
+	}
+
+	for {
+		candidates := x.searchFromTop(q, ef)
+		results := make([]Result, 0, k)
+		for _, c := range candidates {
+			n := x.nodes[c.id]
+			if n.Deleted || (filter != nil && !filter(n.Key)) {
+				continue
+			}
+			results = append(results, Result{Key: n.Key, Score: 1 - float64(c.dist)})
+			if len(results) == k {
+				return results
+			}
+		}
+		// 墓碑或过滤条件导致结果不足时扩大候选集，直至覆盖整个图
+		if ef >= len(x.nodes) {
+			return results
+		}
+		ef *= 2
+	}
+}
+
+// Save 将索引序列化写入 w
+func (x *Index) Save(w io.Writer) error {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return gob.NewEncoder(w).Encode(snapshot{
+		Cfg:      x.cfg,
+		Dim:      x.dim,
+		Nodes:    x.nodes,
+		Entry:    x.entry,
+		MaxLevel: x.maxLevel,
+	})
+}
+
+// Load 从 r 读取 Save 写入的索引
+func Load(r io.Reader) (*Index, error) {
+	var snap snapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, err
+	}
+
+	x := New(snap.Cfg)
+	x.dim = snap.Dim
+	x.nodes = snap.Nodes
+	x.entry = snap.Entry
+	x.maxLevel = snap.MaxLevel
+	for id, n := range x.nodes {
+		if n.Deleted {
+			x.deleted++
+			continue
+		}
+		x.keys[n.Key] = uint32(id)
+	}
+	if x.entry >= len(x.nodes) {
+		return nil, fmt.Errorf("corrupted index: entry point out of range")
+	}
+	return x, nil
+}
+
+type snapshot struct {
+	Cfg      Config
+	Dim      int
+	Nodes    []*node
+	Entry    int
+	MaxLevel int
+}
+
+func (x *Index) reset(dim int) {
+	x.dim = dim
+	x.nodes = nil
+	x.keys = make(map[string]uint32)
+	x.entry = -1
+	x.maxLevel = 0
+	x.deleted = 0
+}
+
+func (x *Index) markDeleted(id uint32) {
+	n := x.nodes[id]
+	if n.Deleted {
+		return
+	}
+	n.Deleted = true
+	delete(x.keys, n.Key)
+	x.deleted++
+}
+
+// maybeCompact 墓碑多于有效节点时重建图，释放已删除节点
+func (x *Index) maybeCompact() {
+	if x.deleted < 64 || x.deleted < len(x.keys) {
+		return
+	}
+	live := make([]*node, 0, len(x.keys))
+	for _, n := range x.nodes {
+		if !n.Deleted {
+			live = append(live, &node{Key: n.Key, Vec: n.Vec})
+		}
+	}
+	x.reset(x.dim)
+	for _, n := range live {
+		x.insert(n)
+	}
+}
+
+func (x *Index) randomLevel() int {
+	return int(math.Floor(-math.Log(1-x.rng.Float64()) * x.levelMult))
+}
+
+func (x *Index) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * x.cfg.M
+	}
+	return x.cfg.M
+}
+
+func (x *Index) insert(n *node) {
+	level := x.randomLevel()
+	n.Links = make([][]uint32, level+1)
+	id := uint32(len(x.nodes))
+	x.nodes = append(x.nodes, n)
+	x.keys[n.Key] = id
+
+	if x.entry < 0 {
+		x.entry = int(id)
+		x.maxLevel = level
+		return
+	}
+
+	ep := candidate{id: uint32(x.entry), dist: distance(n.Vec, x.nodes[x.entry].Vec)}
+	for lc := x.maxLevel; lc > level; lc-- {
+		ep = x.greedy(n.Vec, ep, lc)
+	}
+
+	eps := []candidate{ep}
+	for lc := min(level, x.maxLevel); lc >= 0; lc-- {
+		found := x.searchLayer(n.Vec, eps, x.cfg.EfConstruction, lc)
+		neighbors := found
+		if len(neighbors) > x.cfg.M {
+			neighbors = neighbors[:x.cfg.M]
+		}
+		n.Links[lc] = make([]uint32, 0, len(neighbors))
+		for _, nb := range neighbors {
+			n.Links[lc] = append(n.Links[lc], nb.id)
+			x.connect(nb.id, id, lc)
+		}
+		eps = found
+	}
+
+	if level > x.maxLevel {
+		x.entry = int(id)
+		x.maxLevel = level
+	}
+}
+
+// connect 为 from 增加指向 to 的边，超过上限时只保留最近的邻居
+func (x *Index) connect(from, to uint32, level int) {
+	n := x.nodes[from]
+	n.Links[level] = append(n.Links[level], to)
+	limit := x.maxLinks(level)
+	if len(n.Links[level]) <= limit {
+		return
+	}
+
+	cs := make([]candidate, 0, len(n.Links[level]))
+	for _, id := range n.Links[level] {
+		cs = append(cs, candidate{id: id, dist: distance(n.Vec, x.nodes[id].Vec)})
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].dist < cs[j].dist })
+	links := make([]uint32, 0, limit)
+	for _, c := range cs[:limit] {
+		links = append(links, c.id)
+	}
+	n.Links[level] = links
+}
+
+func (x *Index) greedy(q []float32, ep candidate, level int) candidate {
+	for changed := true; changed; {
+		changed = false
+		for _, id := range x.nodes[ep.id].Links[level] {
+			if d := distance(q, x.nodes[id].Vec); d < ep.dist {
+				ep = candidate{id: id, dist: d}
+				changed = true
+			}
+		}
+	}
+	return ep
+}
+
+func (x *Index) searchFromTop(q []float32, ef int) []candidate {
+	ep := candidate{id: uint32(x.entry), dist: distance(q, x.nodes[x.entry].Vec)}
+	for lc := x.maxLevel; lc > 0; lc-- {
+		ep = x.greedy(q, ep, lc)
+	}
+	return x.searchLayer(q, []candidate{ep}, ef, 0)
+}
+
+// searchLayer 在指定层搜索 ef 个最近的节点，按距离升序返回
+func (x *Index) searchLayer(q []float32, eps []candidate, ef, level int) []candidate {
+	visited := make(map[uint32]struct{}, ef*4)
+	cands := &minHeap{}
+	results := &maxHeap{}
+	for _, ep := range eps {
+		if _, ok := visited[ep.id]; ok {
+			continue
+		}
+		visited[ep.id] = struct{}{}
+		heap.Push(cands, ep)
+		heap.Push(results, ep)
+	}
+	for results.Len() > ef {
+		heap.Pop(results)
+	}
+
+	for cands.Len() > 0 {
+		c := heap.Pop(cands).(candidate)
+		if results.Len() >= ef && c.dist > (*results)[0].dist {
+			break
+		}
+		links := x.nodes[c.id].Links
+		if level >= len(links) {
+			continue
+		}
+		for _, id := range links[level] {
+			if _, ok := visited[id]; ok {
+				continue
+			}
+			visited[id] = struct{}{}
+			d := distance(q, x.nodes[id].Vec)
+			if results.Len() < ef || d < (*results)[0].dist {
+				heap.Push(cands, candidate{id: id, dist: d})
+				heap.Push(results, candidate{id: id, dist: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	out := make([]candidate, results.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(results).(candidate)
+	}
+	return out
+}
+
+func normalize(vec []float64) []float32 {
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	out := make([]float32, len(vec))
+	if norm == 0 {
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for i, v := range vec {
+		out[i] = float32(v / norm)
+	}
+	return out
+}
+
+func distance(a, b []float32) float32 {
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot
+}
+
+type candidate struct {
+	id   uint32
+	dist float32
+}
+
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(v any)        { *h = append(*h, v.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return v
+}
+
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].dist > h[j].dist }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(v any)        { *h = append(*h, v.(candidate)) }
+func (h *maxHeap) Pop() any {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return v
+}
@@ -0,0 +1,174 @@
+package hnsw
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomVectors(n, dim int, seed int64) map[string][]float64 {
+	rng := rand.New(rand.NewSource(seed))
+	vecs := make(map[string][]float64, n)
+	for i := 0; i < n; i++ {
+		v := make([]float64, dim)
+		for j := range v {
+			v[j] = rng.NormFloat64()
+		}
+		vecs[fmt.Sprintf("key_%d", i)] = v
+	}
+	return vecs
+}
+
+func cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func bruteForce(vecs map[string][]float64, q []float64, k int) []string {
+	type scored struct {
+		key   string
+		score float64
+	}
+	all := make([]scored, 0, len(vecs))
+	for key, v := range vecs {
+		all = append(all, scored{key, cosine(q, v)})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].score > all[j].score })
+	keys := make([]string, 0, k)
+	for _, s := range all[:k] {
+		keys = append(keys, s.key)
+	}
+	return keys
+}
+
+func recall(t *testing.T, idx *Index, vecs map[string][]float64, queries [][]float64, k int) float64 {
+	hits := 0
+	for _, q := range queries {
+		want := make(map[string]bool)
+		for _, key := range bruteForce(vecs, q, k) {
+			want[key] = true
+		}
+		results := idx.Search(q, k, nil)
+		require.Len(t, results, k)
+		for _, r := range results {
+			if want[r.Key] {
+				hits++
+			}
+		}
+	}
+	return float64(hits) / float64(len(queries)*k)
+}
+
+func TestIndex_Recall(t *testing.T) {
+	vecs := randomVectors(3000, 32, 1)
+	idx := New(DefaultConfig())
+	for key, v := range vecs {
+		require.NoError(t, idx.Add(key, v))
+	}
+	assert.Equal(t, len(vecs), idx.Len())
+
+	queries := make([][]float64, 0, 50)
+	for _, v := range randomVectors(50, 32, 2) {
+		queries = append(queries, v)
+	}
+	assert.GreaterOrEqual(t, recall(t, idx, vecs, queries, 10), 0.9)
+
+	results := idx.Search(vecs["key_7"], 1, nil)
+	require.Len(t, results, 1)
+	assert.Equal(t, "key_7", results[0].Key)
+	assert.InDelta(t, 1.0, results[0].Score, 1e-5)
+}
+
+func TestIndex_RemoveAndReplace(t *testing.T) {
+	vecs := randomVectors(500, 16, 3)
+	idx := New(DefaultConfig())
+	for key, v := range vecs {
+		require.NoError(t, idx.Add(key, v))
+	}
+
+	// 删除一半，触发墓碑压缩
+	for i := 0; i < 300; i++ {
+		key := fmt.Sprintf("key_%d", i)
+		assert.True(t, idx.Remove(key))
+		delete(vecs, key)
+	}
+	assert.False(t, idx.Remove("key_0"))
+	assert.Equal(t, 200, idx.Len())
+
+	for _, r := range idx.Search(randomVectors(1, 16, 4)["key_0"], 20, nil) {
+		_, ok := vecs[r.Key]
+		assert.True(t, ok, "deleted key %s returned", r.Key)
+	}
+
+	// 替换已有 key 的向量
+	replacement := vecs["key_400"]
+	require.NoError(t, idx.Add("key_450", replacement))
+	assert.Equal(t, 200, idx.Len())
+	results := idx.Search(replacement, 2, nil)
+	require.Len(t, results, 2)
+	assert.ElementsMatch(t, []string{"key_400", "key_450"}, []string{results[0].Key, results[1].Key})
+}
+
+func TestIndex_Filter(t *testing.T) {
+	vecs := randomVectors(400, 8, 5)
+	idx := New(DefaultConfig())
+	for key, v := range vecs {
+		require.NoError(t, idx.Add(key, v))
+	}
+
+	results := idx.Search(vecs["key_1"], 5, func(key string) bool {
+		return strings.HasSuffix(key, "7")
+	})
+	require.Len(t, results, 5)
+	for _, r := range results {
+		assert.True(t, strings.HasSuffix(r.Key, "7"))
+	}
+}
+
+func TestIndex_Dimension(t *testing.T) {
+	idx := New(DefaultConfig())
+	require.NoError(t, idx.Add("a", []float64{1, 0, 0}))
+	assert.ErrorIs(t, idx.Add("b", []float64{1, 0}), ErrDimensionMismatch)
+	assert.Nil(t, idx.Search([]float64{1, 0}, 1, nil))
+	assert.Error(t, idx.Add("c", nil))
+
+	// 清空后维度为 0，允许切换维度
+	idx.Remove("a")
+	assert.Equal(t, 0, idx.Dim())
+	require.NoError(t, idx.Add("b", []float64{1, 0}))
+	assert.Equal(t, 2, idx.Dim())
+}
+
+func TestIndex_SaveLoad(t *testing.T) {
+	vecs := randomVectors(300, 16, 6)
+	idx := New(DefaultConfig())
+	for key, v := range vecs {
+		require.NoError(t, idx.Add(key, v))
+	}
+	idx.Remove("key_3")
+
+	var buf bytes.Buffer
+	require.NoError(t, idx.Save(&buf))
+	loaded, err := Load(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, idx.Len(), loaded.Len())
+	assert.False(t, loaded.Has("key_3"))
+	q := vecs["key_10"]
+	assert.Equal(t, idx.Search(q, 10, nil), loaded.Search(q, 10, nil))
+
+	_, err = Load(bytes.NewReader([]byte("not an index")))
+	assert.Error(t, err)
+}